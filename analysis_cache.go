@@ -0,0 +1,174 @@
+// analysis_cache.go - Space cost analysis cache for the DevOps SDK
+//
+// A cost → waste → optimize run analyzes the same space several times, and
+// each AnalyzeSpace lists, parses and prices every unit. An AnalysisCache
+// shared by the analyzers of a run returns the first analysis until it
+// expires or the space's units change.
+//
+// Example:
+//
+//	cache := NewAnalysisCache(5 * time.Minute)
+//	costAnalyzer.SetCache(cache)
+//	wasteAnalyzer.SetCache(cache)
+//	costAnalyzer.AnalyzeSpace()         // lists units
+//	wasteAnalyzer.AnalyzeWaste(metrics) // reuses the cached analysis
+package sdk
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAnalysisCacheTTL is how long cached analyses are used when
+// NewAnalysisCache is given no TTL
+const DefaultAnalysisCacheTTL = 5 * time.Minute
+
+// ContentVersionFunc returns a value that changes whenever the units of a
+// space change. Cached analyses are only reused while it is unchanged.
+type ContentVersionFunc func(ctx context.Context, spaceID uuid.UUID) (string, error)
+
+// AnalysisCache holds recent AnalyzeSpace results keyed by space ID and
+// content version. It is safe for concurrent use and may be shared between
+// analyzers with the same settings; entries are also keyed by pricing model.
+type AnalysisCache struct {
+	ttl     time.Duration
+	version ContentVersionFunc // nil derives the version from the space's units
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[analysisCacheKey]*analysisCacheEntry
+	hits    int
+	misses  int
+}
+
+type analysisCacheKey struct {
+	spaceID uuid.UUID
+	version string
+	pricing *PricingModel
+}
+
+type analysisCacheEntry struct {
+	analysis *SpaceCostAnalysis
+	expires  time.Time
+}
+
+// NewAnalysisCache creates a cache whose entries expire after ttl
+// (DefaultAnalysisCacheTTL if zero)
+func NewAnalysisCache(ttl time.Duration) *AnalysisCache {
+	if ttl <= 0 {
+		ttl = DefaultAnalysisCacheTTL
+	}
+	return &AnalysisCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[analysisCacheKey]*analysisCacheEntry),
+	}
+}
+
+// SetContentVersion replaces how a space's content version is determined.
+// By default it is derived from the IDs and Versions of the space's units,
+// so editing, applying, adding or deleting a unit changes it; the units are
+// listed but none of their manifests are parsed.
+func (c *AnalysisCache) SetContentVersion(version ContentVersionFunc) {
+	c.version = version
+}
+
+// Invalidate drops every cached analysis of spaceID
+func (c *AnalysisCache) Invalidate(spaceID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.spaceID == spaceID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Stats returns how many lookups were served from the cache and how many
+// had to analyze the space
+func (c *AnalysisCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// get returns a copy of a live cached analysis, or nil
+func (c *AnalysisCache) get(key analysisCacheKey) *SpaceCostAnalysis {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && c.now().After(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	return copyCostAnalysis(entry.analysis)
+}
+
+// put caches a copy of analysis, replacing older versions of the space
+func (c *AnalysisCache) put(key analysisCacheKey, analysis *SpaceCostAnalysis) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for existing := range c.entries {
+		if existing.spaceID == key.spaceID && existing.pricing == key.pricing {
+			delete(c.entries, existing)
+		}
+	}
+	c.entries[key] = &analysisCacheEntry{
+		analysis: copyCostAnalysis(analysis),
+		expires:  c.now().Add(c.ttl),
+	}
+}
+
+// contentVersion returns the space's current content version
+func (c *AnalysisCache) contentVersion(ctx context.Context, cub *ConfigHubClient, spaceID uuid.UUID) (string, error) {
+	if c.version != nil {
+		return c.version(ctx, spaceID)
+	}
+	units, err := cub.ListAllUnitsContext(ctx, ListUnitsParams{SpaceID: spaceID})
+	if err != nil {
+		return "", err
+	}
+	return unitsContentVersion(units), nil
+}
+
+// unitsContentVersion hashes the units' IDs and Versions, which ConfigHub
+// bumps on every change to a unit, independent of list order
+func unitsContentVersion(units []*Unit) string {
+	entries := make([]string, len(units))
+	for i, unit := range units {
+		entries[i] = unit.UnitID.String() + ":" + strconv.FormatInt(unit.Version, 10)
+	}
+	sort.Strings(entries)
+
+	hash := sha256.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry + "\n"))
+	}
+	return strconv.Itoa(len(units)) + "@" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// copyCostAnalysis copies an analysis deeply enough that callers changing
+// its units, budgets or environments don't change the cached one
+func copyCostAnalysis(analysis *SpaceCostAnalysis) *SpaceCostAnalysis {
+	copied := *analysis
+	copied.Units = append([]UnitCostEstimate(nil), analysis.Units...)
+	copied.Environments = make(map[string]*SpaceCostAnalysis, len(analysis.Environments))
+	for env, envAnalysis := range analysis.Environments {
+		copied.Environments[env] = copyCostAnalysis(envAnalysis)
+	}
+	return &copied
+}
@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test reusing cached analyses across analyzers until the space changes
+func TestAnalysisCache(t *testing.T) {
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 2\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: 500m\n            memory: 512Mi\n"
+	spaceID := uuid.New()
+	units := []Unit{{UnitID: uuid.New(), Slug: "web", Data: deployment, Version: 1}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped := make([]map[string]interface{}, len(units))
+		for i, unit := range units {
+			wrapped[i] = map[string]interface{}{"Unit": unit}
+		}
+		json.NewEncoder(w).Encode(wrapped)
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+
+	cache := NewAnalysisCache(time.Minute)
+	ca := NewCostAnalyzer(app, spaceID)
+	ca.SetCache(cache)
+	wa := NewWasteAnalyzer(app, spaceID)
+	wa.SetCache(cache)
+
+	first, err := ca.AnalyzeSpace()
+	require.NoError(t, err)
+	first.Units[0].MonthlyCost = 0 // Changing a result must not change the cache
+
+	_, err = wa.AnalyzeWaste(nil)
+	require.NoError(t, err)
+	second, err := ca.AnalyzeSpace()
+	require.NoError(t, err)
+	assert.Greater(t, second.Units[0].MonthlyCost, 0.0)
+	hits, misses := cache.Stats()
+	assert.Equal(t, 2, hits, "later analyses are served from the cache")
+	assert.Equal(t, 1, misses)
+
+	// Editing or applying a unit bumps its Version, not the space's
+	units[0].Version = 2
+	units[0].Data = strings.Replace(deployment, "replicas: 2", "replicas: 4", 1)
+	third, err := ca.AnalyzeSpace()
+	require.NoError(t, err)
+	assert.InDelta(t, 2*second.Units[0].MonthlyCost, third.Units[0].MonthlyCost, 0.01, "a changed unit is analyzed again")
+
+	units = append(units, Unit{UnitID: uuid.New(), Slug: "worker", Data: deployment, Version: 1})
+	fourth, err := ca.AnalyzeSpace()
+	require.NoError(t, err)
+	assert.Len(t, fourth.Units, 2, "an added unit is analyzed")
+
+	units = units[1:]
+	fifth, err := ca.AnalyzeSpace()
+	require.NoError(t, err)
+	assert.Len(t, fifth.Units, 1, "a deleted unit is dropped")
+	_, misses = cache.Stats()
+	assert.Equal(t, 4, misses)
+
+	ca.InvalidateCache(spaceID)
+	_, err = ca.AnalyzeSpace()
+	require.NoError(t, err)
+	_, misses = cache.Stats()
+	assert.Equal(t, 5, misses, "invalidated analyses are not reused")
+
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = ca.AnalyzeSpace()
+	require.NoError(t, err)
+	_, misses = cache.Stats()
+	assert.Equal(t, 6, misses, "expired analyses are not reused")
+}
@@ -6,8 +6,13 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
 // DevOpsApp provides base structure for DevOps applications
@@ -22,8 +27,22 @@ type DevOpsApp struct {
 	Logger       *log.Logger
 	stopChan     chan struct{}
 	healthServer *HealthServer
+
+	// Namespace is watched by RunWithInformers ("" for all namespaces)
+	Namespace string
+	// DebounceInterval is how long RunWithInformers waits for events to
+	// settle before running the handler (default: 2s)
+	DebounceInterval time.Duration
 }
 
+// defaultDebounceInterval lets a rollout touching many objects trigger a
+// single handler run
+const defaultDebounceInterval = 2 * time.Second
+
+// informerSyncTimeout bounds the initial informer sync; informers that can't
+// list or watch never sync
+const informerSyncTimeout = time.Minute
+
 // DevOpsAppConfig holds configuration for DevOps apps
 type DevOpsAppConfig struct {
 	Name         string
@@ -31,12 +50,21 @@ type DevOpsAppConfig struct {
 	Description  string
 	RunInterval  time.Duration
 	HealthPort   int
-	ClaudeAPIKey string
-	CubToken     string
-	CubBaseURL   string
+	Namespace    string // Default: NAMESPACE, then the pod's namespace, then "default"
+	ClaudeAPIKey string // Default: CLAUDE_API_KEY
+	CubToken     string // Default: CUB_TOKEN
+	CubBaseURL   string // Default: CUB_API_URL, then the hosted ConfigHub API
+
+	// RequireConfigHub and RequireClaude make NewDevOpsApp fail when the
+	// corresponding credentials are missing instead of leaving Cub or Claude nil.
+	// Kubernetes config (KUBECONFIG, ~/.kube/config or in-cluster) is always required.
+	RequireConfigHub bool
+	RequireClaude    bool
 }
 
-// NewDevOpsApp creates a new DevOps application
+// NewDevOpsApp creates a new DevOps application, wiring the Kubernetes,
+// ConfigHub and Claude clients from config or the environment. All missing
+// required credentials are reported together in one error.
 func NewDevOpsApp(config DevOpsAppConfig) (*DevOpsApp, error) {
 	// Set defaults
 	if config.RunInterval == 0 {
@@ -45,34 +73,49 @@ func NewDevOpsApp(config DevOpsAppConfig) (*DevOpsApp, error) {
 	if config.HealthPort == 0 {
 		config.HealthPort = 8080
 	}
+	if config.Namespace == "" {
+		config.Namespace = GetNamespace()
+	}
 
 	// Initialize logger
 	logger := log.New(os.Stdout, fmt.Sprintf("[%s] ", config.Name), log.LstdFlags)
 
+	if config.ClaudeAPIKey == "" {
+		config.ClaudeAPIKey = os.Getenv("CLAUDE_API_KEY")
+	}
+	if config.CubToken == "" {
+		config.CubToken = os.Getenv("CUB_TOKEN")
+	}
+
+	// Validate everything up front so one run reports all missing settings
+	var missing []string
+	if config.RequireConfigHub && config.CubToken == "" {
+		missing = append(missing, "CUB_TOKEN (ConfigHub token)")
+	}
+	if config.RequireClaude && config.ClaudeAPIKey == "" {
+		missing = append(missing, "CLAUDE_API_KEY (Claude API key)")
+	}
+
 	// Initialize Kubernetes clients
 	k8s, err := NewK8sClients()
 	if err != nil {
-		return nil, fmt.Errorf("init k8s clients: %w", err)
+		missing = append(missing, fmt.Sprintf("Kubernetes config via KUBECONFIG, ~/.kube/config or in-cluster (%v)", err))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required configuration: %s", strings.Join(missing, "; "))
 	}
 
 	// Initialize Claude client if API key provided
 	var claude *ClaudeClient
 	if config.ClaudeAPIKey != "" {
 		claude = NewClaudeClient(config.ClaudeAPIKey)
-	} else if key := os.Getenv("CLAUDE_API_KEY"); key != "" {
-		claude = NewClaudeClient(key)
 	}
 
-	// Initialize ConfigHub client if token provided
+	// Initialize ConfigHub client if token provided; an empty base URL falls
+	// back to CUB_API_URL
 	var cub *ConfigHubClient
 	if config.CubToken != "" {
 		cub = NewConfigHubClient(config.CubBaseURL, config.CubToken)
-	} else if token := os.Getenv("CUB_TOKEN"); token != "" {
-		baseURL := config.CubBaseURL
-		if baseURL == "" {
-			baseURL = os.Getenv("CUB_API_URL")
-		}
-		cub = NewConfigHubClient(baseURL, token)
 	}
 
 	app := &DevOpsApp{
@@ -85,6 +128,7 @@ func NewDevOpsApp(config DevOpsAppConfig) (*DevOpsApp, error) {
 		Cub:         cub,
 		Logger:      logger,
 		stopChan:    make(chan struct{}),
+		Namespace:   config.Namespace,
 	}
 
 	// Start health server
@@ -94,46 +138,23 @@ func NewDevOpsApp(config DevOpsAppConfig) (*DevOpsApp, error) {
 	return app, nil
 }
 
-// Run starts the main application loop
+// Run starts the main application loop, running handler every RunInterval
 func (app *DevOpsApp) Run(handler func() error) error {
+	return app.RunWithInterval(app.RunInterval, handler)
+}
+
+// RunWithInterval runs handler immediately and then every interval until
+// SIGTERM, an interrupt, or Stop. It is the polling fallback for environments
+// without watch permissions.
+func (app *DevOpsApp) RunWithInterval(interval time.Duration, handler func() error) error {
 	app.Logger.Printf("%s v%s started", app.Name, app.Version)
 	app.Logger.Printf("Description: %s", app.Description)
-	app.Logger.Printf("Run interval: %v", app.RunInterval)
-
-	// Setup signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	// Run initial execution
-	if err := handler(); err != nil {
-		app.Logger.Printf("Initial run error: %v", err)
-	}
-
-	// Start main loop
-	ticker := time.NewTicker(app.RunInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			app.Logger.Println("Running scheduled task...")
-			if err := handler(); err != nil {
-				app.Logger.Printf("Task error: %v", err)
-				app.healthServer.SetHealthy(false, fmt.Sprintf("Task failed: %v", err))
-			} else {
-				app.healthServer.SetHealthy(true, "Running")
-			}
+	app.Logger.Printf("Run interval: %v", interval)
 
-		case <-sigChan:
-			app.Logger.Println("Received shutdown signal")
-			close(app.stopChan)
-			return nil
+	ctx, cancel := app.shutdownContext(context.Background())
+	defer cancel()
 
-		case <-app.stopChan:
-			app.Logger.Println("Stopping application")
-			return nil
-		}
-	}
+	return app.poll(ctx, interval, handler)
 }
 
 // Stop gracefully stops the application
@@ -141,74 +162,159 @@ func (app *DevOpsApp) Stop() {
 	close(app.stopChan)
 }
 
-// RunWithInformers starts the app in event-driven mode using Kubernetes informers
+// RunWithInformers starts the app in event-driven mode using Kubernetes
+// informers. The handler runs once the caches sync, then again whenever
+// Deployments, StatefulSets or Services in Namespace are added, updated or
+// deleted; bursts of events within DebounceInterval trigger a single run.
+// Without Kubernetes clients, or when the informers can't sync (e.g. no watch
+// permission), it falls back to polling every RunInterval.
 func (app *DevOpsApp) RunWithInformers(handler func() error) error {
+	return app.RunWithInformersContext(context.Background(), handler)
+}
+
+// RunWithInformersContext is like RunWithInformers but also stops when ctx is canceled
+func (app *DevOpsApp) RunWithInformersContext(ctx context.Context, handler func() error) error {
 	app.Logger.Printf("%s v%s started in event-driven mode", app.Name, app.Version)
 	app.Logger.Printf("Description: %s", app.Description)
 
-	// Setup signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	ctx, cancel := app.shutdownContext(ctx)
+	defer cancel()
 
-	// Run initial execution
-	if err := handler(); err != nil {
-		app.Logger.Printf("Initial run error: %v", err)
+	if app.K8s == nil || app.K8s.Clientset == nil {
+		app.Logger.Printf("⚠️  No Kubernetes client, polling every %v instead", app.RunInterval)
+		return app.poll(ctx, app.RunInterval, handler)
 	}
+	return app.runInformers(ctx, app.K8s.Clientset, handler)
+}
 
-	// Setup Kubernetes informers (event-driven)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// runInformers watches Deployments, StatefulSets and Services and runs
+// handler, debounced, on every change until ctx is done
+func (app *DevOpsApp) runInformers(ctx context.Context, client kubernetes.Interface, handler func() error) error {
+	informerCtx, stopInformers := context.WithCancel(ctx)
+	defer stopInformers()
 
-	// Create event channel for Kubernetes events
-	eventChan := make(chan struct{}, 1)
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(app.Namespace))
+	defer factory.Shutdown()
 
-	// Start informer goroutine (this would be implemented per app)
-	go func() {
-		// This is a placeholder for actual informer implementation
-		// Real implementation would use controller-runtime or client-go informers
-		ticker := time.NewTicker(30 * time.Second) // Fallback polling
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				select {
-				case eventChan <- struct{}{}:
-				default: // Don't block if channel is full
-				}
-			case <-ctx.Done():
-				return
-			}
+	events := make(chan struct{}, 1)
+	notify := func(interface{}) {
+		select {
+		case events <- struct{}{}:
+		default: // An event is already pending
 		}
-	}()
+	}
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_, obj interface{}) { notify(obj) },
+		DeleteFunc: notify,
+	}
+	for _, informer := range []cache.SharedIndexInformer{
+		factory.Apps().V1().Deployments().Informer(),
+		factory.Apps().V1().StatefulSets().Informer(),
+		factory.Core().V1().Services().Informer(),
+	} {
+		if _, err := informer.AddEventHandler(eventHandler); err != nil {
+			return fmt.Errorf("add event handler: %w", err)
+		}
+	}
+
+	factory.Start(informerCtx.Done())
+	syncCtx, syncCancel := context.WithTimeout(informerCtx, informerSyncTimeout)
+	synced := factory.WaitForCacheSync(syncCtx.Done())
+	syncCancel()
+	if ctx.Err() != nil {
+		app.Logger.Println("Stopping event-driven application")
+		return nil
+	}
+	for informerType, ok := range synced {
+		if !ok {
+			app.Logger.Printf("⚠️  %v informer did not sync (missing watch permission?), polling every %v instead", informerType, app.RunInterval)
+			stopInformers()
+			return app.poll(ctx, app.RunInterval, handler)
+		}
+	}
+
+	// Objects listed during the initial sync aren't changes
+	select {
+	case <-events:
+	default:
+	}
+	app.runTask(handler, "Initial run", "Event-driven processing")
+
+	debounce := app.DebounceInterval
+	if debounce <= 0 {
+		debounce = defaultDebounceInterval
+	}
 
 	app.Logger.Println("Waiting for Kubernetes events...")
 
+	var pending <-chan time.Time
 	for {
 		select {
-		case <-eventChan:
+		case <-events:
+			pending = time.After(debounce) // Restart the window on every event
+
+		case <-pending:
+			pending = nil
 			app.Logger.Println("Processing Kubernetes event...")
-			if err := handler(); err != nil {
-				app.Logger.Printf("Event handler error: %v", err)
-				app.healthServer.SetHealthy(false, fmt.Sprintf("Event handler failed: %v", err))
-			} else {
-				app.healthServer.SetHealthy(true, "Event-driven processing")
-			}
+			app.runTask(handler, "Event handler", "Event-driven processing")
 
-		case <-sigChan:
-			app.Logger.Println("Received shutdown signal")
-			cancel()
-			close(app.stopChan)
+		case <-ctx.Done():
+			app.Logger.Println("Stopping event-driven application")
 			return nil
+		}
+	}
+}
 
-		case <-app.stopChan:
-			app.Logger.Println("Stopping event-driven application")
-			cancel()
+// poll runs handler immediately and then every interval until ctx is done
+func (app *DevOpsApp) poll(ctx context.Context, interval time.Duration, handler func() error) error {
+	app.runTask(handler, "Initial run", "Running")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.Logger.Println("Running scheduled task...")
+			app.runTask(handler, "Task", "Running")
+
+		case <-ctx.Done():
+			app.Logger.Println("Stopping application")
 			return nil
 		}
 	}
 }
 
+// runTask runs handler once and reports the result to the health server
+func (app *DevOpsApp) runTask(handler func() error, name, status string) {
+	err := handler()
+	if err != nil {
+		app.Logger.Printf("%s error: %v", name, err)
+	}
+	if app.healthServer == nil {
+		return
+	}
+	if err != nil {
+		app.healthServer.SetHealthy(false, fmt.Sprintf("%s failed: %v", name, err))
+	} else {
+		app.healthServer.SetHealthy(true, status)
+	}
+}
+
+// shutdownContext derives a context canceled by SIGTERM, an interrupt, or Stop
+func (app *DevOpsApp) shutdownContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-app.stopChan: // Blocks forever for apps built without NewDevOpsApp
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 // GetEnvOrDefault gets an environment variable with a default value
 func GetEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// Test NewDevOpsApp reports every missing credential at once
+func TestNewDevOpsAppValidation(t *testing.T) {
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("CUB_TOKEN", "")
+	t.Setenv("CLAUDE_API_KEY", "")
+
+	_, err := NewDevOpsApp(DevOpsAppConfig{Name: "test", RequireConfigHub: true, RequireClaude: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CUB_TOKEN")
+	assert.Contains(t, err.Error(), "CLAUDE_API_KEY")
+	assert.Contains(t, err.Error(), "KUBECONFIG")
+
+	t.Setenv("CUB_TOKEN", "token")
+	_, err = NewDevOpsApp(DevOpsAppConfig{Name: "test", RequireConfigHub: true, ClaudeAPIKey: "key", RequireClaude: true})
+	require.Error(t, err, "Kubernetes config is still missing")
+	assert.NotContains(t, err.Error(), "CUB_TOKEN")
+	assert.NotContains(t, err.Error(), "CLAUDE_API_KEY")
+}
+
+// Test the event-driven loop and its polling fallback
+func TestRunWithInformers(t *testing.T) {
+	t.Run("DebouncesEvents", func(t *testing.T) {
+		client := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "apps"}})
+		app := &DevOpsApp{Logger: log.New(io.Discard, "", 0), Namespace: "apps", DebounceInterval: 100 * time.Millisecond, RunInterval: time.Hour}
+
+		runs := make(chan struct{}, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- app.runInformers(ctx, client, func() error {
+				runs <- struct{}{}
+				return nil
+			})
+		}()
+
+		select {
+		case <-runs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no initial run")
+		}
+
+		for _, name := range []string{"web", "api", "worker"} {
+			_, err := client.AppsV1().Deployments("apps").Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}, metav1.CreateOptions{})
+			require.NoError(t, err)
+		}
+		_, err := client.CoreV1().Services("apps").Create(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		select {
+		case <-runs:
+		case <-time.After(5 * time.Second):
+			t.Fatal("events did not trigger the handler")
+		}
+		select {
+		case <-runs:
+			t.Fatal("a burst of events should trigger a single run")
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		_, err = client.CoreV1().Services("other").Create(ctx, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}, metav1.CreateOptions{})
+		require.NoError(t, err)
+		select {
+		case <-runs:
+			t.Fatal("other namespaces are not watched")
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		cancel()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("did not stop on context cancellation")
+		}
+	})
+
+	t.Run("PollsWithoutKubernetes", func(t *testing.T) {
+		app := &DevOpsApp{Logger: log.New(io.Discard, "", 0), RunInterval: 10 * time.Millisecond}
+		var runs int32
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		err := app.RunWithInformersContext(ctx, func() error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, atomic.LoadInt32(&runs), int32(3))
+	})
+}
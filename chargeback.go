@@ -0,0 +1,219 @@
+// chargeback.go - Cost allocation (chargeback) reports for the DevOps SDK
+//
+// Finance allocates a space's estimated cost to cost centers by a unit label.
+// A chargeback splits each cost center's total into resource-type columns and
+// reconciles the grand total with SpaceCostAnalysis.TotalMonthlyCost.
+//
+// Example:
+//
+//	ca := NewCostAnalyzer(app, spaceID)
+//	analysis, _ := ca.AnalyzeSpace()
+//	fmt.Print(ca.GenerateChargebackReport(analysis, "cost-center"))
+//	ca.WriteChargebackCSV(file, analysis, "cost-center")
+package sdk
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UnallocatedCostCenter collects units that don't carry the cost-center label
+const UnallocatedCostCenter = "(unallocated)"
+
+// DefaultUnallocatedThreshold is the unallocated share of cost, in percent,
+// above which chargeback reports warn
+const DefaultUnallocatedThreshold = 10.0
+
+// ChargebackLine is one cost center's monthly cost split by resource type.
+// The columns add up to Total.
+type ChargebackLine struct {
+	CostCenter   string
+	UnitCount    int
+	Compute      float64 // CPU and memory on the default node pool
+	GPU          float64 // CPU and memory on priced node classes (SetNodeClassPricing), e.g. GPU pools
+	Storage      float64
+	LoadBalancer float64
+	Network      float64
+	Total        float64
+}
+
+// Chargeback allocates an analysis's cost to cost centers
+type Chargeback struct {
+	Label              string
+	Lines              []ChargebackLine // Most expensive first; UnallocatedCostCenter last
+	Total              ChargebackLine   // Sum of all lines
+	UnallocatedPercent float64          // Share of Total.Total without the label
+	Reconciled         bool             // Total matches the analysis's TotalMonthlyCost
+}
+
+// SetUnallocatedThreshold sets the unallocated share of cost, in percent,
+// above which chargeback reports warn (default: DefaultUnallocatedThreshold)
+func (ca *CostAnalyzer) SetUnallocatedThreshold(percent float64) {
+	ca.unallocatedThreshold = percent
+}
+
+// BuildChargeback allocates each unit's cost to the cost center named by its
+// costCenterLabel, or to UnallocatedCostCenter without one
+func BuildChargeback(analysis *SpaceCostAnalysis, costCenterLabel string) *Chargeback {
+	chargeback := &Chargeback{Label: costCenterLabel, Total: ChargebackLine{CostCenter: "TOTAL"}}
+	if analysis == nil {
+		chargeback.Reconciled = true
+		return chargeback
+	}
+
+	lines := make(map[string]*ChargebackLine)
+	for _, unit := range analysis.Units {
+		center := unit.Labels[costCenterLabel]
+		if center == "" {
+			center = UnallocatedCostCenter
+		}
+		line, ok := lines[center]
+		if !ok {
+			line = &ChargebackLine{CostCenter: center}
+			lines[center] = line
+		}
+		line.UnitCount++
+		line.add(unit)
+		chargeback.Total.UnitCount++
+		chargeback.Total.add(unit)
+	}
+
+	for _, line := range lines {
+		chargeback.Lines = append(chargeback.Lines, *line)
+	}
+	sort.Slice(chargeback.Lines, func(i, j int) bool {
+		a, b := chargeback.Lines[i], chargeback.Lines[j]
+		if (a.CostCenter == UnallocatedCostCenter) != (b.CostCenter == UnallocatedCostCenter) {
+			return b.CostCenter == UnallocatedCostCenter
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.CostCenter < b.CostCenter
+	})
+
+	if unallocated, ok := lines[UnallocatedCostCenter]; ok && chargeback.Total.Total > 0 {
+		chargeback.UnallocatedPercent = unallocated.Total / chargeback.Total.Total * 100
+	}
+	chargeback.Reconciled = math.Abs(chargeback.Total.Total-analysis.TotalMonthlyCost) < 0.005
+	return chargeback
+}
+
+// add allocates a unit's cost to the line. Multi-workload units are split per
+// workload so only workloads on priced node classes count as GPU.
+func (l *ChargebackLine) add(unit UnitCostEstimate) {
+	parts := unit.Workloads
+	if len(parts) == 0 {
+		parts = []UnitCostEstimate{unit}
+	}
+	for _, part := range parts {
+		compute := part.Breakdown.CPUCost + part.Breakdown.MemoryCost
+		if part.NodeClass != "" {
+			l.GPU += compute
+		} else {
+			l.Compute += compute
+		}
+	}
+	l.Storage += unit.Breakdown.StorageCost
+	l.LoadBalancer += unit.Breakdown.LoadBalancerCost
+	l.Network += unit.Breakdown.NetworkCost
+	l.Total += unit.MonthlyCost
+}
+
+// GenerateChargebackReport renders the monthly chargeback per value of
+// costCenterLabel, with a grand total and a warning when the unallocated
+// share exceeds the threshold set with SetUnallocatedThreshold
+func (ca *CostAnalyzer) GenerateChargebackReport(analysis *SpaceCostAnalysis, costCenterLabel string) string {
+	chargeback := BuildChargeback(analysis, costCenterLabel)
+	pricing := ca.Pricing()
+
+	var report strings.Builder
+	report.WriteString("═══════════════════════════════════════════════════════\n")
+	report.WriteString("       ConfigHub Chargeback Report\n")
+	report.WriteString("═══════════════════════════════════════════════════════\n\n")
+	if analysis != nil {
+		report.WriteString(fmt.Sprintf("Space: %s\n", analysis.SpaceName))
+	}
+	report.WriteString(fmt.Sprintf("Allocated by: %s\n\n", costCenterLabel))
+
+	table := NewTable(costCenterLabel, "Units", "Compute", "GPU", "Storage", "Load Balancers", "Network", "Total/Month", "Share")
+	table.SetAlignment(AlignRight, 1, 2, 3, 4, 5, 6, 7, 8)
+	for _, line := range chargeback.Lines {
+		share := 0.0
+		if chargeback.Total.Total > 0 {
+			share = line.Total / chargeback.Total.Total * 100
+		}
+		table.AddRow(append(chargebackAmounts(pricing, line), fmt.Sprintf("%.1f%%", share))...)
+	}
+	table.AddFooter(append(chargebackAmounts(pricing, chargeback.Total), "")...)
+	report.WriteString(table.Render())
+	report.WriteString("\n")
+
+	if !chargeback.Reconciled {
+		report.WriteString(fmt.Sprintf("⚠️  Total %s does not match the analysis total %s\n",
+			pricing.FormatAmount(chargeback.Total.Total), pricing.FormatAmount(analysis.TotalMonthlyCost)))
+	}
+	if threshold := ca.unallocatedThresholdPercent(); chargeback.UnallocatedPercent > threshold {
+		warning := fmt.Sprintf("%.1f%% of cost is unallocated (threshold %.1f%%); label units with %s",
+			chargeback.UnallocatedPercent, threshold, costCenterLabel)
+		report.WriteString("⚠️  " + warning + "\n")
+		if ca.app != nil && ca.app.Logger != nil {
+			ca.app.Logger.Printf("⚠️  %s", warning)
+		}
+	}
+
+	return report.String()
+}
+
+// WriteChargebackCSV writes the chargeback as CSV, one row per cost center
+// plus a TOTAL row. Amounts are USD with two decimals.
+func (ca *CostAnalyzer) WriteChargebackCSV(w io.Writer, analysis *SpaceCostAnalysis, costCenterLabel string) error {
+	chargeback := BuildChargeback(analysis, costCenterLabel)
+
+	out := csv.NewWriter(w)
+	out.Write([]string{costCenterLabel, "units", "compute_usd", "gpu_usd", "storage_usd", "load_balancer_usd", "network_usd", "total_usd"})
+	for _, line := range append(chargeback.Lines, chargeback.Total) {
+		out.Write([]string{
+			line.CostCenter,
+			strconv.Itoa(line.UnitCount),
+			strconv.FormatFloat(line.Compute, 'f', 2, 64),
+			strconv.FormatFloat(line.GPU, 'f', 2, 64),
+			strconv.FormatFloat(line.Storage, 'f', 2, 64),
+			strconv.FormatFloat(line.LoadBalancer, 'f', 2, 64),
+			strconv.FormatFloat(line.Network, 'f', 2, 64),
+			strconv.FormatFloat(line.Total, 'f', 2, 64),
+		})
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write chargeback CSV: %v", err)
+	}
+	return nil
+}
+
+// unallocatedThresholdPercent returns the configured threshold or the default
+func (ca *CostAnalyzer) unallocatedThresholdPercent() float64 {
+	if ca.unallocatedThreshold > 0 {
+		return ca.unallocatedThreshold
+	}
+	return DefaultUnallocatedThreshold
+}
+
+// chargebackAmounts formats a line's name, unit count and amounts as table cells
+func chargebackAmounts(pricing *PricingModel, line ChargebackLine) []string {
+	return []string{
+		truncate(line.CostCenter, 30),
+		strconv.Itoa(line.UnitCount),
+		pricing.FormatAmount(line.Compute),
+		pricing.FormatAmount(line.GPU),
+		pricing.FormatAmount(line.Storage),
+		pricing.FormatAmount(line.LoadBalancer),
+		pricing.FormatAmount(line.Network),
+		pricing.FormatAmount(line.Total),
+	}
+}
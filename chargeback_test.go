@@ -0,0 +1,104 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test chargeback allocation, report rendering and CSV export
+func TestChargebackReport(t *testing.T) {
+	analysis := &SpaceCostAnalysis{SpaceName: "prod", TotalMonthlyCost: 400, Units: []UnitCostEstimate{
+		{UnitName: "web", MonthlyCost: 120, Breakdown: CostBreakdown{CPUCost: 60, MemoryCost: 40, LoadBalancerCost: 20}, Labels: map[string]string{"cost-center": "retail"}},
+		{UnitName: "db", MonthlyCost: 80, Breakdown: CostBreakdown{CPUCost: 30, MemoryCost: 20, StorageCost: 30}, Labels: map[string]string{"cost-center": "retail"}},
+		{UnitName: "train", MonthlyCost: 150, NodeClass: "gpu", Breakdown: CostBreakdown{CPUCost: 100, MemoryCost: 50}, Labels: map[string]string{"cost-center": "ml"}},
+		{UnitName: "legacy", MonthlyCost: 50, Breakdown: CostBreakdown{CPUCost: 40, NetworkCost: 10}},
+	}}
+
+	t.Run("Allocation", func(t *testing.T) {
+		chargeback := BuildChargeback(analysis, "cost-center")
+		require.Len(t, chargeback.Lines, 3)
+		assert.Equal(t, "retail", chargeback.Lines[0].CostCenter, "most expensive first")
+		assert.Equal(t, "ml", chargeback.Lines[1].CostCenter)
+		assert.Equal(t, UnallocatedCostCenter, chargeback.Lines[2].CostCenter, "unallocated last")
+
+		retail := chargeback.Lines[0]
+		assert.Equal(t, 2, retail.UnitCount)
+		assert.Equal(t, 150.0, retail.Compute)
+		assert.Equal(t, 30.0, retail.Storage)
+		assert.Equal(t, 20.0, retail.LoadBalancer)
+		assert.Equal(t, 200.0, retail.Total)
+		assert.Equal(t, 150.0, chargeback.Lines[1].GPU)
+		assert.Zero(t, chargeback.Lines[1].Compute)
+
+		assert.Equal(t, 4, chargeback.Total.UnitCount)
+		assert.Equal(t, 400.0, chargeback.Total.Total)
+		assert.True(t, chargeback.Reconciled)
+		assert.InDelta(t, 12.5, chargeback.UnallocatedPercent, 0.001)
+	})
+
+	t.Run("MultiWorkloadUnits", func(t *testing.T) {
+		mixed := &SpaceCostAnalysis{TotalMonthlyCost: 100, Units: []UnitCostEstimate{{
+			UnitName:    "pipeline",
+			MonthlyCost: 100,
+			NodeClass:   "gpu",
+			Breakdown:   CostBreakdown{CPUCost: 70, MemoryCost: 30},
+			Labels:      map[string]string{"cost-center": "ml"},
+			Workloads: []UnitCostEstimate{
+				{NodeClass: "gpu", Breakdown: CostBreakdown{CPUCost: 60, MemoryCost: 20}},
+				{Breakdown: CostBreakdown{CPUCost: 10, MemoryCost: 10}},
+			},
+		}}}
+		line := BuildChargeback(mixed, "cost-center").Lines[0]
+		assert.Equal(t, 80.0, line.GPU)
+		assert.Equal(t, 20.0, line.Compute)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		var logs bytes.Buffer
+		ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(&logs, "", 0)}, uuid.New())
+
+		report := ca.GenerateChargebackReport(analysis, "cost-center")
+		assert.Contains(t, report, "Allocated by: cost-center")
+		assert.Contains(t, report, "retail")
+		assert.Contains(t, report, "50.0%")
+		assert.Contains(t, report, "TOTAL")
+		assert.Contains(t, report, "$400.00")
+		assert.Contains(t, report, "12.5% of cost is unallocated (threshold 10.0%)")
+		assert.Contains(t, logs.String(), "unallocated")
+
+		logs.Reset()
+		ca.SetUnallocatedThreshold(20)
+		report = ca.GenerateChargebackReport(analysis, "cost-center")
+		assert.NotContains(t, report, "is unallocated")
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("Unreconciled", func(t *testing.T) {
+		stale := *analysis
+		stale.TotalMonthlyCost = 500
+		ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(&bytes.Buffer{}, "", 0)}, uuid.New())
+		assert.False(t, BuildChargeback(&stale, "cost-center").Reconciled)
+		assert.Contains(t, ca.GenerateChargebackReport(&stale, "cost-center"), "does not match the analysis total $500.00")
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(&bytes.Buffer{}, "", 0)}, uuid.New())
+		var out bytes.Buffer
+		require.NoError(t, ca.WriteChargebackCSV(&out, analysis, "cost-center"))
+
+		records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 5)
+		assert.Equal(t, []string{"cost-center", "units", "compute_usd", "gpu_usd", "storage_usd", "load_balancer_usd", "network_usd", "total_usd"}, records[0])
+		assert.Equal(t, []string{"retail", "2", "150.00", "0.00", "30.00", "20.00", "0.00", "200.00"}, records[1])
+		assert.Equal(t, []string{UnallocatedCostCenter, "1", "40.00", "0.00", "0.00", "0.00", "10.00", "50.00"}, records[3])
+		assert.Equal(t, []string{"TOTAL", "4", "190.00", "150.00", "30.00", "20.00", "10.00", "400.00"}, records[4])
+	})
+}
@@ -3,6 +3,7 @@ package sdk
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,9 +11,84 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 )
 
+// DefaultClaudeModel is the model used when ClaudeClient.Model is empty
+const DefaultClaudeModel = "claude-3-haiku-20240307"
+
+// claudeMessagesURL is the Messages API endpoint
+const claudeMessagesURL = "https://api.anthropic.com/v1/messages"
+
+// statusOverloaded is the status the Claude API returns when it is overloaded
+const statusOverloaded = 529
+
+// ErrTokenBudgetExceeded is returned without calling the API once a client
+// has used its TokenBudget
+var ErrTokenBudgetExceeded = errors.New("claude token budget exceeded")
+
+// ClaudeTokenPricing is the USD price per million input and output tokens
+type ClaudeTokenPricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// ClaudeModelPricing holds list prices used for cost accounting. Models not
+// listed are accounted at zero cost unless SetTokenPricing is called.
+var ClaudeModelPricing = map[string]ClaudeTokenPricing{
+	"claude-3-haiku-20240307":    {InputPerMillion: 0.25, OutputPerMillion: 1.25},
+	"claude-3-5-haiku-20241022":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+	"claude-3-5-sonnet-20241022": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-opus-20240229":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+}
+
+// ClaudeUsage totals the tokens and estimated cost of a client's API calls
+type ClaudeUsage struct {
+	Requests     int64   `json:"requests"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// TotalTokens returns input plus output tokens
+func (u ClaudeUsage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// PromptTemplate is a reusable prompt rendered with text/template
+type PromptTemplate struct {
+	tmpl *template.Template
+}
+
+// NewPromptTemplate parses a prompt template, e.g. "Analyze unit {{.Slug}}"
+func NewPromptTemplate(name, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt template %s: %w", name, err)
+	}
+	return &PromptTemplate{tmpl: tmpl}, nil
+}
+
+// MustPromptTemplate is NewPromptTemplate for package-level templates; it
+// panics if text doesn't parse
+func MustPromptTemplate(name, text string) *PromptTemplate {
+	t, err := NewPromptTemplate(name, text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render executes the template with data
+func (t *PromptTemplate) Render(data interface{}) (string, error) {
+	var prompt strings.Builder
+	if err := t.tmpl.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("render prompt template %s: %w", t.tmpl.Name(), err)
+	}
+	return prompt.String(), nil
+}
+
 // ClaudeAPICall represents a single API interaction with Claude
 type ClaudeAPICall struct {
 	RequestID    string    `json:"request_id"`
@@ -22,6 +98,9 @@ type ClaudeAPICall struct {
 	Duration     string    `json:"duration"`
 	Success      bool      `json:"success"`
 	ErrorMessage string    `json:"error_message,omitempty"`
+	InputTokens  int64     `json:"input_tokens,omitempty"`
+	OutputTokens int64     `json:"output_tokens,omitempty"`
+	CostUSD      float64   `json:"cost_usd,omitempty"`
 }
 
 // ClaudeClient provides a simple interface to Claude API with comprehensive logging
@@ -34,6 +113,25 @@ type ClaudeClient struct {
 	history        []ClaudeAPICall
 	historyMu      sync.RWMutex
 	maxHistory     int
+
+	// Model is the Claude model to call (default DefaultClaudeModel)
+	Model string
+	// MaxTokens caps the length of each response (default 4096)
+	MaxTokens int
+	// MaxRetries is how many times a rate-limited, overloaded or failed
+	// request is retried (0 disables retries)
+	MaxRetries int
+	// RetryDelay is the initial backoff delay, doubled on each retry
+	RetryDelay time.Duration
+	// TokenBudget caps the input plus output tokens used by this client;
+	// once reached, calls fail with ErrTokenBudgetExceeded (0 means no cap)
+	TokenBudget int64
+
+	baseURL     string
+	temperature *float64
+	pricing     *ClaudeTokenPricing
+	usage       ClaudeUsage
+	usageMu     sync.Mutex
 }
 
 // NewClaudeClient creates a new Claude API client with logging
@@ -56,9 +154,70 @@ func NewClaudeClient(apiKey string) *ClaudeClient {
 		requestCounter: 0,
 		history:        make([]ClaudeAPICall, 0, 10),
 		maxHistory:     10, // Keep last 10 API calls
+		Model:          DefaultClaudeModel,
+		MaxTokens:      4096,
+		MaxRetries:     2,
+		RetryDelay:     time.Second,
+		baseURL:        claudeMessagesURL,
 	}
 }
 
+// SetTemperature sets the sampling temperature (0.0-1.0) sent with each
+// request; lower values give more deterministic answers. Without it the API
+// default is used.
+func (c *ClaudeClient) SetTemperature(temperature float64) {
+	c.temperature = &temperature
+}
+
+// SetTokenPricing overrides ClaudeModelPricing for cost accounting, e.g. for
+// negotiated rates or models not listed there
+func (c *ClaudeClient) SetTokenPricing(pricing ClaudeTokenPricing) {
+	c.pricing = &pricing
+}
+
+// Usage returns the tokens and estimated cost of every successful call so far
+func (c *ClaudeClient) Usage() ClaudeUsage {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	return c.usage
+}
+
+// tokenCost estimates the USD cost of a call with the client's pricing
+func (c *ClaudeClient) tokenCost(model string, inputTokens, outputTokens int64) float64 {
+	pricing, ok := ClaudeModelPricing[model]
+	if c.pricing != nil {
+		pricing, ok = *c.pricing, true
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(inputTokens)*pricing.InputPerMillion + float64(outputTokens)*pricing.OutputPerMillion) / 1e6
+}
+
+// recordUsage adds a call's tokens to the running totals and returns its cost
+func (c *ClaudeClient) recordUsage(model string, inputTokens, outputTokens int64) float64 {
+	cost := c.tokenCost(model, inputTokens, outputTokens)
+
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	c.usage.Requests++
+	c.usage.InputTokens += inputTokens
+	c.usage.OutputTokens += outputTokens
+	c.usage.CostUSD += cost
+	return cost
+}
+
+// checkTokenBudget fails once the client has used its TokenBudget
+func (c *ClaudeClient) checkTokenBudget() error {
+	if c.TokenBudget <= 0 {
+		return nil
+	}
+	if used := c.Usage().TotalTokens(); used >= c.TokenBudget {
+		return fmt.Errorf("%w: used %d of %d tokens", ErrTokenBudgetExceeded, used, c.TokenBudget)
+	}
+	return nil
+}
+
 // GetRecentCalls returns the most recent API calls (for dashboard display)
 func (c *ClaudeClient) GetRecentCalls() []ClaudeAPICall {
 	c.historyMu.RLock()
@@ -81,7 +240,9 @@ func (c *ClaudeClient) addToHistory(call ClaudeAPICall) {
 	}
 }
 
-// Complete sends a prompt to Claude and returns the response
+// Complete sends a prompt to Claude and returns the response. Rate-limited,
+// overloaded and failed requests are retried up to MaxRetries times, and the
+// tokens used are added to Usage.
 func (c *ClaudeClient) Complete(prompt string) (string, error) {
 	c.requestCounter++
 	startTime := time.Now()
@@ -90,84 +251,70 @@ func (c *ClaudeClient) Complete(prompt string) (string, error) {
 	// Log the request
 	c.logRequest(requestID, prompt)
 
+	fail := func(err error) (string, error) {
+		c.logError(requestID, err)
+		c.addToHistory(ClaudeAPICall{
+			RequestID:    requestID,
+			Timestamp:    startTime,
+			Prompt:       truncateString(prompt, 200),
+			Response:     "",
+			Duration:     time.Since(startTime).String(),
+			Success:      false,
+			ErrorMessage: err.Error(),
+		})
+		return "", err
+	}
+
+	if err := c.checkTokenBudget(); err != nil {
+		return fail(err)
+	}
+
+	model := c.Model
+	if model == "" {
+		model = DefaultClaudeModel
+	}
+	maxTokens := c.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 4096
+	}
 	request := map[string]interface{}{
-		"model":      "claude-3-haiku-20240307",
-		"max_tokens": 4096,
+		"model":      model,
+		"max_tokens": maxTokens,
 		"messages": []map[string]string{
 			{"role": "user", "content": prompt},
 		},
 	}
-
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		c.logError(requestID, fmt.Errorf("marshal request: %w", err))
-		return "", fmt.Errorf("marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
-	if err != nil {
-		c.logError(requestID, fmt.Errorf("create request: %w", err))
-		return "", fmt.Errorf("create request: %w", err)
+	if c.temperature != nil {
+		request["temperature"] = *c.temperature
 	}
 
-	req.Header.Set("x-api-key", c.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-	req.Header.Set("Content-Type", "application/json")
-
-	c.logger.Printf("%s → Sending API request", requestID)
-
-	resp, err := c.client.Do(req)
+	jsonData, err := json.Marshal(request)
 	if err != nil {
-		c.logError(requestID, fmt.Errorf("send request: %w", err))
-		return "", fmt.Errorf("send request: %w", err)
+		return fail(fmt.Errorf("marshal request: %w", err))
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.send(requestID, jsonData)
 	if err != nil {
-		c.logError(requestID, fmt.Errorf("read response: %w", err))
-		return "", fmt.Errorf("read response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		apiErr := fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
-		c.logError(requestID, apiErr)
-		return "", apiErr
+		return fail(err)
 	}
 
 	var result struct {
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		Usage struct {
+			InputTokens  int64 `json:"input_tokens"`
+			OutputTokens int64 `json:"output_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
-		c.logError(requestID, fmt.Errorf("unmarshal response: %w", err))
-		c.addToHistory(ClaudeAPICall{
-			RequestID:    requestID,
-			Timestamp:    startTime,
-			Prompt:       truncateString(prompt, 200),
-			Response:     "",
-			Duration:     time.Since(startTime).String(),
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("unmarshal response: %v", err),
-		})
-		return "", fmt.Errorf("unmarshal response: %w", err)
+		return fail(fmt.Errorf("unmarshal response: %w", err))
 	}
+	cost := c.recordUsage(model, result.Usage.InputTokens, result.Usage.OutputTokens)
 
 	if len(result.Content) == 0 {
-		err := fmt.Errorf("empty response from Claude")
-		c.logError(requestID, err)
-		c.addToHistory(ClaudeAPICall{
-			RequestID:    requestID,
-			Timestamp:    startTime,
-			Prompt:       truncateString(prompt, 200),
-			Response:     "",
-			Duration:     time.Since(startTime).String(),
-			Success:      false,
-			ErrorMessage: "empty response from Claude",
-		})
-		return "", err
+		return fail(fmt.Errorf("empty response from Claude"))
 	}
 
 	response := result.Content[0].Text
@@ -178,17 +325,125 @@ func (c *ClaudeClient) Complete(prompt string) (string, error) {
 
 	// Add to history for dashboard display
 	c.addToHistory(ClaudeAPICall{
-		RequestID: requestID,
-		Timestamp: startTime,
-		Prompt:    truncateString(prompt, 200),
-		Response:  truncateString(response, 500),
-		Duration:  duration.String(),
-		Success:   true,
+		RequestID:    requestID,
+		Timestamp:    startTime,
+		Prompt:       truncateString(prompt, 200),
+		Response:     truncateString(response, 500),
+		Duration:     duration.String(),
+		Success:      true,
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+		CostUSD:      cost,
 	})
 
 	return response, nil
 }
 
+// send posts a Messages API request, retrying transient failures with
+// exponential backoff, and returns the response body
+func (c *ClaudeClient) send(requestID string, jsonData []byte) ([]byte, error) {
+	delay := c.RetryDelay
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("POST", c.baseURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+
+		req.Header.Set("x-api-key", c.apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+		req.Header.Set("Content-Type", "application/json")
+
+		c.logger.Printf("%s → Sending API request", requestID)
+
+		wait := delay
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if attempt >= c.MaxRetries {
+				return nil, fmt.Errorf("send request: %w", err)
+			}
+		} else {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, fmt.Errorf("read response: %w", readErr)
+			}
+			if resp.StatusCode == http.StatusOK {
+				return body, nil
+			}
+
+			apiErr := fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+			retryable := isRetryableStatus(resp.StatusCode) || resp.StatusCode == statusOverloaded ||
+				resp.StatusCode == http.StatusInternalServerError
+			if attempt >= c.MaxRetries || !retryable {
+				return nil, apiErr
+			}
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+			c.logError(requestID, apiErr)
+		}
+
+		if wait > maxRetryDelay {
+			wait = maxRetryDelay
+		}
+		c.logger.Printf("%s ↻ Retrying in %v (attempt %d of %d)", requestID, wait, attempt+1, c.MaxRetries)
+		time.Sleep(wait)
+
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// CompleteTemplate renders a prompt template with data and sends it to Claude
+func (c *ClaudeClient) CompleteTemplate(tmpl *PromptTemplate, data interface{}) (string, error) {
+	prompt, err := tmpl.Render(data)
+	if err != nil {
+		return "", err
+	}
+	return c.Complete(prompt)
+}
+
+// CompleteJSON sends a prompt that asks for JSON and parses Claude's answer
+// into out. The JSON may be wrapped in a markdown code block or surrounded by
+// prose; the prompt should describe the expected fields.
+func (c *ClaudeClient) CompleteJSON(prompt string, out interface{}) error {
+	response, err := c.Complete(prompt)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), out); err != nil {
+		return fmt.Errorf("unmarshal structured response: %w", err)
+	}
+	return nil
+}
+
+// extractJSON returns the JSON document in a response: the contents of a
+// ```json code block if there is one, otherwise the text from the first
+// opening brace or bracket to the last closing one
+func extractJSON(response string) string {
+	if start := strings.Index(response, "```json"); start != -1 {
+		rest := response[start+len("```json"):]
+		if end := strings.Index(rest, "```"); end != -1 {
+			return strings.TrimSpace(rest[:end])
+		}
+	}
+
+	start := strings.IndexAny(response, "{[")
+	if start == -1 {
+		return response
+	}
+	closing := "}"
+	if response[start] == '[' {
+		closing = "]"
+	}
+	if end := strings.LastIndex(response, closing); end > start {
+		return response[start : end+1]
+	}
+	return response
+}
+
 // truncateString truncates a string to maxLen with ellipsis
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -216,15 +471,7 @@ func (c *ClaudeClient) AnalyzeWithStructuredResponse(prompt string, data interfa
 	}
 
 	// Extract JSON from the response (Claude often wraps in markdown)
-	jsonStart := bytes.Index([]byte(response), []byte("```json"))
-	jsonEnd := bytes.LastIndex([]byte(response), []byte("```"))
-
-	if jsonStart != -1 && jsonEnd != -1 && jsonEnd > jsonStart {
-		jsonStart += 7 // Skip "```json\n"
-		response = response[jsonStart:jsonEnd]
-	}
-
-	if err := json.Unmarshal([]byte(response), result); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(response)), result); err != nil {
 		return fmt.Errorf("unmarshal structured response: %w", err)
 	}
 
@@ -284,5 +531,7 @@ func (c *ClaudeClient) DisableDebugLogging() {
 
 // GetRequestStats returns basic request statistics
 func (c *ClaudeClient) GetRequestStats() (int64, string) {
-	return c.requestCounter, fmt.Sprintf("Total Claude API requests: %d", c.requestCounter)
+	usage := c.Usage()
+	return c.requestCounter, fmt.Sprintf("Total Claude API requests: %d (%d tokens, $%.4f)",
+		c.requestCounter, usage.TotalTokens(), usage.CostUSD)
 }
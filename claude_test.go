@@ -0,0 +1,143 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClaudeClient returns a quiet client that talks to server
+func newTestClaudeClient(server *httptest.Server) *ClaudeClient {
+	client := NewClaudeClient("test-key")
+	client.logger = log.New(io.Discard, "", 0)
+	client.baseURL = server.URL
+	client.RetryDelay = time.Millisecond
+	return client
+}
+
+// claudeResponse writes a Messages API response with text and token usage
+func claudeResponse(w http.ResponseWriter, text string, inputTokens, outputTokens int64) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"usage":   map[string]int64{"input_tokens": inputTokens, "output_tokens": outputTokens},
+	})
+}
+
+// Test the Claude client's JSON parsing, templating, retries and token accounting
+func TestClaudeClient(t *testing.T) {
+	t.Run("CompleteJSON", func(t *testing.T) {
+		var request map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&request)
+			claudeResponse(w, "Here is my review:\n```json\n{\"riskLevel\": \"HIGH\", \"monitoring\": [\"p99 latency\"]}\n```\nLet me know.", 1000, 200)
+		}))
+		defer server.Close()
+
+		client := newTestClaudeClient(server)
+		client.Model = "claude-3-5-sonnet-20241022"
+		client.SetTemperature(0)
+
+		var out AIRecommendation
+		require.NoError(t, client.CompleteJSON("review", &out))
+		assert.Equal(t, "HIGH", out.RiskLevel)
+		assert.Equal(t, []string{"p99 latency"}, out.Monitoring)
+
+		assert.Equal(t, "claude-3-5-sonnet-20241022", request["model"])
+		assert.Equal(t, 0.0, request["temperature"])
+
+		usage := client.Usage()
+		assert.Equal(t, int64(1), usage.Requests)
+		assert.Equal(t, int64(1200), usage.TotalTokens())
+		assert.InDelta(t, 0.003+0.003, usage.CostUSD, 1e-9) // 1000 in at $3/M, 200 out at $15/M
+		assert.Equal(t, int64(200), client.GetRecentCalls()[0].OutputTokens)
+	})
+
+	t.Run("ExtractJSON", func(t *testing.T) {
+		assert.Equal(t, `{"a": 1}`, extractJSON(`Sure! {"a": 1} Hope that helps.`))
+		assert.Equal(t, `["x", "y"]`, extractJSON("```json\n[\"x\", \"y\"]\n```"))
+		assert.Equal(t, "no json", extractJSON("no json"))
+	})
+
+	t.Run("PromptTemplate", func(t *testing.T) {
+		var prompt string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				Messages []struct {
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			prompt = request.Messages[0].Content
+			claudeResponse(w, "ok", 10, 1)
+		}))
+		defer server.Close()
+
+		tmpl := MustPromptTemplate("drift", "Explain drift in {{.Unit}} ({{len .Fields}} fields)")
+		_, err := newTestClaudeClient(server).CompleteTemplate(tmpl, map[string]interface{}{"Unit": "web", "Fields": []string{"replicas", "image"}})
+		require.NoError(t, err)
+		assert.Equal(t, "Explain drift in web (2 fields)", prompt)
+
+		_, err = tmpl.Render(map[string]interface{}{"Fields": nil})
+		assert.Error(t, err, "missing keys are an error")
+		_, err = NewPromptTemplate("bad", "{{.Unit")
+		assert.Error(t, err)
+	})
+
+	t.Run("RetriesOverloaded", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(statusOverloaded)
+				return
+			}
+			claudeResponse(w, "done", 5, 5)
+		}))
+		defer server.Close()
+
+		response, err := newTestClaudeClient(server).Complete("hello")
+		require.NoError(t, err)
+		assert.Equal(t, "done", response)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("NoRetryOnBadRequest", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		_, err := newTestClaudeClient(server).Complete("hello")
+		assert.ErrorContains(t, err, "API error 400")
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("TokenBudget", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			claudeResponse(w, "ok", 80, 40)
+		}))
+		defer server.Close()
+
+		client := newTestClaudeClient(server)
+		client.TokenBudget = 100
+		_, err := client.Complete("first")
+		require.NoError(t, err)
+
+		_, err = client.Complete("second")
+		assert.True(t, errors.Is(err, ErrTokenBudgetExceeded))
+		assert.Equal(t, 1, attempts)
+		assert.False(t, client.GetRecentCalls()[1].Success)
+	})
+}
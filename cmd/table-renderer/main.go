@@ -1,23 +1,58 @@
 // table-renderer - CLI tool to render JSON data as ASCII tables
 // Usage: echo '{"headers":["Name","Age"],"rows":[["Alice","30"],["Bob","25"]]}' | table-renderer
+//
+// With --serve :8080 it instead serves the same JSON on POST /render, plus
+// GET /healthz, so non-Go services can reuse the renderer.
 package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"log"
+	"mime"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	sdk "github.com/monadic/devops-sdk"
 )
 
+// maxRequestBytes caps POST /render bodies
+const maxRequestBytes = 10 << 20
+
 type TableInput struct {
-	Headers []string   `json:"headers"`
-	Rows    [][]string `json:"rows"`
-	Style   string     `json:"style"` // "default", "simple", "double", "none"
+	Headers    []string   `json:"headers"`
+	Rows       [][]string `json:"rows"`
+	Style      string     `json:"style"`                // "default", "simple", "double", "none"
+	Alignments []string   `json:"alignments,omitempty"` // Per column: "left", "right", "center"
+	MaxWidths  []int      `json:"maxWidths,omitempty"`  // Per column; 0 means unlimited
+}
+
+// alignments maps TableInput alignment names to sdk alignments
+var alignments = map[string]sdk.Alignment{
+	"left":   sdk.AlignLeft,
+	"right":  sdk.AlignRight,
+	"center": sdk.AlignCenter,
 }
 
 func main() {
+	serve := flag.String("serve", "", "serve POST /render on this address (e.g. :8080) instead of reading stdin")
+	flag.Parse()
+
+	if *serve != "" {
+		server := &http.Server{
+			Addr:              *serve,
+			Handler:           newServeMux(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		log.Printf("Serving table renderer on %s", *serve)
+		log.Fatal(server.ListenAndServe())
+	}
+
 	// Read JSON from stdin
 	data, err := io.ReadAll(os.Stdin)
 	if err != nil {
@@ -31,8 +66,40 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create table
-	table := sdk.NewTableWriter(input.Headers)
+	// Render and output
+	output, err := renderTable(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(output)
+}
+
+// renderTable renders the input as an ASCII table in the requested style,
+// alignments and max widths
+func renderTable(input TableInput) (string, error) {
+	if len(input.Alignments) > 0 && len(input.Alignments) != len(input.Headers) {
+		return "", fmt.Errorf("got %d alignments for %d headers", len(input.Alignments), len(input.Headers))
+	}
+	if len(input.MaxWidths) > 0 && len(input.MaxWidths) != len(input.Headers) {
+		return "", fmt.Errorf("got %d maxWidths for %d headers", len(input.MaxWidths), len(input.Headers))
+	}
+
+	table := sdk.NewTable(input.Headers...)
+
+	for i, name := range input.Alignments {
+		align, ok := alignments[name]
+		if !ok {
+			return "", fmt.Errorf("invalid alignment %q for column %d (want left, right or center)", name, i)
+		}
+		table.SetAlignment(align, i)
+	}
+	for i, width := range input.MaxWidths {
+		if width < 0 {
+			return "", fmt.Errorf("invalid maxWidth %d for column %d", width, i)
+		}
+		table.SetMaxWidth(i, width)
+	}
 
 	// Set border style
 	switch input.Style {
@@ -48,9 +115,71 @@ func main() {
 
 	// Add rows
 	for _, row := range input.Rows {
-		table.AddRow(row)
+		table.AddRow(row...)
 	}
 
-	// Render and output
-	fmt.Println(table.Render())
+	return table.Render(), nil
+}
+
+// newServeMux routes the HTTP server mode
+func newServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/render", handleRender)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// handleRender renders a TableInput posted as JSON
+func handleRender(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// Only plain text rendering exists today
+	if !acceptsPlainText(r.Header.Get("Accept")) {
+		http.Error(w, "only text/plain is supported", http.StatusNotAcceptable)
+		return
+	}
+
+	var input TableInput
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&input); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("error parsing JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	output, err := renderTable(input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, output)
+}
+
+// acceptsPlainText reports whether an Accept header allows text/plain
+func acceptsPlainText(accept string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/plain", "text/*", "*/*":
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test the HTTP server mode
+func TestServeRender(t *testing.T) {
+	server := httptest.NewServer(newServeMux())
+	defer server.Close()
+
+	render := func(t *testing.T, method, accept, body string) (*http.Response, string) {
+		req, err := http.NewRequest(method, server.URL+"/render", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		out, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(out)
+	}
+
+	t.Run("Rendered", func(t *testing.T) {
+		resp, body := render(t, http.MethodPost, "text/plain", `{"headers":["Name","Age"],"rows":[["Alice","30"]],"style":"simple"}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+		expected, err := renderTable(TableInput{Headers: []string{"Name", "Age"}, Rows: [][]string{{"Alice", "30"}}, Style: "simple"})
+		require.NoError(t, err)
+		assert.Equal(t, expected+"\n", body, "same output as the CLI")
+
+		resp, _ = render(t, http.MethodPost, "", `{"headers":["Name"]}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "no Accept header means anything")
+		resp, _ = render(t, http.MethodPost, "application/json;q=0.9, */*;q=0.1", `{"headers":["Name"]}`)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		resp, _ := render(t, http.MethodGet, "", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+	})
+
+	t.Run("NotAcceptable", func(t *testing.T) {
+		resp, _ := render(t, http.MethodPost, "application/json", `{"headers":["Name"]}`)
+		assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		resp, body := render(t, http.MethodPost, "", `{"headers":`)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, body, "error parsing JSON")
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		body := `{"headers":["Name"],"rows":[["` + strings.Repeat("x", maxRequestBytes) + `"]]}`
+		resp, _ := render(t, http.MethodPost, "", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("Healthz", func(t *testing.T) {
+		resp, err := server.Client().Get(server.URL + "/healthz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+// Test per-column alignments and max widths
+func TestRenderTableColumns(t *testing.T) {
+	output, err := renderTable(TableInput{
+		Headers:    []string{"Name", "Cost"},
+		Rows:       [][]string{{"checkout-service", "12.5"}},
+		Style:      "none",
+		Alignments: []string{"left", "right"},
+		MaxWidths:  []int{8, 0},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "checkout-service", "long cells are truncated")
+	assert.Contains(t, output, "12.5")
+
+	for name, input := range map[string]TableInput{
+		"alignments": {Headers: []string{"Name", "Cost"}, Alignments: []string{"left"}},
+		"maxWidths":  {Headers: []string{"Name", "Cost"}, MaxWidths: []int{10, 10, 10}},
+	} {
+		_, err := renderTable(input)
+		assert.ErrorContains(t, err, "for 2 headers", name)
+	}
+	_, err = renderTable(TableInput{Headers: []string{"Name"}, Alignments: []string{"justify"}})
+	assert.ErrorContains(t, err, `invalid alignment "justify"`)
+	_, err = renderTable(TableInput{Headers: []string{"Name"}, MaxWidths: []int{-1}})
+	assert.ErrorContains(t, err, "invalid maxWidth -1")
+
+	server := httptest.NewServer(newServeMux())
+	defer server.Close()
+	for _, body := range []string{
+		`{"headers":["Name","Cost"],"alignments":["left"]}`,
+		`{"headers":["Name","Cost"],"maxWidths":[10]}`,
+	} {
+		resp, err := server.Client().Post(server.URL+"/render", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		out, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+		assert.Contains(t, string(out), "for 2 headers")
+	}
+}
@@ -2,15 +2,28 @@ package sdk
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+	"gopkg.in/yaml.v3"
 )
 
 // Real ConfigHub API types based on actual source code
@@ -50,6 +63,149 @@ type Unit struct {
 	EntityType     string            `json:"EntityType,omitempty"`
 }
 
+// Manifest parses the unit's Data as a Kubernetes manifest, decoding it first
+// if it is base64-encoded. Data is the only stored form of a unit's
+// configuration; edit the returned map and write it back with SetManifest.
+// When Data holds several documents separated by "---", Manifest returns the
+// first workload document, or the first document if none is a workload; use
+// Manifests to see all of them.
+func (u *Unit) Manifest() (map[string]interface{}, error) {
+	manifests, err := u.Manifests()
+	if err != nil || len(manifests) == 0 {
+		return nil, err
+	}
+	return manifests[primaryManifest(manifests)], nil
+}
+
+// Manifests parses every document in the unit's Data, skipping empty ones
+func (u *Unit) Manifests() ([]map[string]interface{}, error) {
+	data, err := decodeUnitData(*u)
+	if err != nil {
+		return nil, err
+	}
+	return parseManifests(data)
+}
+
+// SetManifest serializes manifest into the unit's Data as YAML, the form
+// CreateUnit and UpdateUnit send to ConfigHub. Data that was base64-encoded
+// stays base64-encoded. In a multi-document unit, manifest replaces the
+// document Manifest returns and the others are kept.
+func (u *Unit) SetManifest(manifest map[string]interface{}) error {
+	if manifests, err := u.Manifests(); err == nil && len(manifests) > 1 {
+		manifests[primaryManifest(manifests)] = manifest
+		return u.SetManifests(manifests)
+	}
+	return u.setManifest(manifest, isBase64UnitData(u.Data))
+}
+
+// SetManifests serializes manifests into the unit's Data as one YAML stream
+// with "---" between documents, keeping the Data encoding like SetManifest
+func (u *Unit) SetManifests(manifests []map[string]interface{}) error {
+	return u.setManifests(manifests, isBase64UnitData(u.Data))
+}
+
+// setManifest is SetManifest with an explicit encoding, for new units that
+// should match the unit they were derived from
+func (u *Unit) setManifest(manifest map[string]interface{}, base64Encoded bool) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	u.Data = encodeUnitData(data, base64Encoded)
+	return nil
+}
+
+// setManifests is SetManifests with an explicit encoding
+func (u *Unit) setManifests(manifests []map[string]interface{}, base64Encoded bool) error {
+	documents := make([]string, 0, len(manifests))
+	for _, manifest := range manifests {
+		data, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		documents = append(documents, string(data))
+	}
+	u.Data = encodeUnitData([]byte(strings.Join(documents, "---\n")), base64Encoded)
+	return nil
+}
+
+// workloadKinds are the manifest kinds that run pods
+var workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"Job":         true,
+	"CronJob":     true,
+}
+
+// parseManifests decodes a YAML stream of one or more documents
+func parseManifests(data []byte) ([]map[string]interface{}, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var manifests []map[string]interface{}
+	for {
+		var manifest map[string]interface{}
+		if err := decoder.Decode(&manifest); err != nil {
+			if errors.Is(err, io.EOF) {
+				return manifests, nil
+			}
+			return nil, err
+		}
+		if len(manifest) > 0 {
+			manifests = append(manifests, manifest)
+		}
+	}
+}
+
+// primaryManifest returns the index of the first workload, or 0 if there is none
+func primaryManifest(manifests []map[string]interface{}) int {
+	for i, manifest := range manifests {
+		if kind, _ := manifest["kind"].(string); workloadKinds[kind] {
+			return i
+		}
+	}
+	return 0
+}
+
+// isBase64UnitData reports whether Data holds a base64-encoded manifest.
+// YAML manifests always contain ':' and spaces, so they never decode.
+func isBase64UnitData(data string) bool {
+	if strings.TrimSpace(data) == "" {
+		return false
+	}
+	_, err := base64.StdEncoding.DecodeString(data)
+	return err == nil
+}
+
+// decodeUnitData returns the unit's manifest bytes, decoding base64 Data
+func decodeUnitData(unit Unit) ([]byte, error) {
+	if !isBase64UnitData(unit.Data) {
+		return []byte(unit.Data), nil
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(unit.Data)
+	if !utf8.Valid(decoded) {
+		return nil, fmt.Errorf("unit %s: base64 Data does not decode to a text manifest", unit.Slug)
+	}
+	return decoded, nil
+}
+
+// encodeUnitData encodes manifest bytes for a unit's Data
+func encodeUnitData(data []byte, base64Encoded bool) string {
+	if base64Encoded {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// UnitRevision is one entry in a unit's revision history
+type UnitRevision struct {
+	Revision    int64     `json:"Revision"` // Unit version this revision produced
+	UnitID      uuid.UUID `json:"UnitID"`
+	CreatedAt   time.Time `json:"CreatedAt"`
+	Author      string    `json:"Author,omitempty"`
+	Description string    `json:"Description,omitempty"`
+	DiffSummary string    `json:"DiffSummary,omitempty"` // e.g. "+3 -1 lines"
+}
+
 // Set represents a group of related Units (REAL ConfigHub feature)
 type Set struct {
 	SetID          uuid.UUID         `json:"SetID,omitempty"`
@@ -85,6 +241,21 @@ type Filter struct {
 	EntityType     string            `json:"EntityType,omitempty"`
 }
 
+// Link represents a ConfigHub link: a dependency edge from one unit to another
+type Link struct {
+	LinkID      uuid.UUID         `json:"LinkID,omitempty"`
+	SpaceID     uuid.UUID         `json:"SpaceID,omitempty"`
+	Slug        string            `json:"Slug"`
+	FromUnitID  uuid.UUID         `json:"FromUnitID"`
+	ToUnitID    uuid.UUID         `json:"ToUnitID"`
+	ToSpaceID   *uuid.UUID        `json:"ToSpaceID,omitempty"`
+	Labels      map[string]string `json:"Labels,omitempty"`
+	Annotations map[string]string `json:"Annotations,omitempty"`
+	CreatedAt   time.Time         `json:"CreatedAt,omitempty"`
+	UpdatedAt   time.Time         `json:"UpdatedAt,omitempty"`
+	Version     int64             `json:"Version,omitempty"`
+}
+
 // LiveState represents the live deployment state (READ-ONLY)
 type LiveState struct {
 	UnitID        uuid.UUID `json:"UnitID"`
@@ -110,6 +281,22 @@ type Target struct {
 	Version        int64             `json:"Version,omitempty"`
 }
 
+// Worker represents a ConfigHub bridge worker that applies units to targets
+type Worker struct {
+	WorkerID    uuid.UUID         `json:"WorkerID,omitempty"`
+	SpaceID     uuid.UUID         `json:"SpaceID,omitempty"`
+	Slug        string            `json:"Slug"`
+	DisplayName string            `json:"DisplayName,omitempty"`
+	Status      string            `json:"Status,omitempty"`     // e.g., "Ready", "Error"
+	Condition   string            `json:"Condition,omitempty"`  // e.g., "Ready", "Disconnected"
+	LastSeenAt  time.Time         `json:"LastSeenAt,omitempty"` // Last check-in; see IsWorkerHealthy
+	Labels      map[string]string `json:"Labels,omitempty"`
+	Annotations map[string]string `json:"Annotations,omitempty"`
+	CreatedAt   time.Time         `json:"CreatedAt,omitempty"`
+	UpdatedAt   time.Time         `json:"UpdatedAt,omitempty"`
+	Version     int64             `json:"Version,omitempty"`
+}
+
 // Request/Response types
 
 type CreateSpaceRequest struct {
@@ -129,6 +316,13 @@ type CreateUnitRequest struct {
 	SetIDs         []uuid.UUID       `json:"SetIDs,omitempty"`
 	TargetID       *uuid.UUID        `json:"TargetID,omitempty"`
 	ChangeSetID    *uuid.UUID        `json:"ChangeSetID,omitempty"`
+
+	// IdempotencyKey is sent as the Idempotency-Key header of CreateUnit so
+	// the server can dedupe a retried create that already succeeded. When
+	// empty and POST retries are enabled, a key is derived from the space, slug
+	// and request body. This requires server support; servers without it
+	// ignore the header.
+	IdempotencyKey string `json:"-"`
 }
 
 type CreateSetRequest struct {
@@ -148,6 +342,15 @@ type CreateFilterRequest struct {
 	Annotations map[string]string `json:"Annotations,omitempty"`
 }
 
+type CreateLinkRequest struct {
+	Slug        string            `json:"Slug"`
+	FromUnitID  uuid.UUID         `json:"FromUnitID"`
+	ToUnitID    uuid.UUID         `json:"ToUnitID"`
+	ToSpaceID   *uuid.UUID        `json:"ToSpaceID,omitempty"` // Defaults to the link's own space
+	Labels      map[string]string `json:"Labels,omitempty"`
+	Annotations map[string]string `json:"Annotations,omitempty"`
+}
+
 type ListUnitsParams struct {
 	SpaceID  uuid.UUID  `json:"SpaceID,omitempty"`
 	FilterID *uuid.UUID `json:"FilterID,omitempty"`
@@ -163,11 +366,51 @@ type BulkApplyParams struct {
 	DryRun  bool      `json:"DryRun,omitempty"`
 }
 
+// ApplyAction is what an apply would do to one unit
+type ApplyAction string
+
+const (
+	ApplyCreate ApplyAction = "create" // Never applied
+	ApplyUpdate ApplyAction = "update" // Applied, but the unit changed or its live state drifted
+	ApplyNoOp   ApplyAction = "no-op"  // Live state matches the unit
+)
+
+// UnitApplyPlan is one unit's entry in an ApplyPlan
+type UnitApplyPlan struct {
+	UnitID uuid.UUID   `json:"UnitID"`
+	Slug   string      `json:"Slug"`
+	Action ApplyAction `json:"Action"`
+	Reason string      `json:"Reason,omitempty"`
+}
+
+// ApplyPlan previews a bulk apply, like a terraform plan
+type ApplyPlan struct {
+	SpaceID     uuid.UUID       `json:"SpaceID"`
+	Where       string          `json:"Where"`
+	Units       []UnitApplyPlan `json:"Units"`
+	Synthesized bool            `json:"Synthesized,omitempty"` // Built from live state because the server returned no plan
+}
+
+// Count returns how many units the plan would apply with action
+func (p *ApplyPlan) Count(action ApplyAction) int {
+	var n int
+	for _, unit := range p.Units {
+		if unit.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
 type BulkPatchParams struct {
 	SpaceID uuid.UUID              `json:"SpaceID"`
 	Where   string                 `json:"Where"`
 	Patch   map[string]interface{} `json:"Patch"`
 	Upgrade bool                   `json:"Upgrade,omitempty"` // For push-upgrade pattern
+
+	// AllowDataOverwrite silences the warning for a Patch with a Data key,
+	// which replaces the configuration of every matched unit
+	AllowDataOverwrite bool `json:"-"`
 }
 
 // ConfigHubClient provides interface to real ConfigHub API
@@ -175,10 +418,57 @@ type ConfigHubClient struct {
 	baseURL string
 	token   string
 	client  *http.Client
+
+	// MaxRetries is how many times a failed request is retried (0 disables retries).
+	// Only idempotent methods are retried unless RetryNonIdempotent is set.
+	MaxRetries int
+	// RetryDelay is the initial backoff delay, doubled on each retry
+	RetryDelay time.Duration
+	// RetryNonIdempotent opts POST/PATCH requests into retries; off by default
+	// because retrying a create can duplicate units
+	RetryNonIdempotent bool
+	// OfflinePrefixFallback makes GetNewSpacePrefix generate a prefix locally
+	// when /space/new-prefix fails. Local prefixes aren't checked for clashes.
+	OfflinePrefixFallback bool
+
+	limiter *rate.Limiter // nil means unlimited
+}
+
+// maxRetryDelay caps exponential backoff and Retry-After waits
+const maxRetryDelay = 30 * time.Second
+
+// ClientOptions tunes the ConfigHub client's HTTP connections. The zero value
+// keeps the defaults used by NewConfigHubClient.
+type ClientOptions struct {
+	Timeout             time.Duration // Per-request timeout (default: 30s)
+	MaxIdleConns        int           // Idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // Idle connections kept per host
+	IdleConnTimeout     time.Duration // How long an idle connection is kept
+
+	// Transport replaces the HTTP transport, e.g. an OpenTelemetry-instrumented
+	// one. The connection pool settings above are ignored when it is set.
+	Transport http.RoundTripper
+
+	// RequestsPerSecond and Burst throttle outbound requests with a token
+	// bucket; zero means unlimited. Retries wait for a token too. For bulk
+	// clone/optimize runs, 10 requests per second with a burst of 20 stays
+	// well under ConfigHub's API quotas. Burst defaults to 1.
+	RequestsPerSecond float64
+	Burst             int
+
+	// Limiter shares one rate limiter across clients, e.g. another client's
+	// Limiter(). It takes precedence over RequestsPerSecond and Burst.
+	Limiter *rate.Limiter
 }
 
 // NewConfigHubClient creates a new ConfigHub API client
 func NewConfigHubClient(baseURL, token string) *ConfigHubClient {
+	return NewConfigHubClientWithOptions(baseURL, token, ClientOptions{})
+}
+
+// NewConfigHubClientWithOptions is like NewConfigHubClient but configures
+// connection pooling, timeouts, or a custom transport
+func NewConfigHubClientWithOptions(baseURL, token string, opts ClientOptions) *ConfigHubClient {
 	if baseURL == "" {
 		// Use environment variable or default to ConfigHub API
 		baseURL = os.Getenv("CUB_API_URL")
@@ -187,19 +477,70 @@ func NewConfigHubClient(baseURL, token string) *ConfigHubClient {
 		}
 	}
 
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	transport := opts.Transport
+	if transport == nil && (opts.MaxIdleConns > 0 || opts.MaxIdleConnsPerHost > 0 || opts.IdleConnTimeout > 0) {
+		pooled := http.DefaultTransport.(*http.Transport).Clone()
+		if opts.MaxIdleConns > 0 {
+			pooled.MaxIdleConns = opts.MaxIdleConns
+		}
+		if opts.MaxIdleConnsPerHost > 0 {
+			pooled.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		}
+		if opts.IdleConnTimeout > 0 {
+			pooled.IdleConnTimeout = opts.IdleConnTimeout
+		}
+		transport = pooled
+	}
+
+	limiter := opts.Limiter
+	if limiter == nil && opts.RequestsPerSecond > 0 {
+		burst := opts.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(opts.RequestsPerSecond), burst)
+	}
+
 	return &ConfigHubClient{
 		baseURL: baseURL,
 		token:   token,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   timeout,
+			Transport: transport, // nil uses http.DefaultTransport
 		},
+		MaxRetries: 3,
+		RetryDelay: 500 * time.Millisecond,
+		limiter:    limiter,
 	}
 }
 
+// Limiter returns the client's rate limiter, or nil if it is unlimited. Pass
+// it as ClientOptions.Limiter to make several clients share one quota.
+func (c *ConfigHubClient) Limiter() *rate.Limiter {
+	return c.limiter
+}
+
+// Close releases idle connections. The client stays usable; later requests
+// open new connections. It always returns nil.
+func (c *ConfigHubClient) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
 // Space operations
 
 func (c *ConfigHubClient) CreateSpace(req CreateSpaceRequest) (*Space, error) {
-	result, err := c.doRequest("POST", "/space", req, &Space{})
+	return c.CreateSpaceContext(context.Background(), req)
+}
+
+// CreateSpaceContext is like CreateSpace but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateSpaceContext(ctx context.Context, req CreateSpaceRequest) (*Space, error) {
+	result, err := c.doRequestContext(ctx, "POST", "/space", req, &Space{})
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +548,12 @@ func (c *ConfigHubClient) CreateSpace(req CreateSpaceRequest) (*Space, error) {
 }
 
 func (c *ConfigHubClient) GetSpace(spaceID uuid.UUID) (*Space, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/space/%s", spaceID), nil, &Space{})
+	return c.GetSpaceContext(context.Background(), spaceID)
+}
+
+// GetSpaceContext is like GetSpace but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetSpaceContext(ctx context.Context, spaceID uuid.UUID) (*Space, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s", spaceID), nil, &Space{})
 	if err != nil {
 		return nil, err
 	}
@@ -229,8 +575,13 @@ type SpaceSummary struct {
 }
 
 func (c *ConfigHubClient) ListSpaces() ([]*Space, error) {
+	return c.ListSpacesContext(context.Background())
+}
+
+// ListSpacesContext is like ListSpaces but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListSpacesContext(ctx context.Context) ([]*Space, error) {
 	var summaries []SpaceSummary
-	if err := c.doRequestList("GET", "/space", nil, &summaries); err != nil {
+	if err := c.doRequestListContext(ctx, "GET", "/space", nil, &summaries); err != nil {
 		return nil, err
 	}
 
@@ -243,22 +594,188 @@ func (c *ConfigHubClient) ListSpaces() ([]*Space, error) {
 }
 
 func (c *ConfigHubClient) DeleteSpace(spaceID uuid.UUID) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/space/%s", spaceID), nil, nil)
+	return c.DeleteSpaceContext(context.Background(), spaceID)
+}
+
+// DeleteSpaceContext is like DeleteSpace but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) DeleteSpaceContext(ctx context.Context, spaceID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "DELETE", fmt.Sprintf("/space/%s", spaceID), nil, nil)
 	return err
 }
 
 // Unit operations
 
 func (c *ConfigHubClient) CreateUnit(spaceID uuid.UUID, req CreateUnitRequest) (*Unit, error) {
-	result, err := c.doRequest("POST", fmt.Sprintf("/space/%s/unit", spaceID), req, &Unit{})
+	return c.CreateUnitContext(context.Background(), spaceID, req)
+}
+
+// CreateUnitContext is like CreateUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateUnitContext(ctx context.Context, spaceID uuid.UUID, req CreateUnitRequest) (*Unit, error) {
+	var headers map[string]string
+	if key := c.unitIdempotencyKey(spaceID, req); key != "" {
+		headers = map[string]string{"Idempotency-Key": key}
+	}
+	respBody, err := c.sendWithHeaders(ctx, "POST", fmt.Sprintf("/space/%s/unit", spaceID), req, headers)
 	if err != nil {
 		return nil, err
 	}
-	return result.(*Unit), nil
+
+	unit := &Unit{}
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, unit); err != nil {
+			return nil, fmt.Errorf("unmarshal response: %w", err)
+		}
+	}
+	return unit, nil
+}
+
+// unitIdempotencyKey returns req's IdempotencyKey, or when POSTs are retried a
+// key hashed from the space, slug and request body, so every retry of one
+// create carries the same key while a create with different content doesn't
+// replay an earlier response
+func (c *ConfigHubClient) unitIdempotencyKey(spaceID uuid.UUID, req CreateUnitRequest) string {
+	if req.IdempotencyKey != "" {
+		return req.IdempotencyKey
+	}
+	if c.MaxRetries > 0 && c.RetryNonIdempotent {
+		body, _ := json.Marshal(req)
+		return uuid.NewSHA1(spaceID, append([]byte("unit/"+req.Slug+"/"), body...)).String()
+	}
+	return ""
+}
+
+// bulkCreateConcurrency bounds per-unit creates when the batch endpoint is unavailable
+const bulkCreateConcurrency = 8
+
+// BulkCreateError reports which requests in a BulkCreateUnits call failed
+type BulkCreateError struct {
+	Failures map[int]error // Request index -> error
+}
+
+func (e *BulkCreateError) Error() string {
+	indices := make([]int, 0, len(e.Failures))
+	for i := range e.Failures {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	messages := make([]string, 0, len(indices))
+	for _, i := range indices {
+		messages = append(messages, fmt.Sprintf("[%d] %v", i, e.Failures[i]))
+	}
+	return fmt.Sprintf("%d unit(s) failed to create: %s", len(e.Failures), strings.Join(messages, "; "))
+}
+
+// BulkCreateUnits creates many units in one batch call. The returned slice is in
+// request order, with nil entries for requests that failed; failures are
+// reported per index in a *BulkCreateError.
+func (c *ConfigHubClient) BulkCreateUnits(spaceID uuid.UUID, reqs []CreateUnitRequest) ([]*Unit, error) {
+	return c.BulkCreateUnitsContext(context.Background(), spaceID, reqs)
+}
+
+// BulkCreateUnitsContext is like BulkCreateUnits but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkCreateUnitsContext(ctx context.Context, spaceID uuid.UUID, reqs []CreateUnitRequest) ([]*Unit, error) {
+	if len(reqs) == 0 {
+		return []*Unit{}, nil
+	}
+
+	var response []struct {
+		Unit  *Unit  `json:"Unit"`
+		Error string `json:"Error,omitempty"`
+	}
+	err := c.doRequestListContext(ctx, "POST", fmt.Sprintf("/space/%s/unit/bulk-create", spaceID), reqs, &response)
+	if isNotFound(err) || isMethodNotAllowed(err) {
+		return c.createUnitsConcurrently(ctx, spaceID, reqs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	units := make([]*Unit, len(reqs))
+	failures := make(map[int]error)
+	for i := range reqs {
+		switch {
+		case i >= len(response):
+			failures[i] = fmt.Errorf("no result returned")
+		case response[i].Error != "":
+			failures[i] = fmt.Errorf("%s", response[i].Error)
+		default:
+			units[i] = response[i].Unit
+		}
+	}
+
+	if len(failures) > 0 {
+		return units, &BulkCreateError{Failures: failures}
+	}
+	return units, nil
+}
+
+// createUnitsConcurrently creates units one at a time with a bounded worker pool
+func (c *ConfigHubClient) createUnitsConcurrently(ctx context.Context, spaceID uuid.UUID, reqs []CreateUnitRequest) ([]*Unit, error) {
+	units := make([]*Unit, len(reqs))
+	failures := make(map[int]error)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, bulkCreateConcurrency)
+
+	for i, req := range reqs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, req CreateUnitRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			unit, err := c.CreateUnitContext(ctx, spaceID, req)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[i] = err
+				return
+			}
+			units[i] = unit
+		}(i, req)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return units, &BulkCreateError{Failures: failures}
+	}
+	return units, nil
 }
 
 func (c *ConfigHubClient) GetUnit(spaceID, unitID uuid.UUID) (*Unit, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/space/%s/unit/%s", spaceID, unitID), nil, &Unit{})
+	return c.GetUnitContext(context.Background(), spaceID, unitID)
+}
+
+// GetUnitContext is like GetUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetUnitContext(ctx context.Context, spaceID, unitID uuid.UUID) (*Unit, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/unit/%s", spaceID, unitID), nil, &Unit{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Unit), nil
+}
+
+func (c *ConfigHubClient) ListUnitRevisions(spaceID, unitID uuid.UUID) ([]UnitRevision, error) {
+	return c.ListUnitRevisionsContext(context.Background(), spaceID, unitID)
+}
+
+// ListUnitRevisionsContext is like ListUnitRevisions but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListUnitRevisionsContext(ctx context.Context, spaceID, unitID uuid.UUID) ([]UnitRevision, error) {
+	var revisions []UnitRevision
+	return revisions, c.doRequestListContext(ctx, "GET", fmt.Sprintf("/space/%s/unit/%s/revisions", spaceID, unitID), nil, &revisions)
+}
+
+// GetUnitAtRevision returns the unit as it was at the given revision
+func (c *ConfigHubClient) GetUnitAtRevision(spaceID, unitID uuid.UUID, revision int64) (*Unit, error) {
+	return c.GetUnitAtRevisionContext(context.Background(), spaceID, unitID, revision)
+}
+
+// GetUnitAtRevisionContext is like GetUnitAtRevision but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetUnitAtRevisionContext(ctx context.Context, spaceID, unitID uuid.UUID, revision int64) (*Unit, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/unit/%s/revisions/%d", spaceID, unitID, revision), nil, &Unit{})
 	if err != nil {
 		return nil, err
 	}
@@ -266,7 +783,12 @@ func (c *ConfigHubClient) GetUnit(spaceID, unitID uuid.UUID) (*Unit, error) {
 }
 
 func (c *ConfigHubClient) UpdateUnit(spaceID, unitID uuid.UUID, req CreateUnitRequest) (*Unit, error) {
-	result, err := c.doRequest("PUT", fmt.Sprintf("/space/%s/unit/%s", spaceID, unitID), req, &Unit{})
+	return c.UpdateUnitContext(context.Background(), spaceID, unitID, req)
+}
+
+// UpdateUnitContext is like UpdateUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) UpdateUnitContext(ctx context.Context, spaceID, unitID uuid.UUID, req CreateUnitRequest) (*Unit, error) {
+	result, err := c.doRequestContext(ctx, "PUT", fmt.Sprintf("/space/%s/unit/%s", spaceID, unitID), req, &Unit{})
 	if err != nil {
 		return nil, err
 	}
@@ -274,15 +796,27 @@ func (c *ConfigHubClient) UpdateUnit(spaceID, unitID uuid.UUID, req CreateUnitRe
 }
 
 func (c *ConfigHubClient) ListUnits(params ListUnitsParams) ([]*Unit, error) {
+	return c.ListUnitsContext(context.Background(), params)
+}
+
+// ListUnitsContext is like ListUnits but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListUnitsContext(ctx context.Context, params ListUnitsParams) ([]*Unit, error) {
 	// API returns wrapped format: [{"Unit": {...}}, ...]
 	var response []struct {
 		Unit *Unit `json:"Unit"`
 	}
 	endpoint := fmt.Sprintf("/space/%s/unit", params.SpaceID)
+	query := url.Values{}
 	if params.Where != "" {
-		endpoint += fmt.Sprintf("?where=%s", params.Where)
+		query.Set("where", params.Where)
 	}
-	err := c.doRequestList("GET", endpoint, nil, &response)
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Offset > 0 {
+		query.Set("offset", strconv.Itoa(params.Offset))
+	}
+	err := c.doRequestListContext(ctx, "GET", withQuery(endpoint, query), nil, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -308,20 +842,89 @@ func (c *ConfigHubClient) ListUnits(params ListUnitsParams) ([]*Unit, error) {
 	return units, nil
 }
 
+// defaultUnitPageSize is the page size ListAllUnits uses when params.Limit is unset
+const defaultUnitPageSize = 100
+
+// ListAllUnits pages through ListUnits until every matching unit has been fetched
+func (c *ConfigHubClient) ListAllUnits(params ListUnitsParams) ([]*Unit, error) {
+	return c.ListAllUnitsContext(context.Background(), params)
+}
+
+// ListAllUnitsContext is like ListAllUnits but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListAllUnitsContext(ctx context.Context, params ListUnitsParams) ([]*Unit, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultUnitPageSize
+	}
+
+	var all []*Unit
+	seen := make(map[uuid.UUID]bool)
+	for {
+		page, err := c.ListUnitsContext(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, unit := range page {
+			if seen[unit.UnitID] {
+				continue
+			}
+			seen[unit.UnitID] = true
+			all = append(all, unit)
+			added++
+		}
+
+		// A short page means we're done; a page with nothing new means the
+		// server is ignoring offset and would loop forever
+		if len(page) < params.Limit || added == 0 {
+			break
+		}
+		params.Offset += len(page)
+	}
+
+	return all, nil
+}
+
 func (c *ConfigHubClient) ApplyUnit(spaceID, unitID uuid.UUID) error {
-	_, err := c.doRequest("POST", fmt.Sprintf("/space/%s/unit/%s/apply", spaceID, unitID), nil, nil)
+	return c.ApplyUnitContext(context.Background(), spaceID, unitID)
+}
+
+// ApplyUnitContext is like ApplyUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ApplyUnitContext(ctx context.Context, spaceID, unitID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/unit/%s/apply", spaceID, unitID), nil, nil)
 	return err
 }
 
 func (c *ConfigHubClient) DestroyUnit(spaceID, unitID uuid.UUID) error {
-	_, err := c.doRequest("POST", fmt.Sprintf("/space/%s/unit/%s/destroy", spaceID, unitID), nil, nil)
+	return c.DestroyUnitContext(context.Background(), spaceID, unitID)
+}
+
+// DestroyUnitContext is like DestroyUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) DestroyUnitContext(ctx context.Context, spaceID, unitID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/unit/%s/destroy", spaceID, unitID), nil, nil)
+	return err
+}
+
+// DeleteUnit removes a unit from ConfigHub without touching live resources
+func (c *ConfigHubClient) DeleteUnit(spaceID, unitID uuid.UUID) error {
+	return c.DeleteUnitContext(context.Background(), spaceID, unitID)
+}
+
+// DeleteUnitContext is like DeleteUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) DeleteUnitContext(ctx context.Context, spaceID, unitID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "DELETE", fmt.Sprintf("/space/%s/unit/%s", spaceID, unitID), nil, nil)
 	return err
 }
 
 // Set operations (REAL)
 
 func (c *ConfigHubClient) CreateSet(spaceID uuid.UUID, req CreateSetRequest) (*Set, error) {
-	result, err := c.doRequest("POST", fmt.Sprintf("/space/%s/set", spaceID), req, &Set{})
+	return c.CreateSetContext(context.Background(), spaceID, req)
+}
+
+// CreateSetContext is like CreateSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateSetContext(ctx context.Context, spaceID uuid.UUID, req CreateSetRequest) (*Set, error) {
+	result, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/set", spaceID), req, &Set{})
 	if err != nil {
 		return nil, err
 	}
@@ -329,7 +932,12 @@ func (c *ConfigHubClient) CreateSet(spaceID uuid.UUID, req CreateSetRequest) (*S
 }
 
 func (c *ConfigHubClient) GetSet(spaceID, setID uuid.UUID) (*Set, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/space/%s/set/%s", spaceID, setID), nil, &Set{})
+	return c.GetSetContext(context.Background(), spaceID, setID)
+}
+
+// GetSetContext is like GetSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetSetContext(ctx context.Context, spaceID, setID uuid.UUID) (*Set, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/set/%s", spaceID, setID), nil, &Set{})
 	if err != nil {
 		return nil, err
 	}
@@ -337,7 +945,12 @@ func (c *ConfigHubClient) GetSet(spaceID, setID uuid.UUID) (*Set, error) {
 }
 
 func (c *ConfigHubClient) UpdateSet(spaceID, setID uuid.UUID, req CreateSetRequest) (*Set, error) {
-	result, err := c.doRequest("PUT", fmt.Sprintf("/space/%s/set/%s", spaceID, setID), req, &Set{})
+	return c.UpdateSetContext(context.Background(), spaceID, setID, req)
+}
+
+// UpdateSetContext is like UpdateSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) UpdateSetContext(ctx context.Context, spaceID, setID uuid.UUID, req CreateSetRequest) (*Set, error) {
+	result, err := c.doRequestContext(ctx, "PUT", fmt.Sprintf("/space/%s/set/%s", spaceID, setID), req, &Set{})
 	if err != nil {
 		return nil, err
 	}
@@ -345,227 +958,906 @@ func (c *ConfigHubClient) UpdateSet(spaceID, setID uuid.UUID, req CreateSetReque
 }
 
 func (c *ConfigHubClient) ListSets(spaceID uuid.UUID) ([]*Set, error) {
+	return c.ListSetsContext(context.Background(), spaceID)
+}
+
+// ListSetsContext is like ListSets but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListSetsContext(ctx context.Context, spaceID uuid.UUID) ([]*Set, error) {
 	var sets []*Set
-	return sets, c.doRequestList("GET", fmt.Sprintf("/space/%s/set", spaceID), nil, &sets)
+	return sets, c.doRequestListContext(ctx, "GET", fmt.Sprintf("/space/%s/set", spaceID), nil, &sets)
 }
 
 // Filter operations (REAL)
 
 func (c *ConfigHubClient) CreateFilter(spaceID uuid.UUID, req CreateFilterRequest) (*Filter, error) {
-	result, err := c.doRequest("POST", fmt.Sprintf("/space/%s/filter", spaceID), req, &Filter{})
+	return c.CreateFilterContext(context.Background(), spaceID, req)
+}
+
+// CreateFilterContext is like CreateFilter but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateFilterContext(ctx context.Context, spaceID uuid.UUID, req CreateFilterRequest) (*Filter, error) {
+	result, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/filter", spaceID), req, &Filter{})
 	if err != nil {
 		return nil, err
 	}
-	return result.(*Filter), nil
+	return result.(*Filter), nil
+}
+
+func (c *ConfigHubClient) GetFilter(spaceID, filterID uuid.UUID) (*Filter, error) {
+	return c.GetFilterContext(context.Background(), spaceID, filterID)
+}
+
+// GetFilterContext is like GetFilter but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetFilterContext(ctx context.Context, spaceID, filterID uuid.UUID) (*Filter, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/filter/%s", spaceID, filterID), nil, &Filter{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Filter), nil
+}
+
+// GetFilteredUnits lists the units matching a stored filter's WHERE clause.
+// If the filter has a Select projection, only those fields (plus UnitID and
+// SpaceID) are kept on each unit.
+func (c *ConfigHubClient) GetFilteredUnits(filterID, spaceID uuid.UUID) ([]*Unit, error) {
+	return c.GetFilteredUnitsContext(context.Background(), filterID, spaceID)
+}
+
+// GetFilteredUnitsContext is like GetFilteredUnits but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetFilteredUnitsContext(ctx context.Context, filterID, spaceID uuid.UUID) ([]*Unit, error) {
+	filter, err := c.GetFilterContext(ctx, spaceID, filterID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("filter %s not found in space %s", filterID, spaceID)
+		}
+		return nil, fmt.Errorf("get filter: %w", err)
+	}
+
+	// A filter may select units from another space
+	unitSpaceID := spaceID
+	if filter.FromSpaceID != nil {
+		unitSpaceID = *filter.FromSpaceID
+	}
+
+	units, err := c.ListAllUnitsContext(ctx, ListUnitsParams{
+		SpaceID: unitSpaceID,
+		Where:   filter.Where,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list units for filter %s: %w", filter.Slug, err)
+	}
+
+	if len(filter.Select) == 0 {
+		return units, nil
+	}
+	for i, unit := range units {
+		if units[i], err = selectUnitFields(unit, filter.Select); err != nil {
+			return nil, fmt.Errorf("select fields for unit %s: %w", unit.Slug, err)
+		}
+	}
+	return units, nil
+}
+
+// selectUnitFields returns a copy of unit with only the named fields set,
+// always keeping the UnitID, SpaceID and Slug needed to act on it
+func selectUnitFields(unit *Unit, fields []string) (*Unit, error) {
+	data, err := json.Marshal(unit)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	selected := map[string]json.RawMessage{
+		"UnitID":  all["UnitID"],
+		"SpaceID": all["SpaceID"],
+		"Slug":    all["Slug"],
+	}
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+
+	data, err = json.Marshal(selected)
+	if err != nil {
+		return nil, err
+	}
+	projected := &Unit{}
+	if err := json.Unmarshal(data, projected); err != nil {
+		return nil, err
+	}
+	return projected, nil
+}
+
+// Link operations
+
+func (c *ConfigHubClient) CreateLink(spaceID uuid.UUID, req CreateLinkRequest) (*Link, error) {
+	return c.CreateLinkContext(context.Background(), spaceID, req)
+}
+
+// CreateLinkContext is like CreateLink but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateLinkContext(ctx context.Context, spaceID uuid.UUID, req CreateLinkRequest) (*Link, error) {
+	result, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/link", spaceID), req, &Link{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Link), nil
+}
+
+func (c *ConfigHubClient) GetLink(spaceID, linkID uuid.UUID) (*Link, error) {
+	return c.GetLinkContext(context.Background(), spaceID, linkID)
+}
+
+// GetLinkContext is like GetLink but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetLinkContext(ctx context.Context, spaceID, linkID uuid.UUID) (*Link, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/link/%s", spaceID, linkID), nil, &Link{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Link), nil
+}
+
+func (c *ConfigHubClient) UpdateLink(spaceID, linkID uuid.UUID, req CreateLinkRequest) (*Link, error) {
+	return c.UpdateLinkContext(context.Background(), spaceID, linkID, req)
+}
+
+// UpdateLinkContext is like UpdateLink but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) UpdateLinkContext(ctx context.Context, spaceID, linkID uuid.UUID, req CreateLinkRequest) (*Link, error) {
+	result, err := c.doRequestContext(ctx, "PUT", fmt.Sprintf("/space/%s/link/%s", spaceID, linkID), req, &Link{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Link), nil
+}
+
+func (c *ConfigHubClient) ListLinks(spaceID uuid.UUID) ([]*Link, error) {
+	return c.ListLinksContext(context.Background(), spaceID)
+}
+
+// ListLinksContext is like ListLinks but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListLinksContext(ctx context.Context, spaceID uuid.UUID) ([]*Link, error) {
+	var links []*Link
+	return links, c.doRequestListContext(ctx, "GET", fmt.Sprintf("/space/%s/link", spaceID), nil, &links)
+}
+
+func (c *ConfigHubClient) DeleteLink(spaceID, linkID uuid.UUID) error {
+	return c.DeleteLinkContext(context.Background(), spaceID, linkID)
+}
+
+// DeleteLinkContext is like DeleteLink but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) DeleteLinkContext(ctx context.Context, spaceID, linkID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "DELETE", fmt.Sprintf("/space/%s/link/%s", spaceID, linkID), nil, nil)
+	return err
+}
+
+// Bulk operations (REAL)
+
+func (c *ConfigHubClient) BulkApplyUnits(params BulkApplyParams) error {
+	return c.BulkApplyUnitsContext(context.Background(), params)
+}
+
+// BulkApplyUnitsContext is like BulkApplyUnits but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkApplyUnitsContext(ctx context.Context, params BulkApplyParams) error {
+	_, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/unit/bulk-apply", params.SpaceID), params, nil)
+	return err
+}
+
+// PlanBulkApply previews a bulk apply: it sends params as a dry run and
+// returns the server's per-unit plan. When the server returns no plan, the
+// plan is synthesized by comparing each matched unit with its live state.
+func (c *ConfigHubClient) PlanBulkApply(params BulkApplyParams) (*ApplyPlan, error) {
+	return c.PlanBulkApplyContext(context.Background(), params)
+}
+
+// PlanBulkApplyContext is like PlanBulkApply but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) PlanBulkApplyContext(ctx context.Context, params BulkApplyParams) (*ApplyPlan, error) {
+	params.DryRun = true
+	respBody, err := c.send(ctx, "POST", fmt.Sprintf("/space/%s/unit/bulk-apply", params.SpaceID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ApplyPlan{}
+	if len(bytes.TrimSpace(respBody)) > 0 {
+		if err := json.Unmarshal(respBody, plan); err != nil {
+			return nil, fmt.Errorf("unmarshal plan: %w", err)
+		}
+	}
+	plan.SpaceID = params.SpaceID
+	plan.Where = params.Where
+	if plan.Units != nil {
+		return plan, nil
+	}
+
+	units, err := c.ListAllUnitsContext(ctx, ListUnitsParams{SpaceID: params.SpaceID, Where: params.Where})
+	if err != nil {
+		return nil, fmt.Errorf("list units to plan: %w", err)
+	}
+	plan.Synthesized = true
+	plan.Units = make([]UnitApplyPlan, 0, len(units))
+	for _, unit := range units {
+		entry, err := c.planUnitApply(ctx, params.SpaceID, unit)
+		if err != nil {
+			return nil, err
+		}
+		plan.Units = append(plan.Units, entry)
+	}
+	return plan, nil
+}
+
+// planUnitApply decides what applying unit would do from its live state
+func (c *ConfigHubClient) planUnitApply(ctx context.Context, spaceID uuid.UUID, unit *Unit) (UnitApplyPlan, error) {
+	entry := UnitApplyPlan{UnitID: unit.UnitID, Slug: unit.Slug, Action: ApplyNoOp}
+
+	state, err := c.GetUnitLiveStateContext(ctx, spaceID, unit.UnitID)
+	switch {
+	case isNotFound(err):
+		entry.Action, entry.Reason = ApplyCreate, "never applied"
+	case err != nil:
+		return entry, fmt.Errorf("get live state of %s: %w", unit.Slug, err)
+	case state.LastAppliedAt.IsZero():
+		entry.Action, entry.Reason = ApplyCreate, "never applied"
+	case state.LastError != "":
+		entry.Action, entry.Reason = ApplyUpdate, "last apply failed: "+state.LastError
+	case state.DriftDetected:
+		entry.Action, entry.Reason = ApplyUpdate, "live state drifted"
+	case unit.UpdatedAt.After(state.LastAppliedAt):
+		entry.Action, entry.Reason = ApplyUpdate, "changed since last apply"
+	}
+	return entry, nil
+}
+
+func (c *ConfigHubClient) BulkPatchUnits(params BulkPatchParams) error {
+	return c.BulkPatchUnitsContext(context.Background(), params)
+}
+
+// BulkPatchUnitsContext is like BulkPatchUnits but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkPatchUnitsContext(ctx context.Context, params BulkPatchParams) error {
+	if _, ok := params.Patch["Data"]; ok && !params.AllowDataOverwrite {
+		log.Printf("⚠️  Bulk patch where %q overwrites Data of every matched unit; set AllowDataOverwrite if intended", params.Where)
+	}
+	_, err := c.doRequestContext(ctx, "PATCH", fmt.Sprintf("/space/%s/unit/bulk-patch", params.SpaceID), params, nil)
+	return err
+}
+
+// BulkSetLabels merges labels into the Labels of every unit matching where,
+// leaving other labels, annotations and Data untouched
+func (c *ConfigHubClient) BulkSetLabels(spaceID uuid.UUID, where string, labels map[string]string) error {
+	return c.BulkSetLabelsContext(context.Background(), spaceID, where, labels)
+}
+
+// BulkSetLabelsContext is like BulkSetLabels but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkSetLabelsContext(ctx context.Context, spaceID uuid.UUID, where string, labels map[string]string) error {
+	return c.bulkPatchMap(ctx, spaceID, where, "Labels", stringMapPatch(labels))
+}
+
+// BulkSetAnnotations merges annotations into the Annotations of every unit
+// matching where, leaving other annotations, labels and Data untouched
+func (c *ConfigHubClient) BulkSetAnnotations(spaceID uuid.UUID, where string, annotations map[string]string) error {
+	return c.BulkSetAnnotationsContext(context.Background(), spaceID, where, annotations)
+}
+
+// BulkSetAnnotationsContext is like BulkSetAnnotations but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkSetAnnotationsContext(ctx context.Context, spaceID uuid.UUID, where string, annotations map[string]string) error {
+	return c.bulkPatchMap(ctx, spaceID, where, "Annotations", stringMapPatch(annotations))
+}
+
+// BulkRemoveLabels deletes the label keys from every unit matching where
+func (c *ConfigHubClient) BulkRemoveLabels(spaceID uuid.UUID, where string, keys ...string) error {
+	return c.BulkRemoveLabelsContext(context.Background(), spaceID, where, keys...)
+}
+
+// BulkRemoveLabelsContext is like BulkRemoveLabels but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkRemoveLabelsContext(ctx context.Context, spaceID uuid.UUID, where string, keys ...string) error {
+	removals := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		removals[key] = nil // null deletes the key in a merge patch
+	}
+	return c.bulkPatchMap(ctx, spaceID, where, "Labels", removals)
+}
+
+// bulkPatchMap sends a merge patch touching only the field map of the units
+// matching where. An empty where is rejected so a typo can't patch the whole space.
+func (c *ConfigHubClient) bulkPatchMap(ctx context.Context, spaceID uuid.UUID, where, field string, entries map[string]interface{}) error {
+	if where == "" {
+		return fmt.Errorf("bulk patch %s: where clause is required", field)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return c.BulkPatchUnitsContext(ctx, BulkPatchParams{
+		SpaceID: spaceID,
+		Where:   where,
+		Patch:   map[string]interface{}{field: entries},
+	})
+}
+
+// stringMapPatch converts labels or annotations to merge patch entries
+func stringMapPatch(values map[string]string) map[string]interface{} {
+	entries := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		entries[k] = v
+	}
+	return entries
+}
+
+// Live State (READ-ONLY)
+
+func (c *ConfigHubClient) GetUnitLiveState(spaceID, unitID uuid.UUID) (*LiveState, error) {
+	return c.GetUnitLiveStateContext(context.Background(), spaceID, unitID)
+}
+
+// GetUnitLiveStateContext is like GetUnitLiveState but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetUnitLiveStateContext(ctx context.Context, spaceID, unitID uuid.UUID) (*LiveState, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/unit/%s/live-state", spaceID, unitID), nil, &LiveState{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*LiveState), nil
+}
+
+// Target operations
+
+func (c *ConfigHubClient) CreateTarget(req Target) (*Target, error) {
+	return c.CreateTargetContext(context.Background(), req)
+}
+
+// CreateTargetContext is like CreateTarget but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateTargetContext(ctx context.Context, req Target) (*Target, error) {
+	result, err := c.doRequestContext(ctx, "POST", "/target", req, &Target{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Target), nil
+}
+
+func (c *ConfigHubClient) GetTarget(targetID uuid.UUID) (*Target, error) {
+	return c.GetTargetContext(context.Background(), targetID)
+}
+
+// GetTargetContext is like GetTarget but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetTargetContext(ctx context.Context, targetID uuid.UUID) (*Target, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/target/%s", targetID), nil, &Target{})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Target), nil
+}
+
+// GetNewSpacePrefix calls ConfigHub to generate a unique space prefix
+// (like cub space new-prefix)
+func (c *ConfigHubClient) GetNewSpacePrefix() (string, error) {
+	return c.GetNewSpacePrefixContext(context.Background())
+}
+
+// GetNewSpacePrefixContext is like GetNewSpacePrefix but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetNewSpacePrefixContext(ctx context.Context) (string, error) {
+	var resp struct {
+		Prefix string `json:"Prefix"`
+	}
+	_, err := c.doRequestContext(ctx, "POST", "/space/new-prefix", nil, &resp)
+	if err == nil {
+		if resp.Prefix != "" {
+			return resp.Prefix, nil
+		}
+		err = fmt.Errorf("empty prefix in response")
+	}
+
+	if c.OfflinePrefixFallback && ctx.Err() == nil {
+		return localSpacePrefix(), nil
+	}
+	return "", fmt.Errorf("get new space prefix: %w", err)
+}
+
+// prefixRand is seeded once; reseeding from the clock on every call made
+// prefixes generated in quick succession correlate
+var (
+	prefixRandMu sync.Mutex
+	prefixRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// localSpacePrefix generates a readable adjective-noun prefix without ConfigHub
+func localSpacePrefix() string {
+	adjectives := []string{"happy", "clever", "swift", "bright", "gentle", "brave", "calm", "eager", "jolly", "lucky", "mellow", "nimble", "quiet", "rapid", "sunny", "witty"}
+	nouns := []string{"paws", "tail", "whisker", "cloud", "star", "river", "maple", "comet", "harbor", "meadow", "pebble", "falcon", "otter", "summit", "lantern", "breeze"}
+
+	prefixRandMu.Lock()
+	defer prefixRandMu.Unlock()
+	return fmt.Sprintf("%s-%s", adjectives[prefixRand.Intn(len(adjectives))], nouns[prefixRand.Intn(len(nouns))])
+}
+
+// Helper methods
+
+func (c *ConfigHubClient) doRequest(method, endpoint string, body interface{}, result interface{}) (interface{}, error) {
+	return c.doRequestContext(context.Background(), method, endpoint, body, result)
+}
+
+func (c *ConfigHubClient) doRequestContext(ctx context.Context, method, endpoint string, body interface{}, result interface{}) (interface{}, error) {
+	respBody, err := c.send(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return nil, fmt.Errorf("unmarshal response: %w", err)
+		}
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+func (c *ConfigHubClient) doRequestList(method, endpoint string, body interface{}, result interface{}) error {
+	return c.doRequestListContext(context.Background(), method, endpoint, body, result)
+}
+
+func (c *ConfigHubClient) doRequestListContext(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	respBody, err := c.send(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("unmarshal response (preview: %s...): %w", string(respBody[:min(100, len(respBody))]), err)
+		}
+	}
+
+	return nil
+}
+
+// send performs a request, retrying transient failures with exponential backoff,
+// and returns the response body of the final successful attempt
+func (c *ConfigHubClient) send(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	return c.sendWithHeaders(ctx, method, endpoint, body, nil)
+}
+
+// sendWithHeaders is like send but adds headers to every attempt
+func (c *ConfigHubClient) sendWithHeaders(ctx context.Context, method, endpoint string, body interface{}, headers map[string]string) (_ []byte, err error) {
+	ctx, span := startSpan(ctx, "ConfigHub "+method)
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("confighub.endpoint", endpoint)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	url := c.baseURL + endpoint
+
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	retries := c.MaxRetries
+	if !c.RetryNonIdempotent && (method == "POST" || method == "PATCH") {
+		retries = 0
+	}
+	delay := c.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limit: %w", err)
+			}
+		}
+
+		var reqBody io.Reader
+		if jsonData != nil {
+			reqBody = bytes.NewReader(jsonData)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		// Debug logging
+		if os.Getenv("CUB_DEBUG") == "true" {
+			log.Printf("DEBUG: %s %s", method, url)
+			log.Printf("DEBUG: Authorization: Bearer %s...", c.token[:min(20, len(c.token))])
+		}
+
+		wait := delay
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if attempt >= retries || ctx.Err() != nil {
+				return nil, fmt.Errorf("send request: %w", err)
+			}
+		} else {
+			span.SetAttribute("http.status_code", resp.StatusCode)
+			span.SetAttribute("http.attempts", attempt+1)
+			respBody, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, fmt.Errorf("read response: %w", readErr)
+			}
+
+			// Debug logging
+			if os.Getenv("CUB_DEBUG") == "true" {
+				log.Printf("DEBUG: Response status: %d", resp.StatusCode)
+				log.Printf("DEBUG: Response body preview: %s", string(respBody[:min(200, len(respBody))]))
+			}
+
+			if resp.StatusCode < 400 {
+				return respBody, nil
+			}
+			if attempt >= retries || !isRetryableStatus(resp.StatusCode) {
+				return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+			}
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+		}
+
+		if wait > maxRetryDelay {
+			wait = maxRetryDelay
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("send request: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// isRetryableStatus reports whether a response status indicates a transient failure
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
 }
 
-func (c *ConfigHubClient) GetFilter(spaceID, filterID uuid.UUID) (*Filter, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/space/%s/filter/%s", spaceID, filterID), nil, &Filter{})
-	if err != nil {
-		return nil, err
+// isTransient reports whether a request error may succeed if retried: a
+// retryable API status, or failing to reach the server at all
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
 	}
-	return result.(*Filter), nil
+	if apiErr := asAPIError(err); apiErr != nil {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
-// Bulk operations (REAL)
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
 
-func (c *ConfigHubClient) BulkApplyUnits(params BulkApplyParams) error {
-	_, err := c.doRequest("POST", fmt.Sprintf("/space/%s/unit/bulk-apply", params.SpaceID), params, nil)
-	return err
+// APIError is a ConfigHub API response with an error status. Client methods
+// return it, possibly wrapped, so callers can check the status with errors.As:
+//
+//	var apiErr *APIError
+//	if errors.As(err, &apiErr) && apiErr.IsNotFound() { ... }
+type APIError struct {
+	StatusCode int
+	Body       string
 }
 
-func (c *ConfigHubClient) BulkPatchUnits(params BulkPatchParams) error {
-	_, err := c.doRequest("PATCH", fmt.Sprintf("/space/%s/unit/bulk-patch", params.SpaceID), params, nil)
-	return err
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
 }
 
-// Live State (READ-ONLY)
+// IsNotFound reports a 404 response
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
 
-func (c *ConfigHubClient) GetUnitLiveState(spaceID, unitID uuid.UUID) (*LiveState, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/space/%s/unit/%s/live-state", spaceID, unitID), nil, &LiveState{})
-	if err != nil {
-		return nil, err
-	}
-	return result.(*LiveState), nil
+// IsConflict reports a 409 response, or a server that says the object
+// already exists with another status
+func (e *APIError) IsConflict() bool {
+	return e.StatusCode == http.StatusConflict || strings.Contains(e.Body, "already exists")
 }
 
-// Target operations
+// IsRateLimited reports a 429 response
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
 
-func (c *ConfigHubClient) CreateTarget(req Target) (*Target, error) {
-	result, err := c.doRequest("POST", "/target", req, &Target{})
-	if err != nil {
-		return nil, err
-	}
-	return result.(*Target), nil
+// IsUnauthorized reports a 401 or 403 response: a missing, expired or
+// insufficiently privileged token
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
 }
 
-func (c *ConfigHubClient) GetTarget(targetID uuid.UUID) (*Target, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/target/%s", targetID), nil, &Target{})
-	if err != nil {
-		return nil, err
+// asAPIError returns the APIError in err's chain, or nil
+func asAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
 	}
-	return result.(*Target), nil
+	return nil
 }
 
-// GetNewSpacePrefix calls ConfigHub to generate a unique space prefix
-// Returns something like "chubby-paws" or "whisker-tail"
-func (c *ConfigHubClient) GetNewSpacePrefix() (string, error) {
-	// This would typically call: cub space new-prefix
-	// Since we don't have direct CLI access, we'd need to call the API endpoint
-	// For now, this is a placeholder that would need the actual API endpoint
-
-	// In practice, this would be:
-	// result, err := c.doRequest("POST", "/space/new-prefix", nil, &struct{Prefix string})
-	// return result.Prefix, err
+// isMethodNotAllowed reports whether err is an API 405 response
+func isMethodNotAllowed(err error) bool {
+	apiErr := asAPIError(err)
+	return apiErr != nil && apiErr.StatusCode == http.StatusMethodNotAllowed
+}
 
-	// For demonstration, generate a readable prefix
-	adjectives := []string{"happy", "clever", "swift", "bright", "gentle"}
-	nouns := []string{"paws", "tail", "whisker", "cloud", "star"}
+// isConflict reports whether err is an API 409 or "already exists" response
+func isConflict(err error) bool {
+	apiErr := asAPIError(err)
+	return apiErr != nil && apiErr.IsConflict()
+}
 
-	adj := adjectives[time.Now().UnixNano()%int64(len(adjectives))]
-	noun := nouns[time.Now().UnixNano()%int64(len(nouns))]
+// isNotFound reports whether err is an API 404 response
+func isNotFound(err error) bool {
+	apiErr := asAPIError(err)
+	return apiErr != nil && apiErr.IsNotFound()
+}
 
-	return fmt.Sprintf("%s-%s", adj, noun), nil
+// withQuery appends URL-encoded query parameters to an endpoint. Query values
+// must always go through here: WHERE clauses contain spaces, '=' and quotes.
+func withQuery(endpoint string, query url.Values) string {
+	if len(query) == 0 {
+		return endpoint
+	}
+	return endpoint + "?" + query.Encode()
 }
 
-// Helper methods
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
 
-func (c *ConfigHubClient) doRequest(method, endpoint string, body interface{}, result interface{}) (interface{}, error) {
-	url := c.baseURL + endpoint
+// High-level convenience helpers
 
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("marshal request: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonData)
-	}
+// GetSpaceBySlug finds a space by its slug name. If there is none, the error
+// is a 404 *APIError.
+func (c *ConfigHubClient) GetSpaceBySlug(slug string) (*Space, error) {
+	return c.GetSpaceBySlugContext(context.Background(), slug)
+}
 
-	req, err := http.NewRequest(method, url, reqBody)
+// GetSpaceBySlugContext is like GetSpaceBySlug but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetSpaceBySlugContext(ctx context.Context, slug string) (*Space, error) {
+	space, err := c.findSpaceBySlug(ctx, slug)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
 	}
+	if space == nil {
+		return nil, &APIError{StatusCode: http.StatusNotFound, Body: fmt.Sprintf("space not found: %s", slug)}
+	}
+	return space, nil
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
+// ListDownstreamSpaces finds the spaces downstream of baseSpaceID: spaces with
+// units whose UpstreamUnitID points into the base space, then spaces cloned
+// from those, and so on. Results are ordered by depth (then slug), so a
+// promotion can walk the chain. If the base space has a "project" label, only
+// spaces with the same label are searched.
+func (c *ConfigHubClient) ListDownstreamSpaces(baseSpaceID uuid.UUID) ([]*Space, error) {
+	return c.ListDownstreamSpacesContext(context.Background(), baseSpaceID)
+}
 
-	resp, err := c.client.Do(req)
+// ListDownstreamSpacesContext is like ListDownstreamSpaces but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListDownstreamSpacesContext(ctx context.Context, baseSpaceID uuid.UUID) ([]*Space, error) {
+	base, err := c.GetSpaceContext(ctx, baseSpaceID)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, fmt.Errorf("get base space: %w", err)
+	}
+	spaces, err := c.ListSpacesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list spaces: %w", err)
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	baseUnits, err := c.ListAllUnitsContext(ctx, ListUnitsParams{SpaceID: baseSpaceID})
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("list units in base space: %w", err)
+	}
+	upstream := make(map[uuid.UUID]bool, len(baseUnits))
+	for _, unit := range baseUnits {
+		upstream[unit.UnitID] = true
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	project := base.Labels["project"]
+	candidates := make(map[uuid.UUID][]*Unit)
+	bySpaceID := make(map[uuid.UUID]*Space)
+	for _, space := range spaces {
+		if space == nil || space.SpaceID == baseSpaceID {
+			continue
+		}
+		if project != "" && space.Labels["project"] != project {
+			continue
+		}
+		units, err := c.ListAllUnitsContext(ctx, ListUnitsParams{SpaceID: space.SpaceID})
+		if err != nil {
+			return nil, fmt.Errorf("list units in space %s: %w", space.Slug, err)
+		}
+		candidates[space.SpaceID] = units
+		bySpaceID[space.SpaceID] = space
 	}
 
-	if result != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return nil, fmt.Errorf("unmarshal response: %w", err)
+	// Walk one level at a time: a space is at depth n when its units clone
+	// units of a space at depth n-1
+	var downstream []*Space
+	for len(candidates) > 0 {
+		var level []*Space
+		for spaceID, units := range candidates {
+			for _, unit := range units {
+				if unit.UpstreamUnitID != nil && upstream[*unit.UpstreamUnitID] {
+					level = append(level, bySpaceID[spaceID])
+					break
+				}
+			}
 		}
-		return result, nil
+		if len(level) == 0 {
+			break
+		}
+
+		sort.Slice(level, func(i, j int) bool { return level[i].Slug < level[j].Slug })
+		upstream = make(map[uuid.UUID]bool)
+		for _, space := range level {
+			for _, unit := range candidates[space.SpaceID] {
+				upstream[unit.UnitID] = true
+			}
+			delete(candidates, space.SpaceID)
+		}
+		downstream = append(downstream, level...)
 	}
 
-	return nil, nil
+	return downstream, nil
 }
 
-func (c *ConfigHubClient) doRequestList(method, endpoint string, body interface{}, result interface{}) error {
-	url := c.baseURL + endpoint
+// EnsureSpace returns the space with req.Slug, creating it if there is none.
+// created reports whether this call made it. If the create fails because a
+// concurrent caller made the space first, their space is returned.
+func (c *ConfigHubClient) EnsureSpace(req CreateSpaceRequest) (space *Space, created bool, err error) {
+	return c.EnsureSpaceContext(context.Background(), req)
+}
 
-	var reqBody io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("marshal request: %w", err)
-		}
-		reqBody = bytes.NewBuffer(jsonData)
+// EnsureSpaceContext is like EnsureSpace but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) EnsureSpaceContext(ctx context.Context, req CreateSpaceRequest) (*Space, bool, error) {
+	space, err := c.findSpaceBySlug(ctx, req.Slug)
+	if err != nil || space != nil {
+		return space, false, err
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+	space, createErr := c.CreateSpaceContext(ctx, req)
+	if createErr == nil {
+		return space, true, nil
+	}
+	if apiErr := asAPIError(createErr); apiErr != nil && apiErr.IsConflict() {
+		if space, err := c.findSpaceBySlug(ctx, req.Slug); err == nil && space != nil {
+			return space, false, nil
+		}
 	}
+	return nil, false, fmt.Errorf("create space %s: %w", req.Slug, createErr)
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
-	req.Header.Set("Content-Type", "application/json")
+// EnsureUnit returns the unit with req.Slug in spaceID, creating it if there
+// is none. created reports whether this call made it. If the create fails
+// because a concurrent caller made the unit first, their unit is returned.
+func (c *ConfigHubClient) EnsureUnit(spaceID uuid.UUID, req CreateUnitRequest) (unit *Unit, created bool, err error) {
+	return c.EnsureUnitContext(context.Background(), spaceID, req)
+}
 
-	// Debug logging
-	if os.Getenv("CUB_DEBUG") == "true" {
-		log.Printf("DEBUG: %s %s", method, url)
-		log.Printf("DEBUG: Authorization: Bearer %s...", c.token[:20])
+// EnsureUnitContext is like EnsureUnit but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) EnsureUnitContext(ctx context.Context, spaceID uuid.UUID, req CreateUnitRequest) (*Unit, bool, error) {
+	unit, err := c.findUnitBySlug(ctx, spaceID, req.Slug)
+	if err != nil || unit != nil {
+		return unit, false, err
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("send request: %w", err)
+	unit, createErr := c.CreateUnitContext(ctx, spaceID, req)
+	if createErr == nil {
+		return unit, true, nil
 	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("read response: %w", err)
+	if apiErr := asAPIError(createErr); apiErr != nil && apiErr.IsConflict() {
+		if unit, err := c.findUnitBySlug(ctx, spaceID, req.Slug); err == nil && unit != nil {
+			return unit, false, nil
+		}
 	}
+	return nil, false, fmt.Errorf("create unit %s: %w", req.Slug, createErr)
+}
 
-	// Debug logging
-	if os.Getenv("CUB_DEBUG") == "true" {
-		log.Printf("DEBUG: Response status: %d", resp.StatusCode)
-		log.Printf("DEBUG: Response body preview: %s", string(respBody[:min(200, len(respBody))]))
-	}
+// EnsureFilter returns the filter with req.Slug in spaceID, creating it if
+// there is none, with the same semantics as EnsureUnit
+func (c *ConfigHubClient) EnsureFilter(spaceID uuid.UUID, req CreateFilterRequest) (filter *Filter, created bool, err error) {
+	return c.EnsureFilterContext(context.Background(), spaceID, req)
+}
 
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+// EnsureFilterContext is like EnsureFilter but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) EnsureFilterContext(ctx context.Context, spaceID uuid.UUID, req CreateFilterRequest) (*Filter, bool, error) {
+	filter, err := c.findFilterBySlug(ctx, spaceID, req.Slug)
+	if err != nil || filter != nil {
+		return filter, false, err
 	}
 
-	if len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("unmarshal response (preview: %s...): %w", string(respBody[:min(100, len(respBody))]), err)
+	filter, createErr := c.CreateFilterContext(ctx, spaceID, req)
+	if createErr == nil {
+		return filter, true, nil
+	}
+	if apiErr := asAPIError(createErr); apiErr != nil && apiErr.IsConflict() {
+		if filter, err := c.findFilterBySlug(ctx, spaceID, req.Slug); err == nil && filter != nil {
+			return filter, false, nil
 		}
 	}
-
-	return nil
+	return nil, false, fmt.Errorf("create filter %s: %w", req.Slug, createErr)
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// findUnitBySlug returns the unit with slug in spaceID, or nil if there is none
+func (c *ConfigHubClient) findUnitBySlug(ctx context.Context, spaceID uuid.UUID, slug string) (*Unit, error) {
+	units, err := c.ListUnitsContext(ctx, ListUnitsParams{
+		SpaceID: spaceID,
+		Where:   NewWhere().Eq("Slug", slug).MustBuild(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list units: %w", err)
 	}
-	return b
-}
 
-// High-level convenience helpers
+	for _, unit := range units {
+		if unit.Slug == slug {
+			return unit, nil
+		}
+	}
+	return nil, nil
+}
 
-// GetSpaceBySlug finds a space by its slug name
-func (c *ConfigHubClient) GetSpaceBySlug(slug string) (*Space, error) {
-	spaces, err := c.ListSpaces()
+// findFilterBySlug returns the filter with slug in spaceID, or nil if there is none
+func (c *ConfigHubClient) findFilterBySlug(ctx context.Context, spaceID uuid.UUID, slug string) (*Filter, error) {
+	filters, err := c.ListFiltersContext(ctx, spaceID)
 	if err != nil {
-		return nil, fmt.Errorf("list spaces: %w", err)
+		return nil, fmt.Errorf("list filters: %w", err)
 	}
 
-	// Filter by slug
-	for i, space := range spaces {
-		if space.Slug == slug {
-			return spaces[i], nil
+	for _, filter := range filters {
+		if filter.Slug == slug {
+			return filter, nil
 		}
 	}
-
-	return nil, fmt.Errorf("space not found: %s", slug)
+	return nil, nil
 }
 
 // CreateSpaceWithUniquePrefix creates a space with a unique prefix + suffix
 func (c *ConfigHubClient) CreateSpaceWithUniquePrefix(suffix string, displayName string, labels map[string]string) (*Space, string, error) {
-	prefix, err := c.GetNewSpacePrefix()
+	return c.CreateSpaceWithUniquePrefixContext(context.Background(), suffix, displayName, labels)
+}
+
+// CreateSpaceWithUniquePrefixContext is like CreateSpaceWithUniquePrefix but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateSpaceWithUniquePrefixContext(ctx context.Context, suffix string, displayName string, labels map[string]string) (*Space, string, error) {
+	prefix, err := c.GetNewSpacePrefixContext(ctx)
 	if err != nil {
 		return nil, "", fmt.Errorf("get unique prefix: %w", err)
 	}
 
 	slug := fmt.Sprintf("%s-%s", prefix, suffix)
-	space, err := c.CreateSpace(CreateSpaceRequest{
+	space, err := c.CreateSpaceContext(ctx, CreateSpaceRequest{
 		Slug:        slug,
 		DisplayName: displayName,
 		Labels:      labels,
@@ -577,39 +1869,101 @@ func (c *ConfigHubClient) CreateSpaceWithUniquePrefix(suffix string, displayName
 	return space, slug, nil
 }
 
+// ErrSpaceExists is returned by EnsureSpaceRecreated when the space exists and
+// Force is not set, or when another client recreates it first
+var ErrSpaceExists = errors.New("space already exists")
+
+// RecreateSpaceOptions gates the destructive path of EnsureSpaceRecreated
+type RecreateSpaceOptions struct {
+	Force     bool   // Required to delete an existing space and its units
+	BackupDir string // If set, the space is backed up there with PackageHelper.BackupSpace before deletion
+}
+
+// RecreateSpaceResult reports what EnsureSpaceRecreated did
+type RecreateSpaceResult struct {
+	Space      *Space
+	Deleted    bool   // An existing space was deleted
+	BackupPath string // Backup package of the deleted space, if one was made
+}
+
 // EnsureSpaceRecreated implements the delete-then-create pattern for spaces.
-// If a space with the given slug exists, it deletes it completely first.
-// Then creates a fresh space with the same slug.
-// This ensures we always start with a clean slate and avoid stale configurations.
-func (c *ConfigHubClient) EnsureSpaceRecreated(req CreateSpaceRequest) (*Space, error) {
-	// First, try to find existing space by slug
-	existingSpace, err := c.GetSpaceBySlug(req.Slug)
-	if err == nil && existingSpace != nil {
-		// Space exists, delete it first
-		fmt.Printf("Deleting existing space: %s\n", req.Slug)
-		if err := c.DeleteSpace(existingSpace.SpaceID); err != nil {
+// If a space with the given slug exists, it is deleted (after an optional
+// backup) and a fresh space with the same slug is created. Deleting requires
+// opts.Force; without it an existing space is left alone and ErrSpaceExists
+// is returned.
+func (c *ConfigHubClient) EnsureSpaceRecreated(req CreateSpaceRequest, opts RecreateSpaceOptions) (*RecreateSpaceResult, error) {
+	return c.EnsureSpaceRecreatedContext(context.Background(), req, opts)
+}
+
+// EnsureSpaceRecreatedContext is like EnsureSpaceRecreated but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) EnsureSpaceRecreatedContext(ctx context.Context, req CreateSpaceRequest, opts RecreateSpaceOptions) (*RecreateSpaceResult, error) {
+	existingSpace, err := c.findSpaceBySlug(ctx, req.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RecreateSpaceResult{}
+	if existingSpace != nil {
+		if !opts.Force {
+			return nil, fmt.Errorf("space %s: %w (set Force to delete and recreate it)", req.Slug, ErrSpaceExists)
+		}
+
+		if opts.BackupDir != "" {
+			log.Printf("WARN: Backing up space %s to %s before deletion", req.Slug, opts.BackupDir)
+			backupPath, err := NewPackageHelper(c).BackupSpace(existingSpace.SpaceID, opts.BackupDir)
+			if err != nil {
+				return nil, fmt.Errorf("back up space %s: %w", req.Slug, err)
+			}
+			result.BackupPath = backupPath
+		}
+
+		log.Printf("WARN: Deleting space %s and all its units", req.Slug)
+		if err := c.DeleteSpaceContext(ctx, existingSpace.SpaceID); err != nil && !isNotFound(err) {
 			return nil, fmt.Errorf("delete existing space %s: %w", req.Slug, err)
 		}
-		fmt.Printf("Successfully deleted space: %s\n", req.Slug)
+		result.Deleted = true
 	}
 
-	// Now create the space (whether it's new or we just deleted the old one)
-	fmt.Printf("Creating space: %s\n", req.Slug)
-	space, err := c.CreateSpace(req)
+	log.Printf("WARN: Creating space %s", req.Slug)
+	space, err := c.CreateSpaceContext(ctx, req)
 	if err != nil {
+		// Another client may have recreated the slug between delete and create
+		if isConflict(err) {
+			return nil, fmt.Errorf("space %s was recreated by another client: %w", req.Slug, ErrSpaceExists)
+		}
 		return nil, fmt.Errorf("create space %s: %w", req.Slug, err)
 	}
 
-	fmt.Printf("Successfully created space: %s\n", req.Slug)
-	return space, nil
+	result.Space = space
+	return result, nil
+}
+
+// findSpaceBySlug returns the space with slug, or nil if there is none
+func (c *ConfigHubClient) findSpaceBySlug(ctx context.Context, slug string) (*Space, error) {
+	spaces, err := c.ListSpacesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list spaces: %w", err)
+	}
+
+	for i, space := range spaces {
+		if space.Slug == slug {
+			return spaces[i], nil
+		}
+	}
+	return nil, nil
 }
 
 // CloneUnitWithUpstream creates a unit in the target space with an upstream relationship
 func (c *ConfigHubClient) CloneUnitWithUpstream(sourceSpaceID, targetSpaceID uuid.UUID, unitSlug string, additionalLabels map[string]string) (*Unit, error) {
+	return c.CloneUnitWithUpstreamContext(context.Background(), sourceSpaceID, targetSpaceID, unitSlug, additionalLabels)
+}
+
+// CloneUnitWithUpstreamContext is like CloneUnitWithUpstream but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CloneUnitWithUpstreamContext(ctx context.Context, sourceSpaceID, targetSpaceID uuid.UUID, unitSlug string, additionalLabels map[string]string) (*Unit, error) {
 	// Get the source unit
-	sourceUnits, err := c.ListUnits(ListUnitsParams{
+	sourceUnits, err := c.ListUnitsContext(ctx, ListUnitsParams{
 		SpaceID: sourceSpaceID,
-		Where:   fmt.Sprintf("Slug = '%s'", unitSlug),
+		Where:   NewWhere().Eq("Slug", unitSlug).MustBuild(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("list source units: %w", err)
@@ -631,7 +1985,7 @@ func (c *ConfigHubClient) CloneUnitWithUpstream(sourceSpaceID, targetSpaceID uui
 	}
 
 	// Create downstream unit with upstream relationship
-	return c.CreateUnit(targetSpaceID, CreateUnitRequest{
+	return c.CreateUnitContext(ctx, targetSpaceID, CreateUnitRequest{
 		Slug:           sourceUnit.Slug,
 		DisplayName:    sourceUnit.DisplayName,
 		Data:           sourceUnit.Data,
@@ -642,46 +1996,192 @@ func (c *ConfigHubClient) CloneUnitWithUpstream(sourceSpaceID, targetSpaceID uui
 
 // BulkCloneUnitsWithUpstream clones multiple units from source to target space
 func (c *ConfigHubClient) BulkCloneUnitsWithUpstream(sourceSpaceID, targetSpaceID uuid.UUID, unitSlugs []string, additionalLabels map[string]string) ([]*Unit, error) {
-	var clonedUnits []*Unit
+	return c.BulkCloneUnitsWithUpstreamContext(context.Background(), sourceSpaceID, targetSpaceID, unitSlugs, additionalLabels)
+}
+
+// BulkCloneUnitsWithUpstreamContext is like BulkCloneUnitsWithUpstream but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) BulkCloneUnitsWithUpstreamContext(ctx context.Context, sourceSpaceID, targetSpaceID uuid.UUID, unitSlugs []string, additionalLabels map[string]string) ([]*Unit, error) {
+	sourceUnits, err := c.ListAllUnitsContext(ctx, ListUnitsParams{SpaceID: sourceSpaceID})
+	if err != nil {
+		return nil, fmt.Errorf("list source units: %w", err)
+	}
+
+	bySlug := make(map[string]*Unit, len(sourceUnits))
+	for _, unit := range sourceUnits {
+		bySlug[unit.Slug] = unit
+	}
 
+	reqs := make([]CreateUnitRequest, 0, len(unitSlugs))
 	for _, slug := range unitSlugs {
-		unit, err := c.CloneUnitWithUpstream(sourceSpaceID, targetSpaceID, slug, additionalLabels)
-		if err != nil {
-			return nil, fmt.Errorf("clone unit %s: %w", slug, err)
+		sourceUnit, ok := bySlug[slug]
+		if !ok {
+			return nil, fmt.Errorf("source unit not found: %s", slug)
 		}
-		clonedUnits = append(clonedUnits, unit)
+		reqs = append(reqs, CreateUnitRequest{
+			Slug:           sourceUnit.Slug,
+			DisplayName:    sourceUnit.DisplayName,
+			Data:           sourceUnit.Data,
+			Labels:         mergeLabels(sourceUnit.Labels, additionalLabels),
+			UpstreamUnitID: &sourceUnit.UnitID,
+		})
+	}
+
+	clonedUnits, err := c.BulkCreateUnitsContext(ctx, targetSpaceID, reqs)
+	if err != nil {
+		return clonedUnits, fmt.Errorf("clone units: %w", err)
 	}
 
 	return clonedUnits, nil
 }
 
-// ApplyUnitsInOrder applies units in the correct dependency order
-func (c *ConfigHubClient) ApplyUnitsInOrder(spaceID uuid.UUID, unitSlugs []string) error {
-	for _, slug := range unitSlugs {
-		units, err := c.ListUnits(ListUnitsParams{
+// Outcomes of applying a unit with ApplyUnitsInOrder
+const (
+	ApplyStatusApplied = "applied"
+	ApplyStatusSkipped = "skipped" // Not found, or not attempted after an earlier failure
+	ApplyStatusFailed  = "failed"
+)
+
+// UnitApplyResult is the outcome of applying one unit with ApplyUnitsInOrder
+type UnitApplyResult struct {
+	Slug     string
+	UnitID   uuid.UUID // Zero if the unit wasn't found
+	Status   string    // ApplyStatusApplied, ApplyStatusSkipped or ApplyStatusFailed
+	Attempts int       // Apply calls made, including retries
+	Reason   string    // Why the unit was skipped
+	Error    error     // Why the unit failed
+}
+
+// ApplyOrderError reports which units ApplyUnitsInOrder failed to apply. It
+// unwraps to every failure, so errors.As finds e.g. an *APIError.
+type ApplyOrderError struct {
+	Failures map[string]error // Unit slug -> error
+}
+
+func (e *ApplyOrderError) Error() string {
+	slugs := e.slugs()
+	messages := make([]string, 0, len(slugs))
+	for _, slug := range slugs {
+		messages = append(messages, fmt.Sprintf("%s: %v", slug, e.Failures[slug]))
+	}
+	return fmt.Sprintf("%d unit(s) failed to apply: %s", len(e.Failures), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the failures in slug order
+func (e *ApplyOrderError) Unwrap() []error {
+	slugs := e.slugs()
+	errs := make([]error, 0, len(slugs))
+	for _, slug := range slugs {
+		errs = append(errs, e.Failures[slug])
+	}
+	return errs
+}
+
+func (e *ApplyOrderError) slugs() []string {
+	slugs := make([]string, 0, len(e.Failures))
+	for slug := range e.Failures {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// ApplyUnitsInOrder applies units in the given dependency order and returns
+// one result per slug, in order. Transient failures (rate limits, gateway
+// errors, unreachable server) are retried with backoff, up to MaxRetries
+// times. A unit that still fails stops the apply, leaving later units
+// skipped, unless continueOnError is set; either way the failures are
+// returned in an *ApplyOrderError, so callers can decide whether to proceed
+// or roll back the units that were applied. Slugs not found in the space are
+// skipped.
+func (c *ConfigHubClient) ApplyUnitsInOrder(spaceID uuid.UUID, unitSlugs []string, continueOnError bool) ([]UnitApplyResult, error) {
+	return c.ApplyUnitsInOrderContext(context.Background(), spaceID, unitSlugs, continueOnError)
+}
+
+// ApplyUnitsInOrderContext is like ApplyUnitsInOrder but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ApplyUnitsInOrderContext(ctx context.Context, spaceID uuid.UUID, unitSlugs []string, continueOnError bool) ([]UnitApplyResult, error) {
+	results := make([]UnitApplyResult, len(unitSlugs))
+	failures := make(map[string]error)
+
+	for i, slug := range unitSlugs {
+		results[i] = UnitApplyResult{Slug: slug, Status: ApplyStatusSkipped}
+		if len(failures) > 0 && !continueOnError {
+			results[i].Reason = "not attempted after an earlier failure"
+			continue
+		}
+
+		units, err := c.ListUnitsContext(ctx, ListUnitsParams{
 			SpaceID: spaceID,
-			Where:   fmt.Sprintf("Slug = '%s'", slug),
+			Where:   NewWhere().Eq("Slug", slug).MustBuild(),
 		})
 		if err != nil {
-			return fmt.Errorf("list units for %s: %w", slug, err)
+			results[i].Status = ApplyStatusFailed
+			results[i].Error = fmt.Errorf("list units for %s: %w", slug, err)
+			failures[slug] = results[i].Error
+			continue
+		}
+		if len(units) == 0 {
+			results[i].Reason = "unit not found"
+			continue
 		}
 
-		if len(units) > 0 {
-			err = c.ApplyUnit(spaceID, units[0].UnitID)
-			if err != nil {
-				return fmt.Errorf("apply unit %s: %w", slug, err)
-			}
+		results[i].UnitID = units[0].UnitID
+		results[i].Attempts, err = c.applyUnitWithRetry(ctx, spaceID, units[0].UnitID)
+		if err != nil {
+			results[i].Status = ApplyStatusFailed
+			results[i].Error = fmt.Errorf("apply unit %s: %w", slug, err)
+			failures[slug] = results[i].Error
+			continue
 		}
+		results[i].Status = ApplyStatusApplied
 	}
 
-	return nil
+	if len(failures) > 0 {
+		return results, &ApplyOrderError{Failures: failures}
+	}
+	return results, nil
+}
+
+// applyUnitWithRetry applies a unit, retrying transient failures with
+// exponential backoff. Apply is a POST, which requests only retry with
+// RetryNonIdempotent; reapplying a unit is harmless, so retry here instead.
+func (c *ConfigHubClient) applyUnitWithRetry(ctx context.Context, spaceID, unitID uuid.UUID) (int, error) {
+	retries := c.MaxRetries
+	if c.RetryNonIdempotent {
+		retries = 0 // Already retried per request
+	}
+	delay := c.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		err := c.ApplyUnitContext(ctx, spaceID, unitID)
+		if err == nil || attempt > retries || !isTransient(err) {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
 }
 
 // ListFilters lists filters in a space
-// TODO: Implement when ConfigHub API supports filter listing
 func (c *ConfigHubClient) ListFilters(spaceID uuid.UUID) ([]*Filter, error) {
-	// Placeholder implementation - would call actual ConfigHub API
-	return []*Filter{}, nil
+	return c.ListFiltersContext(context.Background(), spaceID)
+}
+
+// ListFiltersContext is like ListFilters but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListFiltersContext(ctx context.Context, spaceID uuid.UUID) ([]*Filter, error) {
+	var filters []*Filter
+	err := c.doRequestListContext(ctx, "GET", fmt.Sprintf("/space/%s/filter", spaceID), nil, &filters)
+	if isNotFound(err) {
+		return []*Filter{}, nil // Older servers without the endpoint
+	}
+	return filters, err
 }
 
 // FunctionInvocationRequest represents a request to invoke a ConfigHub function
@@ -716,57 +2216,136 @@ type FunctionResult struct {
 
 // ExecuteFunction runs a ConfigHub function on units
 func (c *ConfigHubClient) ExecuteFunction(spaceID uuid.UUID, req FunctionInvocationRequest) (*FunctionInvocationResponse, error) {
+	return c.ExecuteFunctionContext(context.Background(), spaceID, req)
+}
+
+// ExecuteFunctionContext is like ExecuteFunction but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ExecuteFunctionContext(ctx context.Context, spaceID uuid.UUID, req FunctionInvocationRequest) (*FunctionInvocationResponse, error) {
 	endpoint := fmt.Sprintf("/space/%s/function/invoke", spaceID)
 	var result FunctionInvocationResponse
-	_, err := c.doRequest("POST", endpoint, req, &result)
+	_, err := c.doRequestContext(ctx, "POST", endpoint, req, &result)
 	return &result, err
 }
 
 // SetImageVersion uses the set-image function to update container image
 func (c *ConfigHubClient) SetImageVersion(spaceID, unitID uuid.UUID, containerName, image string) error {
+	return c.SetImageVersionContext(context.Background(), spaceID, unitID, containerName, image)
+}
+
+// SetImageVersionContext is like SetImageVersion but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) SetImageVersionContext(ctx context.Context, spaceID, unitID uuid.UUID, containerName, image string) error {
 	req := FunctionInvocationRequest{
 		FunctionName:  "set-image",
 		ToolchainType: "Kubernetes/YAML",
-		Where:         fmt.Sprintf("UnitID = '%s'", unitID),
+		Where:         NewWhere().Eq("UnitID", unitID).MustBuild(),
 		Arguments: []FunctionArgument{
 			{ParameterName: "container-name", Value: containerName},
 			{ParameterName: "image", Value: image},
 		},
 	}
-	_, err := c.ExecuteFunction(spaceID, req)
+	_, err := c.ExecuteFunctionContext(ctx, spaceID, req)
 	return err
 }
 
 // SetReplicas uses the set-replicas function to update replica count
 func (c *ConfigHubClient) SetReplicas(spaceID, unitID uuid.UUID, replicas int) error {
+	return c.SetReplicasContext(context.Background(), spaceID, unitID, replicas)
+}
+
+// SetReplicasContext is like SetReplicas but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) SetReplicasContext(ctx context.Context, spaceID, unitID uuid.UUID, replicas int) error {
 	req := FunctionInvocationRequest{
 		FunctionName:  "set-replicas",
 		ToolchainType: "Kubernetes/YAML",
-		Where:         fmt.Sprintf("UnitID = '%s'", unitID),
+		Where:         NewWhere().Eq("UnitID", unitID).MustBuild(),
 		Arguments: []FunctionArgument{
 			{ParameterName: "replicas", Value: replicas},
 		},
 	}
-	_, err := c.ExecuteFunction(spaceID, req)
+	_, err := c.ExecuteFunctionContext(ctx, spaceID, req)
+	return err
+}
+
+// SetResources uses the set-container-resources function to patch only the
+// requests and limits of one container, e.g. requests {"cpu": "250m"}
+func (c *ConfigHubClient) SetResources(spaceID, unitID uuid.UUID, containerName string, requests, limits map[string]string) error {
+	return c.SetResourcesContext(context.Background(), spaceID, unitID, containerName, requests, limits)
+}
+
+// SetResourcesContext is like SetResources but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) SetResourcesContext(ctx context.Context, spaceID, unitID uuid.UUID, containerName string, requests, limits map[string]string) error {
+	return c.SetResourcesWhereContext(ctx, spaceID, NewWhere().Eq("UnitID", unitID).MustBuild(), containerName, requests, limits)
+}
+
+// SetResourcesWhere is like SetResources but patches the named container in
+// every unit matching where, e.g. "Sets.Slug = 'backend'"
+func (c *ConfigHubClient) SetResourcesWhere(spaceID uuid.UUID, where, containerName string, requests, limits map[string]string) error {
+	return c.SetResourcesWhereContext(context.Background(), spaceID, where, containerName, requests, limits)
+}
+
+// SetResourcesWhereContext is like SetResourcesWhere but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) SetResourcesWhereContext(ctx context.Context, spaceID uuid.UUID, where, containerName string, requests, limits map[string]string) error {
+	req, ok := setResourcesRequest(where, containerName, requests, limits)
+	if !ok {
+		return nil
+	}
+	_, err := c.ExecuteFunctionContext(ctx, spaceID, req)
 	return err
 }
 
-// ListWorkers lists workers in a space (placeholder for PRINCIPLE #1 requirement)
-// TODO: Implement when ConfigHub API supports worker listing
-func (c *ConfigHubClient) ListWorkers(spaceID string) ([]interface{}, error) {
-	// Placeholder implementation - ConfigHub worker API not yet available
-	// In production, this would call the actual ConfigHub API
-	// For now, return empty to trigger health check warnings
-	return []interface{}{}, nil
+// setResourcesRequest builds the set-container-resources invocation; ok is
+// false when there is nothing to set
+func setResourcesRequest(where, containerName string, requests, limits map[string]string) (FunctionInvocationRequest, bool) {
+	if len(requests) == 0 && len(limits) == 0 {
+		return FunctionInvocationRequest{}, false
+	}
+
+	args := []FunctionArgument{
+		{ParameterName: "container-name", Value: containerName},
+	}
+	for _, name := range sortedKeys(requests) {
+		args = append(args, FunctionArgument{ParameterName: "requests." + name, Value: requests[name]})
+	}
+	for _, name := range sortedKeys(limits) {
+		args = append(args, FunctionArgument{ParameterName: "limits." + name, Value: limits[name]})
+	}
+
+	return FunctionInvocationRequest{
+		FunctionName:  "set-container-resources",
+		ToolchainType: "Kubernetes/YAML",
+		Where:         where,
+		Arguments:     args,
+	}, true
+}
+
+// ListWorkers lists bridge workers in a space
+func (c *ConfigHubClient) ListWorkers(spaceID uuid.UUID) ([]*Worker, error) {
+	return c.ListWorkersContext(context.Background(), spaceID)
 }
 
-// ListTargets lists targets in a space (placeholder for PRINCIPLE #4 requirement)
-// TODO: Implement when ConfigHub API supports target listing
-func (c *ConfigHubClient) ListTargets(spaceID string) ([]interface{}, error) {
-	// Placeholder implementation - ConfigHub target API not yet available
-	// In production, this would call the actual ConfigHub API
-	// For now, return empty to trigger health check warnings
-	return []interface{}{}, nil
+// ListWorkersContext is like ListWorkers but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListWorkersContext(ctx context.Context, spaceID uuid.UUID) ([]*Worker, error) {
+	var workers []*Worker
+	err := c.doRequestListContext(ctx, "GET", fmt.Sprintf("/space/%s/worker", spaceID), nil, &workers)
+	if isNotFound(err) {
+		return []*Worker{}, nil // Older servers without the endpoint
+	}
+	return workers, err
+}
+
+// ListTargets lists targets in a space
+func (c *ConfigHubClient) ListTargets(spaceID uuid.UUID) ([]*Target, error) {
+	return c.ListTargetsContext(context.Background(), spaceID)
+}
+
+// ListTargetsContext is like ListTargets but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ListTargetsContext(ctx context.Context, spaceID uuid.UUID) ([]*Target, error) {
+	var targets []*Target
+	err := c.doRequestListContext(ctx, "GET", fmt.Sprintf("/space/%s/target", spaceID), nil, &targets)
+	if isNotFound(err) {
+		return []*Target{}, nil // Older servers without the endpoint
+	}
+	return targets, err
 }
 
 // ChangeSet operations for grouping related changes
@@ -788,7 +2367,12 @@ type CreateChangeSetRequest struct {
 
 // CreateChangeSet creates a new ChangeSet for grouping related changes
 func (c *ConfigHubClient) CreateChangeSet(spaceID uuid.UUID, req CreateChangeSetRequest) (*ChangeSet, error) {
-	result, err := c.doRequest("POST", fmt.Sprintf("/space/%s/changeset", spaceID), req, &ChangeSet{})
+	return c.CreateChangeSetContext(context.Background(), spaceID, req)
+}
+
+// CreateChangeSetContext is like CreateChangeSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) CreateChangeSetContext(ctx context.Context, spaceID uuid.UUID, req CreateChangeSetRequest) (*ChangeSet, error) {
+	result, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/changeset", spaceID), req, &ChangeSet{})
 	if err != nil {
 		return nil, err
 	}
@@ -797,7 +2381,12 @@ func (c *ConfigHubClient) CreateChangeSet(spaceID uuid.UUID, req CreateChangeSet
 
 // GetChangeSet retrieves a ChangeSet
 func (c *ConfigHubClient) GetChangeSet(spaceID, changeSetID uuid.UUID) (*ChangeSet, error) {
-	result, err := c.doRequest("GET", fmt.Sprintf("/space/%s/changeset/%s", spaceID, changeSetID), nil, &ChangeSet{})
+	return c.GetChangeSetContext(context.Background(), spaceID, changeSetID)
+}
+
+// GetChangeSetContext is like GetChangeSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetChangeSetContext(ctx context.Context, spaceID, changeSetID uuid.UUID) (*ChangeSet, error) {
+	result, err := c.doRequestContext(ctx, "GET", fmt.Sprintf("/space/%s/changeset/%s", spaceID, changeSetID), nil, &ChangeSet{})
 	if err != nil {
 		return nil, err
 	}
@@ -806,18 +2395,33 @@ func (c *ConfigHubClient) GetChangeSet(spaceID, changeSetID uuid.UUID) (*ChangeS
 
 // DeleteChangeSet deletes a ChangeSet
 func (c *ConfigHubClient) DeleteChangeSet(spaceID, changeSetID uuid.UUID) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/space/%s/changeset/%s", spaceID, changeSetID), nil, nil)
+	return c.DeleteChangeSetContext(context.Background(), spaceID, changeSetID)
+}
+
+// DeleteChangeSetContext is like DeleteChangeSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) DeleteChangeSetContext(ctx context.Context, spaceID, changeSetID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "DELETE", fmt.Sprintf("/space/%s/changeset/%s", spaceID, changeSetID), nil, nil)
 	return err
 }
 
 // ApplyChangeSet applies all changes in a ChangeSet
 func (c *ConfigHubClient) ApplyChangeSet(spaceID, changeSetID uuid.UUID) error {
-	_, err := c.doRequest("POST", fmt.Sprintf("/space/%s/changeset/%s/apply", spaceID, changeSetID), nil, nil)
+	return c.ApplyChangeSetContext(context.Background(), spaceID, changeSetID)
+}
+
+// ApplyChangeSetContext is like ApplyChangeSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ApplyChangeSetContext(ctx context.Context, spaceID, changeSetID uuid.UUID) error {
+	_, err := c.doRequestContext(ctx, "POST", fmt.Sprintf("/space/%s/changeset/%s/apply", spaceID, changeSetID), nil, nil)
 	return err
 }
 
 // UpdateUnitWithChangeSet updates a unit and associates it with a ChangeSet
 func (c *ConfigHubClient) UpdateUnitWithChangeSet(spaceID, unitID, changeSetID uuid.UUID, data interface{}) (*Unit, error) {
+	return c.UpdateUnitWithChangeSetContext(context.Background(), spaceID, unitID, changeSetID, data)
+}
+
+// UpdateUnitWithChangeSetContext is like UpdateUnitWithChangeSet but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) UpdateUnitWithChangeSetContext(ctx context.Context, spaceID, unitID, changeSetID uuid.UUID, data interface{}) (*Unit, error) {
 	// Convert data to JSON string if it's not already a string
 	var dataStr string
 	if str, ok := data.(string); ok {
@@ -834,19 +2438,24 @@ func (c *ConfigHubClient) UpdateUnitWithChangeSet(spaceID, unitID, changeSetID u
 		Data:        dataStr,
 		ChangeSetID: &changeSetID,
 	}
-	return c.UpdateUnit(spaceID, unitID, req)
+	return c.UpdateUnitContext(ctx, spaceID, unitID, req)
 }
 
 // Validation Functions - Use ConfigHub's built-in validation
 
 // ValidateNoPlaceholders checks if a unit has any unresolved placeholders
 func (c *ConfigHubClient) ValidateNoPlaceholders(spaceID, unitID uuid.UUID) (bool, string, error) {
+	return c.ValidateNoPlaceholdersContext(context.Background(), spaceID, unitID)
+}
+
+// ValidateNoPlaceholdersContext is like ValidateNoPlaceholders but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ValidateNoPlaceholdersContext(ctx context.Context, spaceID, unitID uuid.UUID) (bool, string, error) {
 	req := FunctionInvocationRequest{
 		FunctionName:  "no-placeholders",
 		ToolchainType: "Kubernetes/YAML",
-		Where:         fmt.Sprintf("UnitID = '%s'", unitID),
+		Where:         NewWhere().Eq("UnitID", unitID).MustBuild(),
 	}
-	result, err := c.ExecuteFunction(spaceID, req)
+	result, err := c.ExecuteFunctionContext(ctx, spaceID, req)
 	if err != nil {
 		return false, "", err
 	}
@@ -859,6 +2468,11 @@ func (c *ConfigHubClient) ValidateNoPlaceholders(spaceID, unitID uuid.UUID) (boo
 
 // ValidateCEL validates units against a CEL (Common Expression Language) expression
 func (c *ConfigHubClient) ValidateCEL(spaceID uuid.UUID, where, expression string) ([]FunctionResult, error) {
+	return c.ValidateCELContext(context.Background(), spaceID, where, expression)
+}
+
+// ValidateCELContext is like ValidateCEL but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) ValidateCELContext(ctx context.Context, spaceID uuid.UUID, where, expression string) ([]FunctionResult, error) {
 	req := FunctionInvocationRequest{
 		FunctionName:  "cel-validate",
 		ToolchainType: "Kubernetes/YAML",
@@ -867,7 +2481,7 @@ func (c *ConfigHubClient) ValidateCEL(spaceID uuid.UUID, where, expression strin
 			{ParameterName: "expression", Value: expression},
 		},
 	}
-	result, err := c.ExecuteFunction(spaceID, req)
+	result, err := c.ExecuteFunctionContext(ctx, spaceID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -876,12 +2490,17 @@ func (c *ConfigHubClient) ValidateCEL(spaceID uuid.UUID, where, expression strin
 
 // GetReplicas uses the get-replicas function to retrieve replica counts
 func (c *ConfigHubClient) GetReplicas(spaceID uuid.UUID, where string) ([]FunctionResult, error) {
+	return c.GetReplicasContext(context.Background(), spaceID, where)
+}
+
+// GetReplicasContext is like GetReplicas but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) GetReplicasContext(ctx context.Context, spaceID uuid.UUID, where string) ([]FunctionResult, error) {
 	req := FunctionInvocationRequest{
 		FunctionName:  "get-replicas",
 		ToolchainType: "Kubernetes/YAML",
 		Where:         where,
 	}
-	result, err := c.ExecuteFunction(spaceID, req)
+	result, err := c.ExecuteFunctionContext(ctx, spaceID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -890,10 +2509,15 @@ func (c *ConfigHubClient) GetReplicas(spaceID uuid.UUID, where string) ([]Functi
 
 // SetIntPath sets an integer value at a specific path in the configuration
 func (c *ConfigHubClient) SetIntPath(spaceID, unitID uuid.UUID, apiVersion, kind, path string, value int) error {
+	return c.SetIntPathContext(context.Background(), spaceID, unitID, apiVersion, kind, path, value)
+}
+
+// SetIntPathContext is like SetIntPath but uses ctx for cancellation and deadlines
+func (c *ConfigHubClient) SetIntPathContext(ctx context.Context, spaceID, unitID uuid.UUID, apiVersion, kind, path string, value int) error {
 	req := FunctionInvocationRequest{
 		FunctionName:  "set-int-path",
 		ToolchainType: "Kubernetes/YAML",
-		Where:         fmt.Sprintf("UnitID = '%s'", unitID),
+		Where:         NewWhere().Eq("UnitID", unitID).MustBuild(),
 		Arguments: []FunctionArgument{
 			{ParameterName: "apiVersion", Value: apiVersion},
 			{ParameterName: "kind", Value: kind},
@@ -901,6 +2525,6 @@ func (c *ConfigHubClient) SetIntPath(spaceID, unitID uuid.UUID, apiVersion, kind
 			{ParameterName: "value", Value: value},
 		},
 	}
-	_, err := c.ExecuteFunction(spaceID, req)
+	_, err := c.ExecuteFunctionContext(ctx, spaceID, req)
 	return err
 }
@@ -0,0 +1,1256 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test ConfigHub client query handling
+func TestConfigHubClientQueries(t *testing.T) {
+	t.Run("ListUnitsEncodesWhere", func(t *testing.T) {
+		var gotWhere, gotRawQuery string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotWhere = r.URL.Query().Get("where")
+			gotRawQuery = r.URL.RawQuery
+			w.Write([]byte("[]"))
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		_, err := client.ListUnits(ListUnitsParams{
+			SpaceID: uuid.New(),
+			Where:   "Labels.app = 'frontend'",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Labels.app = 'frontend'", gotWhere)
+		assert.NotContains(t, gotRawQuery, " ")
+	})
+
+}
+
+// Test API failures surfacing as *APIError
+func TestAPIError(t *testing.T) {
+	status := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/space" && r.Method == http.MethodGet {
+			w.Write([]byte("[]"))
+			return
+		}
+		http.Error(w, "nope", status)
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+	client.RetryDelay = time.Millisecond
+
+	for _, tc := range []struct {
+		status                                     int
+		notFound, conflict, rateLimited, forbidden bool
+	}{
+		{status: http.StatusNotFound, notFound: true},
+		{status: http.StatusConflict, conflict: true},
+		{status: http.StatusTooManyRequests, rateLimited: true},
+		{status: http.StatusUnauthorized, forbidden: true},
+		{status: http.StatusInternalServerError},
+	} {
+		status = tc.status
+		_, err := client.GetUnit(uuid.New(), uuid.New())
+
+		var apiErr *APIError
+		require.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &apiErr), "status %d", tc.status)
+		assert.Equal(t, tc.status, apiErr.StatusCode)
+		assert.Equal(t, "nope\n", apiErr.Body)
+		assert.Equal(t, fmt.Sprintf("API error %d: nope\n", tc.status), err.Error())
+		assert.Equal(t, tc.notFound, apiErr.IsNotFound(), "status %d", tc.status)
+		assert.Equal(t, tc.conflict, apiErr.IsConflict(), "status %d", tc.status)
+		assert.Equal(t, tc.rateLimited, apiErr.IsRateLimited(), "status %d", tc.status)
+		assert.Equal(t, tc.forbidden, apiErr.IsUnauthorized(), "status %d", tc.status)
+	}
+
+	assert.True(t, (&APIError{StatusCode: http.StatusBadRequest, Body: "space shop already exists"}).IsConflict())
+
+	_, err := client.GetSpaceBySlug("missing")
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.IsNotFound())
+	assert.Contains(t, err.Error(), "space not found: missing")
+}
+
+// Test ConfigHub client retry behavior
+func TestConfigHubClientRetry(t *testing.T) {
+	t.Run("RetriesGetOnServiceUnavailable", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("[]"))
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		client.RetryDelay = time.Millisecond
+
+		_, err := client.ListSpaces()
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("DoesNotRetryPostByDefault", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		client.RetryDelay = time.Millisecond
+
+		_, err := client.CreateUnit(uuid.New(), CreateUnitRequest{Slug: "test"})
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("IdempotencyKey", func(t *testing.T) {
+		var keys []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			if len(keys)%2 == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			json.NewEncoder(w).Encode(Unit{Slug: "test"})
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		client.RetryDelay = time.Millisecond
+		spaceID := uuid.New()
+
+		// Without POST retries no key is sent, and the 503 is not retried
+		_, err := client.CreateUnit(spaceID, CreateUnitRequest{Slug: "test"})
+		assert.Error(t, err)
+		assert.Equal(t, []string{""}, keys)
+
+		// With POST retries every attempt carries the same derived key
+		keys = nil
+		client.RetryNonIdempotent = true
+		unit, err := client.CreateUnit(spaceID, CreateUnitRequest{Slug: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, "test", unit.Slug)
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+
+		// Derived keys depend on the space, slug and request body
+		derived := client.unitIdempotencyKey(spaceID, CreateUnitRequest{Slug: "test"})
+		assert.Equal(t, keys[0], derived)
+		assert.NotEqual(t, derived, client.unitIdempotencyKey(spaceID, CreateUnitRequest{Slug: "test", Data: "changed"}))
+		assert.NotEqual(t, derived, client.unitIdempotencyKey(spaceID, CreateUnitRequest{Slug: "test", Labels: map[string]string{"tier": "web"}}))
+		assert.NotEqual(t, derived, client.unitIdempotencyKey(uuid.New(), CreateUnitRequest{Slug: "test"}))
+
+		// An explicit key is always sent
+		keys = nil
+		client.RetryNonIdempotent = false
+		_, err = client.CreateUnit(spaceID, CreateUnitRequest{Slug: "test", IdempotencyKey: "create-test-1"})
+		assert.Error(t, err)
+		assert.Equal(t, []string{"create-test-1"}, keys)
+	})
+
+	t.Run("DoesNotRetryClientErrors", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		client.RetryDelay = time.Millisecond
+
+		_, err := client.GetSpace(uuid.New())
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+// Test ConfigHub client context propagation
+func TestConfigHubClientContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewConfigHubClient(server.URL, "test-token")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.ListSpacesContext(ctx)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// Test typed list endpoints
+func TestConfigHubClientListEndpoints(t *testing.T) {
+	t.Run("ListWorkersDecodesTyped", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Contains(t, r.URL.Path, "/worker")
+			w.Write([]byte(`[{"Slug": "cluster-worker", "Condition": "Ready"}]`))
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		workers, err := client.ListWorkers(uuid.New())
+		require.NoError(t, err)
+		require.Len(t, workers, 1)
+		assert.Equal(t, "cluster-worker", workers[0].Slug)
+		assert.Equal(t, "Ready", workers[0].Condition)
+	})
+
+	t.Run("NotFoundFallsBackToEmpty", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		targets, err := client.ListTargets(uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, targets)
+
+		filters, err := client.ListFilters(uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, filters)
+	})
+}
+
+// Test batch unit creation
+func TestConfigHubClientBulkCreate(t *testing.T) {
+	t.Run("ReportsPerIndexFailures", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.True(t, strings.HasSuffix(r.URL.Path, "/unit/bulk-create"))
+			w.Write([]byte(`[{"Unit": {"Slug": "a"}}, {"Error": "slug already exists"}]`))
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		units, err := client.BulkCreateUnits(uuid.New(), []CreateUnitRequest{{Slug: "a"}, {Slug: "b"}})
+
+		var bulkErr *BulkCreateError
+		require.ErrorAs(t, err, &bulkErr)
+		assert.Len(t, bulkErr.Failures, 1)
+		assert.Contains(t, bulkErr.Failures[1].Error(), "already exists")
+		require.Len(t, units, 2)
+		assert.Equal(t, "a", units[0].Slug)
+		assert.Nil(t, units[1])
+	})
+
+	t.Run("FallsBackToPerUnitCreates", func(t *testing.T) {
+		var mu sync.Mutex
+		created := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/bulk-create") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			var req CreateUnitRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			created++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(Unit{Slug: req.Slug})
+		}))
+		defer server.Close()
+
+		reqs := make([]CreateUnitRequest, 20)
+		for i := range reqs {
+			reqs[i] = CreateUnitRequest{Slug: fmt.Sprintf("unit-%d", i)}
+		}
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		units, err := client.BulkCreateUnits(uuid.New(), reqs)
+		require.NoError(t, err)
+		assert.Equal(t, 20, created)
+		for i, unit := range units {
+			assert.Equal(t, reqs[i].Slug, unit.Slug)
+		}
+	})
+}
+
+// countingTransport counts requests and idle-connection cleanups
+type countingTransport struct {
+	requests, closes int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (c *countingTransport) CloseIdleConnections() {
+	c.closes++
+}
+
+// Test HTTP connection options and cleanup
+func TestConfigHubClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Space{Slug: "prod"})
+	}))
+	defer server.Close()
+
+	t.Run("DefaultsUnchanged", func(t *testing.T) {
+		client := NewConfigHubClient(server.URL, "test-token")
+		assert.Nil(t, client.client.Transport, "default transport is shared")
+		assert.Equal(t, 30*time.Second, client.client.Timeout)
+		assert.NoError(t, client.Close())
+	})
+
+	t.Run("ConnectionPool", func(t *testing.T) {
+		client := NewConfigHubClientWithOptions(server.URL, "test-token", ClientOptions{
+			Timeout:             5 * time.Second,
+			MaxIdleConnsPerHost: 20,
+			IdleConnTimeout:     time.Minute,
+		})
+		transport, ok := client.client.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.Equal(t, 20, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+		assert.Equal(t, http.DefaultTransport.(*http.Transport).MaxIdleConns, transport.MaxIdleConns)
+		assert.Equal(t, 5*time.Second, client.client.Timeout)
+
+		space, err := client.GetSpace(uuid.New())
+		require.NoError(t, err)
+		assert.Equal(t, "prod", space.Slug)
+		assert.NoError(t, client.Close())
+	})
+
+	t.Run("CustomTransport", func(t *testing.T) {
+		transport := &countingTransport{}
+		client := NewConfigHubClientWithOptions(server.URL, "test-token", ClientOptions{Transport: transport})
+
+		_, err := client.GetSpace(uuid.New())
+		require.NoError(t, err)
+		assert.Equal(t, 1, transport.requests)
+
+		require.NoError(t, client.Close())
+		assert.Equal(t, 1, transport.closes)
+
+		_, err = client.GetSpace(uuid.New())
+		assert.NoError(t, err, "client stays usable after Close")
+	})
+}
+
+// Test client-side rate limiting
+func TestConfigHubClientRateLimit(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(Space{Slug: "prod"})
+	}))
+	defer server.Close()
+
+	t.Run("UnlimitedByDefault", func(t *testing.T) {
+		assert.Nil(t, NewConfigHubClient(server.URL, "test-token").Limiter())
+	})
+
+	t.Run("ThrottlesAfterBurst", func(t *testing.T) {
+		client := NewConfigHubClientWithOptions(server.URL, "test-token", ClientOptions{RequestsPerSecond: 20, Burst: 2})
+		require.NotNil(t, client.Limiter())
+
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			_, err := client.GetSpace(uuid.New())
+			require.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond, "two requests past the burst wait 50ms each")
+	})
+
+	t.Run("SharedLimiter", func(t *testing.T) {
+		first := NewConfigHubClientWithOptions(server.URL, "test-token", ClientOptions{RequestsPerSecond: 1, Burst: 1})
+		second := NewConfigHubClientWithOptions(server.URL, "other-token", ClientOptions{Limiter: first.Limiter()})
+		assert.Same(t, first.Limiter(), second.Limiter())
+
+		_, err := first.GetSpace(uuid.New())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		requests = 0
+		_, err = second.GetSpaceContext(ctx, uuid.New())
+		assert.Error(t, err, "the shared bucket is empty")
+		assert.Zero(t, requests)
+	})
+}
+
+// Test paging through every unit in a space
+func TestListAllUnits(t *testing.T) {
+	spaceID := uuid.New()
+	units := make([]Unit, 250)
+	for i := range units {
+		units[i] = Unit{UnitID: uuid.New(), SpaceID: spaceID, Slug: fmt.Sprintf("unit-%03d", i)}
+	}
+
+	// newServer serves units by limit and offset, or always the first page
+	// when ignoreOffset is set
+	newServer := func(t *testing.T, ignoreOffset bool) (*ConfigHubClient, *[]string) {
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RawQuery)
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			if ignoreOffset {
+				offset = 0
+			}
+			var page []map[string]Unit
+			for i := offset; i < len(units) && i < offset+limit; i++ {
+				page = append(page, map[string]Unit{"Unit": units[i]})
+			}
+			json.NewEncoder(w).Encode(page)
+		}))
+		t.Cleanup(server.Close)
+		return NewConfigHubClient(server.URL, "test-token"), &requests
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		client, requests := newServer(t, false)
+		all, err := client.ListAllUnits(ListUnitsParams{SpaceID: spaceID, Where: "Labels.tier = 'web'"})
+		require.NoError(t, err)
+		require.Len(t, all, 250)
+		assert.Equal(t, "unit-000", all[0].Slug)
+		assert.Equal(t, "unit-249", all[249].Slug)
+		require.Len(t, *requests, 3, "two full pages and a short one")
+		assert.Contains(t, (*requests)[2], "offset=200")
+		assert.Contains(t, (*requests)[2], "where=")
+
+		_, err = client.ListAllUnits(ListUnitsParams{SpaceID: spaceID, Limit: 50})
+		require.NoError(t, err)
+		assert.Len(t, *requests, 9, "five full pages and an empty one")
+	})
+
+	t.Run("RepeatedPage", func(t *testing.T) {
+		client, requests := newServer(t, true)
+		all, err := client.ListAllUnits(ListUnitsParams{SpaceID: spaceID})
+		require.NoError(t, err)
+		assert.Len(t, all, 100, "duplicates are dropped")
+		assert.Len(t, *requests, 2, "stops when a page adds nothing new")
+	})
+}
+
+// Test Link create, get, update, list and delete
+func TestLinks(t *testing.T) {
+	spaceID := uuid.New()
+	web, db := uuid.New(), uuid.New()
+	links := make(map[uuid.UUID]*Link)
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		prefix := fmt.Sprintf("/space/%s/link", spaceID)
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path == prefix {
+			switch r.Method {
+			case http.MethodPost:
+				var req CreateLinkRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				link := &Link{LinkID: uuid.New(), SpaceID: spaceID, Slug: req.Slug, FromUnitID: req.FromUnitID, ToUnitID: req.ToUnitID, Labels: req.Labels, Version: 1}
+				links[link.LinkID] = link
+				json.NewEncoder(w).Encode(link)
+			case http.MethodGet:
+				var list []*Link
+				for _, link := range links {
+					list = append(list, link)
+				}
+				json.NewEncoder(w).Encode(list)
+			}
+			return
+		}
+
+		link, ok := links[uuid.MustParse(strings.TrimPrefix(r.URL.Path, prefix+"/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(link)
+		case http.MethodPut:
+			var req CreateLinkRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			link.Slug, link.Labels = req.Slug, req.Labels
+			link.Version++
+			json.NewEncoder(w).Encode(link)
+		case http.MethodDelete:
+			delete(links, link.LinkID)
+		}
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+
+	created, err := client.CreateLink(spaceID, CreateLinkRequest{Slug: "web-to-db", FromUnitID: web, ToUnitID: db})
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.LinkID)
+	assert.Equal(t, web, created.FromUnitID)
+	assert.Equal(t, db, created.ToUnitID)
+
+	fetched, err := client.GetLink(spaceID, created.LinkID)
+	require.NoError(t, err)
+	assert.Equal(t, "web-to-db", fetched.Slug)
+
+	updated, err := client.UpdateLink(spaceID, created.LinkID, CreateLinkRequest{Slug: "web-needs-db", FromUnitID: web, ToUnitID: db, Labels: map[string]string{"kind": "dependency"}})
+	require.NoError(t, err)
+	assert.Equal(t, "web-needs-db", updated.Slug)
+	assert.Equal(t, int64(2), updated.Version)
+
+	list, err := client.ListLinks(spaceID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "dependency", list[0].Labels["kind"])
+	table := RenderLinksTable(list, []*Unit{{UnitID: web, Slug: "web"}, {UnitID: db, Slug: "db"}})
+	assert.Contains(t, table, "web-needs-db")
+	assert.Contains(t, table, "db")
+
+	require.NoError(t, client.DeleteLink(spaceID, created.LinkID))
+	_, err = client.GetLink(spaceID, created.LinkID)
+	assert.True(t, isNotFound(err), "deleted: %v", err)
+	_, err = client.UpdateLink(spaceID, created.LinkID, CreateLinkRequest{Slug: "gone"})
+	assert.True(t, isNotFound(err))
+	list, err = client.ListLinks(spaceID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+// Test listing units through a stored filter
+func TestGetFilteredUnits(t *testing.T) {
+	spaceID, filterID, unitID := uuid.New(), uuid.New(), uuid.New()
+	var where string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/space/%s/filter/%s", spaceID, filterID):
+			json.NewEncoder(w).Encode(Filter{FilterID: filterID, Slug: "frontend", From: "Unit", Where: "Labels.tier = 'frontend'", Select: []string{"Slug", "Labels"}})
+		case fmt.Sprintf("/space/%s/unit", spaceID):
+			where = r.URL.Query().Get("where")
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Unit": Unit{UnitID: unitID, SpaceID: spaceID, Slug: "web", Data: "kind: Deployment", Labels: map[string]string{"tier": "frontend"}}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+
+	t.Run("AppliesWhereAndSelect", func(t *testing.T) {
+		units, err := client.GetFilteredUnits(filterID, spaceID)
+		require.NoError(t, err)
+		assert.Equal(t, "Labels.tier = 'frontend'", where)
+		require.Len(t, units, 1)
+		assert.Equal(t, unitID, units[0].UnitID)
+		assert.Equal(t, "web", units[0].Slug)
+		assert.Equal(t, "frontend", units[0].Labels["tier"])
+		assert.Empty(t, units[0].Data, "Data is not selected")
+	})
+
+	t.Run("MissingFilter", func(t *testing.T) {
+		missing := uuid.New()
+		_, err := client.GetFilteredUnits(missing, spaceID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("filter %s not found in space %s", missing, spaceID))
+	})
+}
+
+// Test discovering downstream spaces through upstream unit links
+func TestListDownstreamSpaces(t *testing.T) {
+	project := map[string]string{"project": "shop"}
+	baseID, eastID, prodID, filtersID, otherID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	baseUnit, eastUnit := uuid.New(), uuid.New()
+	spaces := map[uuid.UUID]Space{
+		baseID:    {SpaceID: baseID, Slug: "shop", Labels: project},
+		eastID:    {SpaceID: eastID, Slug: "shop-east", Labels: project},
+		prodID:    {SpaceID: prodID, Slug: "shop-live", Labels: project},
+		filtersID: {SpaceID: filtersID, Slug: "shop-filters", Labels: project},
+		otherID:   {SpaceID: otherID, Slug: "fork", Labels: map[string]string{"project": "fork"}},
+	}
+	units := map[uuid.UUID][]Unit{
+		baseID:  {{UnitID: baseUnit, Slug: "web"}},
+		eastID:  {{UnitID: eastUnit, Slug: "web", UpstreamUnitID: &baseUnit}},
+		prodID:  {{UnitID: uuid.New(), Slug: "web", UpstreamUnitID: &eastUnit}},
+		otherID: {{UnitID: uuid.New(), Slug: "web", UpstreamUnitID: &baseUnit}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) == 1 {
+			var list []map[string]interface{}
+			for _, space := range spaces {
+				list = append(list, map[string]interface{}{"Space": space})
+			}
+			json.NewEncoder(w).Encode(list)
+			return
+		}
+		spaceID := uuid.MustParse(parts[1])
+		if len(parts) == 2 {
+			json.NewEncoder(w).Encode(spaces[spaceID])
+			return
+		}
+		var list []map[string]interface{}
+		for _, unit := range units[spaceID] {
+			list = append(list, map[string]interface{}{"Unit": unit})
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+
+	t.Run("OrderedByDepth", func(t *testing.T) {
+		downstream, err := client.ListDownstreamSpaces(baseID)
+		require.NoError(t, err)
+		var slugs []string
+		for _, space := range downstream {
+			slugs = append(slugs, space.Slug)
+		}
+		assert.Equal(t, []string{"shop-east", "shop-live"}, slugs, "unlinked and other-project spaces are skipped")
+	})
+
+	t.Run("UnlabeledBaseSearchesAllSpaces", func(t *testing.T) {
+		spaces[baseID] = Space{SpaceID: baseID, Slug: "shop"}
+		downstream, err := client.ListDownstreamSpaces(baseID)
+		require.NoError(t, err)
+		require.Len(t, downstream, 3)
+		assert.Equal(t, "fork", downstream[0].Slug)
+		assert.Equal(t, "shop-east", downstream[1].Slug)
+		assert.Equal(t, "shop-live", downstream[2].Slug)
+	})
+}
+
+// Test space prefixes from ConfigHub and the offline fallback
+func TestGetNewSpacePrefix(t *testing.T) {
+	t.Run("FromConfigHub", func(t *testing.T) {
+		var method, path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			w.Write([]byte(`{"Prefix":"chubby-paws"}`))
+		}))
+		defer server.Close()
+
+		prefix, err := NewConfigHubClient(server.URL, "test-token").GetNewSpacePrefix()
+		require.NoError(t, err)
+		assert.Equal(t, "chubby-paws", prefix)
+		assert.Equal(t, http.MethodPost, method)
+		assert.Equal(t, "/space/new-prefix", path)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	t.Run("ErrorWithoutFallback", func(t *testing.T) {
+		_, err := NewConfigHubClient(server.URL, "test-token").GetNewSpacePrefix()
+		assert.Error(t, err)
+	})
+
+	t.Run("OfflineFallback", func(t *testing.T) {
+		client := NewConfigHubClient(server.URL, "test-token")
+		client.OfflinePrefixFallback = true
+
+		seen := make(map[string]bool)
+		for i := 0; i < 20; i++ {
+			prefix, err := client.GetNewSpacePrefix()
+			require.NoError(t, err)
+			assert.Regexp(t, `^[a-z]+-[a-z]+$`, prefix)
+			seen[prefix] = true
+		}
+		assert.Greater(t, len(seen), 1, "back-to-back prefixes differ")
+	})
+}
+
+// Test the force and backup gates around recreating a space
+func TestEnsureSpaceRecreated(t *testing.T) {
+	existingID := uuid.New()
+	var (
+		exists       bool
+		deleteStatus int
+		createStatus int
+		calls        []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method)
+		switch r.Method {
+		case http.MethodGet:
+			var list []map[string]interface{}
+			if exists {
+				list = append(list, map[string]interface{}{"Space": Space{SpaceID: existingID, Slug: "shop-staging"}})
+			}
+			json.NewEncoder(w).Encode(list)
+		case http.MethodDelete:
+			w.WriteHeader(deleteStatus)
+		case http.MethodPost:
+			if createStatus != http.StatusOK {
+				w.WriteHeader(createStatus)
+				return
+			}
+			json.NewEncoder(w).Encode(Space{SpaceID: uuid.New(), Slug: "shop-staging"})
+		}
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+	client.MaxRetries = 0
+	req := CreateSpaceRequest{Slug: "shop-staging"}
+
+	reset := func(spaceExists bool) {
+		exists, deleteStatus, createStatus, calls = spaceExists, http.StatusOK, http.StatusOK, nil
+	}
+
+	t.Run("CreatesMissingSpace", func(t *testing.T) {
+		reset(false)
+		result, err := client.EnsureSpaceRecreated(req, RecreateSpaceOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "shop-staging", result.Space.Slug)
+		assert.False(t, result.Deleted)
+		assert.Empty(t, result.BackupPath)
+	})
+
+	t.Run("RefusesWithoutForce", func(t *testing.T) {
+		reset(true)
+		_, err := client.EnsureSpaceRecreated(req, RecreateSpaceOptions{})
+		assert.ErrorIs(t, err, ErrSpaceExists)
+		assert.Equal(t, []string{http.MethodGet}, calls, "nothing is deleted or created")
+	})
+
+	t.Run("ForceDeletesAndRecreates", func(t *testing.T) {
+		reset(true)
+		result, err := client.EnsureSpaceRecreated(req, RecreateSpaceOptions{Force: true})
+		require.NoError(t, err)
+		assert.True(t, result.Deleted)
+		assert.Equal(t, []string{http.MethodGet, http.MethodDelete, http.MethodPost}, calls)
+	})
+
+	t.Run("DeleteIsIdempotent", func(t *testing.T) {
+		reset(true)
+		deleteStatus = http.StatusNotFound
+		result, err := client.EnsureSpaceRecreated(req, RecreateSpaceOptions{Force: true})
+		require.NoError(t, err)
+		assert.NotNil(t, result.Space)
+	})
+
+	t.Run("FailedBackupKeepsSpace", func(t *testing.T) {
+		reset(true)
+		t.Setenv("PATH", t.TempDir()) // No cub CLI to create the package
+		_, err := client.EnsureSpaceRecreated(req, RecreateSpaceOptions{Force: true, BackupDir: t.TempDir()})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "back up space shop-staging")
+		assert.Equal(t, []string{http.MethodGet}, calls, "nothing is deleted without a backup")
+	})
+
+	t.Run("RecreatedConcurrently", func(t *testing.T) {
+		reset(true)
+		createStatus = http.StatusConflict
+		_, err := client.EnsureSpaceRecreated(req, RecreateSpaceOptions{Force: true})
+		assert.ErrorIs(t, err, ErrSpaceExists)
+		assert.Contains(t, err.Error(), "recreated by another client")
+	})
+}
+
+// Test get-or-create for spaces and units, including losing a creation race
+func TestEnsureCreate(t *testing.T) {
+	spaceID := uuid.New()
+	var (
+		mu      sync.Mutex
+		spaces  []Space
+		units   []Unit
+		posts   int
+		racer   bool // A concurrent caller creates the object just before our POST
+		failing bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		unitPath := fmt.Sprintf("/space/%s/unit", spaceID)
+		if r.Method == http.MethodPost {
+			posts++
+			var req struct{ Slug string }
+			json.NewDecoder(r.Body).Decode(&req)
+			if racer {
+				if r.URL.Path == unitPath {
+					units = append(units, Unit{UnitID: uuid.New(), Slug: req.Slug})
+				} else {
+					spaces = append(spaces, Space{SpaceID: uuid.New(), Slug: req.Slug})
+				}
+				http.Error(w, "conflict", http.StatusConflict)
+				return
+			}
+			if failing {
+				http.Error(w, "quota exceeded", http.StatusForbidden)
+				return
+			}
+			if r.URL.Path == unitPath {
+				units = append(units, Unit{UnitID: uuid.New(), Slug: req.Slug})
+				json.NewEncoder(w).Encode(units[len(units)-1])
+				return
+			}
+			spaces = append(spaces, Space{SpaceID: uuid.New(), Slug: req.Slug})
+			json.NewEncoder(w).Encode(spaces[len(spaces)-1])
+			return
+		}
+
+		var list []map[string]interface{}
+		if r.URL.Path == unitPath {
+			where := r.URL.Query().Get("where")
+			for _, unit := range units {
+				if where == fmt.Sprintf("Slug = '%s'", unit.Slug) {
+					list = append(list, map[string]interface{}{"Unit": unit})
+				}
+			}
+		} else {
+			for _, space := range spaces {
+				list = append(list, map[string]interface{}{"Space": space})
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+	client.MaxRetries = 0
+
+	reset := func() {
+		spaces, units, posts, racer, failing = nil, nil, 0, false, false
+	}
+
+	t.Run("CreatesThenFinds", func(t *testing.T) {
+		reset()
+		space, created, err := client.EnsureSpace(CreateSpaceRequest{Slug: "shop"})
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		again, created, err := client.EnsureSpace(CreateSpaceRequest{Slug: "shop"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, space.SpaceID, again.SpaceID)
+
+		unit, created, err := client.EnsureUnit(spaceID, CreateUnitRequest{Slug: "web"})
+		require.NoError(t, err)
+		assert.True(t, created)
+		sameUnit, created, err := client.EnsureUnit(spaceID, CreateUnitRequest{Slug: "web"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, unit.UnitID, sameUnit.UnitID)
+		assert.Equal(t, 2, posts, "existing objects are not created again")
+	})
+
+	t.Run("ConcurrentCallerWins", func(t *testing.T) {
+		reset()
+		racer = true
+		space, created, err := client.EnsureSpace(CreateSpaceRequest{Slug: "shop"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "shop", space.Slug)
+
+		unit, created, err := client.EnsureUnit(spaceID, CreateUnitRequest{Slug: "web"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "web", unit.Slug)
+	})
+
+	t.Run("CreateFails", func(t *testing.T) {
+		reset()
+		failing = true
+		_, _, err := client.EnsureSpace(CreateSpaceRequest{Slug: "shop"})
+		assert.ErrorContains(t, err, "create space shop: API error 403")
+		_, _, err = client.EnsureUnit(spaceID, CreateUnitRequest{Slug: "web"})
+		assert.ErrorContains(t, err, "create unit web: API error 403")
+	})
+}
+
+// Test parsing and serializing a unit's manifest through Data
+func TestUnitManifest(t *testing.T) {
+	unit := &Unit{Data: "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 2\n"}
+
+	manifest, err := unit.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", manifest["kind"])
+
+	manifest["spec"].(map[string]interface{})["replicas"] = 3
+	require.NoError(t, unit.SetManifest(manifest))
+	assert.Contains(t, unit.Data, "replicas: 3")
+
+	roundTrip, err := unit.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, manifest, roundTrip)
+
+	_, err = (&Unit{Data: "kind: [unterminated"}).Manifest()
+	assert.Error(t, err)
+}
+
+// Test units packing a Deployment, Service and HPA into one Data blob
+func TestMultiDocumentUnit(t *testing.T) {
+	const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: "1"
+            memory: 1Gi
+          limits:
+            cpu: "2"
+            memory: 2Gi
+`
+	const data = `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  ports:
+  - port: 80
+---
+` + deployment + `---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: shop
+spec:
+  minReplicas: 2
+  maxReplicas: 5
+`
+	app := &DevOpsApp{Logger: log.New(io.Discard, "", 0)}
+	newUnit := func() *Unit {
+		return &Unit{UnitID: uuid.New(), SpaceID: uuid.New(), Slug: "web", Data: data}
+	}
+	kinds := func(t *testing.T, unit *Unit) []string {
+		manifests, err := unit.Manifests()
+		require.NoError(t, err)
+		var kinds []string
+		for _, manifest := range manifests {
+			kinds = append(kinds, manifest["kind"].(string))
+		}
+		return kinds
+	}
+
+	t.Run("Manifests", func(t *testing.T) {
+		unit := newUnit()
+		assert.Equal(t, []string{"Service", "Deployment", "HorizontalPodAutoscaler"}, kinds(t, unit))
+
+		manifest, err := unit.Manifest()
+		require.NoError(t, err)
+		assert.Equal(t, "Deployment", manifest["kind"], "the workload, not the leading Service")
+
+		manifest["spec"].(map[string]interface{})["replicas"] = 3
+		require.NoError(t, unit.SetManifest(manifest))
+		assert.Equal(t, []string{"Service", "Deployment", "HorizontalPodAutoscaler"}, kinds(t, unit))
+		assert.Contains(t, unit.Data, "replicas: 3")
+		assert.Contains(t, unit.Data, "maxReplicas: 5")
+	})
+
+	t.Run("Cost", func(t *testing.T) {
+		ca := NewCostAnalyzer(app, uuid.New())
+		single, err := ca.AnalyzeUnit(Unit{Slug: "web", Data: deployment})
+		require.NoError(t, err)
+
+		estimate, err := ca.AnalyzeUnit(*newUnit())
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, "Deployment", estimate.Type)
+		assert.Equal(t, int32(2), estimate.Replicas)
+		assert.InDelta(t, single.MonthlyCost, estimate.MonthlyCost, 0.0001)
+		assert.Empty(t, estimate.Workloads)
+
+		worker := strings.ReplaceAll(deployment, "name: web", "name: worker")
+		estimate, err = ca.AnalyzeUnit(Unit{Slug: "web", Data: data + "---\n" + worker})
+		require.NoError(t, err)
+		require.Len(t, estimate.Workloads, 2)
+		assert.InDelta(t, 2*single.MonthlyCost, estimate.MonthlyCost, 0.0001)
+		assert.InDelta(t, 2*single.Breakdown.CPUCost, estimate.Breakdown.CPUCost, 0.0001)
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		unit := newUnit()
+		config, err := NewOptimizationEngine(app, uuid.New()).GenerateOptimizedUnit(unit, &WasteMetrics{
+			CPUWastePercent:    0.6,
+			MemoryWastePercent: 0.5,
+			WasteConfidence:    0.9,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "web-optimized", config.OptimizedUnit.Slug)
+		assert.Equal(t, unit, config.OriginalUnit)
+		assert.Len(t, config.Optimizations, 2)
+		assert.Greater(t, config.EstimatedSavings.MonthlySavings, 0.0)
+		assert.Equal(t, []string{"Service", "Deployment", "HorizontalPodAutoscaler"}, kinds(t, config.OptimizedUnit))
+
+		plan, err := DecodeRollbackPlan(config.OptimizedUnit)
+		require.NoError(t, err)
+		assert.Equal(t, data, plan.OriginalManifest)
+		require.NotEmpty(t, plan.Steps)
+		assert.Equal(t, "Deployment/web", plan.Steps[0].Workload)
+
+		_, err = NewOptimizationEngine(app, uuid.New()).GenerateOptimizedUnit(&Unit{Slug: "svc", Data: "kind: Service\n---\nkind: ConfigMap\n"}, &WasteMetrics{})
+		assert.ErrorContains(t, err, "unsupported resource type")
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		e := NewEnterpriseModeDeployer(app, uuid.New(), "https://example.com/repo.git", "main")
+		e.SetWorkDir(t.TempDir())
+		require.NoError(t, e.exportUnitToGit(*newUnit()))
+
+		for _, file := range []string{"service/web.yaml", "deployment/web.yaml", "horizontalpodautoscaler/web.yaml"} {
+			content, err := os.ReadFile(filepath.Join(e.workDir, "manifests", "shop", file))
+			require.NoError(t, err, file)
+			assert.NotContains(t, string(content), "---", "one document per file")
+			assert.Contains(t, string(content), "confighub.io/unit-id")
+		}
+	})
+}
+
+// Test previewing a bulk apply from the server's plan or from live state
+func TestPlanBulkApply(t *testing.T) {
+	spaceID := uuid.New()
+	applied := time.Now().Add(-time.Hour)
+	units := map[string]Unit{
+		"new":     {UnitID: uuid.New(), Slug: "new"},
+		"edited":  {UnitID: uuid.New(), Slug: "edited", UpdatedAt: time.Now()},
+		"drifted": {UnitID: uuid.New(), Slug: "drifted", UpdatedAt: applied.Add(-time.Hour)},
+		"failed":  {UnitID: uuid.New(), Slug: "failed", UpdatedAt: applied.Add(-time.Hour)},
+		"current": {UnitID: uuid.New(), Slug: "current", UpdatedAt: applied.Add(-time.Hour)},
+	}
+	liveStates := map[uuid.UUID]LiveState{
+		units["edited"].UnitID:  {Status: "Applied", LastAppliedAt: applied},
+		units["drifted"].UnitID: {Status: "Applied", LastAppliedAt: applied, DriftDetected: true},
+		units["failed"].UnitID:  {Status: "Failed", LastAppliedAt: applied, LastError: "image pull backoff"},
+		units["current"].UnitID: {Status: "Applied", LastAppliedAt: applied},
+	}
+
+	newServer := func(planBody string, dryRun *bool, where *string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == fmt.Sprintf("/space/%s/unit/bulk-apply", spaceID):
+				var params BulkApplyParams
+				json.NewDecoder(r.Body).Decode(&params)
+				*dryRun = params.DryRun
+				w.Write([]byte(planBody))
+			case r.URL.Path == fmt.Sprintf("/space/%s/unit", spaceID):
+				*where = r.URL.Query().Get("where")
+				var list []map[string]interface{}
+				for _, slug := range []string{"new", "edited", "drifted", "failed", "current"} {
+					list = append(list, map[string]interface{}{"Unit": units[slug]})
+				}
+				json.NewEncoder(w).Encode(list)
+			case strings.HasSuffix(r.URL.Path, "/live-state"):
+				for unitID, state := range liveStates {
+					if strings.Contains(r.URL.Path, unitID.String()) {
+						json.NewEncoder(w).Encode(state)
+						return
+					}
+				}
+				http.Error(w, "not found", http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+
+	t.Run("ServerPlan", func(t *testing.T) {
+		var dryRun bool
+		var where string
+		server := newServer(`{"Units":[{"Slug":"web","Action":"update","Reason":"image changed"},{"Slug":"db","Action":"no-op"}]}`, &dryRun, &where)
+		defer server.Close()
+
+		plan, err := NewConfigHubClient(server.URL, "test-token").PlanBulkApply(BulkApplyParams{SpaceID: spaceID, Where: "Labels.app = 'web'"})
+		require.NoError(t, err)
+		assert.True(t, dryRun)
+		assert.False(t, plan.Synthesized)
+		assert.Equal(t, "Labels.app = 'web'", plan.Where)
+		require.Len(t, plan.Units, 2)
+		assert.Equal(t, ApplyUpdate, plan.Units[0].Action)
+		assert.Equal(t, 1, plan.Count(ApplyNoOp))
+		assert.Empty(t, where, "units are not listed when the server plans")
+	})
+
+	t.Run("SynthesizedPlan", func(t *testing.T) {
+		var dryRun bool
+		var where string
+		server := newServer("", &dryRun, &where)
+		defer server.Close()
+
+		plan, err := NewConfigHubClient(server.URL, "test-token").PlanBulkApply(BulkApplyParams{SpaceID: spaceID, Where: "Labels.app = 'web'"})
+		require.NoError(t, err)
+		assert.True(t, plan.Synthesized)
+		assert.Equal(t, "Labels.app = 'web'", where)
+
+		actions := map[string]ApplyAction{}
+		for _, unit := range plan.Units {
+			actions[unit.Slug] = unit.Action
+		}
+		assert.Equal(t, map[string]ApplyAction{
+			"new":     ApplyCreate,
+			"edited":  ApplyUpdate,
+			"drifted": ApplyUpdate,
+			"failed":  ApplyUpdate,
+			"current": ApplyNoOp,
+		}, actions)
+		assert.Equal(t, "last apply failed: image pull backoff", plan.Units[3].Reason)
+
+		table := RenderApplyPlanTable(plan)
+		assert.Contains(t, table, "changed since last apply")
+		assert.Contains(t, table, "1 to create, 3 to update, 1 unchanged")
+	})
+}
+
+// Test label- and annotation-only bulk patches
+func TestBulkSetLabels(t *testing.T) {
+	spaceID := uuid.New()
+	var patches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, fmt.Sprintf("/space/%s/unit/bulk-patch", spaceID), r.URL.Path)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		patches = append(patches, body)
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+	where := NewWhere().Eq("Labels.app", "web").MustBuild()
+
+	require.NoError(t, client.BulkSetLabels(spaceID, where, map[string]string{"team": "payments"}))
+	require.NoError(t, client.BulkSetAnnotations(spaceID, where, map[string]string{"owner": "alice"}))
+	require.NoError(t, client.BulkRemoveLabels(spaceID, where, "legacy"))
+	require.Len(t, patches, 3)
+	assert.Equal(t, map[string]interface{}{"Labels": map[string]interface{}{"team": "payments"}}, patches[0]["Patch"])
+	assert.Equal(t, map[string]interface{}{"Annotations": map[string]interface{}{"owner": "alice"}}, patches[1]["Patch"])
+	assert.Equal(t, map[string]interface{}{"Labels": map[string]interface{}{"legacy": nil}}, patches[2]["Patch"])
+	assert.Equal(t, where, patches[0]["Where"])
+	assert.NotContains(t, patches[0], "AllowDataOverwrite")
+
+	assert.ErrorContains(t, client.BulkSetLabels(spaceID, "", map[string]string{"team": "x"}), "where clause is required")
+	require.NoError(t, client.BulkRemoveLabels(spaceID, where))
+	assert.Len(t, patches, 3, "empty and rejected patches are not sent")
+
+	var logged strings.Builder
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	dataPatch := BulkPatchParams{SpaceID: spaceID, Where: where, Patch: map[string]interface{}{"Data": ""}}
+	require.NoError(t, client.BulkPatchUnits(dataPatch))
+	assert.Contains(t, logged.String(), "overwrites Data")
+
+	logged.Reset()
+	dataPatch.AllowDataOverwrite = true
+	require.NoError(t, client.BulkPatchUnits(dataPatch))
+	assert.Empty(t, logged.String())
+}
+
+func TestApplyUnitsInOrder(t *testing.T) {
+	spaceID := uuid.New()
+	unitIDs := map[string]uuid.UUID{"namespace": uuid.New(), "service": uuid.New(), "deployment": uuid.New()}
+	var applied []string
+	failures := map[string]int{} // Slug -> 503s still to return; -1 fails permanently with 400
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			for slug, id := range unitIDs {
+				if r.URL.Query().Get("where") == NewWhere().Eq("Slug", slug).MustBuild() {
+					json.NewEncoder(w).Encode([]map[string]*Unit{{"Unit": {UnitID: id, Slug: slug}}})
+					return
+				}
+			}
+			w.Write([]byte("[]"))
+			return
+		}
+		for slug, id := range unitIDs {
+			if r.URL.Path != fmt.Sprintf("/space/%s/unit/%s/apply", spaceID, id) {
+				continue
+			}
+			switch {
+			case failures[slug] < 0:
+				w.WriteHeader(http.StatusBadRequest)
+			case failures[slug] > 0:
+				failures[slug]--
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				applied = append(applied, slug)
+			}
+			return
+		}
+	}))
+	defer server.Close()
+	client := NewConfigHubClient(server.URL, "test-token")
+	client.RetryDelay = time.Millisecond
+	slugs := []string{"namespace", "missing", "service", "deployment"}
+
+	t.Run("RetriesTransientFailures", func(t *testing.T) {
+		applied = nil
+		failures = map[string]int{"service": 2}
+		results, err := client.ApplyUnitsInOrder(spaceID, slugs, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"namespace", "service", "deployment"}, applied)
+		require.Len(t, results, 4)
+		assert.Equal(t, ApplyStatusApplied, results[0].Status)
+		assert.Equal(t, ApplyStatusSkipped, results[1].Status)
+		assert.Equal(t, "unit not found", results[1].Reason)
+		assert.Equal(t, unitIDs["service"], results[2].UnitID)
+		assert.Equal(t, 3, results[2].Attempts)
+		assert.Contains(t, RenderApplyResultsTable(results), "unit not found")
+	})
+
+	t.Run("StopsAtFirstFailure", func(t *testing.T) {
+		applied = nil
+		failures = map[string]int{"service": -1}
+		results, err := client.ApplyUnitsInOrder(spaceID, slugs, false)
+		var applyErr *ApplyOrderError
+		require.ErrorAs(t, err, &applyErr)
+		assert.Len(t, applyErr.Failures, 1)
+		apiErr := asAPIError(err)
+		require.NotNil(t, apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, []string{"namespace"}, applied)
+		assert.Equal(t, ApplyStatusFailed, results[2].Status)
+		assert.Equal(t, 1, results[2].Attempts, "client errors are not retried")
+		assert.Equal(t, ApplyStatusSkipped, results[3].Status)
+		assert.Equal(t, "not attempted after an earlier failure", results[3].Reason)
+	})
+
+	t.Run("ContinuesOnError", func(t *testing.T) {
+		applied = nil
+		failures = map[string]int{"namespace": 10, "service": -1}
+		results, err := client.ApplyUnitsInOrder(spaceID, slugs, true)
+		var applyErr *ApplyOrderError
+		require.ErrorAs(t, err, &applyErr)
+		assert.Len(t, applyErr.Failures, 2)
+		assert.Contains(t, err.Error(), "2 unit(s) failed to apply: namespace: ")
+		assert.Equal(t, []string{"deployment"}, applied)
+		assert.Equal(t, client.MaxRetries+1, results[0].Attempts)
+		assert.Equal(t, ApplyStatusApplied, results[3].Status)
+	})
+}
@@ -1,13 +1,15 @@
 // cost.go - Cost analysis module for the DevOps SDK
 //
 // This module provides comprehensive cost analysis capabilities for ConfigHub units,
-// analyzing Kubernetes resources (Deployments, StatefulSets, DaemonSets) and
-// calculating estimated monthly costs based on CPU, memory, and storage usage.
+// analyzing Kubernetes resources (Deployments, StatefulSets, DaemonSets, Jobs,
+// CronJobs) and calculating estimated monthly costs based on CPU, memory, and
+// storage usage.
 //
 // Features:
 // - Parse ConfigHub units containing Kubernetes manifests
 // - Extract resource requests/limits from containers
 // - Calculate monthly costs using configurable pricing models
+// - Estimate network egress cost from measured traffic (never from manifests)
 // - Generate human-readable cost reports
 // - Provide optimization recommendations
 // - Store cost annotations back to ConfigHub units
@@ -18,15 +20,17 @@
 package sdk
 
 import (
-	"encoding/base64"
+	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // CostAnalyzer analyzes costs from ConfigHub units
@@ -34,13 +38,89 @@ type CostAnalyzer struct {
 	app     *DevOpsApp
 	spaceID uuid.UUID
 	pricing *PricingModel
+
+	// limitRangeDefaults holds LimitRange default requests keyed by namespace
+	limitRangeDefaults map[string]ResourceSpecs
+
+	// clusterNodeCount is how many nodes DaemonSets run on; 0 means unknown
+	clusterNodeCount int
+
+	// nodeClassPricing prices pods pinned to a node pool, keyed by the value
+	// of the nodeClassLabel node label
+	nodeClassLabel   string
+	nodeClassPricing map[string]*PricingModel
+
+	// environments are the downstream spaces AnalyzeHierarchy visits, as
+	// "<base>-<env>" slug suffixes; nil discovers them by EnvironmentLabel
+	environments []string
+	concurrency  int // Max environments analyzed in parallel
+
+	// budget is the space's monthly budget in USD; 0 means none. labelBudgets
+	// are per-value budgets for units grouped by budgetLabel.
+	budget       float64
+	budgetLabel  string
+	labelBudgets map[string]float64
+	budgetAlerts Notifier // Notified when an analysis is over budget; nil sends nothing
+
+	// checkLiveState fetches each unit's live state to flag drifted estimates
+	checkLiveState bool
+
+	// unallocatedThreshold is the unallocated chargeback share, in percent,
+	// above which reports warn; 0 uses DefaultUnallocatedThreshold
+	unallocatedThreshold float64
+
+	// cache reuses recent AnalyzeSpace results; nil analyzes every time
+	cache *AnalysisCache
 }
 
+// EnvironmentLabel marks a downstream space's environment (e.g. "staging")
+// for AnalyzeHierarchy discovery
+const EnvironmentLabel = "environment"
+
+// defaultEnvironments are analyzed when no downstream space carries EnvironmentLabel
+var defaultEnvironments = []string{"dev", "staging", "prod"}
+
+// MissingRequestsAnnotation flags estimates for containers with no requests, limits, or namespace default
+const MissingRequestsAnnotation = "cost-optimizer.io/missing-requests"
+
+// RunDurationAnnotation records a Job's typical run time from historical runs
+// (e.g. "20m"), set on the unit or the manifest
+const RunDurationAnnotation = "cost-optimizer.io/run-duration"
+
+// MissingDurationAnnotation flags Job estimates with no run duration to price
+const MissingDurationAnnotation = "cost-optimizer.io/missing-duration"
+
+// LiveDriftAnnotation flags estimates for units whose live state has drifted
+// from the manifest they were priced from
+const LiveDriftAnnotation = "cost-optimizer.io/live-drift"
+
+// defaultDaemonSetNodes is the node count assumed for DaemonSets when the
+// cluster size is unknown
+const defaultDaemonSetNodes = 3
+
+// AssumedNodeCountAnnotation flags DaemonSet estimates priced at an assumed
+// node count because SetClusterNodeCount wasn't called
+const AssumedNodeCountAnnotation = "cost-optimizer.io/assumed-node-count"
+
 // PricingModel for cost calculations
 type PricingModel struct {
 	CPUHourly    float64 // Cost per CPU core per hour
 	MemoryHourly float64 // Cost per GB memory per hour
 	StorageGB    float64 // Cost per GB storage per month
+	EgressGBCost float64 // Cost per GB of network egress (applied to measured traffic only)
+
+	LoadBalancerHourly float64 // Cost per cloud load balancer per hour, charged for each LoadBalancer Service
+
+	HoursPerMonth float64 // Billable hours in a month (default: DefaultHoursPerMonth)
+
+	// Display currency; all prices above and all cost math stay in USD
+	Currency       string  // ISO 4217 code, e.g. "EUR" (default: USD)
+	CurrencySymbol string  // Symbol printed before amounts, e.g. "€" (default: $)
+	CurrencyRate   float64 // Units of Currency per USD (default: 1)
+
+	// Committed-use (reserved instance) discount on baseline CPU and memory
+	CommitmentCoverage float64 // Fraction of CPU/memory priced at the committed rate (0-1)
+	CommitmentDiscount float64 // Discount of the committed rate off on-demand (0-1)
 }
 
 // DefaultPricing based on AWS EKS m5.large pricing
@@ -48,6 +128,39 @@ var DefaultPricing = &PricingModel{
 	CPUHourly:    0.024, // $0.024 per vCPU hour
 	MemoryHourly: 0.006, // $0.006 per GB hour
 	StorageGB:    0.10,  // $0.10 per GB per month
+	EgressGBCost: 0.09,  // $0.09 per GB transferred out
+
+	LoadBalancerHourly: 0.0225, // $0.0225 per NLB hour
+
+	HoursPerMonth: DefaultHoursPerMonth,
+
+	Currency:       "USD",
+	CurrencySymbol: "$",
+	CurrencyRate:   1,
+}
+
+// DefaultHoursPerMonth is the average month, 8760 hours a year / 12
+const DefaultHoursPerMonth = 730.0
+
+// hoursPerMonth returns the billable hours in a month
+func (p *PricingModel) hoursPerMonth() float64 {
+	if p == nil || p.HoursPerMonth <= 0 {
+		return DefaultHoursPerMonth
+	}
+	return p.HoursPerMonth
+}
+
+// FormatAmount converts a USD amount to the display currency and formats it,
+// e.g. "€12.34". Conversion happens only here, so rounding never compounds.
+func (p *PricingModel) FormatAmount(usd float64) string {
+	symbol, rate := "$", 1.0
+	if p != nil && p.CurrencySymbol != "" {
+		symbol = p.CurrencySymbol
+	}
+	if p != nil && p.CurrencyRate > 0 {
+		rate = p.CurrencyRate
+	}
+	return fmt.Sprintf("%s%.2f", symbol, usd*rate)
 }
 
 // ResourceQuantity represents a simple resource quantity (avoiding k8s dependency)
@@ -185,20 +298,42 @@ type UnitCostEstimate struct {
 	UnitID      string
 	UnitName    string
 	Space       string
-	Type        string // deployment, service, statefulset, etc
+	Type        string // Deployment, StatefulSet, DaemonSet, Job, CronJob or LoadBalancerServiceType
+	NodeClass   string // Node pool the pods are pinned to and priced for; empty for DefaultPricing
 	Replicas    int32
 	CPU         ResourceQuantity
 	Memory      ResourceQuantity
 	Storage     ResourceQuantity
 	MonthlyCost float64
 	Breakdown   CostBreakdown
+	Annotations map[string]string  // Unit annotations plus analysis flags, e.g. cost-optimizer.io/missing-requests
+	Labels      map[string]string  // Unit labels, e.g. cost-optimizer.io/schedule
+	Workloads   []UnitCostEstimate // Per-document estimates when the unit holds several workloads
+	RunFraction float64            // Share of the month a Job or CronJob's pods run; unused for other kinds
+
+	// UptimeFraction is the share of the month the pods are up, set by
+	// ApplyUptime for e.g. spot workloads; 0 prices them as always up (1)
+	UptimeFraction float64
 }
 
+// LoadBalancerServiceType is the UnitCostEstimate.Type of a LoadBalancer Service
+const LoadBalancerServiceType = "Service (LoadBalancer)"
+
 // CostBreakdown shows cost components
 type CostBreakdown struct {
 	CPUCost     float64
 	MemoryCost  float64
 	StorageCost float64
+	NetworkCost float64 // Measured egress only; zero without usage data
+
+	// Hourly load balancer charge; traffic-based data processing charges
+	// are not included
+	LoadBalancerCost float64
+
+	// CPU and memory split by commitment coverage; CommittedCost is zero
+	// without a commitment
+	CommittedCost float64
+	OnDemandCost  float64
 }
 
 // SpaceCostAnalysis represents total cost for a space
@@ -209,14 +344,19 @@ type SpaceCostAnalysis struct {
 	UnitCount        int
 	Units            []UnitCostEstimate
 	Environments     map[string]*SpaceCostAnalysis // For hierarchical spaces
+	Budget           *BudgetStatus                 // Set when the analyzer has a budget
+	LabelBudgets     map[string]*BudgetStatus      // Keyed by label value, for SetLabelBudgets
+	DriftedUnits     int                           // Units whose live state drifted; only counted with SetLiveStateCheck
+	Repricing        *RepricingSummary             // Set by RepriceAnalysis
 }
 
 // NewCostAnalyzer creates analyzer for ConfigHub units
 func NewCostAnalyzer(app *DevOpsApp, spaceID uuid.UUID) *CostAnalyzer {
 	return &CostAnalyzer{
-		app:     app,
-		spaceID: spaceID,
-		pricing: DefaultPricing,
+		app:         app,
+		spaceID:     spaceID,
+		pricing:     DefaultPricing,
+		concurrency: 4,
 	}
 }
 
@@ -225,17 +365,155 @@ func (ca *CostAnalyzer) SetPricing(pricing *PricingModel) {
 	ca.pricing = pricing
 }
 
+// ConvertCurrency displays report amounts in another currency, e.g.
+// ConvertCurrency(0.92, "EUR", "€"). rate is units of the currency per USD;
+// estimates and stored annotations stay in USD.
+func (ca *CostAnalyzer) ConvertCurrency(rate float64, code, symbol string) {
+	pricing := *DefaultPricing
+	if ca.pricing != nil {
+		pricing = *ca.pricing // Copy so the shared DefaultPricing is never modified
+	}
+	pricing.CurrencyRate = rate
+	pricing.Currency = code
+	pricing.CurrencySymbol = symbol
+	ca.pricing = &pricing
+}
+
+// Pricing returns the pricing model in use, including its display currency
+func (ca *CostAnalyzer) Pricing() *PricingModel {
+	if ca.pricing == nil {
+		return DefaultPricing
+	}
+	return ca.pricing
+}
+
+// SetCommitment prices coveragePercent of every workload's CPU and memory at a
+// committed rate discountPercent below on-demand, e.g. SetCommitment(60, 40)
+// for 1- or 3-year commitments covering 60% of baseline capacity
+func (ca *CostAnalyzer) SetCommitment(coveragePercent, discountPercent float64) {
+	pricing := *DefaultPricing
+	if ca.pricing != nil {
+		pricing = *ca.pricing // Copy so the shared DefaultPricing is never modified
+	}
+	pricing.CommitmentCoverage = math.Max(0, math.Min(coveragePercent, 100)) / 100
+	pricing.CommitmentDiscount = math.Max(0, math.Min(discountPercent, 100)) / 100
+	ca.pricing = &pricing
+}
+
+// SetNamespaceDefaults sets LimitRange default requests per namespace, used for
+// containers that don't declare their own requests
+func (ca *CostAnalyzer) SetNamespaceDefaults(defaults map[string]ResourceSpecs) {
+	ca.limitRangeDefaults = defaults
+}
+
+// SetClusterNodeCount sets how many nodes DaemonSets run one pod on. Without
+// it, DaemonSets are priced at 3 nodes and flagged with AssumedNodeCountAnnotation.
+func (ca *CostAnalyzer) SetClusterNodeCount(n int) {
+	ca.clusterNodeCount = n
+}
+
+// SetLiveStateCheck fetches each unit's live state during AnalyzeSpace.
+// Estimates of drifted units are flagged with LiveDriftAnnotation, since the
+// manifest they were priced from may not match what is running, and counted
+// in SpaceCostAnalysis.DriftedUnits. Live state carries no resource values, so
+// estimates still come from the manifest.
+func (ca *CostAnalyzer) SetLiveStateCheck(enabled bool) {
+	ca.checkLiveState = enabled
+}
+
+// SetNodeClassPricing prices pods pinned to specialized node pools with their
+// own pricing model, e.g. SetNodeClassPricing("node.kubernetes.io/instance-type",
+// map[string]*PricingModel{"p3.2xlarge": gpuPricing}). A pod is pinned by a
+// nodeSelector or required node affinity on labelKey, or by tolerating a taint
+// keyed on labelKey; other pods keep the analyzer's pricing.
+func (ca *CostAnalyzer) SetNodeClassPricing(labelKey string, pricing map[string]*PricingModel) {
+	ca.nodeClassLabel = labelKey
+	ca.nodeClassPricing = pricing
+}
+
+// SetEnvironments sets the downstream environments AnalyzeHierarchy analyzes,
+// found as "<base>-<env>" spaces. By default they are discovered instead.
+func (ca *CostAnalyzer) SetEnvironments(environments ...string) {
+	ca.environments = environments
+}
+
+// SetConcurrency sets how many environments AnalyzeHierarchy analyzes in parallel
+func (ca *CostAnalyzer) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	ca.concurrency = n
+}
+
+// SetCache makes AnalyzeSpace reuse analyses held in cache while they are
+// live and the space's content version is unchanged. Share one cache between
+// the analyzers of a run; nil turns caching off.
+func (ca *CostAnalyzer) SetCache(cache *AnalysisCache) {
+	ca.cache = cache
+}
+
+// InvalidateCache drops cached analyses of spaceID, e.g. after applying
+// changes the content version doesn't reflect yet
+func (ca *CostAnalyzer) InvalidateCache(spaceID uuid.UUID) {
+	if ca.cache != nil {
+		ca.cache.Invalidate(spaceID)
+	}
+}
+
 // AnalyzeSpace analyzes all units in a ConfigHub space
 func (ca *CostAnalyzer) AnalyzeSpace() (*SpaceCostAnalysis, error) {
+	return ca.analyzeSpace(context.Background())
+}
+
+// analyzeSpace is AnalyzeSpace traced as a child of ctx
+func (ca *CostAnalyzer) analyzeSpace(ctx context.Context) (*SpaceCostAnalysis, error) {
+	ctx, span := startSpan(ctx, "CostAnalyzer.AnalyzeSpace")
+	defer span.End()
+	span.SetAttribute("space.id", ca.spaceID.String())
+
+	var cacheKey analysisCacheKey
+	if ca.cache != nil {
+		version, err := ca.cache.contentVersion(ctx, ca.app.Cub, ca.spaceID)
+		if err != nil {
+			// Without a version a cached analysis can't be trusted
+			ca.app.Logger.Printf("⚠️  Could not get content version of space %s, analysis not cached: %v", ca.spaceID, err)
+		} else {
+			cacheKey = analysisCacheKey{spaceID: ca.spaceID, version: version, pricing: ca.Pricing()}
+			if analysis := ca.cache.get(cacheKey); analysis != nil {
+				// Budget alerts were sent when the analysis was cached
+				span.SetAttribute("cache.hit", true)
+				ca.applyBudgets(analysis)
+				return analysis, nil
+			}
+		}
+	}
+
+	analysis, err := ca.listAndAnalyze(ctx, span)
+	if err != nil {
+		return nil, err
+	}
+	if cacheKey.version != "" {
+		ca.cache.put(cacheKey, analysis)
+	}
+
+	ca.applyBudgets(analysis)
+	ca.notifyOverBudget(ctx, analysis)
+	return analysis, nil
+}
+
+// listAndAnalyze lists the space's units and estimates each one
+func (ca *CostAnalyzer) listAndAnalyze(ctx context.Context, span Span) (*SpaceCostAnalysis, error) {
 	ca.app.Logger.Printf("🔍 Analyzing ConfigHub space: %s", ca.spaceID)
 
 	// Get all units in the space
-	units, err := ca.app.Cub.ListUnits(ListUnitsParams{
+	units, err := ca.app.Cub.ListAllUnitsContext(ctx, ListUnitsParams{
 		SpaceID: ca.spaceID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list units: %v", err)
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list units: %w", err)
 	}
+	span.SetAttribute("unit.count", len(units))
 
 	analysis := &SpaceCostAnalysis{
 		SpaceID:      ca.spaceID.String(),
@@ -254,36 +532,95 @@ func (ca *CostAnalyzer) AnalyzeSpace() (*SpaceCostAnalysis, error) {
 		}
 
 		if estimate != nil {
+			if ca.checkLiveState && ca.liveStateDrifted(ctx, unit) {
+				estimate.Annotations[LiveDriftAnnotation] = "manifest may not reflect live state"
+				analysis.DriftedUnits++
+			}
 			analysis.Units = append(analysis.Units, *estimate)
 			analysis.TotalMonthlyCost += estimate.MonthlyCost
 		}
 	}
+	if analysis.DriftedUnits > 0 {
+		ca.app.Logger.Printf("⚠️  %d units have drifted from their manifests; their estimates may be off", analysis.DriftedUnits)
+	}
 
 	ca.app.Logger.Printf("✅ Analysis complete: %d units, $%.2f/month estimated cost",
 		len(analysis.Units), analysis.TotalMonthlyCost)
-
 	return analysis, nil
 }
 
-// analyzeUnit analyzes a single ConfigHub unit
+// liveStateDrifted reports whether ConfigHub saw the unit's live state drift.
+// Units never applied have no live state and haven't drifted.
+func (ca *CostAnalyzer) liveStateDrifted(ctx context.Context, unit *Unit) bool {
+	state, err := ca.app.Cub.GetUnitLiveStateContext(ctx, ca.spaceID, unit.UnitID)
+	if err != nil {
+		if !isNotFound(err) {
+			ca.app.Logger.Printf("⚠️  Could not get live state of %s: %v", unit.Slug, err)
+		}
+		return false
+	}
+	return state.DriftDetected
+}
+
+// AnalyzeUnit estimates the monthly cost of a single unit the caller already
+// holds, without listing the space. It returns nil for non-workload kinds and
+// an error for malformed manifests. Nothing is persisted; see
+// StoreAnalysisInConfigHub for that.
+func (ca *CostAnalyzer) AnalyzeUnit(unit Unit) (*UnitCostEstimate, error) {
+	return ca.analyzeUnit(unit)
+}
+
+// analyzeUnit analyzes a single ConfigHub unit. Each workload document in a
+// multi-document unit is estimated separately and the results are summed.
 func (ca *CostAnalyzer) analyzeUnit(unit Unit) (*UnitCostEstimate, error) {
 	// Decode base64 data if needed
-	data := unit.Data
-	if decoded, err := base64.StdEncoding.DecodeString(unit.Data); err == nil {
-		data = string(decoded)
+	decoded, err := decodeUnitData(unit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode unit data: %v", err)
 	}
 
 	// Skip non-Kubernetes resources
-	if !strings.Contains(data, "apiVersion") {
+	if !strings.Contains(string(decoded), "apiVersion") {
 		return nil, nil
 	}
 
-	// Parse the Kubernetes manifest
-	var manifest map[string]interface{}
-	if err := yaml.Unmarshal([]byte(data), &manifest); err != nil {
+	// Parse the Kubernetes manifests
+	manifests, err := parseManifests(decoded)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse manifest: %v", err)
 	}
 
+	var workloads, services []*UnitCostEstimate
+	for _, manifest := range manifests {
+		if kind, _ := manifest["kind"].(string); kind == "Service" {
+			if estimate := ca.analyzeService(unit, manifest); estimate != nil {
+				services = append(services, estimate)
+			}
+			continue
+		}
+		estimate, err := ca.analyzeWorkload(unit, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if estimate != nil {
+			workloads = append(workloads, estimate)
+		}
+	}
+
+	// Services go last so a unit's workload describes it
+	estimate := aggregateWorkloadEstimates(append(workloads, services...))
+	if estimate != nil {
+		// Copy so the estimate doesn't alias the unit; flags set during analysis
+		// take precedence over the unit's own annotations
+		estimate.Labels = mergeLabels(unit.Labels, nil)
+		estimate.Annotations = mergeLabels(unit.Annotations, estimate.Annotations)
+	}
+	return estimate, nil
+}
+
+// analyzeWorkload estimates one manifest document, returning nil for
+// non-workload kinds such as ConfigMaps and HorizontalPodAutoscalers
+func (ca *CostAnalyzer) analyzeWorkload(unit Unit, manifest map[string]interface{}) (*UnitCostEstimate, error) {
 	kind, _ := manifest["kind"].(string)
 
 	switch kind {
@@ -293,19 +630,75 @@ func (ca *CostAnalyzer) analyzeUnit(unit Unit) (*UnitCostEstimate, error) {
 		return ca.analyzeStatefulSet(unit, manifest)
 	case "DaemonSet":
 		return ca.analyzeDaemonSet(unit, manifest)
+	case "Job":
+		return ca.analyzeJob(unit, manifest)
+	case "CronJob":
+		return ca.analyzeCronJob(unit, manifest)
 	default:
 		// Skip non-workload resources
 		return nil, nil
 	}
 }
 
+// aggregateWorkloadEstimates combines the estimates of a unit's workload
+// documents. Costs are summed; Type, NodeClass, Replicas, CPU and Memory are
+// those of the first workload, and Workloads lists every document's estimate.
+func aggregateWorkloadEstimates(workloads []*UnitCostEstimate) *UnitCostEstimate {
+	switch len(workloads) {
+	case 0:
+		return nil
+	case 1:
+		return workloads[0]
+	}
+
+	total := *workloads[0]
+	total.Workloads = make([]UnitCostEstimate, 0, len(workloads))
+	total.MonthlyCost = 0
+	total.Breakdown = CostBreakdown{}
+	total.Storage = ResourceQuantity{}
+	for _, workload := range workloads {
+		total.Workloads = append(total.Workloads, *workload)
+		total.MonthlyCost += workload.MonthlyCost
+		total.Breakdown.CPUCost += workload.Breakdown.CPUCost
+		total.Breakdown.MemoryCost += workload.Breakdown.MemoryCost
+		total.Breakdown.StorageCost += workload.Breakdown.StorageCost
+		total.Breakdown.NetworkCost += workload.Breakdown.NetworkCost
+		total.Breakdown.LoadBalancerCost += workload.Breakdown.LoadBalancerCost
+		total.Breakdown.CommittedCost += workload.Breakdown.CommittedCost
+		total.Breakdown.OnDemandCost += workload.Breakdown.OnDemandCost
+		total.Storage.Add(workload.Storage)
+		total.Annotations = mergeLabels(total.Annotations, workload.Annotations)
+	}
+	return &total
+}
+
+// analyzeService prices a LoadBalancer Service as one cloud load balancer
+// running all month. ClusterIP and NodePort Services cost nothing and return nil.
+func (ca *CostAnalyzer) analyzeService(unit Unit, manifest map[string]interface{}) *UnitCostEstimate {
+	spec, _ := manifest["spec"].(map[string]interface{})
+	if serviceType, _ := spec["type"].(string); serviceType != "LoadBalancer" {
+		return nil
+	}
+
+	loadBalancerCost := ca.Pricing().LoadBalancerHourly * ca.Pricing().hoursPerMonth()
+	return &UnitCostEstimate{
+		UnitID:      unit.UnitID.String(),
+		UnitName:    unit.Slug,
+		Space:       ca.spaceID.String(),
+		Type:        LoadBalancerServiceType,
+		MonthlyCost: loadBalancerCost,
+		Breakdown:   CostBreakdown{LoadBalancerCost: loadBalancerCost},
+	}
+}
+
 // analyzeDeployment analyzes a Deployment unit
 func (ca *CostAnalyzer) analyzeDeployment(unit Unit, manifest map[string]interface{}) (*UnitCostEstimate, error) {
 	estimate := &UnitCostEstimate{
-		UnitID:   unit.UnitID.String(),
-		UnitName: unit.Slug,
-		Space:    ca.spaceID.String(),
-		Type:     "Deployment",
+		UnitID:    unit.UnitID.String(),
+		UnitName:  unit.Slug,
+		Space:     ca.spaceID.String(),
+		Type:      "Deployment",
+		NodeClass: ca.nodeClass(manifest),
 	}
 
 	// Extract replicas
@@ -322,7 +715,7 @@ func (ca *CostAnalyzer) analyzeDeployment(unit Unit, manifest map[string]interfa
 				if containers, ok := podSpec["containers"].([]interface{}); ok {
 					for _, container := range containers {
 						if c, ok := container.(map[string]interface{}); ok {
-							ca.extractContainerResources(c, estimate)
+							ca.extractContainerResources(c, manifestNamespace(manifest), estimate)
 						}
 					}
 				}
@@ -339,10 +732,11 @@ func (ca *CostAnalyzer) analyzeDeployment(unit Unit, manifest map[string]interfa
 // analyzeStatefulSet analyzes a StatefulSet unit
 func (ca *CostAnalyzer) analyzeStatefulSet(unit Unit, manifest map[string]interface{}) (*UnitCostEstimate, error) {
 	estimate := &UnitCostEstimate{
-		UnitID:   unit.UnitID.String(),
-		UnitName: unit.Slug,
-		Space:    ca.spaceID.String(),
-		Type:     "StatefulSet",
+		UnitID:    unit.UnitID.String(),
+		UnitName:  unit.Slug,
+		Space:     ca.spaceID.String(),
+		Type:      "StatefulSet",
+		NodeClass: ca.nodeClass(manifest),
 	}
 
 	// Similar to deployment but check for volumeClaimTemplates
@@ -368,7 +762,7 @@ func (ca *CostAnalyzer) analyzeStatefulSet(unit Unit, manifest map[string]interf
 				if containers, ok := podSpec["containers"].([]interface{}); ok {
 					for _, container := range containers {
 						if c, ok := container.(map[string]interface{}); ok {
-							ca.extractContainerResources(c, estimate)
+							ca.extractContainerResources(c, manifestNamespace(manifest), estimate)
 						}
 					}
 				}
@@ -383,11 +777,18 @@ func (ca *CostAnalyzer) analyzeStatefulSet(unit Unit, manifest map[string]interf
 // analyzeDaemonSet analyzes a DaemonSet unit
 func (ca *CostAnalyzer) analyzeDaemonSet(unit Unit, manifest map[string]interface{}) (*UnitCostEstimate, error) {
 	estimate := &UnitCostEstimate{
-		UnitID:   unit.UnitID.String(),
-		UnitName: unit.Slug,
-		Space:    ca.spaceID.String(),
-		Type:     "DaemonSet",
-		Replicas: 3, // Assume 3 nodes as default
+		UnitID:    unit.UnitID.String(),
+		UnitName:  unit.Slug,
+		Space:     ca.spaceID.String(),
+		Type:      "DaemonSet",
+		NodeClass: ca.nodeClass(manifest),
+		Replicas:  int32(ca.clusterNodeCount), // One pod per node
+	}
+	if ca.clusterNodeCount <= 0 {
+		estimate.Replicas = defaultDaemonSetNodes
+		estimate.Annotations = map[string]string{
+			AssumedNodeCountAnnotation: strconv.Itoa(defaultDaemonSetNodes),
+		}
 	}
 
 	// Extract container resources
@@ -397,7 +798,7 @@ func (ca *CostAnalyzer) analyzeDaemonSet(unit Unit, manifest map[string]interfac
 				if containers, ok := podSpec["containers"].([]interface{}); ok {
 					for _, container := range containers {
 						if c, ok := container.(map[string]interface{}); ok {
-							ca.extractContainerResources(c, estimate)
+							ca.extractContainerResources(c, manifestNamespace(manifest), estimate)
 						}
 					}
 				}
@@ -409,31 +810,317 @@ func (ca *CostAnalyzer) analyzeDaemonSet(unit Unit, manifest map[string]interfac
 	return estimate, nil
 }
 
-// extractContainerResources extracts CPU/memory from container spec
-func (ca *CostAnalyzer) extractContainerResources(container map[string]interface{}, estimate *UnitCostEstimate) {
-	if resources, ok := container["resources"].(map[string]interface{}); ok {
-		// Check requests first (what we're guaranteed)
-		if requests, ok := resources["requests"].(map[string]interface{}); ok {
-			if cpu, ok := requests["cpu"].(string); ok {
-				quantity := ParseQuantity(cpu)
-				estimate.CPU.Add(quantity)
+// analyzeJob analyzes a one-off Job unit, priced as a single run per month
+func (ca *CostAnalyzer) analyzeJob(unit Unit, manifest map[string]interface{}) (*UnitCostEstimate, error) {
+	estimate := &UnitCostEstimate{
+		UnitID:    unit.UnitID.String(),
+		UnitName:  unit.Slug,
+		Space:     ca.spaceID.String(),
+		Type:      "Job",
+		NodeClass: ca.nodeClass(manifest),
+	}
+
+	jobSpec, _ := manifest["spec"].(map[string]interface{})
+	ca.priceJob(unit, manifest, jobSpec, 1, estimate)
+	return estimate, nil
+}
+
+// analyzeCronJob analyzes a CronJob unit, multiplying the per-run cost by the
+// number of runs its schedule produces in a month
+func (ca *CostAnalyzer) analyzeCronJob(unit Unit, manifest map[string]interface{}) (*UnitCostEstimate, error) {
+	estimate := &UnitCostEstimate{
+		UnitID:    unit.UnitID.String(),
+		UnitName:  unit.Slug,
+		Space:     ca.spaceID.String(),
+		Type:      "CronJob",
+		NodeClass: ca.nodeClass(manifest),
+	}
+
+	spec, _ := manifest["spec"].(map[string]interface{})
+	schedule, _ := spec["schedule"].(string)
+	runsPerMonth, err := cronRunsPerMonth(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %q: %v", schedule, err)
+	}
+	if suspended, _ := spec["suspend"].(bool); suspended {
+		runsPerMonth = 0
+	}
+
+	var jobSpec map[string]interface{}
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		jobSpec, _ = jobTemplate["spec"].(map[string]interface{})
+	}
+	ca.priceJob(unit, manifest, jobSpec, runsPerMonth, estimate)
+	return estimate, nil
+}
+
+// priceJob prices a Job spec as completions x parallelism pods running for the
+// job's duration, runsPerMonth times. The duration comes from
+// RunDurationAnnotation, falling back to activeDeadlineSeconds; without either
+// the estimate is flagged with MissingDurationAnnotation and costs nothing.
+func (ca *CostAnalyzer) priceJob(unit Unit, manifest, jobSpec map[string]interface{}, runsPerMonth float64, estimate *UnitCostEstimate) {
+	estimate.Replicas = int32(manifestInt(jobSpec, "completions", 1) * manifestInt(jobSpec, "parallelism", 1))
+
+	if template, ok := jobSpec["template"].(map[string]interface{}); ok {
+		if podSpec, ok := template["spec"].(map[string]interface{}); ok {
+			if containers, ok := podSpec["containers"].([]interface{}); ok {
+				for _, container := range containers {
+					if c, ok := container.(map[string]interface{}); ok {
+						ca.extractContainerResources(c, manifestNamespace(manifest), estimate)
+					}
+				}
+			}
+		}
+	}
+
+	runHours := jobRunDuration(unit, manifest, jobSpec).Hours()
+	if runHours <= 0 {
+		if estimate.Annotations == nil {
+			estimate.Annotations = make(map[string]string)
+		}
+		estimate.Annotations[MissingDurationAnnotation] = "true"
+	}
+
+	// calculateMonthlyCost assumes pods run all month; scale to the hours they
+	// actually run. Storage isn't time-based, but Jobs don't claim volumes.
+	estimate.RunFraction = runHours * runsPerMonth / ca.pricingFor(estimate).hoursPerMonth()
+	scaleToRunFraction(estimate, ca.calculateMonthlyCost(estimate))
+}
+
+// scaleToRunFraction scales a full-month cost and its breakdown down to the
+// share of the month a Job's pods run
+func scaleToRunFraction(estimate *UnitCostEstimate, monthlyCost float64) {
+	fraction := estimate.RunFraction
+	estimate.Breakdown.CPUCost *= fraction
+	estimate.Breakdown.MemoryCost *= fraction
+	estimate.Breakdown.StorageCost *= fraction
+	estimate.Breakdown.CommittedCost *= fraction
+	estimate.Breakdown.OnDemandCost *= fraction
+	estimate.MonthlyCost = monthlyCost * fraction
+}
+
+// jobRunDuration returns how long one run of a Job takes, preferring the
+// historical run time over the activeDeadlineSeconds upper bound
+func jobRunDuration(unit Unit, manifest, jobSpec map[string]interface{}) time.Duration {
+	value := unit.Annotations[RunDurationAnnotation]
+	if value == "" {
+		if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				value, _ = annotations[RunDurationAnnotation].(string)
+			}
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return duration
+	}
+
+	return time.Duration(manifestInt(jobSpec, "activeDeadlineSeconds", 0)) * time.Second
+}
+
+// manifestInt reads an integer field from a parsed manifest, or def if unset
+func manifestInt(fields map[string]interface{}, key string, def int) int {
+	if value, ok := fields[key].(int); ok && value > 0 {
+		return value
+	}
+	return def
+}
+
+// cronMacros maps the predefined schedules to their five-field equivalents
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronRunsPerMonth returns how many times a cron schedule fires in an average
+// month, counted over a full (non-leap) year so that month and day-of-week
+// restrictions average out
+func cronRunsPerMonth(schedule string) (float64, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		fields = fields[1:] // The time zone doesn't change the run count
+	}
+	if len(fields) == 2 && fields[0] == "@every" {
+		interval, err := time.ParseDuration(fields[1])
+		if err != nil || interval <= 0 {
+			return 0, fmt.Errorf("invalid interval %q", fields[1])
+		}
+		return DefaultHoursPerMonth / interval.Hours(), nil
+	}
+	if len(fields) == 1 {
+		macro, ok := cronMacros[fields[0]]
+		if !ok {
+			return 0, fmt.Errorf("unknown schedule %q", fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return 0, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+
+	minutes, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return 0, fmt.Errorf("minute: %v", err)
+	}
+	hours, err := parseCronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return 0, fmt.Errorf("hour: %v", err)
+	}
+	daysOfMonth, err := parseCronField(fields[2], 1, 31, nil)
+	if err != nil {
+		return 0, fmt.Errorf("day of month: %v", err)
+	}
+	months, err := parseCronField(fields[3], 1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"})
+	if err != nil {
+		return 0, fmt.Errorf("month: %v", err)
+	}
+	daysOfWeek, err := parseCronField(fields[4], 0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"})
+	if err != nil {
+		return 0, fmt.Errorf("day of week: %v", err)
+	}
+	daysOfWeek[0] = daysOfWeek[0] || daysOfWeek[7] // 7 is also Sunday
+
+	// Like cron, a day matches either field when both are restricted
+	domRestricted := !strings.HasPrefix(fields[2], "*") && fields[2] != "?"
+	dowRestricted := !strings.HasPrefix(fields[4], "*") && fields[4] != "?"
+
+	runsPerDay := countTrue(minutes) * countTrue(hours)
+	runs := 0
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for day := start; day.Year() == start.Year(); day = day.AddDate(0, 0, 1) {
+		if !months[int(day.Month())] {
+			continue
+		}
+		domMatch, dowMatch := daysOfMonth[day.Day()], daysOfWeek[int(day.Weekday())]
+		if (domRestricted && dowRestricted && (domMatch || dowMatch)) ||
+			(!(domRestricted && dowRestricted) && domMatch && dowMatch) {
+			runs += runsPerDay
+		}
+	}
+
+	return float64(runs) / 12, nil
+}
+
+// parseCronField parses one cron field (lists, ranges, steps and names) into
+// a set indexed by value
+func parseCronField(field string, min, max int, names []string) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
 			}
-			if memory, ok := requests["memory"].(string); ok {
-				quantity := ParseQuantity(memory)
-				estimate.Memory.Add(quantity)
+		}
+
+		low, high := min, max
+		if rangePart != "*" && rangePart != "?" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = cronValue(lowPart, min, max, names); err != nil {
+				return nil, err
 			}
-		} else if limits, ok := resources["limits"].(map[string]interface{}); ok {
-			// Fall back to limits if no requests
-			if cpu, ok := limits["cpu"].(string); ok {
-				quantity := ParseQuantity(cpu)
-				estimate.CPU.Add(quantity)
+			high = low
+			if isRange {
+				if high, err = cronValue(highPart, min, max, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				high = max // "5/15" means from 5 to the end
 			}
-			if memory, ok := limits["memory"].(string); ok {
-				quantity := ParseQuantity(memory)
-				estimate.Memory.Add(quantity)
+			if high < low {
+				return nil, fmt.Errorf("invalid range %q", rangePart)
 			}
 		}
+
+		for value := low; value <= high; value += step {
+			set[value] = true
+		}
+	}
+	return set, nil
+}
+
+// cronValue parses a single cron value, accepting names for months and weekdays
+func cronValue(value string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(value, name) {
+			return min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+// countTrue counts the members of a parsed cron field
+func countTrue(set []bool) int {
+	count := 0
+	for _, ok := range set {
+		if ok {
+			count++
+		}
 	}
+	return count
+}
+
+// extractContainerResources extracts CPU/memory from container spec, falling back
+// to the namespace's LimitRange default and then to limits when requests are missing
+func (ca *CostAnalyzer) extractContainerResources(container map[string]interface{}, namespace string, estimate *UnitCostEstimate) {
+	var requests, limits map[string]interface{}
+	if resources, ok := container["resources"].(map[string]interface{}); ok {
+		requests, _ = resources["requests"].(map[string]interface{})
+		limits, _ = resources["limits"].(map[string]interface{})
+	}
+
+	defaults, hasDefaults := ca.limitRangeDefaults[namespace]
+
+	// CPU: requests (what we're guaranteed), then namespace default, then limits
+	if cpu, ok := requests["cpu"].(string); ok {
+		estimate.CPU.Add(ParseQuantity(cpu))
+	} else if hasDefaults && defaults.CPU.MilliValue() > 0 {
+		estimate.CPU.Add(defaults.CPU)
+	} else if cpu, ok := limits["cpu"].(string); ok {
+		estimate.CPU.Add(ParseQuantity(cpu))
+	} else {
+		ca.flagMissingRequests(estimate)
+	}
+
+	// Memory: same precedence as CPU
+	if memory, ok := requests["memory"].(string); ok {
+		estimate.Memory.Add(ParseQuantity(memory))
+	} else if hasDefaults && defaults.Memory.BytesValue() > 0 {
+		estimate.Memory.Add(defaults.Memory)
+	} else if memory, ok := limits["memory"].(string); ok {
+		estimate.Memory.Add(ParseQuantity(memory))
+	} else {
+		ca.flagMissingRequests(estimate)
+	}
+}
+
+// flagMissingRequests marks an estimate as undercounted instead of silently dropping cost
+func (ca *CostAnalyzer) flagMissingRequests(estimate *UnitCostEstimate) {
+	if estimate.Annotations == nil {
+		estimate.Annotations = make(map[string]string)
+	}
+	estimate.Annotations[MissingRequestsAnnotation] = "true"
+}
+
+// manifestNamespace returns the manifest's namespace, or "default" if unset
+func manifestNamespace(manifest map[string]interface{}) string {
+	if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+		if namespace, ok := metadata["namespace"].(string); ok && namespace != "" {
+			return namespace
+		}
+	}
+	return "default"
 }
 
 // extractStorageResources extracts storage from PVC templates
@@ -450,6 +1137,77 @@ func (ca *CostAnalyzer) extractStorageResources(vct map[string]interface{}, esti
 	}
 }
 
+// nodeClass returns the priced node class a workload's pods are pinned to by
+// nodeSelector, required node affinity, or tolerations, or "" if none
+func (ca *CostAnalyzer) nodeClass(manifest map[string]interface{}) string {
+	if ca.nodeClassLabel == "" || len(ca.nodeClassPricing) == 0 {
+		return ""
+	}
+	podSpec := manifestPodSpec(manifest)
+	priced := func(value interface{}) bool {
+		class, ok := value.(string)
+		return ok && ca.nodeClassPricing[class] != nil
+	}
+
+	if selector, ok := podSpec["nodeSelector"].(map[string]interface{}); ok && priced(selector[ca.nodeClassLabel]) {
+		return selector[ca.nodeClassLabel].(string)
+	}
+
+	// The first priced value of a required "In" expression on the label
+	affinity, _, _ := unstructured.NestedFieldNoCopy(podSpec, "affinity", "nodeAffinity", "requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+	terms, _ := affinity.([]interface{})
+	for _, term := range terms {
+		termMap, _ := term.(map[string]interface{})
+		expressions, _ := termMap["matchExpressions"].([]interface{})
+		for _, expression := range expressions {
+			expr, _ := expression.(map[string]interface{})
+			if expr["key"] != ca.nodeClassLabel || expr["operator"] != "In" {
+				continue
+			}
+			values, _ := expr["values"].([]interface{})
+			for _, value := range values {
+				if priced(value) {
+					return value.(string)
+				}
+			}
+		}
+	}
+
+	// Dedicated pools are usually tainted with the same key as their label
+	tolerations, _ := podSpec["tolerations"].([]interface{})
+	for _, toleration := range tolerations {
+		tol, _ := toleration.(map[string]interface{})
+		if tol["key"] == ca.nodeClassLabel && priced(tol["value"]) {
+			return tol["value"].(string)
+		}
+	}
+	return ""
+}
+
+// manifestPodSpec returns a workload manifest's pod spec, including a
+// CronJob's job template
+func manifestPodSpec(manifest map[string]interface{}) map[string]interface{} {
+	path := []string{"spec", "template", "spec"}
+	if kind, _ := manifest["kind"].(string); kind == "CronJob" {
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+	podSpec, _, _ := unstructured.NestedFieldNoCopy(manifest, path...)
+	spec, _ := podSpec.(map[string]interface{})
+	return spec
+}
+
+// pricingFor returns the pricing model of the node pool an estimate's pods
+// run on
+func (ca *CostAnalyzer) pricingFor(estimate *UnitCostEstimate) *PricingModel {
+	if ca.pricing == nil {
+		ca.pricing = DefaultPricing
+	}
+	if classPricing, ok := ca.nodeClassPricing[estimate.NodeClass]; ok && classPricing != nil {
+		return classPricing
+	}
+	return ca.pricing
+}
+
 // calculateMonthlyCost calculates the monthly cost for a unit with bounds checking
 func (ca *CostAnalyzer) calculateMonthlyCost(estimate *UnitCostEstimate) float64 {
 	// Validate inputs
@@ -459,16 +1217,19 @@ func (ca *CostAnalyzer) calculateMonthlyCost(estimate *UnitCostEstimate) float64
 	if estimate.Replicas < 0 {
 		estimate.Replicas = 0
 	}
-	if ca.pricing == nil {
-		ca.pricing = DefaultPricing
-	}
+	pricing := ca.pricingFor(estimate)
 
 	// Validate pricing model
-	if ca.pricing.CPUHourly < 0 || ca.pricing.MemoryHourly < 0 || ca.pricing.StorageGB < 0 {
+	if pricing.CPUHourly < 0 || pricing.MemoryHourly < 0 || pricing.StorageGB < 0 {
 		return 0.0 // Invalid pricing
 	}
 
-	hoursPerMonth := 24.0 * 30.0
+	// Pods are billed only while up; storage is billed either way
+	hoursPerMonth := pricing.hoursPerMonth()
+	uptime := estimate.UptimeFraction
+	if uptime <= 0 || uptime > 1 {
+		uptime = 1
+	}
 	replicas := float64(estimate.Replicas)
 
 	// CPU cost (convert millicores to cores) with bounds checking
@@ -476,7 +1237,7 @@ func (ca *CostAnalyzer) calculateMonthlyCost(estimate *UnitCostEstimate) float64
 	if cpuCores < 0 {
 		cpuCores = 0
 	}
-	cpuCost := cpuCores * ca.pricing.CPUHourly * hoursPerMonth * replicas
+	cpuCost := cpuCores * pricing.CPUHourly * hoursPerMonth * uptime * replicas
 	if math.IsNaN(cpuCost) || math.IsInf(cpuCost, 0) {
 		cpuCost = 0
 	}
@@ -487,7 +1248,7 @@ func (ca *CostAnalyzer) calculateMonthlyCost(estimate *UnitCostEstimate) float64
 		memoryBytes = 0
 	}
 	memoryGB := memoryBytes / (1024 * 1024 * 1024)
-	memoryCost := memoryGB * ca.pricing.MemoryHourly * hoursPerMonth * replicas
+	memoryCost := memoryGB * pricing.MemoryHourly * hoursPerMonth * uptime * replicas
 	if math.IsNaN(memoryCost) || math.IsInf(memoryCost, 0) {
 		memoryCost = 0
 	}
@@ -498,16 +1259,27 @@ func (ca *CostAnalyzer) calculateMonthlyCost(estimate *UnitCostEstimate) float64
 		storageBytes = 0
 	}
 	storageGB := storageBytes / (1024 * 1024 * 1024)
-	storageCost := storageGB * ca.pricing.StorageGB * replicas
+	storageCost := storageGB * pricing.StorageGB * replicas
 	if math.IsNaN(storageCost) || math.IsInf(storageCost, 0) {
 		storageCost = 0
 	}
 
+	// Blend committed and on-demand rates for CPU and memory
+	coverage := math.Max(0, math.Min(pricing.CommitmentCoverage, 1))
+	discount := math.Max(0, math.Min(pricing.CommitmentDiscount, 1))
+	onDemandCost := (cpuCost + memoryCost) * (1 - coverage)
+	committedCost := (cpuCost + memoryCost) * coverage * (1 - discount)
+	blended := 1 - coverage*discount
+	cpuCost *= blended
+	memoryCost *= blended
+
 	// Set breakdown
 	estimate.Breakdown = CostBreakdown{
-		CPUCost:     cpuCost,
-		MemoryCost:  memoryCost,
-		StorageCost: storageCost,
+		CPUCost:       cpuCost,
+		MemoryCost:    memoryCost,
+		StorageCost:   storageCost,
+		CommittedCost: committedCost,
+		OnDemandCost:  onDemandCost,
 	}
 
 	totalCost := cpuCost + memoryCost + storageCost
@@ -520,7 +1292,71 @@ func (ca *CostAnalyzer) calculateMonthlyCost(estimate *UnitCostEstimate) float64
 	return totalCost
 }
 
-// AnalyzeHierarchy analyzes a full environment hierarchy
+// NetworkCost estimates monthly egress cost from measured network bytes,
+// normalized from the usage time range to the pricing model's HoursPerMonth.
+// Egress can't be inferred from manifests, so it is zero unless real metrics
+// are supplied.
+func (ca *CostAnalyzer) NetworkCost(usage ActualUsageMetrics) float64 {
+	window := usage.TimeRangeEnd.Sub(usage.TimeRangeStart)
+	if usage.NetworkBytesTotal <= 0 || window <= 0 {
+		return 0
+	}
+	if ca.pricing == nil {
+		ca.pricing = DefaultPricing
+	}
+
+	egressGB := float64(usage.NetworkBytesTotal) / (1024 * 1024 * 1024)
+	monthlyGB := egressGB * ca.pricing.hoursPerMonth() / window.Hours()
+	return math.Max(monthlyGB*ca.pricing.EgressGBCost, 0)
+}
+
+// ApplyNetworkCost adds measured egress cost to an estimate's breakdown and total
+func (ca *CostAnalyzer) ApplyNetworkCost(estimate *UnitCostEstimate, usage ActualUsageMetrics) {
+	networkCost := ca.NetworkCost(usage)
+	estimate.Breakdown.NetworkCost = networkCost
+	estimate.MonthlyCost += networkCost
+}
+
+// ApplyUptime prices an estimate's CPU and memory for the share of the month
+// its pods were up, from usage.UptimePercent, e.g. for spot or preemptible
+// workloads. Storage is billed whether pods run or not and isn't scaled.
+// Jobs and CronJobs are already priced for their run time and are unchanged.
+func (ca *CostAnalyzer) ApplyUptime(estimate *UnitCostEstimate, usage ActualUsageMetrics) {
+	if usage.UptimePercent <= 0 {
+		return // No uptime measured
+	}
+	switch estimate.Type {
+	case "Job", "CronJob", LoadBalancerServiceType:
+		return
+	}
+	estimate.UptimeFraction = math.Min(usage.UptimePercent/100, 1)
+	networkCost := estimate.Breakdown.NetworkCost
+
+	if len(estimate.Workloads) > 0 {
+		workloads := make([]*UnitCostEstimate, len(estimate.Workloads))
+		for i := range estimate.Workloads {
+			workload := estimate.Workloads[i]
+			ca.ApplyUptime(&workload, usage)
+			workloads[i] = &workload
+		}
+		total := aggregateWorkloadEstimates(workloads)
+		total.Annotations = estimate.Annotations
+		total.Labels = estimate.Labels
+		total.UptimeFraction = estimate.UptimeFraction
+		*estimate = *total
+		estimate.MonthlyCost += networkCost - estimate.Breakdown.NetworkCost
+		estimate.Breakdown.NetworkCost = networkCost
+		return
+	}
+
+	estimate.MonthlyCost = ca.calculateMonthlyCost(estimate) + networkCost
+	estimate.Breakdown.NetworkCost = networkCost
+}
+
+// AnalyzeHierarchy analyzes the base space and its downstream environment
+// spaces concurrently. Environments are the spaces set with SetEnvironments,
+// or else the "<base>-*" spaces labeled with EnvironmentLabel, falling back to
+// <base>-dev, <base>-staging and <base>-prod.
 func (ca *CostAnalyzer) AnalyzeHierarchy(baseSpaceSlug string) (*SpaceCostAnalysis, error) {
 	ca.app.Logger.Printf("🔍 Analyzing ConfigHub hierarchy starting from: %s", baseSpaceSlug)
 
@@ -530,28 +1366,405 @@ func (ca *CostAnalyzer) AnalyzeHierarchy(baseSpaceSlug string) (*SpaceCostAnalys
 		return nil, err
 	}
 
-	// Find downstream spaces (dev, staging, prod) by slug patterns
-	environments := []string{"dev", "staging", "prod"}
-
-	for _, env := range environments {
-		envSpaceSlug := fmt.Sprintf("%s-%s", baseSpaceSlug, env)
-
-		// Try to find the space by slug
-		envSpace, err := ca.app.Cub.GetSpaceBySlug(envSpaceSlug)
+	spaces, err := ca.app.Cub.ListSpaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spaces: %v", err)
+	}
+	var downstream []*Space
+	if ca.environments == nil {
+		downstream, err = ca.app.Cub.ListDownstreamSpaces(ca.spaceID)
 		if err != nil {
-			continue // Space doesn't exist
+			ca.app.Logger.Printf("⚠️  Could not follow upstream links from %s: %v", baseSpaceSlug, err)
 		}
+	}
+	environments := ca.hierarchyEnvironments(baseSpaceSlug, spaces, downstream)
 
-		// Check if space exists
-		envAnalyzer := NewCostAnalyzer(ca.app, envSpace.SpaceID)
-		if envAnalysis, err := envAnalyzer.AnalyzeSpace(); err == nil {
+	workers := ca.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for env, space := range environments {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(env string, space *Space) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Environments share this analyzer's pricing and settings, but
+			// the budgets and their alert were set for the base space only
+			envAnalyzer := *ca
+			envAnalyzer.spaceID = space.SpaceID
+			envAnalyzer.budget = 0
+			envAnalyzer.budgetLabel = ""
+			envAnalyzer.labelBudgets = nil
+			envAnalyzer.budgetAlerts = nil
+			envAnalysis, err := envAnalyzer.AnalyzeSpace()
+			if err != nil {
+				ca.app.Logger.Printf("⚠️  Could not analyze environment %s (%s): %v", env, space.Slug, err)
+				return
+			}
+			envAnalysis.SpaceName = space.Slug
+
+			mu.Lock()
 			baseAnalysis.Environments[env] = envAnalysis
-		}
+			mu.Unlock()
+		}(env, space)
 	}
+	wg.Wait()
 
 	return baseAnalysis, nil
 }
 
+// hierarchyEnvironments maps environment names to the downstream spaces of
+// baseSpaceSlug. Configured names win, then spaces linked by upstream units,
+// then labeled <base>-* spaces, then the default environment slugs.
+func (ca *CostAnalyzer) hierarchyEnvironments(baseSpaceSlug string, spaces, downstream []*Space) map[string]*Space {
+	prefix := baseSpaceSlug + "-"
+	if ca.environments == nil && len(downstream) > 0 {
+		linked := make(map[string]*Space, len(downstream))
+		for _, space := range downstream {
+			env := space.Labels[EnvironmentLabel]
+			if env == "" {
+				env = strings.TrimPrefix(space.Slug, prefix)
+			}
+			linked[env] = space
+		}
+		return linked
+	}
+
+	bySlug := make(map[string]*Space)
+	environments := make(map[string]*Space)
+	for _, space := range spaces {
+		if space == nil {
+			continue
+		}
+		bySlug[space.Slug] = space
+		if env := space.Labels[EnvironmentLabel]; env != "" && strings.HasPrefix(space.Slug, prefix) {
+			environments[env] = space
+		}
+	}
+
+	names := ca.environments
+	if names == nil {
+		if len(environments) > 0 {
+			return environments
+		}
+		names = defaultEnvironments
+	}
+
+	environments = make(map[string]*Space)
+	for _, env := range names {
+		if space, ok := bySlug[prefix+env]; ok {
+			environments[env] = space
+		}
+	}
+	return environments
+}
+
+// BudgetState says how a cost compares with its budget
+type BudgetState string
+
+const (
+	BudgetUnder BudgetState = "under"
+	BudgetNear  BudgetState = "near" // At least BudgetNearThreshold of the budget
+	BudgetOver  BudgetState = "over"
+)
+
+// BudgetNearThreshold is the fraction of a budget at which it is reported as near
+const BudgetNearThreshold = 0.8
+
+// BudgetStatus compares an estimated monthly cost with a budget
+type BudgetStatus struct {
+	Scope            string // "space", or "<label>=<value>" for label budgets
+	State            BudgetState
+	MonthlyBudget    float64
+	MonthlyCost      float64
+	PercentConsumed  float64
+	ProjectedOverage float64 // Monthly cost above the budget; 0 when within it
+}
+
+// BudgetExceededError is returned by CheckBudget when any budget is over
+type BudgetExceededError struct {
+	Over []*BudgetStatus
+}
+
+func (e *BudgetExceededError) Error() string {
+	scopes := make([]string, len(e.Over))
+	for i, status := range e.Over {
+		scopes[i] = fmt.Sprintf("%s $%.2f/$%.2f (%.0f%%)", status.Scope, status.MonthlyCost, status.MonthlyBudget, status.PercentConsumed)
+	}
+	return fmt.Sprintf("over budget: %s", strings.Join(scopes, ", "))
+}
+
+// SetBudget sets the space's monthly budget in USD; AnalyzeSpace reports the
+// result in SpaceCostAnalysis.Budget. Zero removes it.
+func (ca *CostAnalyzer) SetBudget(monthlyUSD float64) {
+	ca.budget = monthlyUSD
+}
+
+// SetLabelBudgets sets monthly USD budgets per value of a unit label, e.g.
+// SetLabelBudgets("team", map[string]float64{"payments": 500}). Units are
+// grouped as in GroupCostBy and reported in SpaceCostAnalysis.LabelBudgets.
+func (ca *CostAnalyzer) SetLabelBudgets(labelKey string, budgets map[string]float64) {
+	ca.budgetLabel = labelKey
+	ca.labelBudgets = budgets
+}
+
+// SetBudgetAlert sends an alert to notifier whenever AnalyzeSpace finds the
+// space or a label over budget. nil turns alerts off.
+func (ca *CostAnalyzer) SetBudgetAlert(notifier Notifier) {
+	ca.budgetAlerts = notifier
+}
+
+// CheckBudget analyzes the space and returns a *BudgetExceededError listing
+// every budget it is over, or nil when all are within budget
+func (ca *CostAnalyzer) CheckBudget() error {
+	analysis, err := ca.AnalyzeSpace()
+	if err != nil {
+		return err
+	}
+
+	if over := overBudget(analysis); len(over) > 0 {
+		return &BudgetExceededError{Over: over}
+	}
+	return nil
+}
+
+// overBudget returns the analysis's over-budget statuses, space first
+func overBudget(analysis *SpaceCostAnalysis) []*BudgetStatus {
+	var over []*BudgetStatus
+	if analysis.Budget != nil && analysis.Budget.State == BudgetOver {
+		over = append(over, analysis.Budget)
+	}
+	for _, value := range sortedBudgetKeys(analysis.LabelBudgets) {
+		if status := analysis.LabelBudgets[value]; status.State == BudgetOver {
+			over = append(over, status)
+		}
+	}
+	return over
+}
+
+// applyBudgets fills in the analysis's budget statuses from the analyzer's budgets
+func (ca *CostAnalyzer) applyBudgets(analysis *SpaceCostAnalysis) {
+	if ca.budget > 0 {
+		analysis.Budget = newBudgetStatus("space", ca.budget, analysis.TotalMonthlyCost)
+		if analysis.Budget.State != BudgetUnder {
+			ca.app.Logger.Printf("⚠️  Space is %s budget: $%.2f of $%.2f/month (%.0f%%)",
+				analysis.Budget.State, analysis.TotalMonthlyCost, ca.budget, analysis.Budget.PercentConsumed)
+		}
+	}
+
+	if ca.budgetLabel == "" || len(ca.labelBudgets) == 0 {
+		return
+	}
+	groups := GroupCostBy(analysis, ca.budgetLabel)
+	analysis.LabelBudgets = make(map[string]*BudgetStatus, len(ca.labelBudgets))
+	for value, budget := range ca.labelBudgets {
+		if budget <= 0 {
+			continue
+		}
+		var cost float64
+		if group, ok := groups[value]; ok {
+			cost = group.TotalMonthlyCost
+		}
+		analysis.LabelBudgets[value] = newBudgetStatus(fmt.Sprintf("%s=%s", ca.budgetLabel, value), budget, cost)
+	}
+}
+
+// notifyOverBudget sends the budget alert when the analysis is over any budget
+func (ca *CostAnalyzer) notifyOverBudget(ctx context.Context, analysis *SpaceCostAnalysis) {
+	if ca.budgetAlerts == nil {
+		return
+	}
+	over := overBudget(analysis)
+	if len(over) == 0 {
+		return
+	}
+	if err := ca.budgetAlerts.Notify(ctx, budgetAlert(ca.app.Cub, ca.spaceID, analysis, over)); err != nil {
+		ca.app.Logger.Printf("⚠️  Could not send budget alert: %v", err)
+	}
+}
+
+// newBudgetStatus compares a monthly cost with a positive monthly budget
+func newBudgetStatus(scope string, budget, cost float64) *BudgetStatus {
+	status := &BudgetStatus{
+		Scope:           scope,
+		State:           BudgetUnder,
+		MonthlyBudget:   budget,
+		MonthlyCost:     cost,
+		PercentConsumed: cost / budget * 100,
+	}
+	switch {
+	case cost > budget:
+		status.State = BudgetOver
+		status.ProjectedOverage = cost - budget
+	case cost >= budget*BudgetNearThreshold:
+		status.State = BudgetNear
+	}
+	return status
+}
+
+func sortedBudgetKeys(statuses map[string]*BudgetStatus) []string {
+	keys := make([]string, 0, len(statuses))
+	for k := range statuses {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UnlabeledCostGroup collects units that don't carry the grouping label
+const UnlabeledCostGroup = "(unlabeled)"
+
+// CostGroup totals the cost of units sharing one label value
+type CostGroup struct {
+	Value            string // Label value, or UnlabeledCostGroup
+	UnitCount        int
+	TotalMonthlyCost float64
+	Breakdown        CostBreakdown
+}
+
+// GroupCostBy slices an analysis by a unit label such as team or cost-center,
+// returning one group per distinct value plus UnlabeledCostGroup for the rest
+func GroupCostBy(analysis *SpaceCostAnalysis, labelKey string) map[string]*CostGroup {
+	groups := make(map[string]*CostGroup)
+	if analysis == nil {
+		return groups
+	}
+
+	for _, unit := range analysis.Units {
+		value := unit.Labels[labelKey]
+		if value == "" {
+			value = UnlabeledCostGroup
+		}
+
+		group, ok := groups[value]
+		if !ok {
+			group = &CostGroup{Value: value}
+			groups[value] = group
+		}
+		group.UnitCount++
+		group.TotalMonthlyCost += unit.MonthlyCost
+		group.Breakdown.CPUCost += unit.Breakdown.CPUCost
+		group.Breakdown.MemoryCost += unit.Breakdown.MemoryCost
+		group.Breakdown.StorageCost += unit.Breakdown.StorageCost
+		group.Breakdown.NetworkCost += unit.Breakdown.NetworkCost
+		group.Breakdown.LoadBalancerCost += unit.Breakdown.LoadBalancerCost
+		group.Breakdown.CommittedCost += unit.Breakdown.CommittedCost
+		group.Breakdown.OnDemandCost += unit.Breakdown.OnDemandCost
+	}
+
+	return groups
+}
+
+// RepricingSummary compares a repriced analysis with the original
+type RepricingSummary struct {
+	OriginalMonthlyCost float64
+	RepricedMonthlyCost float64
+	Delta               float64            // Repriced minus original; negative is a saving
+	DeltaPercent        float64            // Delta as a percentage of the original; 0 when the original is 0
+	UnitDeltas          map[string]float64 // Per-unit delta keyed by unit name
+}
+
+// RepriceAnalysis recomputes every unit's cost from the CPU, memory, storage
+// and replica counts already in analysis using newPricing, e.g. to see the
+// effect of renegotiated rates. It makes no API calls and leaves analysis
+// untouched. Units pinned to a node class and measured network cost keep
+// their original prices, since newPricing describes the default pool and the
+// egress volume isn't retained. Budgets are re-evaluated against the new
+// costs and Repricing holds the delta.
+func RepriceAnalysis(analysis *SpaceCostAnalysis, newPricing *PricingModel) *SpaceCostAnalysis {
+	if analysis == nil {
+		return nil
+	}
+	if newPricing == nil {
+		newPricing = DefaultPricing
+	}
+	ca := &CostAnalyzer{pricing: newPricing}
+
+	repriced := *analysis
+	repriced.Units = make([]UnitCostEstimate, len(analysis.Units))
+	repriced.TotalMonthlyCost = 0
+	summary := &RepricingSummary{
+		OriginalMonthlyCost: analysis.TotalMonthlyCost,
+		UnitDeltas:          make(map[string]float64, len(analysis.Units)),
+	}
+	for i, unit := range analysis.Units {
+		repriced.Units[i] = ca.repriceUnit(unit)
+		repriced.TotalMonthlyCost += repriced.Units[i].MonthlyCost
+		summary.UnitDeltas[unit.UnitName] += repriced.Units[i].MonthlyCost - unit.MonthlyCost
+	}
+	summary.RepricedMonthlyCost = repriced.TotalMonthlyCost
+	summary.Delta = summary.RepricedMonthlyCost - summary.OriginalMonthlyCost
+	if summary.OriginalMonthlyCost > 0 {
+		summary.DeltaPercent = summary.Delta / summary.OriginalMonthlyCost * 100
+	}
+	repriced.Repricing = summary
+
+	if analysis.Environments != nil {
+		repriced.Environments = make(map[string]*SpaceCostAnalysis, len(analysis.Environments))
+		for env, envAnalysis := range analysis.Environments {
+			repriced.Environments[env] = RepriceAnalysis(envAnalysis, newPricing)
+		}
+	}
+
+	if analysis.Budget != nil {
+		repriced.Budget = newBudgetStatus(analysis.Budget.Scope, analysis.Budget.MonthlyBudget, repriced.TotalMonthlyCost)
+	}
+	if analysis.LabelBudgets != nil {
+		repriced.LabelBudgets = make(map[string]*BudgetStatus, len(analysis.LabelBudgets))
+		for value, status := range analysis.LabelBudgets {
+			labelKey, _, _ := strings.Cut(status.Scope, "=")
+			var cost float64
+			if group, ok := GroupCostBy(&repriced, labelKey)[value]; ok {
+				cost = group.TotalMonthlyCost
+			}
+			repriced.LabelBudgets[value] = newBudgetStatus(status.Scope, status.MonthlyBudget, cost)
+		}
+	}
+	return &repriced
+}
+
+// repriceUnit recomputes one estimate with the analyzer's pricing, keeping
+// its quantities, annotations and labels
+func (ca *CostAnalyzer) repriceUnit(unit UnitCostEstimate) UnitCostEstimate {
+	if len(unit.Workloads) > 0 {
+		workloads := make([]*UnitCostEstimate, len(unit.Workloads))
+		for i, workload := range unit.Workloads {
+			repriced := ca.repriceUnit(workload)
+			workloads[i] = &repriced
+		}
+		total := aggregateWorkloadEstimates(workloads)
+		total.Annotations = unit.Annotations
+		total.Labels = unit.Labels
+		return *total
+	}
+
+	if unit.NodeClass != "" {
+		return unit
+	}
+	networkCost := unit.Breakdown.NetworkCost
+
+	switch unit.Type {
+	case LoadBalancerServiceType:
+		loadBalancerCost := ca.pricing.LoadBalancerHourly * ca.pricing.hoursPerMonth()
+		unit.MonthlyCost = loadBalancerCost
+		unit.Breakdown = CostBreakdown{LoadBalancerCost: loadBalancerCost}
+	case "Job", "CronJob":
+		scaleToRunFraction(&unit, ca.calculateMonthlyCost(&unit))
+	default:
+		unit.MonthlyCost = ca.calculateMonthlyCost(&unit)
+	}
+
+	unit.Breakdown.NetworkCost = networkCost
+	unit.MonthlyCost += networkCost
+	return unit
+}
+
 // GenerateReport creates a human-readable cost report
 func (ca *CostAnalyzer) GenerateReport(analysis *SpaceCostAnalysis) string {
 	var report strings.Builder
@@ -562,7 +1775,48 @@ func (ca *CostAnalyzer) GenerateReport(analysis *SpaceCostAnalysis) string {
 
 	report.WriteString(fmt.Sprintf("Space: %s\n", analysis.SpaceName))
 	report.WriteString(fmt.Sprintf("Units Analyzed: %d\n", analysis.UnitCount))
-	report.WriteString(fmt.Sprintf("Estimated Monthly Cost: $%.2f\n\n", analysis.TotalMonthlyCost))
+	pricing := ca.Pricing()
+	report.WriteString(fmt.Sprintf("Estimated Monthly Cost: %s\n", pricing.FormatAmount(analysis.TotalMonthlyCost)))
+	if budget := analysis.Budget; budget != nil {
+		line := fmt.Sprintf("Budget: %s (%.0f%% used, %s)", pricing.FormatAmount(budget.MonthlyBudget), budget.PercentConsumed, budget.State)
+		if budget.ProjectedOverage > 0 {
+			line += fmt.Sprintf(", %s over", pricing.FormatAmount(budget.ProjectedOverage))
+		}
+		report.WriteString(line + "\n")
+	}
+	if repricing := analysis.Repricing; repricing != nil {
+		sign := "+"
+		if repricing.Delta < 0 {
+			sign = "-"
+		}
+		report.WriteString(fmt.Sprintf("Repriced: was %s, %s%s (%+.1f%%)\n", pricing.FormatAmount(repricing.OriginalMonthlyCost),
+			sign, pricing.FormatAmount(math.Abs(repricing.Delta)), repricing.DeltaPercent))
+	}
+	if analysis.DriftedUnits > 0 {
+		report.WriteString(fmt.Sprintf("Drifted Units: %d (live state differs from the manifest; estimates may be off)\n", analysis.DriftedUnits))
+	}
+	var loadBalancers int
+	var loadBalancerCost float64
+	for _, unit := range analysis.Units {
+		if unit.Breakdown.LoadBalancerCost > 0 {
+			loadBalancers++
+			loadBalancerCost += unit.Breakdown.LoadBalancerCost
+		}
+	}
+	if loadBalancers > 0 {
+		report.WriteString(fmt.Sprintf("Load Balancers: %s/month in %d units\n", pricing.FormatAmount(loadBalancerCost), loadBalancers))
+	}
+	if pricing.CommitmentCoverage > 0 {
+		var committed, onDemand float64
+		for _, unit := range analysis.Units {
+			committed += unit.Breakdown.CommittedCost
+			onDemand += unit.Breakdown.OnDemandCost
+		}
+		report.WriteString(fmt.Sprintf("Committed / On-Demand: %s / %s (%.0f%% coverage, %.0f%% discount)\n",
+			pricing.FormatAmount(committed), pricing.FormatAmount(onDemand),
+			pricing.CommitmentCoverage*100, pricing.CommitmentDiscount*100))
+	}
+	report.WriteString("\n")
 
 	report.WriteString("Top Cost Drivers:\n")
 	report.WriteString("─────────────────────────────────────────────\n")
@@ -572,24 +1826,39 @@ func (ca *CostAnalyzer) GenerateReport(analysis *SpaceCostAnalysis) string {
 		if i >= 5 {
 			break
 		}
-		report.WriteString(fmt.Sprintf("%-30s %s %dx %6s CPU %8s Mem  $%.2f/mo\n",
+		report.WriteString(fmt.Sprintf("%-30s %s %dx %6s CPU %8s Mem  %s/mo\n",
 			unit.UnitName,
 			unit.Type,
 			unit.Replicas,
 			unit.CPU.String(),
 			unit.Memory.String(),
-			unit.MonthlyCost,
+			pricing.FormatAmount(unit.MonthlyCost),
 		))
 	}
 
-	// Environment comparison
+	// Environment comparison, with each environment's drift from the base space
 	if len(analysis.Environments) > 0 {
 		report.WriteString("\n\nEnvironment Cost Comparison:\n")
 		report.WriteString("─────────────────────────────────────────────\n")
 
-		for env, envAnalysis := range analysis.Environments {
-			report.WriteString(fmt.Sprintf("%-10s: $%.2f/month (%d units)\n",
-				env, envAnalysis.TotalMonthlyCost, envAnalysis.UnitCount))
+		envs := make([]string, 0, len(analysis.Environments))
+		for env := range analysis.Environments {
+			envs = append(envs, env)
+		}
+		sort.Strings(envs)
+
+		for _, env := range envs {
+			envAnalysis := analysis.Environments[env]
+			drift := envAnalysis.TotalMonthlyCost - analysis.TotalMonthlyCost
+			report.WriteString(fmt.Sprintf("%-10s: %s/month (%d units), %s vs base",
+				env, pricing.FormatAmount(envAnalysis.TotalMonthlyCost), envAnalysis.UnitCount, formatDrift(pricing, drift)))
+			if analysis.TotalMonthlyCost > 0 {
+				report.WriteString(fmt.Sprintf(" (%+.1f%%)", drift/analysis.TotalMonthlyCost*100))
+			}
+			report.WriteString("\n")
+			if unit, unitDrift := largestUnitDrift(analysis, envAnalysis); unit != "" {
+				report.WriteString(fmt.Sprintf("            largest drift: %s %s\n", unit, formatDrift(pricing, unitDrift)))
+			}
 		}
 	}
 
@@ -610,24 +1879,80 @@ func (ca *CostAnalyzer) GenerateReport(analysis *SpaceCostAnalysis) string {
 	}
 
 	report.WriteString(fmt.Sprintf("• %d units appear over-provisioned\n", overProvisionedCount))
-	report.WriteString(fmt.Sprintf("• Potential savings: $%.2f/month (30%% reduction)\n", potentialSavings))
+	report.WriteString(fmt.Sprintf("• Potential savings: %s/month (30%% reduction)\n", pricing.FormatAmount(potentialSavings)))
 	report.WriteString("• Run with actual metrics for accurate analysis\n")
 
 	return report.String()
 }
 
-// StoreAnalysisInConfigHub stores cost analysis as ConfigHub annotations
+// formatDrift formats a signed cost difference, e.g. "+$12.00"
+func formatDrift(pricing *PricingModel, drift float64) string {
+	if drift < 0 {
+		return "-" + pricing.FormatAmount(-drift)
+	}
+	return "+" + pricing.FormatAmount(drift)
+}
+
+// largestUnitDrift finds the unit, matched by name, whose cost differs most
+// between the base and an environment. Units only in env count in full.
+func largestUnitDrift(base, env *SpaceCostAnalysis) (string, float64) {
+	baseCosts := make(map[string]float64, len(base.Units))
+	for _, unit := range base.Units {
+		baseCosts[unit.UnitName] = unit.MonthlyCost
+	}
+
+	var name string
+	var largest float64
+	for _, unit := range env.Units {
+		drift := unit.MonthlyCost - baseCosts[unit.UnitName]
+		if math.Abs(drift) > math.Abs(largest) {
+			name, largest = unit.UnitName, drift
+		}
+	}
+	return name, largest
+}
+
+// AnnotationWrite is the set of cost annotations intended for one unit
+type AnnotationWrite struct {
+	UnitID      uuid.UUID
+	UnitName    string
+	Annotations map[string]string
+}
+
+// StoreAnalysisInConfigHub stores cost analysis as ConfigHub annotations. Each
+// unit gets an annotation-only merge patch (BulkSetAnnotations), so Data,
+// Labels and its other annotations are never written.
 func (ca *CostAnalyzer) StoreAnalysisInConfigHub(analysis *SpaceCostAnalysis) error {
-	for _, unit := range analysis.Units {
-		annotations := map[string]string{
-			"cost-optimizer.io/monthly-cost":  fmt.Sprintf("$%.2f", unit.MonthlyCost),
-			"cost-optimizer.io/cpu-cost":      fmt.Sprintf("$%.2f", unit.Breakdown.CPUCost),
-			"cost-optimizer.io/memory-cost":   fmt.Sprintf("$%.2f", unit.Breakdown.MemoryCost),
-			"cost-optimizer.io/storage-cost":  fmt.Sprintf("$%.2f", unit.Breakdown.StorageCost),
-			"cost-optimizer.io/analyzed-at":   time.Now().Format(time.RFC3339),
-			"cost-optimizer.io/analysis-type": "pre-deployment",
+	for _, write := range ca.planAnnotationWrites(analysis) {
+		where := NewWhere().Eq("UnitID", write.UnitID).MustBuild()
+		if err := ca.app.Cub.BulkSetAnnotations(ca.spaceID, where, write.Annotations); err != nil {
+			ca.app.Logger.Printf("⚠️  Failed to annotate unit %s: %v", write.UnitName, err)
 		}
+	}
 
+	return nil
+}
+
+// StoreAnalysisDryRun returns the annotation writes StoreAnalysisInConfigHub
+// would make, without touching ConfigHub
+func (ca *CostAnalyzer) StoreAnalysisDryRun(analysis *SpaceCostAnalysis) []AnnotationWrite {
+	writes := ca.planAnnotationWrites(analysis)
+
+	ca.app.Logger.Printf("📝 [Dry Run] Would annotate %d units:", len(writes))
+	for _, write := range writes {
+		ca.app.Logger.Printf("   - %s: %s/month", write.UnitName, write.Annotations["cost-optimizer.io/monthly-cost"])
+	}
+
+	return writes
+}
+
+// planAnnotationWrites builds the cost annotations for each analyzed unit,
+// skipping units with invalid IDs
+func (ca *CostAnalyzer) planAnnotationWrites(analysis *SpaceCostAnalysis) []AnnotationWrite {
+	var writes []AnnotationWrite
+	analyzedAt := time.Now().Format(time.RFC3339)
+
+	for _, unit := range analysis.Units {
 		// Parse UnitID back to UUID
 		unitID, err := uuid.Parse(unit.UnitID)
 		if err != nil {
@@ -635,17 +1960,21 @@ func (ca *CostAnalyzer) StoreAnalysisInConfigHub(analysis *SpaceCostAnalysis) er
 			continue
 		}
 
-		// Update unit with cost annotations
-		_, err = ca.app.Cub.UpdateUnit(ca.spaceID, unitID, CreateUnitRequest{
-			Slug:        unit.UnitName, // Use existing slug
-			Annotations: annotations,
+		writes = append(writes, AnnotationWrite{
+			UnitID:   unitID,
+			UnitName: unit.UnitName,
+			Annotations: map[string]string{
+				"cost-optimizer.io/monthly-cost":  fmt.Sprintf("$%.2f", unit.MonthlyCost),
+				"cost-optimizer.io/cpu-cost":      fmt.Sprintf("$%.2f", unit.Breakdown.CPUCost),
+				"cost-optimizer.io/memory-cost":   fmt.Sprintf("$%.2f", unit.Breakdown.MemoryCost),
+				"cost-optimizer.io/storage-cost":  fmt.Sprintf("$%.2f", unit.Breakdown.StorageCost),
+				"cost-optimizer.io/analyzed-at":   analyzedAt,
+				"cost-optimizer.io/analysis-type": "pre-deployment",
+			},
 		})
-		if err != nil {
-			ca.app.Logger.Printf("⚠️  Failed to annotate unit %s: %v", unit.UnitName, err)
-		}
 	}
 
-	return nil
+	return writes
 }
 
 // GetOptimizationRecommendations provides AI-powered cost optimization suggestions
@@ -681,8 +2010,9 @@ func (ca *CostAnalyzer) GetOptimizationRecommendations(analysis *SpaceCostAnalys
 			})
 		}
 
-		// Replica optimization
-		if unit.Replicas > 3 && unit.MonthlyCost < 50 {
+		// Replica optimization; a Job's replicas are completions x parallelism, not replicas
+		isJob := unit.Type == "Job" || unit.Type == "CronJob"
+		if unit.Replicas > 3 && unit.MonthlyCost < 50 && !isJob {
 			recommendations = append(recommendations, OptimizationRecommendation{
 				UnitID:           unit.UnitID,
 				UnitName:         unit.UnitName,
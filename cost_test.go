@@ -0,0 +1,851 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test Job and CronJob cost estimates
+func TestJobCost(t *testing.T) {
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	podHourly := ca.Pricing().CPUHourly*2 + ca.Pricing().MemoryHourly*1
+
+	t.Run("CronRunsPerMonth", func(t *testing.T) {
+		for schedule, expected := range map[string]float64{
+			"*/15 * * * *":            4 * 24 * 365 / 12.0,
+			"@daily":                  365 / 12.0,
+			"0 9 * * 1-5":             260 / 12.0, // Weekdays in 2023
+			"0 0 1 JAN *":             1 / 12.0,
+			"30 2 * * SUN":            53 / 12.0,
+			"0 0 1,15 * *":            2,
+			"CRON_TZ=UTC 0 */6 * * *": 4 * 365 / 12.0,
+			"@every 2h":               365, // An average month is 730 hours
+		} {
+			runs, err := cronRunsPerMonth(schedule)
+			require.NoError(t, err, schedule)
+			assert.InDelta(t, expected, runs, 0.001, schedule)
+		}
+
+		for _, schedule := range []string{"", "* * *", "61 * * * *", "0 0 * * MON-FRI/0", "@fortnightly"} {
+			_, err := cronRunsPerMonth(schedule)
+			assert.Error(t, err, schedule)
+		}
+	})
+
+	t.Run("JobUsesDeadline", func(t *testing.T) {
+		estimate, err := ca.analyzeUnit(Unit{Slug: "migrate", Data: `apiVersion: batch/v1
+kind: Job
+spec:
+  completions: 2
+  parallelism: 2
+  activeDeadlineSeconds: 3600
+  template:
+    spec:
+      containers:
+      - name: migrate
+        resources:
+          requests:
+            cpu: "2"
+            memory: 1Gi
+`})
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, "Job", estimate.Type)
+		assert.Equal(t, int32(4), estimate.Replicas)
+		assert.InDelta(t, 4*podHourly, estimate.MonthlyCost, 0.0001)
+		assert.InDelta(t, estimate.MonthlyCost, estimate.Breakdown.CPUCost+estimate.Breakdown.MemoryCost, 0.0001)
+	})
+
+	t.Run("CronJobUsesHistoricalDuration", func(t *testing.T) {
+		estimate, err := ca.analyzeUnit(Unit{
+			Slug:        "report",
+			Annotations: map[string]string{RunDurationAnnotation: "30m"},
+			Data: `apiVersion: batch/v1
+kind: CronJob
+spec:
+  schedule: "@hourly"
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 7200
+      template:
+        spec:
+          containers:
+          - name: report
+            resources:
+              requests:
+                cpu: "2"
+                memory: 1Gi
+`})
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, "CronJob", estimate.Type)
+		assert.InDelta(t, 730*0.5*podHourly, estimate.MonthlyCost, 0.0001)
+	})
+
+	t.Run("MissingDurationIsFlagged", func(t *testing.T) {
+		estimate, err := ca.analyzeUnit(Unit{Slug: "backfill", Data: `apiVersion: batch/v1
+kind: Job
+spec:
+  template:
+    spec:
+      containers:
+      - name: backfill
+        resources:
+          requests:
+            cpu: "8"
+`})
+		require.NoError(t, err)
+		assert.Zero(t, estimate.MonthlyCost)
+		assert.Equal(t, "true", estimate.Annotations[MissingDurationAnnotation])
+	})
+
+	t.Run("SuspendedAndInvalidCronJobs", func(t *testing.T) {
+		cronJob := func(schedule, suspend string) Unit {
+			return Unit{Slug: "nightly", Data: `apiVersion: batch/v1
+kind: CronJob
+spec:
+  schedule: "` + schedule + `"
+  suspend: ` + suspend + `
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 600
+      template:
+        spec:
+          containers:
+          - name: nightly
+            resources:
+              requests:
+                cpu: "1"
+                memory: 1Gi
+`}
+		}
+
+		estimate, err := ca.analyzeUnit(cronJob("@daily", "true"))
+		require.NoError(t, err)
+		assert.Zero(t, estimate.MonthlyCost)
+
+		_, err = ca.analyzeUnit(cronJob("every night", "false"))
+		assert.ErrorContains(t, err, "every night")
+	})
+}
+
+// Test grouping cost by a unit label
+func TestGroupCostBy(t *testing.T) {
+	analysis := &SpaceCostAnalysis{Units: []UnitCostEstimate{
+		{UnitName: "web", MonthlyCost: 100, Breakdown: CostBreakdown{CPUCost: 60, MemoryCost: 40}, Labels: map[string]string{"team": "payments"}},
+		{UnitName: "api", MonthlyCost: 50, Breakdown: CostBreakdown{CPUCost: 50}, Labels: map[string]string{"team": "payments"}},
+		{UnitName: "search", MonthlyCost: 200, Labels: map[string]string{"team": "discovery"}},
+		{UnitName: "legacy", MonthlyCost: 25, Labels: map[string]string{"app": "legacy"}},
+		{UnitName: "cron", MonthlyCost: 5},
+	}}
+
+	groups := GroupCostBy(analysis, "team")
+	require.Len(t, groups, 3)
+	assert.Equal(t, 2, groups["payments"].UnitCount)
+	assert.Equal(t, 150.0, groups["payments"].TotalMonthlyCost)
+	assert.Equal(t, 110.0, groups["payments"].Breakdown.CPUCost)
+	assert.Equal(t, 1, groups["discovery"].UnitCount)
+	assert.Equal(t, 2, groups[UnlabeledCostGroup].UnitCount)
+	assert.Equal(t, 30.0, groups[UnlabeledCostGroup].TotalMonthlyCost)
+
+	output := RenderCostGroupTable("team", groups)
+	lines := strings.Split(output, "\n")
+	assert.Contains(t, lines[1], "team")
+	assert.Contains(t, lines[3], "discovery", "most expensive group first")
+	assert.Contains(t, lines[3], "52.6%")
+	assert.Contains(t, output, "$380.00")
+
+	assert.Empty(t, GroupCostBy(nil, "team"))
+}
+
+// Test that estimates keep the unit's labels and annotations
+func TestUnitCostEstimateMetadata(t *testing.T) {
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	unit := Unit{
+		Slug:   "web",
+		Labels: map[string]string{"team": "payments"},
+		Annotations: map[string]string{
+			"owner":                   "alice@example.com",
+			MissingRequestsAnnotation: "false",
+		},
+		Data: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+`,
+	}
+
+	estimate, err := ca.analyzeUnit(unit)
+	require.NoError(t, err)
+	assert.Equal(t, "payments", estimate.Labels["team"])
+	assert.Equal(t, "alice@example.com", estimate.Annotations["owner"])
+	assert.Equal(t, "true", estimate.Annotations[MissingRequestsAnnotation], "analysis flags win")
+
+	estimate.Labels["team"] = "search"
+	assert.Equal(t, "payments", unit.Labels["team"], "estimate must not alias the unit")
+
+	data, err := json.Marshal(estimate)
+	require.NoError(t, err)
+	var decoded UnitCostEstimate
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, estimate.Labels, decoded.Labels)
+	assert.Equal(t, estimate.Annotations, decoded.Annotations)
+
+	wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	wa.SetExclusions(map[string]string{"team": "search"}, nil)
+	assert.NotEmpty(t, wa.exclusionReason(*estimate))
+}
+
+// Test storing cost annotations back to ConfigHub
+func TestStoreAnalysisInConfigHub(t *testing.T) {
+	unitID := uuid.New()
+	analysis := &SpaceCostAnalysis{Units: []UnitCostEstimate{
+		{UnitID: unitID.String(), UnitName: "web", MonthlyCost: 42.5},
+		{UnitID: "not-a-uuid", UnitName: "broken"},
+	}}
+
+	t.Run("DryRunMakesNoRequests", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected %s %s during dry run", r.Method, r.URL.Path)
+		}))
+		defer server.Close()
+
+		ca := NewCostAnalyzer(&DevOpsApp{
+			Cub:    NewConfigHubClient(server.URL, "test-token"),
+			Logger: log.New(io.Discard, "", 0),
+		}, uuid.New())
+
+		writes := ca.StoreAnalysisDryRun(analysis)
+		require.Len(t, writes, 1, "invalid unit IDs are skipped")
+		assert.Equal(t, unitID, writes[0].UnitID)
+		assert.Equal(t, "$42.50", writes[0].Annotations["cost-optimizer.io/monthly-cost"])
+	})
+
+	t.Run("PatchesOnlyAnnotations", func(t *testing.T) {
+		var patches []BulkPatchParams
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "PATCH" {
+				t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+				return
+			}
+			var params BulkPatchParams
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+			patches = append(patches, params)
+		}))
+		defer server.Close()
+
+		ca := NewCostAnalyzer(&DevOpsApp{
+			Cub:    NewConfigHubClient(server.URL, "test-token"),
+			Logger: log.New(io.Discard, "", 0),
+		}, uuid.New())
+
+		require.NoError(t, ca.StoreAnalysisInConfigHub(analysis))
+		require.Len(t, patches, 1)
+		assert.Equal(t, "UnitID = '"+unitID.String()+"'", patches[0].Where)
+		require.Len(t, patches[0].Patch, 1, "Data and Labels are never sent")
+		annotations, ok := patches[0].Patch["Annotations"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "$42.50", annotations["cost-optimizer.io/monthly-cost"])
+	})
+}
+
+// Test blending committed-use and on-demand rates
+func TestCommitmentDiscount(t *testing.T) {
+	unit := Unit{UnitID: uuid.New(), Slug: "api", Data: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: api
+        resources:
+          requests:
+            cpu: "2"
+            memory: 4Gi
+`}
+
+	onDemand, err := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New()).analyzeUnit(unit)
+	require.NoError(t, err)
+	assert.Zero(t, onDemand.Breakdown.CommittedCost)
+	computeCost := onDemand.Breakdown.CPUCost + onDemand.Breakdown.MemoryCost
+	assert.InDelta(t, computeCost, onDemand.Breakdown.OnDemandCost, 0.0001)
+
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	ca.SetCommitment(60, 40)
+	assert.Zero(t, DefaultPricing.CommitmentCoverage, "DefaultPricing must not be modified")
+
+	committed, err := ca.analyzeUnit(unit)
+	require.NoError(t, err)
+	assert.InDelta(t, computeCost*0.6*0.6, committed.Breakdown.CommittedCost, 0.0001)
+	assert.InDelta(t, computeCost*0.4, committed.Breakdown.OnDemandCost, 0.0001)
+	assert.InDelta(t, onDemand.Breakdown.CPUCost*0.76, committed.Breakdown.CPUCost, 0.0001)
+	assert.InDelta(t, committed.Breakdown.CommittedCost+committed.Breakdown.OnDemandCost, committed.MonthlyCost, 0.0001)
+
+	report := ca.GenerateReport(&SpaceCostAnalysis{Units: []UnitCostEstimate{*committed}, TotalMonthlyCost: committed.MonthlyCost})
+	assert.Contains(t, report, "(60% coverage, 40% discount)")
+
+	ca.SetCommitment(150, -5)
+	assert.Equal(t, 1.0, ca.Pricing().CommitmentCoverage)
+	assert.Zero(t, ca.Pricing().CommitmentDiscount)
+}
+
+// Test analyzing a single unit
+func TestAnalyzeUnit(t *testing.T) {
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+
+	t.Run("Deployment", func(t *testing.T) {
+		estimate, err := ca.AnalyzeUnit(Unit{UnitID: uuid.New(), Slug: "web", Data: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: 500m
+            memory: 1Gi
+`})
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, "web", estimate.UnitName)
+		assert.Equal(t, "Deployment", estimate.Type)
+		assert.Equal(t, int32(3), estimate.Replicas)
+		assert.InDelta(t, 3*(0.5*ca.Pricing().CPUHourly+ca.Pricing().MemoryHourly)*730, estimate.MonthlyCost, 0.0001)
+	})
+
+	t.Run("ServiceIsNotAWorkload", func(t *testing.T) {
+		estimate, err := ca.AnalyzeUnit(Unit{Slug: "web-svc", Data: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"})
+		require.NoError(t, err)
+		assert.Nil(t, estimate)
+	})
+
+	t.Run("LoadBalancerService", func(t *testing.T) {
+		lbCost := ca.Pricing().LoadBalancerHourly * 730
+		require.Greater(t, lbCost, 0.0)
+
+		estimate, err := ca.AnalyzeUnit(Unit{Slug: "web-lb", Data: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  type: LoadBalancer\n"})
+		require.NoError(t, err)
+		require.NotNil(t, estimate)
+		assert.Equal(t, LoadBalancerServiceType, estimate.Type)
+		assert.InDelta(t, lbCost, estimate.MonthlyCost, 0.0001)
+		assert.InDelta(t, lbCost, estimate.Breakdown.LoadBalancerCost, 0.0001)
+
+		estimate, err = ca.AnalyzeUnit(Unit{Slug: "web-np", Data: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  type: NodePort\n"})
+		require.NoError(t, err)
+		assert.Nil(t, estimate, "NodePort Services are free")
+
+		estimate, err = ca.AnalyzeUnit(Unit{Slug: "web", Data: "apiVersion: v1\nkind: Service\nspec:\n  type: LoadBalancer\n---\napiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - name: web\n        resources:\n          requests:\n            cpu: \"1\"\n"})
+		require.NoError(t, err)
+		assert.Equal(t, "Deployment", estimate.Type, "the workload describes the unit")
+		assert.InDelta(t, ca.Pricing().CPUHourly*730+lbCost, estimate.MonthlyCost, 0.0001)
+	})
+
+	t.Run("MalformedManifest", func(t *testing.T) {
+		_, err := ca.AnalyzeUnit(Unit{Slug: "broken", Data: "apiVersion: apps/v1\nkind: [Deployment\n"})
+		assert.ErrorContains(t, err, "failed to parse manifest")
+	})
+}
+
+// Test DaemonSets are priced per cluster node
+func TestDaemonSetNodeCount(t *testing.T) {
+	daemonSet := Unit{Slug: "node-exporter", Data: `apiVersion: apps/v1
+kind: DaemonSet
+spec:
+  template:
+    spec:
+      containers:
+      - name: exporter
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+`}
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+
+	assumed, err := ca.AnalyzeUnit(daemonSet)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), assumed.Replicas)
+	assert.Equal(t, "3", assumed.Annotations[AssumedNodeCountAnnotation])
+
+	ca.SetClusterNodeCount(200)
+	known, err := ca.AnalyzeUnit(daemonSet)
+	require.NoError(t, err)
+	assert.Equal(t, int32(200), known.Replicas)
+	assert.NotContains(t, known.Annotations, AssumedNodeCountAnnotation)
+	assert.InDelta(t, assumed.MonthlyCost/3*200, known.MonthlyCost, 0.0001)
+}
+
+// Test pods pinned to specialized node pools use that pool's pricing
+func TestNodeClassPricing(t *testing.T) {
+	const label = "node.kubernetes.io/instance-type"
+	gpuPricing := &PricingModel{CPUHourly: DefaultPricing.CPUHourly * 4, MemoryHourly: DefaultPricing.MemoryHourly * 4}
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	ca.SetNodeClassPricing(label, map[string]*PricingModel{"p3.2xlarge": gpuPricing})
+
+	deployment := func(podSpec string) Unit {
+		return Unit{Slug: "trainer", Data: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 1
+  template:
+    spec:
+` + podSpec + `      containers:
+      - name: trainer
+        ports:
+        - containerPort: 8080
+        resources:
+          requests:
+            cpu: "1"
+            memory: 1Gi
+`}
+	}
+
+	unpinned, err := ca.AnalyzeUnit(deployment(""))
+	require.NoError(t, err)
+	assert.Empty(t, unpinned.NodeClass)
+
+	for name, podSpec := range map[string]string{
+		"NodeSelector": "      nodeSelector:\n        node.kubernetes.io/instance-type: p3.2xlarge\n",
+		"Affinity": `      affinity:
+        nodeAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            nodeSelectorTerms:
+            - matchExpressions:
+              - key: node.kubernetes.io/instance-type
+                operator: In
+                values: [m5.large, p3.2xlarge]
+`,
+		"Toleration": "      tolerations:\n      - key: node.kubernetes.io/instance-type\n        operator: Equal\n        value: p3.2xlarge\n        effect: NoSchedule\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			estimate, err := ca.AnalyzeUnit(deployment(podSpec))
+			require.NoError(t, err)
+			assert.Equal(t, "p3.2xlarge", estimate.NodeClass)
+			assert.InDelta(t, unpinned.MonthlyCost*4, estimate.MonthlyCost, 0.0001)
+		})
+	}
+
+	t.Run("UnpricedClassFallsBack", func(t *testing.T) {
+		estimate, err := ca.AnalyzeUnit(deployment("      nodeSelector:\n        node.kubernetes.io/instance-type: m5.large\n"))
+		require.NoError(t, err)
+		assert.Empty(t, estimate.NodeClass)
+		assert.InDelta(t, unpinned.MonthlyCost, estimate.MonthlyCost, 0.0001)
+	})
+
+	t.Run("CronJobTemplate", func(t *testing.T) {
+		estimate, err := ca.AnalyzeUnit(Unit{Slug: "nightly", Data: `apiVersion: batch/v1
+kind: CronJob
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          nodeSelector:
+            node.kubernetes.io/instance-type: p3.2xlarge
+          containers:
+          - name: train
+`})
+		require.NoError(t, err)
+		assert.Equal(t, "p3.2xlarge", estimate.NodeClass)
+	})
+}
+
+// Test concurrent hierarchy analysis, environment discovery and drift reporting
+func TestAnalyzeHierarchy(t *testing.T) {
+	deployment := func(replicas int) string {
+		return fmt.Sprintf("apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: %d\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n", replicas)
+	}
+	baseID, devID, prodID, qaID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	replicas := map[uuid.UUID]int{baseID: 2, devID: 1, prodID: 6, qaID: 1}
+	spaces := []map[string]interface{}{
+		{"Space": Space{SpaceID: baseID, Slug: "shop"}},
+		{"Space": Space{SpaceID: devID, Slug: "shop-dev", Labels: map[string]string{EnvironmentLabel: "dev"}}},
+		{"Space": Space{SpaceID: prodID, Slug: "shop-prod", Labels: map[string]string{EnvironmentLabel: "prod"}}},
+		{"Space": Space{SpaceID: qaID, Slug: "shop-qa"}},
+		{"Space": Space{SpaceID: uuid.New(), Slug: "other-prod", Labels: map[string]string{EnvironmentLabel: "prod"}}},
+	}
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/space" {
+			json.NewEncoder(w).Encode(spaces)
+			return
+		}
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		spaceID := uuid.MustParse(strings.Split(r.URL.Path, "/")[2])
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "web", Data: deployment(replicas[spaceID])}},
+		})
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+
+	t.Run("DiscoversLabeledEnvironments", func(t *testing.T) {
+		analysis, err := NewCostAnalyzer(app, baseID).AnalyzeHierarchy("shop")
+		require.NoError(t, err)
+		require.Len(t, analysis.Environments, 2)
+		assert.Equal(t, "shop-dev", analysis.Environments["dev"].SpaceName)
+		assert.InDelta(t, analysis.TotalMonthlyCost*3, analysis.Environments["prod"].TotalMonthlyCost, 0.0001)
+		assert.Greater(t, maxInFlight, 1, "environments are analyzed concurrently")
+
+		report := NewCostAnalyzer(app, baseID).GenerateReport(analysis)
+		assert.Contains(t, report, "dev       : ")
+		assert.Contains(t, report, "(-50.0%)")
+		assert.Contains(t, report, "(+200.0%)")
+		assert.Contains(t, report, "largest drift: web +")
+	})
+
+	t.Run("ConfiguredEnvironments", func(t *testing.T) {
+		ca := NewCostAnalyzer(app, baseID)
+		ca.SetEnvironments("qa", "staging")
+		ca.SetConcurrency(1)
+		analysis, err := ca.AnalyzeHierarchy("shop")
+		require.NoError(t, err)
+		require.Len(t, analysis.Environments, 1, "shop-staging doesn't exist")
+		assert.Contains(t, analysis.Environments, "qa")
+	})
+
+	t.Run("BaseBudgetOnly", func(t *testing.T) {
+		base, err := NewCostAnalyzer(app, baseID).AnalyzeSpace()
+		require.NoError(t, err)
+
+		ca := NewCostAnalyzer(app, baseID)
+		ca.SetBudget(base.TotalMonthlyCost * 1.5)
+		ca.SetLabelBudgets("team", map[string]float64{"payments": 1})
+		analysis, err := ca.AnalyzeHierarchy("shop")
+		require.NoError(t, err)
+		require.NotNil(t, analysis.Budget)
+		assert.Equal(t, BudgetUnder, analysis.Budget.State)
+		assert.NotNil(t, analysis.LabelBudgets)
+		require.Len(t, analysis.Environments, 2)
+		for env, envAnalysis := range analysis.Environments {
+			assert.Nil(t, envAnalysis.Budget, env)
+			assert.Nil(t, envAnalysis.LabelBudgets, env)
+		}
+	})
+
+	t.Run("AlertsOnce", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		ca := NewCostAnalyzer(app, baseID)
+		ca.SetBudget(1)
+		ca.SetBudgetAlert(notifier)
+		_, err := ca.AnalyzeHierarchy("shop")
+		require.NoError(t, err)
+		require.Len(t, notifier.alerts, 1, "only the base space is alerted")
+		assert.Contains(t, notifier.alerts[0].Link, baseID.String())
+	})
+}
+
+// Test space and per-label budgets
+func TestBudget(t *testing.T) {
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "checkout", Data: deployment, Labels: map[string]string{"team": "payments"}}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "ledger", Data: deployment, Labels: map[string]string{"team": "payments"}}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "search", Data: deployment, Labels: map[string]string{"team": "discovery"}}},
+		})
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+
+	unbudgeted, err := NewCostAnalyzer(app, uuid.New()).AnalyzeSpace()
+	require.NoError(t, err)
+	assert.Nil(t, unbudgeted.Budget)
+	unitCost := unbudgeted.TotalMonthlyCost / 3
+
+	t.Run("States", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			budget float64
+			state  BudgetState
+		}{
+			"Under": {budget: unitCost * 10, state: BudgetUnder},
+			"Near":  {budget: unitCost * 3.5, state: BudgetNear},
+			"Over":  {budget: unitCost * 2, state: BudgetOver},
+		} {
+			ca := NewCostAnalyzer(app, uuid.New())
+			ca.SetBudget(tc.budget)
+			analysis, err := ca.AnalyzeSpace()
+			require.NoError(t, err, name)
+			require.NotNil(t, analysis.Budget, name)
+			assert.Equal(t, tc.state, analysis.Budget.State, name)
+			assert.InDelta(t, analysis.TotalMonthlyCost/tc.budget*100, analysis.Budget.PercentConsumed, 0.0001, name)
+		}
+	})
+
+	t.Run("CheckBudget", func(t *testing.T) {
+		ca := NewCostAnalyzer(app, uuid.New())
+		ca.SetBudget(unitCost * 2)
+		err := ca.CheckBudget()
+		var exceeded *BudgetExceededError
+		require.True(t, errors.As(err, &exceeded))
+		require.Len(t, exceeded.Over, 1)
+		assert.Equal(t, "space", exceeded.Over[0].Scope)
+		assert.InDelta(t, unitCost, exceeded.Over[0].ProjectedOverage, 0.0001)
+		assert.Contains(t, err.Error(), "over budget: space")
+
+		ca.SetBudget(unitCost * 10)
+		assert.NoError(t, ca.CheckBudget())
+	})
+
+	t.Run("LabelBudgets", func(t *testing.T) {
+		ca := NewCostAnalyzer(app, uuid.New())
+		ca.SetLabelBudgets("team", map[string]float64{"payments": unitCost * 1.5, "discovery": unitCost * 4, "growth": 100})
+		analysis, err := ca.AnalyzeSpace()
+		require.NoError(t, err)
+		require.Len(t, analysis.LabelBudgets, 3)
+		assert.Equal(t, BudgetOver, analysis.LabelBudgets["payments"].State)
+		assert.InDelta(t, unitCost*0.5, analysis.LabelBudgets["payments"].ProjectedOverage, 0.0001)
+		assert.Equal(t, BudgetUnder, analysis.LabelBudgets["discovery"].State)
+		assert.Zero(t, analysis.LabelBudgets["growth"].MonthlyCost)
+
+		err = ca.CheckBudget()
+		var exceeded *BudgetExceededError
+		require.True(t, errors.As(err, &exceeded))
+		require.Len(t, exceeded.Over, 1)
+		assert.Equal(t, "team=payments", exceeded.Over[0].Scope)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		ca := NewCostAnalyzer(app, uuid.New())
+		ca.SetBudget(unitCost * 2)
+		analysis, err := ca.AnalyzeSpace()
+		require.NoError(t, err)
+		report := ca.GenerateReport(analysis)
+		assert.Contains(t, report, "Budget: ")
+		assert.Contains(t, report, "(150% used, over), ")
+	})
+}
+
+// Test flagging estimates of units whose live state drifted
+func TestLiveStateDrift(t *testing.T) {
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: 500m\n"
+	drifted, clean, unapplied := uuid.New(), uuid.New(), uuid.New()
+	var liveStateCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/live-state") {
+			liveStateCalls++
+			switch {
+			case strings.Contains(r.URL.Path, drifted.String()):
+				json.NewEncoder(w).Encode(LiveState{UnitID: drifted, Status: "Applied", DriftDetected: true})
+			case strings.Contains(r.URL.Path, clean.String()):
+				json.NewEncoder(w).Encode(LiveState{UnitID: clean, Status: "Applied"})
+			default:
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Unit": Unit{UnitID: drifted, Slug: "web", Data: deployment}},
+			{"Unit": Unit{UnitID: clean, Slug: "api", Data: deployment}},
+			{"Unit": Unit{UnitID: unapplied, Slug: "worker", Data: deployment}},
+		})
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+
+	ca := NewCostAnalyzer(app, uuid.New())
+	analysis, err := ca.AnalyzeSpace()
+	require.NoError(t, err)
+	assert.Zero(t, analysis.DriftedUnits)
+	assert.Zero(t, liveStateCalls, "live state is only fetched when enabled")
+
+	ca.SetLiveStateCheck(true)
+	analysis, err = ca.AnalyzeSpace()
+	require.NoError(t, err)
+	assert.Equal(t, 1, analysis.DriftedUnits)
+	require.Len(t, analysis.Units, 3)
+	for _, unit := range analysis.Units {
+		_, flagged := unit.Annotations[LiveDriftAnnotation]
+		assert.Equal(t, unit.UnitName == "web", flagged, unit.UnitName)
+	}
+	assert.Contains(t, ca.GenerateReport(analysis), "Drifted Units: 1")
+}
+
+// Test repricing an analysis under a new pricing model without API calls
+func TestRepriceAnalysis(t *testing.T) {
+	units := []Unit{
+		{Slug: "web", Data: "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 3\n  template:\n    spec:\n      containers:\n      - name: web\n        resources:\n          requests:\n            cpu: 500m\n            memory: 1Gi\n"},
+		{Slug: "report", Data: "apiVersion: batch/v1\nkind: CronJob\nspec:\n  schedule: \"@daily\"\n  jobTemplate:\n    spec:\n      activeDeadlineSeconds: 3600\n      template:\n        spec:\n          containers:\n          - name: report\n            resources:\n              requests:\n                cpu: \"2\"\n"},
+		{Slug: "api", Labels: map[string]string{"team": "core"}, Data: "apiVersion: v1\nkind: Service\nspec:\n  type: LoadBalancer\n---\napiVersion: apps/v1\nkind: StatefulSet\nspec:\n  replicas: 2\n  template:\n    spec:\n      containers:\n      - name: api\n        resources:\n          requests:\n            cpu: \"1\"\n  volumeClaimTemplates:\n  - spec:\n      resources:\n        requests:\n          storage: 10Gi\n"},
+	}
+	analyze := func(pricing *PricingModel) *SpaceCostAnalysis {
+		ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+		ca.SetPricing(pricing)
+		analysis := &SpaceCostAnalysis{UnitCount: len(units)}
+		for _, unit := range units {
+			estimate, err := ca.AnalyzeUnit(unit)
+			require.NoError(t, err, unit.Slug)
+			analysis.Units = append(analysis.Units, *estimate)
+			analysis.TotalMonthlyCost += estimate.MonthlyCost
+		}
+		return analysis
+	}
+
+	newPricing := *DefaultPricing
+	newPricing.CPUHourly *= 2
+	newPricing.StorageGB = 0.08
+	newPricing.LoadBalancerHourly = 0.03
+	newPricing.CommitmentCoverage, newPricing.CommitmentDiscount = 0.5, 0.3
+
+	original := analyze(DefaultPricing)
+	original.Budget = newBudgetStatus("space", original.TotalMonthlyCost*1.1, original.TotalMonthlyCost)
+	original.LabelBudgets = map[string]*BudgetStatus{"core": newBudgetStatus("team=core", 1000, original.Units[2].MonthlyCost)}
+	originalTotal := original.TotalMonthlyCost
+	originalWeb := original.Units[0]
+
+	repriced := RepriceAnalysis(original, &newPricing)
+	expected := analyze(&newPricing)
+
+	t.Run("MatchesFreshAnalysis", func(t *testing.T) {
+		require.Len(t, repriced.Units, len(expected.Units))
+		for i, unit := range expected.Units {
+			assert.InDelta(t, unit.MonthlyCost, repriced.Units[i].MonthlyCost, 0.0001, unit.UnitName)
+			assert.InDelta(t, unit.Breakdown.CommittedCost, repriced.Units[i].Breakdown.CommittedCost, 0.0001, unit.UnitName)
+		}
+		assert.InDelta(t, expected.TotalMonthlyCost, repriced.TotalMonthlyCost, 0.0001)
+		assert.InDelta(t, newPricing.LoadBalancerHourly*730, repriced.Units[2].Breakdown.LoadBalancerCost, 0.0001)
+		assert.Equal(t, map[string]string{"team": "core"}, repriced.Units[2].Labels)
+	})
+
+	t.Run("OriginalUntouched", func(t *testing.T) {
+		assert.Equal(t, originalTotal, original.TotalMonthlyCost)
+		assert.Equal(t, originalWeb, original.Units[0])
+		assert.Nil(t, original.Repricing)
+	})
+
+	t.Run("DeltaSummary", func(t *testing.T) {
+		summary := repriced.Repricing
+		require.NotNil(t, summary)
+		assert.Equal(t, originalTotal, summary.OriginalMonthlyCost)
+		assert.InDelta(t, repriced.TotalMonthlyCost-originalTotal, summary.Delta, 0.0001)
+		assert.InDelta(t, summary.Delta/originalTotal*100, summary.DeltaPercent, 0.0001)
+		assert.InDelta(t, repriced.Units[0].MonthlyCost-originalWeb.MonthlyCost, summary.UnitDeltas["web"], 0.0001)
+		assert.Len(t, summary.UnitDeltas, 3)
+
+		report := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New()).GenerateReport(repriced)
+		assert.Contains(t, report, "Repriced: was "+DefaultPricing.FormatAmount(originalTotal))
+	})
+
+	t.Run("BudgetsReevaluated", func(t *testing.T) {
+		require.Greater(t, repriced.TotalMonthlyCost, original.Budget.MonthlyBudget)
+		assert.Equal(t, BudgetNear, original.Budget.State)
+		assert.Equal(t, BudgetOver, repriced.Budget.State)
+		assert.InDelta(t, repriced.Units[2].MonthlyCost, repriced.LabelBudgets["core"].MonthlyCost, 0.0001)
+	})
+
+	t.Run("NodeClassKeepsPrice", func(t *testing.T) {
+		gpu := SpaceCostAnalysis{Units: []UnitCostEstimate{{UnitName: "train", NodeClass: "gpu", Replicas: 1, CPU: ParseQuantity("4"), MonthlyCost: 900}}, TotalMonthlyCost: 900}
+		assert.Equal(t, 900.0, RepriceAnalysis(&gpu, &newPricing).TotalMonthlyCost)
+	})
+}
+
+// Test configurable month length and uptime-scaled compute cost
+func TestHoursPerMonthAndUptime(t *testing.T) {
+	deployment := Unit{Slug: "spot", Data: "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 2\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n"}
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+
+	estimate, err := ca.AnalyzeUnit(deployment)
+	require.NoError(t, err)
+	full := estimate.MonthlyCost
+	assert.InDelta(t, 2*(DefaultPricing.CPUHourly+DefaultPricing.MemoryHourly)*DefaultHoursPerMonth, full, 0.0001)
+
+	t.Run("HoursPerMonth", func(t *testing.T) {
+		pricing := *DefaultPricing
+		pricing.HoursPerMonth = 720
+		custom := NewCostAnalyzer(ca.app, uuid.New())
+		custom.SetPricing(&pricing)
+		estimate, err := custom.AnalyzeUnit(deployment)
+		require.NoError(t, err)
+		assert.InDelta(t, full*720/730, estimate.MonthlyCost, 0.0001)
+
+		pricing.HoursPerMonth = 0
+		estimate, err = custom.AnalyzeUnit(deployment)
+		require.NoError(t, err)
+		assert.InDelta(t, full, estimate.MonthlyCost, 0.0001, "unset uses DefaultHoursPerMonth")
+	})
+
+	t.Run("Uptime", func(t *testing.T) {
+		withStorage := *estimate
+		withStorage.Storage = ParseQuantity("10Gi")
+		withStorage.MonthlyCost = ca.calculateMonthlyCost(&withStorage)
+		storageCost := withStorage.Breakdown.StorageCost
+		require.Greater(t, storageCost, 0.0)
+
+		withStorage.Breakdown.NetworkCost = 5
+		withStorage.MonthlyCost += 5
+		ca.ApplyUptime(&withStorage, ActualUsageMetrics{UptimePercent: 40})
+		assert.Equal(t, 0.4, withStorage.UptimeFraction)
+		assert.InDelta(t, storageCost, withStorage.Breakdown.StorageCost, 0.0001, "storage is billed while pods are down")
+		assert.InDelta(t, full*0.4+storageCost+5, withStorage.MonthlyCost, 0.0001)
+		assert.InDelta(t, 5, withStorage.Breakdown.NetworkCost, 0.0001)
+
+		unmeasured := *estimate
+		ca.ApplyUptime(&unmeasured, ActualUsageMetrics{})
+		assert.InDelta(t, full, unmeasured.MonthlyCost, 0.0001, "no uptime data keeps always-on pricing")
+	})
+}
+
+// Test LimitRange defaults for containers that don't declare requests
+func TestSetNamespaceDefaults(t *testing.T) {
+	deployment := func(namespace, resources string) Unit {
+		return Unit{Slug: "web", Data: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: " + namespace + "\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - name: app\n" + resources}
+	}
+	ca := NewCostAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	ca.SetNamespaceDefaults(map[string]ResourceSpecs{
+		"team-a": {CPU: ParseQuantity("250m"), Memory: ParseQuantity("256Mi")},
+	})
+
+	estimate, err := ca.AnalyzeUnit(deployment("team-a", "        image: nginx:1.25\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), estimate.CPU.MilliValue())
+	assert.Equal(t, int64(256*1024*1024), estimate.Memory.BytesValue())
+	assert.NotContains(t, estimate.Annotations, MissingRequestsAnnotation)
+
+	estimate, err = ca.AnalyzeUnit(deployment("team-a", "        resources:\n          limits:\n            cpu: \"2\"\n            memory: 2Gi\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), estimate.CPU.MilliValue(), "LimitRange defaults apply before limits")
+
+	estimate, err = ca.AnalyzeUnit(deployment("team-a", "        resources:\n          requests:\n            cpu: 500m\n            memory: 1Gi\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), estimate.CPU.MilliValue(), "declared requests win")
+	assert.Equal(t, int64(1024*1024*1024), estimate.Memory.BytesValue())
+
+	estimate, err = ca.AnalyzeUnit(deployment("team-b", "        image: nginx:1.25\n"))
+	require.NoError(t, err)
+	assert.Zero(t, estimate.CPU.MilliValue(), "no defaults for the namespace")
+	assert.Equal(t, "true", estimate.Annotations[MissingRequestsAnnotation])
+}
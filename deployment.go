@@ -1,8 +1,10 @@
 package sdk
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,7 +21,6 @@ type DeploymentHelper struct {
 // NewDeploymentHelper creates a deployment helper for a DevOps app
 func NewDeploymentHelper(cub *ConfigHubClient, appName string) (*DeploymentHelper, error) {
 	// Use ConfigHub's new-prefix to generate unique names (like "chubby-paws")
-	// This would call: cub space new-prefix
 	prefix, err := cub.GetNewSpacePrefix()
 	if err != nil {
 		// Fallback to timestamp if API call fails
@@ -39,7 +40,7 @@ func NewDeploymentHelper(cub *ConfigHubClient, appName string) (*DeploymentHelpe
 // SetupBaseSpace creates the base ConfigHub structure
 func (d *DeploymentHelper) SetupBaseSpace() error {
 	// Create main space
-	_, err := d.Cub.CreateSpace(CreateSpaceRequest{
+	_, _, err := d.Cub.EnsureSpace(CreateSpaceRequest{
 		Slug:        d.ProjectName,
 		DisplayName: fmt.Sprintf("%s DevOps App", d.AppName),
 		Labels: map[string]string{
@@ -48,12 +49,12 @@ func (d *DeploymentHelper) SetupBaseSpace() error {
 			"project": d.ProjectName,
 		},
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
+	if err != nil {
 		return fmt.Errorf("create main space: %w", err)
 	}
 
 	// Create base space for base configurations
-	_, err = d.Cub.CreateSpace(CreateSpaceRequest{
+	_, _, err = d.Cub.EnsureSpace(CreateSpaceRequest{
 		Slug:        fmt.Sprintf("%s-base", d.ProjectName),
 		DisplayName: fmt.Sprintf("%s Base Configurations", d.AppName),
 		Labels: map[string]string{
@@ -61,12 +62,12 @@ func (d *DeploymentHelper) SetupBaseSpace() error {
 			"project": d.ProjectName,
 		},
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
+	if err != nil {
 		return fmt.Errorf("create base space: %w", err)
 	}
 
 	// Create filters space
-	_, err = d.Cub.CreateSpace(CreateSpaceRequest{
+	_, _, err = d.Cub.EnsureSpace(CreateSpaceRequest{
 		Slug:        fmt.Sprintf("%s-filters", d.ProjectName),
 		DisplayName: fmt.Sprintf("%s Filters", d.AppName),
 		Labels: map[string]string{
@@ -74,7 +75,7 @@ func (d *DeploymentHelper) SetupBaseSpace() error {
 			"project": d.ProjectName,
 		},
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
+	if err != nil {
 		return fmt.Errorf("create filters space: %w", err)
 	}
 
@@ -93,35 +94,35 @@ func (d *DeploymentHelper) CreateStandardFilters() error {
 	}
 
 	// All project units filter
-	_, err = d.Cub.CreateFilter(filtersSpaceID, CreateFilterRequest{
+	_, _, err = d.Cub.EnsureFilter(filtersSpaceID, CreateFilterRequest{
 		Slug:        "all",
 		DisplayName: "All Project Units",
 		From:        "Unit",
-		Where:       fmt.Sprintf("Space.Labels.project = '%s'", d.ProjectName),
+		Where:       NewWhere().Eq("Space.Labels.project", d.ProjectName).MustBuild(),
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
+	if err != nil {
 		return fmt.Errorf("create all filter: %w", err)
 	}
 
 	// App-specific filter
-	_, err = d.Cub.CreateFilter(filtersSpaceID, CreateFilterRequest{
+	_, _, err = d.Cub.EnsureFilter(filtersSpaceID, CreateFilterRequest{
 		Slug:        d.AppName,
 		DisplayName: fmt.Sprintf("%s Units", d.AppName),
 		From:        "Unit",
-		Where:       fmt.Sprintf("Labels.app = '%s'", d.AppName),
+		Where:       NewWhere().Eq("Labels.app", d.AppName).MustBuild(),
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
+	if err != nil {
 		return fmt.Errorf("create app filter: %w", err)
 	}
 
 	// Critical services filter
-	_, err = d.Cub.CreateFilter(filtersSpaceID, CreateFilterRequest{
+	_, _, err = d.Cub.EnsureFilter(filtersSpaceID, CreateFilterRequest{
 		Slug:        "critical",
 		DisplayName: "Critical Services",
 		From:        "Unit",
-		Where:       "Labels.tier = 'critical'",
+		Where:       NewWhere().Eq("Labels.tier", "critical").MustBuild(),
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
+	if err != nil {
 		return fmt.Errorf("create critical filter: %w", err)
 	}
 
@@ -151,7 +152,7 @@ func (d *DeploymentHelper) LoadBaseConfigurations(configPath string) error {
 	for _, cfg := range configs {
 		filePath := filepath.Join(configPath, cfg.file)
 		// In real implementation, would read file content
-		_, err = d.Cub.CreateUnit(baseSpaceID, CreateUnitRequest{
+		_, _, err = d.Cub.EnsureUnit(baseSpaceID, CreateUnitRequest{
 			Slug:        cfg.name,
 			DisplayName: fmt.Sprintf("%s Configuration", cfg.name),
 			Data:        fmt.Sprintf("# Content from %s", filePath),
@@ -161,7 +162,7 @@ func (d *DeploymentHelper) LoadBaseConfigurations(configPath string) error {
 				"tier": cfg.tier,
 			},
 		})
-		if err != nil && !strings.Contains(err.Error(), "already exists") {
+		if err != nil {
 			return fmt.Errorf("create unit %s: %w", cfg.name, err)
 		}
 	}
@@ -209,7 +210,7 @@ func (d *DeploymentHelper) CreateVariant(unitName, spaceName string, changes map
 	// ConfigHub will create a new revision automatically
 	err = d.Cub.BulkPatchUnits(BulkPatchParams{
 		SpaceID: spaceID,
-		Where:   fmt.Sprintf("Slug = '%s'", unitName),
+		Where:   NewWhere().Eq("Slug", unitName).MustBuild(),
 		Patch:   changes,
 		Upgrade: false, // Don't push to downstream, this is a local variant
 	})
@@ -220,50 +221,275 @@ func (d *DeploymentHelper) CreateVariant(unitName, spaceName string, changes map
 	return nil
 }
 
-// ApplyToEnvironment applies all units to a specific environment
+// ApplyToEnvironment applies the app's units to a specific environment
 func (d *DeploymentHelper) ApplyToEnvironment(environment string) error {
 	spaceID, err := d.getSpaceID(fmt.Sprintf("%s-%s", d.ProjectName, environment))
 	if err != nil {
 		return fmt.Errorf("get environment space: %w", err)
 	}
 
-	// Apply units in correct order
-	units := []string{
-		"namespace",
-		fmt.Sprintf("%s-rbac", d.AppName),
-		fmt.Sprintf("%s-service", d.AppName),
-		fmt.Sprintf("%s-deployment", d.AppName),
+	// Other apps may share the environment space
+	units, err := d.Cub.ListAllUnits(ListUnitsParams{
+		SpaceID: spaceID,
+		Where:   NewWhere().Eq("Labels.app", d.AppName).MustBuild(),
+	})
+	if err != nil {
+		return fmt.Errorf("list units: %w", err)
+	}
+
+	links, err := d.Cub.ListLinks(spaceID)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("list links: %w", err)
 	}
 
-	for _, unit := range units {
-		// Get unit ID by slug
-		unitList, err := d.Cub.ListUnits(ListUnitsParams{
-			SpaceID: spaceID,
-			Where:   fmt.Sprintf("Slug = '%s'", unit),
+	// Apply units in dependency order
+	ordered, err := TopologicalApplyOrderWithLinks(units, links)
+	if err != nil {
+		return fmt.Errorf("order units: %w", err)
+	}
+
+	for _, unit := range ordered {
+		if err := d.Cub.ApplyUnit(spaceID, unit.UnitID); err != nil {
+			return fmt.Errorf("apply unit %s: %w", unit.Slug, err)
+		}
+	}
+
+	return nil
+}
+
+// kindApplyPriority orders Kubernetes kinds so dependencies are created first.
+// Kinds not listed (e.g. custom resources) are applied with the workloads.
+var kindApplyPriority = map[string]int{
+	"Namespace":                0,
+	"CustomResourceDefinition": 1,
+	"ServiceAccount":           2,
+	"Role":                     2,
+	"ClusterRole":              2,
+	"RoleBinding":              2,
+	"ClusterRoleBinding":       2,
+	"ConfigMap":                3,
+	"Secret":                   3,
+	"PersistentVolumeClaim":    3,
+	"Service":                  4,
+	"Deployment":               5,
+	"StatefulSet":              5,
+	"DaemonSet":                5,
+	"Job":                      5,
+	"CronJob":                  5,
+	"Ingress":                  6,
+	"HorizontalPodAutoscaler":  6,
+	"PodDisruptionBudget":      6,
+}
+
+const defaultKindApplyPriority = 5
+
+// TopologicalApplyOrder orders units by Kubernetes kind so that namespaces,
+// CRDs, RBAC, config and services are applied before the workloads using them
+func TopologicalApplyOrder(units []*Unit) ([]*Unit, error) {
+	return TopologicalApplyOrderWithLinks(units, nil)
+}
+
+// TopologicalApplyOrderWithLinks is like TopologicalApplyOrder but also applies
+// each link's target unit before the unit linking to it. The order is
+// TopologicalApplyPhases flattened, so sequential and phased deploys always
+// agree. A cycle of links returns an error naming the units involved.
+func TopologicalApplyOrderWithLinks(units []*Unit, links []*Link) ([]*Unit, error) {
+	phases, err := TopologicalApplyPhases(units, links)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*Unit, 0, len(units))
+	for _, phase := range phases {
+		ordered = append(ordered, phase...)
+	}
+	return ordered, nil
+}
+
+// TopologicalApplyPhases groups units into phases that can each be applied
+// concurrently: a phase holds the ready units of the lowest kind priority, so
+// every unit's links and lower-priority kinds (namespaces, CRDs, ...) are in
+// earlier phases. Kind priority also holds while a unit waits on links: when
+// a lower-priority unit (say a Namespace) is still waiting, only the units it
+// waits on are applied ahead of it. A cycle of links returns an error naming the units involved.
+func TopologicalApplyPhases(units []*Unit, links []*Link) ([][]*Unit, error) {
+	priority, dependents, pending := applyGraph(units, links)
+
+	var ready []int
+	for i := range units {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	var phases [][]*Unit
+	placed := make([]bool, len(units))
+	count := 0
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(a, b int) bool {
+			if priority[ready[a]] != priority[ready[b]] {
+				return priority[ready[a]] < priority[ready[b]]
+			}
+			return ready[a] < ready[b]
 		})
-		if err != nil {
-			return fmt.Errorf("list units for %s: %w", unit, err)
+
+		// Hold back ready units a waiting lower-priority unit doesn't need
+		candidates, deferred := ready, []int(nil)
+		if needed := waitedOnBelow(priority[ready[0]], priority, dependents, pending, placed); needed != nil {
+			var wanted []int
+			for _, i := range ready {
+				if needed[i] {
+					wanted = append(wanted, i)
+				} else {
+					deferred = append(deferred, i)
+				}
+			}
+			if len(wanted) > 0 {
+				candidates = wanted
+			} else {
+				deferred = nil // Only a cycle is waiting; it is reported below
+			}
+		}
+		n := 1
+		for n < len(candidates) && priority[candidates[n]] == priority[candidates[0]] {
+			n++
 		}
 
-		if len(unitList) > 0 {
-			err = d.Cub.ApplyUnit(spaceID, unitList[0].UnitID)
-			if err != nil {
-				return fmt.Errorf("apply unit %s: %w", unit, err)
+		phase := make([]*Unit, 0, n)
+		var unlocked []int
+		for _, next := range candidates[:n] {
+			phase = append(phase, units[next])
+			placed[next] = true
+			for _, dependent := range dependents[next] {
+				pending[dependent]--
+				if pending[dependent] == 0 {
+					unlocked = append(unlocked, dependent)
+				}
 			}
 		}
+		phases = append(phases, phase)
+		count += n
+		ready = append(append(candidates[n:], deferred...), unlocked...)
 	}
 
-	// Alternative: Use bulk apply
-	err = d.Cub.BulkApplyUnits(BulkApplyParams{
-		SpaceID: spaceID,
-		Where:   fmt.Sprintf("Labels.app = '%s'", d.AppName),
-		DryRun:  false,
-	})
+	if count < len(units) {
+		return nil, fmt.Errorf("dependency cycle between units: %s", strings.Join(cycleUnitSlugs(units, dependents, pending), ", "))
+	}
+	return phases, nil
+}
+
+// waitedOnBelow returns the unplaced units that units of kind priority below p
+// still wait on through links, directly or not, or nil when no such unit waits
+func waitedOnBelow(p int, priority []int, dependents [][]int, pending []int, placed []bool) map[int]bool {
+	// targets[i] lists the unplaced units that unit i waits on
+	targets := make([][]int, len(priority))
+	for to, froms := range dependents {
+		if placed[to] {
+			continue
+		}
+		for _, from := range froms {
+			targets[from] = append(targets[from], to)
+		}
+	}
+
+	var stack []int
+	for i := range priority {
+		if pending[i] > 0 && priority[i] < p {
+			stack = append(stack, i)
+		}
+	}
+	if len(stack) == 0 {
+		return nil
+	}
+
+	needed := make(map[int]bool)
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, to := range targets[i] {
+			if !needed[to] {
+				needed[to] = true
+				stack = append(stack, to)
+			}
+		}
+	}
+	return needed
+}
+
+// applyGraph returns each unit's kind priority, the units waiting on each
+// unit through links, and how many link targets each unit still waits on
+func applyGraph(units []*Unit, links []*Link) (priority []int, dependents [][]int, pending []int) {
+	index := make(map[uuid.UUID]int, len(units))
+	priority = make([]int, len(units))
+	for i, unit := range units {
+		index[unit.UnitID] = i
+		priority[i] = unitApplyPriority(unit)
+	}
+
+	// dependents[i] lists units that must wait for unit i
+	dependents = make([][]int, len(units))
+	pending = make([]int, len(units))
+	for _, link := range links {
+		from, ok := index[link.FromUnitID]
+		if !ok {
+			continue
+		}
+		to, ok := index[link.ToUnitID]
+		if !ok {
+			continue // Target lives in another space or was not listed
+		}
+		dependents[to] = append(dependents[to], from)
+		pending[from]++
+	}
+	return priority, dependents, pending
+}
+
+// unitApplyPriority returns the apply priority of the unit's Kubernetes kind
+func unitApplyPriority(unit *Unit) int {
+	manifest, err := unit.Manifest()
 	if err != nil {
-		return fmt.Errorf("bulk apply: %w", err)
+		return defaultKindApplyPriority
+	}
+	kind, _ := manifest["kind"].(string)
+	if p, ok := kindApplyPriority[kind]; ok {
+		return p
 	}
+	return defaultKindApplyPriority
+}
 
-	return nil
+// cycleUnitSlugs narrows the units left over by the topological sort to those
+// on a cycle, dropping units that only wait on a cycle without being part of one
+func cycleUnitSlugs(units []*Unit, dependents [][]int, pending []int) []string {
+	remaining := make(map[int]bool)
+	for i := range units {
+		if pending[i] > 0 {
+			remaining[i] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := range remaining {
+			blocksOthers := false
+			for _, dependent := range dependents[i] {
+				if remaining[dependent] {
+					blocksOthers = true
+					break
+				}
+			}
+			if !blocksOthers {
+				delete(remaining, i)
+				changed = true
+			}
+		}
+	}
+
+	slugs := make([]string, 0, len(remaining))
+	for i := range remaining {
+		slugs = append(slugs, units[i].Slug)
+	}
+	sort.Strings(slugs)
+	return slugs
 }
 
 // PromoteEnvironment promotes changes from one environment to another
@@ -281,7 +507,7 @@ func (d *DeploymentHelper) PromoteEnvironment(from, to string) error {
 	// Use push-upgrade pattern
 	err = d.Cub.BulkPatchUnits(BulkPatchParams{
 		SpaceID: toSpaceID,
-		Where:   fmt.Sprintf("UpstreamSpaceID = '%s'", fromSpaceID),
+		Where:   NewWhere().Eq("UpstreamSpaceID", fromSpaceID).MustBuild(),
 		Patch:   map[string]interface{}{},
 		Upgrade: true, // Push-upgrade
 	})
@@ -294,10 +520,145 @@ func (d *DeploymentHelper) PromoteEnvironment(from, to string) error {
 
 // Helper functions
 
+// PromotionPlan lists the unit changes a push-upgrade from one environment to
+// the next would apply, for review before ExecutePromotion
+type PromotionPlan struct {
+	From        string
+	To          string
+	FromSpaceID uuid.UUID
+	ToSpaceID   uuid.UUID
+	Units       []UnitPromotion // Only units whose manifest would change
+}
+
+// UnitPromotion is the pending change to one downstream unit. In Changes, Live
+// is the downstream value and Desired the upstream value being promoted.
+type UnitPromotion struct {
+	UnitSlug       string
+	UnitID         uuid.UUID // Downstream unit
+	UpstreamUnitID uuid.UUID
+	Changes        []FieldChange
+}
+
+// HasChanges reports whether executing the plan would change any unit
+func (p *PromotionPlan) HasChanges() bool {
+	return len(p.Units) > 0
+}
+
+// PromoteEnvironmentWithDiff computes what PromoteEnvironment would change,
+// comparing each downstream unit in to with its upstream unit in from.
+// Passing unit slugs limits the plan to those units. Nothing is written;
+// review the plan (see RenderPromotionPlanTable) and apply it with ExecutePromotion.
+func (d *DeploymentHelper) PromoteEnvironmentWithDiff(from, to string, units ...string) (*PromotionPlan, error) {
+	fromSpaceID, err := d.getSpaceID(fmt.Sprintf("%s-%s", d.ProjectName, from))
+	if err != nil {
+		return nil, fmt.Errorf("get from space: %w", err)
+	}
+
+	toSpaceID, err := d.getSpaceID(fmt.Sprintf("%s-%s", d.ProjectName, to))
+	if err != nil {
+		return nil, fmt.Errorf("get to space: %w", err)
+	}
+
+	upstreamUnits, err := d.Cub.ListAllUnits(ListUnitsParams{SpaceID: fromSpaceID})
+	if err != nil {
+		return nil, fmt.Errorf("list %s units: %w", from, err)
+	}
+	upstreamByID := make(map[uuid.UUID]*Unit, len(upstreamUnits))
+	for _, unit := range upstreamUnits {
+		upstreamByID[unit.UnitID] = unit
+	}
+
+	downstreamUnits, err := d.Cub.ListAllUnits(ListUnitsParams{SpaceID: toSpaceID})
+	if err != nil {
+		return nil, fmt.Errorf("list %s units: %w", to, err)
+	}
+
+	selected := make(map[string]bool, len(units))
+	for _, slug := range units {
+		selected[slug] = false
+	}
+
+	plan := &PromotionPlan{
+		From:        from,
+		To:          to,
+		FromSpaceID: fromSpaceID,
+		ToSpaceID:   toSpaceID,
+	}
+	for _, unit := range downstreamUnits {
+		if len(selected) > 0 {
+			if _, ok := selected[unit.Slug]; !ok {
+				continue
+			}
+			selected[unit.Slug] = true
+		}
+		if unit.UpstreamUnitID == nil {
+			continue
+		}
+		upstream, ok := upstreamByID[*unit.UpstreamUnitID]
+		if !ok || upstream.Data == unit.Data {
+			continue
+		}
+
+		current, err := unit.Manifest()
+		if err != nil {
+			return nil, fmt.Errorf("parse %s unit %s: %w", to, unit.Slug, err)
+		}
+		promoted, err := upstream.Manifest()
+		if err != nil {
+			return nil, fmt.Errorf("parse %s unit %s: %w", from, upstream.Slug, err)
+		}
+
+		changes := diffFields("", current, promoted, true)
+		if len(changes) == 0 {
+			continue
+		}
+		plan.Units = append(plan.Units, UnitPromotion{
+			UnitSlug:       unit.Slug,
+			UnitID:         unit.UnitID,
+			UpstreamUnitID: upstream.UnitID,
+			Changes:        changes,
+		})
+	}
+
+	for _, slug := range units {
+		if !selected[slug] {
+			return nil, fmt.Errorf("unit %s not found in %s", slug, to)
+		}
+	}
+
+	sort.Slice(plan.Units, func(i, j int) bool { return plan.Units[i].UnitSlug < plan.Units[j].UnitSlug })
+	return plan, nil
+}
+
+// ExecutePromotion push-upgrades exactly the units in a plan from
+// PromoteEnvironmentWithDiff. A plan without changes is a no-op.
+func (d *DeploymentHelper) ExecutePromotion(plan *PromotionPlan) error {
+	if plan == nil || !plan.HasChanges() {
+		return nil
+	}
+
+	ids := make([]interface{}, len(plan.Units))
+	for i, unit := range plan.Units {
+		ids[i] = unit.UnitID
+	}
+
+	err := d.Cub.BulkPatchUnits(BulkPatchParams{
+		SpaceID: plan.ToSpaceID,
+		Where:   NewWhere().Eq("UpstreamSpaceID", plan.FromSpaceID).And().In("UnitID", ids...).MustBuild(),
+		Patch:   map[string]interface{}{},
+		Upgrade: true, // Push-upgrade
+	})
+	if err != nil {
+		return fmt.Errorf("promote from %s to %s: %w", plan.From, plan.To, err)
+	}
+
+	return nil
+}
+
 func (d *DeploymentHelper) createEnvironment(env string, upstreamSpaceID *uuid.UUID) (uuid.UUID, error) {
 	spaceName := fmt.Sprintf("%s-%s", d.ProjectName, env)
 
-	space, err := d.Cub.CreateSpace(CreateSpaceRequest{
+	space, _, err := d.Cub.EnsureSpace(CreateSpaceRequest{
 		Slug:        spaceName,
 		DisplayName: fmt.Sprintf("%s %s Environment", d.AppName, strings.Title(env)),
 		Labels: map[string]string{
@@ -305,8 +666,8 @@ func (d *DeploymentHelper) createEnvironment(env string, upstreamSpaceID *uuid.U
 			"environment": env,
 		},
 	})
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
-		return uuid.UUID{}, fmt.Errorf("create space: %w", err)
+	if err != nil {
+		return uuid.UUID{}, err
 	}
 
 	// Clone units from upstream
@@ -321,31 +682,76 @@ func (d *DeploymentHelper) createEnvironment(env string, upstreamSpaceID *uuid.U
 }
 
 func (d *DeploymentHelper) cloneUnitsFromUpstream(fromSpaceID, toSpaceID uuid.UUID, env string) error {
-	// List units in upstream space
-	units, err := d.Cub.ListUnits(ListUnitsParams{
+	// List units in upstream space, across pages
+	units, err := d.Cub.ListAllUnits(ListUnitsParams{
 		SpaceID: fromSpaceID,
 	})
 	if err != nil {
 		return fmt.Errorf("list upstream units: %w", err)
 	}
 
-	// Clone each unit with upstream relationship
+	// Clone all units with upstream relationship in one batch
+	reqs := make([]CreateUnitRequest, 0, len(units))
 	for _, unit := range units {
-		_, err = d.Cub.CreateUnit(toSpaceID, CreateUnitRequest{
+		reqs = append(reqs, CreateUnitRequest{
 			Slug:           unit.Slug,
 			DisplayName:    unit.DisplayName,
 			Data:           unit.Data,
 			Labels:         mergeLabels(unit.Labels, map[string]string{"environment": env}),
 			UpstreamUnitID: &unit.UnitID,
 		})
-		if err != nil && !strings.Contains(err.Error(), "already exists") {
-			return fmt.Errorf("clone unit %s: %w", unit.Slug, err)
+	}
+
+	existing, err := d.existingUnitSlugs(toSpaceID)
+	if err != nil {
+		return err
+	}
+	missing := reqs[:0]
+	for _, req := range reqs {
+		if !existing[req.Slug] {
+			missing = append(missing, req)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	_, err = d.Cub.BulkCreateUnits(toSpaceID, missing)
+	var bulkErr *BulkCreateError
+	if errors.As(err, &bulkErr) {
+		// Failures are fine if a concurrent clone created those units first
+		existing, listErr := d.existingUnitSlugs(toSpaceID)
+		if listErr != nil {
+			return fmt.Errorf("clone units: %w", err)
 		}
+		for i, failure := range bulkErr.Failures {
+			if !existing[missing[i].Slug] {
+				return fmt.Errorf("clone unit %s: %w", missing[i].Slug, failure)
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("clone units: %w", err)
 	}
 
 	return nil
 }
 
+// existingUnitSlugs returns the slugs of the units already in a space
+func (d *DeploymentHelper) existingUnitSlugs(spaceID uuid.UUID) (map[string]bool, error) {
+	units, err := d.Cub.ListAllUnits(ListUnitsParams{SpaceID: spaceID})
+	if err != nil {
+		return nil, fmt.Errorf("list existing units: %w", err)
+	}
+
+	slugs := make(map[string]bool, len(units))
+	for _, unit := range units {
+		slugs[unit.Slug] = true
+	}
+	return slugs, nil
+}
+
 // getSpaceID resolves space name to UUID by querying ConfigHub
 func (d *DeploymentHelper) getSpaceID(spaceName string) (uuid.UUID, error) {
 	spaces, err := d.Cub.ListSpaces()
@@ -365,20 +771,13 @@ func (d *DeploymentHelper) getSpaceID(spaceName string) (uuid.UUID, error) {
 
 // getSpaceIDOrCreate resolves space name to UUID, creating it if it doesn't exist
 func (d *DeploymentHelper) getSpaceIDOrCreate(spaceName, displayName string, labels map[string]string) (uuid.UUID, error) {
-	// Try to get existing space first
-	spaceID, err := d.getSpaceID(spaceName)
-	if err == nil {
-		return spaceID, nil
-	}
-
-	// Space doesn't exist, create it
-	space, err := d.Cub.CreateSpace(CreateSpaceRequest{
+	space, _, err := d.Cub.EnsureSpace(CreateSpaceRequest{
 		Slug:        spaceName,
 		DisplayName: displayName,
 		Labels:      labels,
 	})
 	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("create space %s: %w", spaceName, err)
+		return uuid.UUID{}, err
 	}
 
 	return space.SpaceID, nil
@@ -3,10 +3,16 @@ package sdk
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
-	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,59 +25,395 @@ type DevModeDeployer struct {
 	app           *DevOpsApp
 	dynamicClient dynamic.Interface
 	spaceID       uuid.UUID
+
+	verifyTimeout  time.Duration // How long ApplyViaConfigHubThenVerify waits for the worker
+	verifyInterval time.Duration // How often it polls the unit's live state
+	concurrency    int           // Max units DeploySpace deploys in parallel within a phase
 }
 
+// DefaultDeployConcurrency is how many units DeploySpace deploys in parallel
+const DefaultDeployConcurrency = 8
+
 // NewDevModeDeployer creates a new development mode deployer
 func NewDevModeDeployer(app *DevOpsApp, spaceID uuid.UUID) *DevModeDeployer {
 	return &DevModeDeployer{
 		app:           app,
 		dynamicClient: app.K8s.DynamicClient,
 		spaceID:       spaceID,
+		concurrency:   DefaultDeployConcurrency,
+	}
+}
+
+// SetConcurrency sets how many units DeploySpace deploys in parallel
+func (d *DevModeDeployer) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
 	}
+	d.concurrency = n
 }
 
 // DeployUnit deploys a single ConfigHub unit directly to Kubernetes
 func (d *DevModeDeployer) DeployUnit(unitID uuid.UUID) error {
+	return d.deployUnit(d.spaceID, unitID)
+}
+
+// deployUnit deploys a unit from the given space, which a filter may have
+// selected from outside the deployer's own
+func (d *DevModeDeployer) deployUnit(spaceID, unitID uuid.UUID) error {
 	d.app.Logger.Printf("🚀 [Dev Mode] Deploying unit %s directly to Kubernetes", unitID)
 
 	// Get unit from ConfigHub
-	unit, err := d.app.Cub.GetUnit(d.spaceID, unitID)
+	unit, err := d.app.Cub.GetUnit(spaceID, unitID)
 	if err != nil {
 		return fmt.Errorf("get unit: %w", err)
 	}
 
-	// Parse manifest from Data field
-	var manifest map[string]interface{}
-	if err := yaml.Unmarshal([]byte(unit.Data), &manifest); err != nil {
+	// Parse manifests from Data field; a unit may hold several documents
+	manifests, err := unit.Manifests()
+	if err != nil {
 		return fmt.Errorf("parse manifest: %w", err)
 	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("unit %s has no manifest", unit.Slug)
+	}
+
+	for _, manifest := range manifests {
+		if err := d.applyManifest(manifest, unit.Slug); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifiedApply reports how ApplyViaConfigHubThenVerify applied a unit
+type VerifiedApply struct {
+	UnitID        uuid.UUID
+	ViaWorker     bool       // False if no worker was running and the unit was applied directly
+	LiveState     *LiveState // Last live state polled from ConfigHub; nil for direct applies
+	DriftDetected bool
+}
+
+// SetApplyVerification sets how long ApplyViaConfigHubThenVerify waits for
+// the worker to report the apply, and how often it checks (default 2m, 2s)
+func (d *DevModeDeployer) SetApplyVerification(timeout, pollInterval time.Duration) {
+	d.verifyTimeout = timeout
+	d.verifyInterval = pollInterval
+}
+
+// ApplyViaConfigHubThenVerify applies a unit through ConfigHub and waits for
+// its worker to report the result, so the apply is observable in development.
+// Only live state recorded after the apply started counts: Status "applied"
+// succeeds and a LastError fails with that error. Drift is logged and reported.
+// When the space has no connected worker, ConfigHub can't deploy anything, so
+// the unit is applied directly with the dynamic client instead.
+func (d *DevModeDeployer) ApplyViaConfigHubThenVerify(ctx context.Context, unitID uuid.UUID) (*VerifiedApply, error) {
+	result := &VerifiedApply{UnitID: unitID}
+
+	workers, err := d.app.Cub.ListWorkersContext(ctx, d.spaceID)
+	if err != nil {
+		return nil, fmt.Errorf("list workers: %w", err)
+	}
+	if !hasConnectedWorker(workers) {
+		d.app.Logger.Printf("⚠️  [Dev Mode] No ConfigHub worker in space %s, applying unit %s directly", d.spaceID, unitID)
+		return result, d.DeployUnit(unitID)
+	}
+
+	timeout, interval := d.verifyTimeout, d.verifyInterval
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	if err := d.app.Cub.ApplyUnitContext(ctx, d.spaceID, unitID); err != nil {
+		return nil, fmt.Errorf("apply unit: %w", err)
+	}
+	result.ViaWorker = true
+	d.app.Logger.Printf("🚀 [Dev Mode] Applied unit %s via ConfigHub, waiting for the worker", unitID)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		state, err := d.app.Cub.GetUnitLiveStateContext(ctx, d.spaceID, unitID)
+		if err != nil && !isNotFound(err) && ctx.Err() == nil {
+			return result, fmt.Errorf("get live state: %w", err)
+		}
+		if state != nil {
+			result.LiveState = state
+			result.DriftDetected = state.DriftDetected
+			if !state.LastAppliedAt.Before(started) {
+				if state.LastError != "" {
+					return result, fmt.Errorf("apply unit %s failed: %s", unitID, state.LastError)
+				}
+				if strings.EqualFold(state.Status, "applied") {
+					if state.DriftDetected {
+						d.app.Logger.Printf("⚠️  [Dev Mode] Unit %s applied but drift detected", unitID)
+					}
+					d.app.Logger.Printf("✅ [Dev Mode] Unit %s applied in %v", unitID, time.Since(started).Round(time.Millisecond))
+					return result, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			status := "no live state"
+			if result.LiveState != nil {
+				status = fmt.Sprintf("status %q", result.LiveState.Status)
+			}
+			return result, fmt.Errorf("waiting for unit %s to apply (%s): %w", unitID, status, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasConnectedWorker reports whether any worker can pick up applies
+func hasConnectedWorker(workers []*Worker) bool {
+	for _, worker := range workers {
+		if worker.Condition != "Disconnected" {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceDiff is a structured diff between a unit's manifest and the live object
+type ResourceDiff struct {
+	UnitSlug  string
+	Kind      string
+	Name      string
+	Namespace string
+	Exists    bool // False if the object is not in the cluster yet
+	Changes   []FieldChange
+}
+
+// FieldChange is a single field difference, from the live object to ConfigHub
+type FieldChange struct {
+	Path    string      // Dotted field path, e.g. spec.template.spec.containers[0].image
+	Type    string      // added, removed, changed
+	Live    interface{} // Value in the cluster (nil if added)
+	Desired interface{} // Value in ConfigHub (nil if removed)
+}
+
+// HasChanges reports whether applying the unit would change the cluster
+func (rd *ResourceDiff) HasChanges() bool {
+	return !rd.Exists || len(rd.Changes) > 0
+}
+
+// ignoredDiffPaths are fields the API server owns, which never appear in manifests
+var ignoredDiffPaths = map[string]bool{
+	"status":                     true,
+	"metadata.uid":               true,
+	"metadata.resourceVersion":   true,
+	"metadata.generation":        true,
+	"metadata.creationTimestamp": true,
+	"metadata.managedFields":     true,
+	"metadata.selfLink":          true,
+	"metadata.annotations.deployment.kubernetes.io/revision":                true,
+	"metadata.annotations.kubectl.kubernetes.io/last-applied-configuration": true,
+}
+
+// DiffUnit compares a unit's ConfigHub manifest with the live object in Kubernetes
+func (d *DevModeDeployer) DiffUnit(unitID uuid.UUID) (*ResourceDiff, error) {
+	diff, _, err := d.diffUnit(unitID)
+	return diff, err
+}
+
+// DeployUnitWithConfirm shows the diff to confirm and only applies the unit if
+// it returns true. Units without changes are not applied and confirm is not called.
+func (d *DevModeDeployer) DeployUnitWithConfirm(unitID uuid.UUID, confirm func(*ResourceDiff) bool) error {
+	diff, manifest, err := d.diffUnit(unitID)
+	if err != nil {
+		return err
+	}
+
+	if !diff.HasChanges() {
+		d.app.Logger.Printf("ℹ️  [Dev Mode] %s is up to date, nothing to apply", diff.UnitSlug)
+		return nil
+	}
+
+	if !confirm(diff) {
+		d.app.Logger.Printf("⏭️  [Dev Mode] Skipped %s: not confirmed", diff.UnitSlug)
+		return nil
+	}
+
+	return d.applyManifest(manifest, diff.UnitSlug)
+}
+
+// diffUnit returns the diff along with the parsed manifest it was computed from
+func (d *DevModeDeployer) diffUnit(unitID uuid.UUID) (*ResourceDiff, map[string]interface{}, error) {
+	unit, err := d.app.Cub.GetUnit(d.spaceID, unitID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get unit: %w", err)
+	}
+
+	manifest, err := unit.Manifest()
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse manifest: %w", err)
+	}
 
-	return d.applyManifest(manifest, unit.Slug)
+	live, err := d.getLiveObject(context.Background(), manifest)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, nil, fmt.Errorf("get live object: %w", err)
+	}
+
+	diff := &ResourceDiff{
+		UnitSlug: unit.Slug,
+		Exists:   live != nil,
+	}
+	diff.Kind, _ = manifest["kind"].(string)
+	if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+		diff.Name, _ = metadata["name"].(string)
+		diff.Namespace, _ = metadata["namespace"].(string)
+	}
+
+	var liveObject map[string]interface{}
+	if live != nil {
+		liveObject = live.Object
+	}
+	diff.Changes = diffValues("", liveObject, manifest)
+
+	return diff, manifest, nil
 }
 
-// DeploySpace deploys all units in a ConfigHub space directly to Kubernetes
+// diffValues walks live and desired in parallel and records every differing leaf.
+// Fields only present in the cluster are usually server-side defaults, so they
+// are only reported as removed for labels and annotations.
+func diffValues(path string, live, desired interface{}) []FieldChange {
+	return diffFields(path, live, desired, false)
+}
+
+// diffFields is diffValues, optionally reporting every removed field, which is
+// what comparing two ConfigHub manifests needs
+func diffFields(path string, live, desired interface{}, allRemovals bool) []FieldChange {
+	if ignoredDiffPaths[path] {
+		return nil
+	}
+
+	liveMap, liveIsMap := live.(map[string]interface{})
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	if liveIsMap && desiredIsMap || live == nil && desiredIsMap || desired == nil && liveIsMap {
+		keys := make(map[string]bool)
+		for k := range liveMap {
+			keys[k] = true
+		}
+		for k := range desiredMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var changes []FieldChange
+		for _, k := range sorted {
+			changes = append(changes, diffFields(joinDiffPath(path, k), liveMap[k], desiredMap[k], allRemovals)...)
+		}
+		return changes
+	}
+
+	liveList, liveIsList := live.([]interface{})
+	desiredList, desiredIsList := desired.([]interface{})
+	if liveIsList && desiredIsList {
+		var changes []FieldChange
+		for i := 0; i < len(liveList) || i < len(desiredList); i++ {
+			var l, r interface{}
+			if i < len(liveList) {
+				l = liveList[i]
+			}
+			if i < len(desiredList) {
+				r = desiredList[i]
+			}
+			changes = append(changes, diffFields(fmt.Sprintf("%s[%d]", path, i), l, r, allRemovals)...)
+		}
+		return changes
+	}
+
+	switch {
+	case live == nil && desired == nil:
+		return nil
+	case live == nil:
+		return []FieldChange{{Path: path, Type: "added", Desired: desired}}
+	case desired == nil:
+		if !allRemovals && !strings.HasPrefix(path, "metadata.labels.") && !strings.HasPrefix(path, "metadata.annotations.") {
+			return nil
+		}
+		return []FieldChange{{Path: path, Type: "removed", Live: live}}
+	case isResourcesPath(path) && quantitiesEqual(live, desired):
+		return nil // The cluster canonicalizes quantities, e.g. 0.5 to 500m
+	case !reflect.DeepEqual(normalizeDiffValue(live), normalizeDiffValue(desired)):
+		return []FieldChange{{Path: path, Type: "changed", Live: live, Desired: desired}}
+	}
+	return nil
+}
+
+// isResourcesPath reports whether a diff path is under a container's resources
+func isResourcesPath(path string) bool {
+	return strings.HasPrefix(path, "resources.") || strings.Contains(path, ".resources.")
+}
+
+func joinDiffPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// normalizeDiffValue makes YAML ints and JSON int64/float64 numbers comparable
+func normalizeDiffValue(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
+
+// DeploySpace deploys all units in a space directly to Kubernetes. Units are
+// deployed in the phases of TopologicalApplyPhases, so namespaces, CRDs and
+// link targets are applied before the units that depend on them; units within
+// a phase are deployed concurrently (see SetConcurrency). A failed unit is
+// logged and does not stop the deployment.
 func (d *DevModeDeployer) DeploySpace() error {
 	d.app.Logger.Printf("🚀 [Dev Mode] Deploying all units from space %s", d.spaceID)
 	start := time.Now()
 
-	// List all units in space
-	units, err := d.app.Cub.ListUnits(ListUnitsParams{
+	// List all units in space, across pages
+	units, err := d.app.Cub.ListAllUnits(ListUnitsParams{
 		SpaceID: d.spaceID,
 	})
 	if err != nil {
 		return fmt.Errorf("list units: %w", err)
 	}
 
+	links, err := d.app.Cub.ListLinks(d.spaceID)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("list links: %w", err)
+	}
+
+	phases, err := TopologicalApplyPhases(units, links)
+	if err != nil {
+		return fmt.Errorf("order units: %w", err)
+	}
+
 	deployed := 0
 	failed := 0
 
-	for _, unit := range units {
-		if err := d.DeployUnit(unit.UnitID); err != nil {
-			d.app.Logger.Printf("⚠️  Failed to deploy %s: %v", unit.Slug, err)
-			failed++
-		} else {
-			deployed++
-		}
+	for i, phase := range phases {
+		d.app.Logger.Printf("📦 [Dev Mode] Phase %d/%d: %d unit(s)", i+1, len(phases), len(phase))
+		phaseFailed := d.deployPhase(phase)
+		deployed += len(phase) - phaseFailed
+		failed += phaseFailed
 	}
 
 	d.app.Logger.Printf("✅ [Dev Mode] Deployment complete: %d succeeded, %d failed in %v",
@@ -79,28 +421,53 @@ func (d *DevModeDeployer) DeploySpace() error {
 	return nil
 }
 
+// deployPhase deploys units concurrently, bounded by the deployer's
+// concurrency, and returns how many failed once all have finished
+func (d *DevModeDeployer) deployPhase(units []*Unit) int {
+	workers := d.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var failed int32
+
+	for _, unit := range units {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(unit *Unit) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := d.DeployUnit(unit.UnitID); err != nil {
+				d.app.Logger.Printf("⚠️  Failed to deploy %s: %v", unit.Slug, err)
+				atomic.AddInt32(&failed, 1)
+			}
+		}(unit)
+	}
+	wg.Wait()
+
+	return int(failed)
+}
+
 // DeployWithFilter deploys units matching a filter directly to Kubernetes
 func (d *DevModeDeployer) DeployWithFilter(filterID uuid.UUID) error {
 	d.app.Logger.Printf("🚀 [Dev Mode] Deploying units matching filter %s", filterID)
 
-	// Get filter and apply it
-	filter, err := d.app.Cub.GetFilter(d.spaceID, filterID)
-	if err != nil {
-		return fmt.Errorf("get filter: %w", err)
-	}
-
-	// List units using filter's WHERE clause
-	units, err := d.app.Cub.ListUnits(ListUnitsParams{
-		SpaceID: d.spaceID,
-		Where:   filter.Where,
-	})
+	// List units using the filter's WHERE clause
+	units, err := d.app.Cub.GetFilteredUnits(filterID, d.spaceID)
 	if err != nil {
 		return fmt.Errorf("list filtered units: %w", err)
 	}
 
 	deployed := 0
 	for _, unit := range units {
-		if err := d.DeployUnit(unit.UnitID); err != nil {
+		// The filter may select units from another space
+		spaceID := unit.SpaceID
+		if spaceID == uuid.Nil {
+			spaceID = d.spaceID
+		}
+		if err := d.deployUnit(spaceID, unit.UnitID); err != nil {
 			d.app.Logger.Printf("⚠️  Failed to deploy %s: %v", unit.Slug, err)
 		} else {
 			deployed++
@@ -126,7 +493,7 @@ func (d *DevModeDeployer) WatchAndSync(ctx context.Context, interval time.Durati
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			if err := d.syncChanges(lastRevisions); err != nil {
+			if err := d.syncChanges(ctx, lastRevisions); err != nil {
 				d.app.Logger.Printf("⚠️  Sync error: %v", err)
 			}
 		}
@@ -134,8 +501,8 @@ func (d *DevModeDeployer) WatchAndSync(ctx context.Context, interval time.Durati
 }
 
 // syncChanges syncs any changed units to Kubernetes
-func (d *DevModeDeployer) syncChanges(lastRevisions map[uuid.UUID]int64) error {
-	units, err := d.app.Cub.ListUnits(ListUnitsParams{
+func (d *DevModeDeployer) syncChanges(ctx context.Context, lastRevisions map[uuid.UUID]int64) error {
+	units, err := d.app.Cub.ListUnitsContext(ctx, ListUnitsParams{
 		SpaceID: d.spaceID,
 	})
 	if err != nil {
@@ -144,6 +511,10 @@ func (d *DevModeDeployer) syncChanges(lastRevisions map[uuid.UUID]int64) error {
 
 	changes := 0
 	for _, unit := range units {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		// Check if unit has changed
 		lastRev, exists := lastRevisions[unit.UnitID]
 		currentRev := unit.Version // Use Version field for revision tracking
@@ -220,27 +591,29 @@ func (d *DevModeDeployer) applyManifest(manifest map[string]interface{}, name st
 func (d *DevModeDeployer) parseGVR(apiVersion, kind string, manifest map[string]interface{}) (schema.GroupVersionResource, string, error) {
 	// Common resource mappings
 	resourceMap := map[string]string{
-		"Deployment":            "deployments",
-		"Service":               "services",
-		"ConfigMap":             "configmaps",
-		"Secret":                "secrets",
-		"StatefulSet":           "statefulsets",
-		"DaemonSet":             "daemonsets",
-		"Pod":                   "pods",
-		"Ingress":               "ingresses",
-		"ServiceAccount":        "serviceaccounts",
-		"Role":                  "roles",
-		"RoleBinding":           "rolebindings",
-		"ClusterRole":           "clusterroles",
-		"ClusterRoleBinding":    "clusterrolebindings",
-		"PersistentVolumeClaim": "persistentvolumeclaims",
-		"HorizontalPodAutoscaler": "horizontalpodautoscalers",
+		"Deployment":               "deployments",
+		"Service":                  "services",
+		"ConfigMap":                "configmaps",
+		"Secret":                   "secrets",
+		"StatefulSet":              "statefulsets",
+		"DaemonSet":                "daemonsets",
+		"Pod":                      "pods",
+		"Ingress":                  "ingresses",
+		"ServiceAccount":           "serviceaccounts",
+		"Role":                     "roles",
+		"RoleBinding":              "rolebindings",
+		"ClusterRole":              "clusterroles",
+		"ClusterRoleBinding":       "clusterrolebindings",
+		"PersistentVolumeClaim":    "persistentvolumeclaims",
+		"HorizontalPodAutoscaler":  "horizontalpodautoscalers",
+		"Namespace":                "namespaces",
+		"CustomResourceDefinition": "customresourcedefinitions",
 	}
 
 	resource, ok := resourceMap[kind]
 	if !ok {
 		// Try to pluralize by adding 's'
-		resource = kind + "s"
+		resource = strings.ToLower(kind) + "s"
 	}
 
 	// Parse group and version from apiVersion
@@ -270,15 +643,42 @@ func (d *DevModeDeployer) parseGVR(apiVersion, kind string, manifest map[string]
 	}, namespace, nil
 }
 
-// Rollback rolls back a deployment to a previous ConfigHub revision
+// Rollback rolls back a deployment to a previous ConfigHub revision. The old
+// data is written back to ConfigHub (the source of truth) and then applied.
 func (d *DevModeDeployer) Rollback(unitID uuid.UUID, targetRevision int) error {
 	d.app.Logger.Printf("⏮️  [Dev Mode] Rolling back unit %s to revision %d", unitID, targetRevision)
 
-	// In Dev Mode, rollback is instant - just get the old revision and apply it
-	// This would require ConfigHub to support revision history API
+	current, err := d.app.Cub.GetUnit(d.spaceID, unitID)
+	if err != nil {
+		return fmt.Errorf("get unit: %w", err)
+	}
 
-	// For now, just re-deploy current version
-	return d.DeployUnit(unitID)
+	previous, err := d.app.Cub.GetUnitAtRevision(d.spaceID, unitID, int64(targetRevision))
+	if err != nil {
+		return fmt.Errorf("get revision %d: %w", targetRevision, err)
+	}
+
+	_, err = d.app.Cub.UpdateUnit(d.spaceID, unitID, CreateUnitRequest{
+		Slug:           current.Slug,
+		DisplayName:    current.DisplayName,
+		Data:           previous.Data,
+		Labels:         current.Labels,
+		Annotations:    current.Annotations,
+		UpstreamUnitID: current.UpstreamUnitID,
+		SetIDs:         current.SetIDs,
+		TargetID:       current.TargetID,
+	})
+	if err != nil {
+		return fmt.Errorf("restore revision %d: %w", targetRevision, err)
+	}
+
+	// In Dev Mode, rollback is instant - apply the old revision directly
+	manifest, err := previous.Manifest()
+	if err != nil {
+		return fmt.Errorf("parse manifest: %w", err)
+	}
+
+	return d.applyManifest(manifest, current.Slug)
 }
 
 // ValidateDeployment validates that Kubernetes matches ConfigHub configuration
@@ -295,8 +695,8 @@ func (d *DevModeDeployer) ValidateDeployment() (bool, []string) {
 	var issues []string
 	for _, unit := range units {
 		// Parse manifest from Data field
-		var manifest map[string]interface{}
-		if err := yaml.Unmarshal([]byte(unit.Data), &manifest); err != nil {
+		manifest, err := unit.Manifest()
+		if err != nil {
 			issues = append(issues, fmt.Sprintf("%s: failed to parse manifest: %v", unit.Slug, err))
 			continue
 		}
@@ -320,11 +720,153 @@ func (d *DevModeDeployer) ValidateDeployment() (bool, []string) {
 	return valid, issues
 }
 
+// DetectDrift compares each unit's desired replicas, container images and
+// resource requests with the live object, for RenderStateComparisonTable.
+// A live object that can't be read is reported in its unit's ActualState.
+func (d *DevModeDeployer) DetectDrift(ctx context.Context) ([]ResourceState, error) {
+	units, err := d.app.Cub.ListAllUnitsContext(ctx, ListUnitsParams{SpaceID: d.spaceID})
+	if err != nil {
+		return nil, fmt.Errorf("list units: %w", err)
+	}
+
+	var states []ResourceState
+	for _, unit := range units {
+		manifest, err := unit.Manifest()
+		if err != nil {
+			d.app.Logger.Printf("⚠️  Skipping %s: failed to parse manifest: %v", unit.Slug, err)
+			continue
+		}
+
+		state := ResourceState{
+			Name:             unit.Slug,
+			LastSyncTime:     time.Now(),
+			ConfigHubVersion: unit.Version,
+		}
+
+		live, err := d.getLiveObject(ctx, manifest)
+		if apierrors.IsNotFound(err) {
+			state.DesiredState = "present"
+			state.ActualState = "missing"
+			state.Drift = true
+			states = append(states, state)
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// One unreadable object shouldn't hide the drift of the others
+			d.app.Logger.Printf("⚠️  Could not read live object for %s: %v", unit.Slug, err)
+			state.DesiredState = "present"
+			state.ActualState = fmt.Sprintf("error: %v", err)
+			states = append(states, state)
+			continue
+		}
+		state.KubernetesVersion = live.GetResourceVersion()
+
+		drifted := driftedFields(manifest, live.Object)
+		if len(drifted) == 0 {
+			state.DesiredState = "in sync"
+			state.ActualState = "in sync"
+		} else {
+			var desired, actual []string
+			for _, field := range drifted {
+				desired = append(desired, fmt.Sprintf("%s=%v", field.Path, field.Desired))
+				actual = append(actual, fmt.Sprintf("%s=%v", field.Path, field.Live))
+			}
+			state.DesiredState = strings.Join(desired, ", ")
+			state.ActualState = strings.Join(actual, ", ")
+			state.Drift = true
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// driftedFields returns replica, image and resource request differences.
+// Paths are short (replicas, <container>.image, <container>.cpu) for display.
+func driftedFields(desired, live map[string]interface{}) []FieldChange {
+	var drifted []FieldChange
+
+	if want, ok, _ := unstructured.NestedFieldNoCopy(desired, "spec", "replicas"); ok {
+		got, _, _ := unstructured.NestedFieldNoCopy(live, "spec", "replicas")
+		if !reflect.DeepEqual(normalizeDiffValue(want), normalizeDiffValue(got)) {
+			drifted = append(drifted, FieldChange{Path: "replicas", Type: "changed", Live: got, Desired: want})
+		}
+	}
+
+	liveContainers := make(map[string]map[string]interface{})
+	for _, c := range podContainers(live) {
+		name, _ := c["name"].(string)
+		liveContainers[name] = c
+	}
+
+	for _, want := range podContainers(desired) {
+		name, _ := want["name"].(string)
+		got, ok := liveContainers[name]
+		if !ok {
+			drifted = append(drifted, FieldChange{Path: name, Type: "added", Desired: "container"})
+			continue
+		}
+
+		if image, _ := want["image"].(string); image != "" && image != got["image"] {
+			drifted = append(drifted, FieldChange{Path: name + ".image", Type: "changed", Live: got["image"], Desired: image})
+		}
+
+		// NoCopy accessors: YAML-decoded manifests hold int values the copying ones reject
+		wantRequestsField, _, _ := unstructured.NestedFieldNoCopy(want, "resources", "requests")
+		gotRequestsField, _, _ := unstructured.NestedFieldNoCopy(got, "resources", "requests")
+		wantRequests, _ := wantRequestsField.(map[string]interface{})
+		gotRequests, _ := gotRequestsField.(map[string]interface{})
+		for _, resourceName := range []string{"cpu", "memory"} {
+			w, ok := wantRequests[resourceName]
+			if !ok {
+				continue
+			}
+			if !quantitiesEqual(w, gotRequests[resourceName]) {
+				drifted = append(drifted, FieldChange{
+					Path:    name + "." + resourceName,
+					Type:    "changed",
+					Live:    gotRequests[resourceName],
+					Desired: w,
+				})
+			}
+		}
+	}
+
+	return drifted
+}
+
+// podContainers returns the containers of a workload's pod template, or of a bare Pod
+func podContainers(obj map[string]interface{}) []map[string]interface{} {
+	field, ok, _ := unstructured.NestedFieldNoCopy(obj, "spec", "template", "spec", "containers")
+	if !ok {
+		field, _, _ = unstructured.NestedFieldNoCopy(obj, "spec", "containers")
+	}
+	containers, _ := field.([]interface{})
+
+	var result []map[string]interface{}
+	for _, c := range containers {
+		if m, ok := c.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// quantitiesEqual compares resource quantities semantically, so 0.5 equals 500m
+func quantitiesEqual(a, b interface{}) bool {
+	qa, errA := resource.ParseQuantity(fmt.Sprintf("%v", a))
+	qb, errB := resource.ParseQuantity(fmt.Sprintf("%v", b))
+	if errA != nil || errB != nil || b == nil {
+		return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+	}
+	return qa.Cmp(qb) == 0
+}
+
 // resourceExists checks if a resource exists in Kubernetes
 func (d *DevModeDeployer) resourceExists(manifest map[string]interface{}) (bool, error) {
-	apiVersion, _ := manifest["apiVersion"].(string)
-	kind, _ := manifest["kind"].(string)
-
 	metadata, ok := manifest["metadata"].(map[string]interface{})
 	if !ok {
 		return false, fmt.Errorf("missing metadata")
@@ -335,20 +877,33 @@ func (d *DevModeDeployer) resourceExists(manifest map[string]interface{}) (bool,
 		return false, fmt.Errorf("missing name in metadata")
 	}
 
-	gvr, namespace, err := d.parseGVR(apiVersion, kind, manifest)
-	if err != nil {
-		return false, err
+	if _, err := d.getLiveObject(context.Background(), manifest); err != nil {
+		return false, nil // Resource doesn't exist
 	}
+	return true, nil
+}
 
-	ctx := context.Background()
-	if namespace == "" {
-		_, err = d.dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
-	} else {
-		_, err = d.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+// getLiveObject fetches the cluster object a manifest describes
+func (d *DevModeDeployer) getLiveObject(ctx context.Context, manifest map[string]interface{}) (*unstructured.Unstructured, error) {
+	apiVersion, _ := manifest["apiVersion"].(string)
+	kind, _ := manifest["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		return nil, fmt.Errorf("missing apiVersion or kind in manifest")
+	}
+
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("missing name in metadata")
 	}
 
+	gvr, namespace, err := d.parseGVR(apiVersion, kind, manifest)
 	if err != nil {
-		return false, nil // Resource doesn't exist
+		return nil, err
 	}
-	return true, nil
-}
\ No newline at end of file
+
+	if namespace == "" {
+		return d.dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	}
+	return d.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -0,0 +1,640 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const diffTestManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: default
+  labels:
+    app: web
+data:
+  LOG_LEVEL: debug
+  WORKERS: "4"
+`
+
+// newDiffTestDeployer serves diffTestManifest from a fake ConfigHub and backs
+// the deployer with a fake cluster holding the given objects
+func newDiffTestDeployer(t *testing.T, objects ...k8sruntime.Object) *DevModeDeployer {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Unit{Slug: "app-config", Data: diffTestManifest})
+	}))
+	t.Cleanup(server.Close)
+
+	app := &DevOpsApp{
+		Cub:    NewConfigHubClient(server.URL, "test-token"),
+		Logger: log.New(io.Discard, "", 0),
+	}
+	return &DevModeDeployer{
+		app:           app,
+		dynamicClient: dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme(), objects...),
+		spaceID:       uuid.New(),
+	}
+}
+
+func liveConfigMap() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "app-config",
+			"namespace":       "default",
+			"resourceVersion": "42",
+			"labels": map[string]interface{}{
+				"app":  "web",
+				"tier": "frontend",
+			},
+		},
+		"data": map[string]interface{}{
+			"LOG_LEVEL": "info",
+			"WORKERS":   "4",
+		},
+	}}
+}
+
+// Test diffing ConfigHub manifests against live objects
+func TestDevModeDeployerDiff(t *testing.T) {
+	t.Run("DiffValues", func(t *testing.T) {
+		live := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas":                int64(3),
+				"progressDeadlineSeconds": int64(600), // Server default
+				"containers": []interface{}{
+					map[string]interface{}{
+						"image": "nginx:1.24",
+						"resources": map[string]interface{}{
+							"requests": map[string]interface{}{"cpu": "500m", "memory": "1Gi"},
+							"limits":   map[string]interface{}{"memory": "2Gi"},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{"readyReplicas": int64(3)},
+		}
+		desired := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 3,
+				"paused":   true,
+				"containers": []interface{}{
+					map[string]interface{}{
+						"image": "nginx:1.25",
+						"resources": map[string]interface{}{
+							"requests": map[string]interface{}{"cpu": 0.5, "memory": "1024Mi"},
+							"limits":   map[string]interface{}{"memory": "3Gi"},
+						},
+					},
+				},
+			},
+		}
+
+		changes := diffValues("", live, desired)
+		assert.Equal(t, []FieldChange{
+			{Path: "spec.containers[0].image", Type: "changed", Live: "nginx:1.24", Desired: "nginx:1.25"},
+			{Path: "spec.containers[0].resources.limits.memory", Type: "changed", Live: "2Gi", Desired: "3Gi"},
+			{Path: "spec.paused", Type: "added", Desired: true},
+		}, changes, "0.5 equals 500m and 1024Mi equals 1Gi")
+	})
+
+	t.Run("DiffUnitAgainstLiveObject", func(t *testing.T) {
+		d := newDiffTestDeployer(t, liveConfigMap())
+
+		diff, err := d.DiffUnit(uuid.New())
+		require.NoError(t, err)
+		assert.True(t, diff.Exists)
+		assert.Equal(t, "ConfigMap", diff.Kind)
+		assert.Equal(t, []FieldChange{
+			{Path: "data.LOG_LEVEL", Type: "changed", Live: "info", Desired: "debug"},
+			{Path: "metadata.labels.tier", Type: "removed", Live: "frontend"},
+		}, diff.Changes)
+
+		output := RenderResourceDiffTable(diff)
+		assert.Contains(t, output, "data.LOG_LEVEL")
+		assert.Contains(t, output, "metadata.labels.tier")
+	})
+
+	t.Run("DiffUnitNotDeployed", func(t *testing.T) {
+		d := newDiffTestDeployer(t)
+
+		diff, err := d.DiffUnit(uuid.New())
+		require.NoError(t, err)
+		assert.False(t, diff.Exists)
+		assert.True(t, diff.HasChanges())
+		assert.Contains(t, RenderResourceDiffTable(diff), "(not deployed)")
+	})
+
+	t.Run("ConfirmGatesApply", func(t *testing.T) {
+		d := newDiffTestDeployer(t, liveConfigMap())
+
+		var shown *ResourceDiff
+		err := d.DeployUnitWithConfirm(uuid.New(), func(diff *ResourceDiff) bool {
+			shown = diff
+			return false
+		})
+		require.NoError(t, err)
+		require.NotNil(t, shown)
+
+		diff, err := d.DiffUnit(uuid.New())
+		require.NoError(t, err)
+		assert.Len(t, diff.Changes, 2, "declined diff must not be applied")
+
+		err = d.DeployUnitWithConfirm(uuid.New(), func(*ResourceDiff) bool { return true })
+		require.NoError(t, err)
+
+		diff, err = d.DiffUnit(uuid.New())
+		require.NoError(t, err)
+		assert.False(t, diff.HasChanges(), "unexpected changes: %v", diff.Changes)
+
+		called := false
+		err = d.DeployUnitWithConfirm(uuid.New(), func(*ResourceDiff) bool {
+			called = true
+			return true
+		})
+		require.NoError(t, err)
+		assert.False(t, called, "confirm should not be called without changes")
+	})
+}
+
+// Test drift detection against live workloads
+func TestDevModeDeployerDetectDrift(t *testing.T) {
+	deployment := func(name string, replicas int64, image, cpu string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            name,
+				"namespace":       "default",
+				"resourceVersion": "7",
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": image,
+								"resources": map[string]interface{}{
+									"requests": map[string]interface{}{"cpu": cpu, "memory": "256Mi"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}}
+	}
+	manifest := func(name string, replicas int, image, cpu string) string {
+		return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+  namespace: default
+spec:
+  replicas: %d
+  template:
+    spec:
+      containers:
+      - name: app
+        image: %s
+        resources:
+          requests:
+            cpu: %s
+            memory: 256Mi
+`, name, replicas, image, cpu)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "synced", Version: 3, Data: manifest("synced", 2, "nginx:1.25", "0.5")}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "drifted", Version: 5, Data: manifest("drifted", 3, "nginx:1.25", "250m")}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "forbidden", Data: manifest("forbidden", 1, "nginx:1.25", "100m")}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "missing", Data: manifest("missing", 1, "nginx:1.25", "100m")}},
+		})
+	}))
+	defer server.Close()
+
+	client := dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme(),
+		deployment("synced", 2, "nginx:1.25", "500m"),
+		deployment("drifted", 2, "nginx:1.24", "250m"),
+	)
+	client.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		if action.(k8stesting.GetAction).GetName() != "forbidden" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(action.GetResource().GroupResource(), "forbidden", fmt.Errorf("RBAC denied"))
+	})
+	d := &DevModeDeployer{
+		app: &DevOpsApp{
+			Cub:    NewConfigHubClient(server.URL, "test-token"),
+			Logger: log.New(io.Discard, "", 0),
+		},
+		dynamicClient: client,
+		spaceID:       uuid.New(),
+	}
+
+	states, err := d.DetectDrift(context.Background())
+	require.NoError(t, err)
+	require.Len(t, states, 4)
+
+	assert.False(t, states[0].Drift, "0.5 and 500m are the same quantity")
+	assert.Equal(t, "7", states[0].KubernetesVersion)
+
+	assert.True(t, states[1].Drift)
+	assert.Equal(t, int64(5), states[1].ConfigHubVersion)
+	assert.Equal(t, "replicas=3, app.image=nginx:1.25", states[1].DesiredState)
+	assert.Equal(t, "replicas=2, app.image=nginx:1.24", states[1].ActualState)
+
+	assert.False(t, states[2].Drift, "unknown, not drifted")
+	assert.Contains(t, states[2].ActualState, "error: ")
+	assert.Contains(t, states[2].ActualState, "RBAC denied")
+
+	assert.True(t, states[3].Drift, "the scan continues past the error")
+	assert.Equal(t, "missing", states[3].ActualState)
+
+	assert.Contains(t, RenderStateComparisonTable(states), "drifted")
+}
+
+// Test applying through ConfigHub and waiting for the worker's live state
+func TestApplyViaConfigHubThenVerify(t *testing.T) {
+	// newDeployer fakes ConfigHub with the given workers and a live state
+	// sequence; the last state repeats once the sequence runs out
+	newDeployer := func(t *testing.T, workers []Worker, states ...LiveState) (*DevModeDeployer, *int32) {
+		var applies, polls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/worker"):
+				json.NewEncoder(w).Encode(workers)
+			case strings.HasSuffix(r.URL.Path, "/apply"):
+				atomic.AddInt32(&applies, 1)
+			case strings.HasSuffix(r.URL.Path, "/live-state"):
+				i := int(atomic.AddInt32(&polls, 1)) - 1
+				if i >= len(states) {
+					i = len(states) - 1
+				}
+				json.NewEncoder(w).Encode(states[i])
+			default:
+				json.NewEncoder(w).Encode(Unit{Slug: "app-config", Data: diffTestManifest})
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		d := &DevModeDeployer{
+			app:           &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)},
+			dynamicClient: dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme()),
+			spaceID:       uuid.New(),
+		}
+		d.SetApplyVerification(200*time.Millisecond, 5*time.Millisecond)
+		return d, &applies
+	}
+	ready := []Worker{{Slug: "dev-worker", Condition: "Ready"}}
+	earlier := time.Now().Add(-time.Hour)
+	later := time.Now().Add(time.Hour)
+
+	t.Run("Applied", func(t *testing.T) {
+		d, applies := newDeployer(t, ready,
+			LiveState{Status: "Applied", LastAppliedAt: earlier}, // Previous apply
+			LiveState{Status: "Applying", LastAppliedAt: earlier},
+			LiveState{Status: "Applied", LastAppliedAt: later, DriftDetected: true},
+		)
+		result, err := d.ApplyViaConfigHubThenVerify(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.True(t, result.ViaWorker)
+		assert.True(t, result.DriftDetected)
+		assert.Equal(t, "Applied", result.LiveState.Status)
+		assert.Equal(t, int32(1), atomic.LoadInt32(applies))
+	})
+
+	t.Run("LastError", func(t *testing.T) {
+		d, _ := newDeployer(t, ready, LiveState{Status: "Failed", LastAppliedAt: later, LastError: "admission webhook denied the request"})
+		result, err := d.ApplyViaConfigHubThenVerify(context.Background(), uuid.New())
+		assert.ErrorContains(t, err, "admission webhook denied the request")
+		assert.Equal(t, "Failed", result.LiveState.Status)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		d, _ := newDeployer(t, ready, LiveState{Status: "Applying", LastAppliedAt: earlier})
+		_, err := d.ApplyViaConfigHubThenVerify(context.Background(), uuid.New())
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, `status "Applying"`)
+	})
+
+	t.Run("NoWorkerAppliesDirectly", func(t *testing.T) {
+		d, applies := newDeployer(t, []Worker{{Slug: "old-worker", Condition: "Disconnected"}})
+		result, err := d.ApplyViaConfigHubThenVerify(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.False(t, result.ViaWorker)
+		assert.Zero(t, atomic.LoadInt32(applies))
+
+		diff, err := d.DiffUnit(uuid.New())
+		require.NoError(t, err)
+		assert.True(t, diff.Exists, "applied with the dynamic client")
+	})
+}
+
+// Test deploying a space in dependency phases with concurrent units
+func TestDevModeDeploySpace(t *testing.T) {
+	namespace := newKindUnit("apps", "Namespace")
+	units := []*Unit{namespace}
+	for i := 0; i < 6; i++ {
+		slug := fmt.Sprintf("web-%d", i)
+		units = append(units, &Unit{
+			UnitID: uuid.New(),
+			Slug:   slug,
+			Data:   fmt.Sprintf("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: %s\n  namespace: apps\n", slug),
+		})
+	}
+	units = append(units, &Unit{UnitID: uuid.New(), Slug: "broken", Data: "apiVersion: v1\nmetadata:\n  name: broken\n"})
+	byID := make(map[string]*Unit)
+	for _, unit := range units {
+		byID[unit.UnitID.String()] = unit
+	}
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/link"):
+			w.Write([]byte("[]"))
+		case strings.HasSuffix(r.URL.Path, "/unit"):
+			wrapped := make([]map[string]*Unit, len(units))
+			for i, unit := range units {
+				wrapped[i] = map[string]*Unit{"Unit": unit}
+			}
+			json.NewEncoder(w).Encode(wrapped)
+		default:
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			parts := strings.Split(r.URL.Path, "/")
+			json.NewEncoder(w).Encode(byID[parts[len(parts)-1]])
+		}
+	}))
+	defer server.Close()
+
+	var logged strings.Builder
+	var mu sync.Mutex
+	client := dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme())
+	var created []string
+	client.PrependReactor("create", "*", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, action.GetResource().Resource)
+		return false, nil, nil
+	})
+	deployer := &DevModeDeployer{
+		app: &DevOpsApp{
+			Cub:    NewConfigHubClient(server.URL, "test-token"),
+			Logger: log.New(&logged, "", 0),
+		},
+		dynamicClient: client,
+		spaceID:       uuid.New(),
+	}
+	deployer.SetConcurrency(3)
+
+	require.NoError(t, deployer.DeploySpace())
+	require.Len(t, created, 7)
+	assert.Equal(t, "namespaces", created[0], "namespace is applied before the deployments in it")
+	assert.Greater(t, maxInFlight, int32(1))
+	assert.LessOrEqual(t, maxInFlight, int32(3))
+	assert.Contains(t, logged.String(), "Failed to deploy broken")
+	assert.Contains(t, logged.String(), "Deployment complete: 7 succeeded, 1 failed")
+}
+
+// Test deploying a space larger than one page of units
+func TestDevModeDeploySpacePages(t *testing.T) {
+	units := make([]*Unit, defaultUnitPageSize+20)
+	byID := make(map[string]*Unit)
+	for i := range units {
+		units[i] = &Unit{
+			UnitID: uuid.New(),
+			Slug:   fmt.Sprintf("config-%03d", i),
+			Data:   fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config-%03d\n  namespace: default\n", i),
+		}
+		byID[units[i].UnitID.String()] = units[i]
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/link"):
+			w.Write([]byte("[]"))
+		case strings.HasSuffix(r.URL.Path, "/unit"):
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			wrapped := []map[string]*Unit{}
+			for _, unit := range units[min(offset, len(units)):min(offset+limit, len(units))] {
+				wrapped = append(wrapped, map[string]*Unit{"Unit": unit})
+			}
+			json.NewEncoder(w).Encode(wrapped)
+		default:
+			parts := strings.Split(r.URL.Path, "/")
+			json.NewEncoder(w).Encode(byID[parts[len(parts)-1]])
+		}
+	}))
+	defer server.Close()
+
+	var logged strings.Builder
+	deployer := &DevModeDeployer{
+		app: &DevOpsApp{
+			Cub:    NewConfigHubClient(server.URL, "test-token"),
+			Logger: log.New(&logged, "", 0),
+		},
+		dynamicClient: dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme()),
+		spaceID:       uuid.New(),
+	}
+	deployer.SetConcurrency(8)
+
+	require.NoError(t, deployer.DeploySpace())
+	assert.Contains(t, logged.String(), fmt.Sprintf("Deployment complete: %d succeeded, 0 failed", len(units)))
+	_, err := deployer.dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("default").Get(context.Background(), units[len(units)-1].Slug, metav1.GetOptions{})
+	assert.NoError(t, err, "units past the first page are applied")
+}
+
+// Test deploying the units a filter selects from another space
+func TestDevModeDeployWithFilter(t *testing.T) {
+	spaceID, otherID, filterID := uuid.New(), uuid.New(), uuid.New()
+	unit := &Unit{
+		UnitID:  uuid.New(),
+		SpaceID: otherID,
+		Slug:    "web",
+		Data:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: default\n",
+		Labels:  map[string]string{"tier": "frontend"},
+	}
+
+	var gets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/space/%s/filter/%s", spaceID, filterID):
+			json.NewEncoder(w).Encode(Filter{FilterID: filterID, Slug: "frontend", From: "Unit", FromSpaceID: &otherID, Where: "Labels.tier = 'frontend'", Select: []string{"Labels"}})
+		case fmt.Sprintf("/space/%s/unit", otherID):
+			json.NewEncoder(w).Encode([]map[string]*Unit{{"Unit": unit}})
+		case fmt.Sprintf("/space/%s/unit/%s", otherID, unit.UnitID):
+			gets = append(gets, r.URL.Path)
+			json.NewEncoder(w).Encode(unit)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var logged strings.Builder
+	deployer := &DevModeDeployer{
+		app: &DevOpsApp{
+			Cub:    NewConfigHubClient(server.URL, "test-token"),
+			Logger: log.New(&logged, "", 0),
+		},
+		dynamicClient: dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme()),
+		spaceID:       spaceID,
+	}
+
+	require.NoError(t, deployer.DeployWithFilter(filterID))
+	assert.Len(t, gets, 1, "the unit is fetched from the filter's space")
+	assert.Contains(t, logged.String(), "Deployed 1/1 units matching filter")
+
+	units, err := deployer.app.Cub.GetFilteredUnits(filterID, spaceID)
+	require.NoError(t, err)
+	require.Len(t, units, 1)
+	assert.Equal(t, "web", units[0].Slug, "Slug is always selected")
+	assert.Equal(t, otherID, units[0].SpaceID)
+}
+
+// Test rolling a unit back to an earlier revision
+func TestDevModeRollback(t *testing.T) {
+	spaceID, unitID, upstreamID := uuid.New(), uuid.New(), uuid.New()
+	current := &Unit{
+		UnitID:         unitID,
+		SpaceID:        spaceID,
+		Slug:           "app-config",
+		DisplayName:    "App Config",
+		Data:           diffTestManifest,
+		Labels:         map[string]string{"app": "web", "owner": "platform"},
+		UpstreamUnitID: &upstreamID,
+	}
+	previous := &Unit{
+		UnitID: unitID,
+		Slug:   "app-config-old",
+		Data:   strings.Replace(diffTestManifest, "LOG_LEVEL: debug", "LOG_LEVEL: warn", 1),
+		Labels: map[string]string{"app": "web"},
+	}
+
+	// rollbackServer fakes ConfigHub holding revision 3 of the unit;
+	// failUpdate rejects the restore
+	rollbackServer := func(t *testing.T, failUpdate bool) (*DevModeDeployer, *[]CreateUnitRequest) {
+		var updates []CreateUnitRequest
+		unitPath := fmt.Sprintf("/space/%s/unit/%s", spaceID, unitID)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == unitPath:
+				json.NewEncoder(w).Encode(current)
+			case r.Method == http.MethodGet && r.URL.Path == unitPath+"/revisions":
+				json.NewEncoder(w).Encode([]UnitRevision{
+					{Revision: 3, UnitID: unitID, Author: "alice", DiffSummary: "+1 -1 lines"},
+					{Revision: 4, UnitID: unitID, Author: "bob"},
+				})
+			case r.Method == http.MethodGet && r.URL.Path == unitPath+"/revisions/3":
+				json.NewEncoder(w).Encode(previous)
+			case r.Method == http.MethodPut && r.URL.Path == unitPath && !failUpdate:
+				var req CreateUnitRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				updates = append(updates, req)
+				json.NewEncoder(w).Encode(Unit{UnitID: unitID, Slug: req.Slug, Data: req.Data})
+			case r.Method == http.MethodPut:
+				http.Error(w, "rejected", http.StatusBadRequest)
+			default:
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		deployer := &DevModeDeployer{
+			app: &DevOpsApp{
+				Cub:    NewConfigHubClient(server.URL, "test-token"),
+				Logger: log.New(io.Discard, "", 0),
+			},
+			dynamicClient: dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme(), liveConfigMap()),
+			spaceID:       spaceID,
+		}
+		return deployer, &updates
+	}
+
+	t.Run("Revisions", func(t *testing.T) {
+		deployer, _ := rollbackServer(t, false)
+		revisions, err := deployer.app.Cub.ListUnitRevisions(spaceID, unitID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, int64(3), revisions[0].Revision)
+		assert.Equal(t, "+1 -1 lines", revisions[0].DiffSummary)
+
+		unit, err := deployer.app.Cub.GetUnitAtRevision(spaceID, unitID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, previous.Data, unit.Data)
+
+		_, err = deployer.app.Cub.GetUnitAtRevision(spaceID, unitID, 9)
+		assert.Error(t, err)
+	})
+
+	t.Run("Restored", func(t *testing.T) {
+		deployer, updates := rollbackServer(t, false)
+		require.NoError(t, deployer.Rollback(unitID, 3))
+
+		require.Len(t, *updates, 1)
+		update := (*updates)[0]
+		assert.Equal(t, previous.Data, update.Data, "the old configuration is restored")
+		assert.Equal(t, "app-config", update.Slug, "current metadata is kept")
+		assert.Equal(t, "App Config", update.DisplayName)
+		assert.Equal(t, current.Labels, update.Labels)
+		assert.Equal(t, &upstreamID, update.UpstreamUnitID)
+
+		live, err := deployer.dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+			Namespace("default").Get(context.Background(), "app-config", metav1.GetOptions{})
+		require.NoError(t, err)
+		level, _, _ := unstructured.NestedString(live.Object, "data", "LOG_LEVEL")
+		assert.Equal(t, "warn", level, "the old revision is applied to the cluster")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		deployer, updates := rollbackServer(t, false)
+		assert.ErrorContains(t, deployer.Rollback(unitID, 9), "get revision 9")
+		assert.Empty(t, *updates, "nothing is restored without the revision")
+		assert.ErrorContains(t, deployer.Rollback(uuid.New(), 3), "get unit")
+
+		deployer, _ = rollbackServer(t, true)
+		assert.ErrorContains(t, deployer.Rollback(unitID, 3), "restore revision 3")
+		live, err := deployer.dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+			Namespace("default").Get(context.Background(), "app-config", metav1.GetOptions{})
+		require.NoError(t, err)
+		level, _, _ := unstructured.NestedString(live.Object, "data", "LOG_LEVEL")
+		assert.Equal(t, "info", level, "the cluster is untouched when ConfigHub rejects the restore")
+	})
+}
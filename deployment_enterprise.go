@@ -1,10 +1,12 @@
 package sdk
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -22,6 +24,8 @@ type EnterpriseModeDeployer struct {
 	gitBranch   string
 	gitopsPath  string
 	gitopsTool  string // "flux" or "argo"
+	workDir     string // Git checkout directory; commands run here
+	dryRun      bool   // Log commands instead of executing them
 }
 
 // NewEnterpriseModeDeployer creates a new enterprise mode deployer
@@ -36,14 +40,26 @@ func NewEnterpriseModeDeployer(app *DevOpsApp, spaceID uuid.UUID, gitRepo, branc
 	}
 }
 
+// SetWorkDir sets the directory the Git repository is checked out into.
+// Commands and exported manifests are relative to it.
+func (e *EnterpriseModeDeployer) SetWorkDir(dir string) {
+	e.workDir = dir
+}
+
+// SetDryRun makes the deployer log git, flux, argocd and kubectl commands
+// instead of executing them
+func (e *EnterpriseModeDeployer) SetDryRun(dryRun bool) {
+	e.dryRun = dryRun
+}
+
 // detectGitOpsTool detects whether Flux or Argo is installed
 func detectGitOpsTool() string {
 	// Check for Flux
-	if _, err := os.Stat("/usr/local/bin/flux"); err == nil {
+	if _, err := exec.LookPath("flux"); err == nil {
 		return "flux"
 	}
 	// Check for Argo
-	if _, err := os.Stat("/usr/local/bin/argocd"); err == nil {
+	if _, err := exec.LookPath("argocd"); err == nil {
 		return "argo"
 	}
 	// Default to Flux
@@ -115,14 +131,31 @@ func (e *EnterpriseModeDeployer) DeploySpace() error {
 	return nil
 }
 
-// exportUnitToGit exports a ConfigHub unit as a YAML file in the Git repository
+// exportUnitToGit exports a ConfigHub unit as YAML files in the Git
+// repository, one file per document
 func (e *EnterpriseModeDeployer) exportUnitToGit(unit Unit) error {
-	// Parse manifest from Data field
-	var manifest map[string]interface{}
-	if err := yaml.Unmarshal([]byte(unit.Data), &manifest); err != nil {
+	// Parse manifests from Data field
+	manifests, err := unit.Manifests()
+	if err != nil {
 		return fmt.Errorf("parse manifest: %w", err)
 	}
 
+	for i, manifest := range manifests {
+		defaultName := unit.Slug
+		if len(manifests) > 1 {
+			defaultName = fmt.Sprintf("%s-%d", unit.Slug, i)
+		}
+		if err := e.exportManifestToGit(unit, manifest, defaultName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportManifestToGit writes one document of a unit to
+// <gitopsPath>/<namespace>/<kind>/<name>.yaml, using defaultName for
+// documents without metadata.name
+func (e *EnterpriseModeDeployer) exportManifestToGit(unit Unit, manifest map[string]interface{}, defaultName string) error {
 	// Determine file path based on resource type
 	kind, _ := manifest["kind"].(string)
 	metadata, _ := manifest["metadata"].(map[string]interface{})
@@ -130,7 +163,7 @@ func (e *EnterpriseModeDeployer) exportUnitToGit(unit Unit) error {
 	namespace, _ := metadata["namespace"].(string)
 
 	if name == "" {
-		name = unit.Slug
+		name = defaultName
 	}
 
 	// Create directory structure: manifests/namespace/kind/
@@ -142,6 +175,7 @@ func (e *EnterpriseModeDeployer) exportUnitToGit(unit Unit) error {
 	}
 
 	// Ensure directory exists
+	filePath = filepath.Join(e.workDir, filePath)
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("create directory: %w", err)
@@ -183,15 +217,14 @@ func (e *EnterpriseModeDeployer) exportUnitToGit(unit Unit) error {
 // ensureGitRepo ensures the Git repository is cloned and up to date
 func (e *EnterpriseModeDeployer) ensureGitRepo() error {
 	// Check if repo exists
-	if _, err := os.Stat(filepath.Join(".git")); os.IsNotExist(err) {
+	if _, err := os.Stat(filepath.Join(e.workDir, ".git")); os.IsNotExist(err) {
 		// Clone repository
-		cmd := fmt.Sprintf("git clone -b %s %s .", e.gitBranch, e.gitRepo)
-		if err := e.runGitCommand(cmd); err != nil {
+		if err := e.runGitCommand("clone", "-b", e.gitBranch, e.gitRepo, "."); err != nil {
 			return fmt.Errorf("clone repo: %w", err)
 		}
 	} else {
 		// Pull latest changes
-		if err := e.runGitCommand("git pull origin " + e.gitBranch); err != nil {
+		if err := e.runGitCommand("pull", "origin", e.gitBranch); err != nil {
 			return fmt.Errorf("pull changes: %w", err)
 		}
 	}
@@ -201,17 +234,17 @@ func (e *EnterpriseModeDeployer) ensureGitRepo() error {
 // commitAndPush commits changes and pushes to Git
 func (e *EnterpriseModeDeployer) commitAndPush(message string) error {
 	// Add all changes
-	if err := e.runGitCommand("git add " + e.gitopsPath); err != nil {
+	if err := e.runGitCommand("add", e.gitopsPath); err != nil {
 		return fmt.Errorf("git add: %w", err)
 	}
 
 	// Check if there are changes to commit
-	status, err := e.runGitCommandOutput("git status --porcelain " + e.gitopsPath)
+	status, err := e.runGitCommandOutput("status", "--porcelain", e.gitopsPath)
 	if err != nil {
 		return fmt.Errorf("git status: %w", err)
 	}
 
-	if strings.TrimSpace(status) == "" {
+	if strings.TrimSpace(status) == "" && !e.dryRun {
 		e.app.Logger.Println("ℹ️  [Enterprise Mode] No changes to commit")
 		return nil
 	}
@@ -220,12 +253,12 @@ func (e *EnterpriseModeDeployer) commitAndPush(message string) error {
 	commitMsg := fmt.Sprintf("%s\n\nAutomated by ConfigHub Enterprise Deployer\nSpace: %s\nTimestamp: %s",
 		message, e.spaceID, time.Now().Format(time.RFC3339))
 
-	if err := e.runGitCommand(fmt.Sprintf(`git commit -m "%s"`, commitMsg)); err != nil {
+	if err := e.runGitCommand("commit", "-m", commitMsg); err != nil {
 		return fmt.Errorf("git commit: %w", err)
 	}
 
 	// Push to remote
-	if err := e.runGitCommand("git push origin " + e.gitBranch); err != nil {
+	if err := e.runGitCommand("push", "origin", e.gitBranch); err != nil {
 		return fmt.Errorf("git push: %w", err)
 	}
 
@@ -251,14 +284,12 @@ func (e *EnterpriseModeDeployer) triggerFluxSync() error {
 	e.app.Logger.Println("🔄 [Enterprise Mode] Triggering Flux reconciliation...")
 
 	// Trigger Flux reconciliation for the source
-	cmd := fmt.Sprintf("flux reconcile source git %s", e.getFluxSourceName())
-	if err := e.runCommand(cmd); err != nil {
+	if err := e.runCommand("flux", "reconcile", "source", "git", e.getFluxSourceName()); err != nil {
 		return fmt.Errorf("flux reconcile source: %w", err)
 	}
 
 	// Trigger Flux reconciliation for kustomization
-	cmd = fmt.Sprintf("flux reconcile kustomization %s", e.getFluxKustomizationName())
-	if err := e.runCommand(cmd); err != nil {
+	if err := e.runCommand("flux", "reconcile", "kustomization", e.getFluxKustomizationName()); err != nil {
 		return fmt.Errorf("flux reconcile kustomization: %w", err)
 	}
 
@@ -271,14 +302,12 @@ func (e *EnterpriseModeDeployer) triggerArgoSync() error {
 	e.app.Logger.Println("🔄 [Enterprise Mode] Triggering Argo CD sync...")
 
 	appName := e.getArgoAppName()
-	cmd := fmt.Sprintf("argocd app sync %s", appName)
-	if err := e.runCommand(cmd); err != nil {
+	if err := e.runCommand("argocd", "app", "sync", appName); err != nil {
 		return fmt.Errorf("argocd sync: %w", err)
 	}
 
 	// Wait for sync to complete
-	cmd = fmt.Sprintf("argocd app wait %s --timeout 300", appName)
-	if err := e.runCommand(cmd); err != nil {
+	if err := e.runCommand("argocd", "app", "wait", appName, "--timeout", "300"); err != nil {
 		return fmt.Errorf("argocd wait: %w", err)
 	}
 
@@ -410,7 +439,7 @@ func (e *EnterpriseModeDeployer) validateFluxDeployment() (bool, []string) {
 	var issues []string
 
 	// Check GitRepository status
-	output, err := e.runCommandOutput(fmt.Sprintf("flux get source git %s", e.getFluxSourceName()))
+	output, err := e.runCommandOutput("flux", "get", "source", "git", e.getFluxSourceName())
 	if err != nil {
 		issues = append(issues, fmt.Sprintf("GitRepository check failed: %v", err))
 	} else if !strings.Contains(output, "True") {
@@ -418,7 +447,7 @@ func (e *EnterpriseModeDeployer) validateFluxDeployment() (bool, []string) {
 	}
 
 	// Check Kustomization status
-	output, err = e.runCommandOutput(fmt.Sprintf("flux get kustomization %s", e.getFluxKustomizationName()))
+	output, err = e.runCommandOutput("flux", "get", "kustomization", e.getFluxKustomizationName())
 	if err != nil {
 		issues = append(issues, fmt.Sprintf("Kustomization check failed: %v", err))
 	} else if !strings.Contains(output, "True") {
@@ -439,7 +468,7 @@ func (e *EnterpriseModeDeployer) validateFluxDeployment() (bool, []string) {
 func (e *EnterpriseModeDeployer) validateArgoDeployment() (bool, []string) {
 	var issues []string
 
-	output, err := e.runCommandOutput(fmt.Sprintf("argocd app get %s --output json", e.getArgoAppName()))
+	output, err := e.runCommandOutput("argocd", "app", "get", e.getArgoAppName(), "--output", "json")
 	if err != nil {
 		issues = append(issues, fmt.Sprintf("Argo app check failed: %v", err))
 		return false, issues
@@ -489,34 +518,66 @@ func (e *EnterpriseModeDeployer) getArgoAppName() string {
 	return fmt.Sprintf("confighub-%s", e.spaceID.String()[:8])
 }
 
-func (e *EnterpriseModeDeployer) runGitCommand(cmd string) error {
-	return e.runCommand("git " + cmd)
+func (e *EnterpriseModeDeployer) runGitCommand(args ...string) error {
+	return e.runCommand("git", args...)
 }
 
-func (e *EnterpriseModeDeployer) runGitCommandOutput(cmd string) (string, error) {
-	return e.runCommandOutput("git " + cmd)
+func (e *EnterpriseModeDeployer) runGitCommandOutput(args ...string) (string, error) {
+	return e.runCommandOutput("git", args...)
 }
 
-func (e *EnterpriseModeDeployer) runCommand(cmd string) error {
-	_, err := e.runCommandOutput(cmd)
+func (e *EnterpriseModeDeployer) runCommand(name string, args ...string) error {
+	_, err := e.runCommandOutput(name, args...)
 	return err
 }
 
-func (e *EnterpriseModeDeployer) runCommandOutput(cmd string) (string, error) {
-	// Implementation would use exec.Command to run shell commands
-	// For now, this is a placeholder
-	e.app.Logger.Printf("🔧 [Enterprise Mode] Running: %s", cmd)
-	return "", nil
+func (e *EnterpriseModeDeployer) runCommandOutput(name string, args ...string) (string, error) {
+	return e.execCommand(context.Background(), nil, name, args...)
 }
 
+// execCommand runs name with args in the work directory, feeding stdin if set.
+// It returns stdout; on failure the error includes the command's stderr.
+func (e *EnterpriseModeDeployer) execCommand(ctx context.Context, stdin []byte, name string, args ...string) (string, error) {
+	cmdline := strings.Join(append([]string{name}, args...), " ")
+	if e.dryRun {
+		e.app.Logger.Printf("🔧 [Enterprise Mode] [dry-run] Would run: %s", cmdline)
+		return "", nil
+	}
+	e.app.Logger.Printf("🔧 [Enterprise Mode] Running: %s", cmdline)
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = e.workDir
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%s: %w: %s", cmdline, err, msg)
+		}
+		return stdout.String(), fmt.Errorf("%s: %w", cmdline, err)
+	}
+	return stdout.String(), nil
+}
+
+// applyResource pipes the resource as YAML to kubectl apply
 func (e *EnterpriseModeDeployer) applyResource(resource map[string]interface{}) error {
-	// Implementation would apply the resource to Kubernetes
-	// For now, log the action
 	kind, _ := resource["kind"].(string)
 	metadata, _ := resource["metadata"].(map[string]interface{})
 	name, _ := metadata["name"].(string)
 
+	data, err := yaml.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("marshal %s %s: %w", kind, name, err)
+	}
+
 	e.app.Logger.Printf("📦 [Enterprise Mode] Applying %s: %s", kind, name)
+	if _, err := e.execCommand(context.Background(), data, "kubectl", "apply", "-f", "-"); err != nil {
+		return err
+	}
 	return nil
 }
 
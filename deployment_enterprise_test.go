@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test command execution in the enterprise deployer
+func TestEnterpriseModeDeployerExec(t *testing.T) {
+	newDeployer := func(t *testing.T) *EnterpriseModeDeployer {
+		app := &DevOpsApp{Logger: log.New(io.Discard, "", 0)}
+		e := NewEnterpriseModeDeployer(app, uuid.New(), "https://example.com/repo.git", "main")
+		e.SetWorkDir(t.TempDir())
+		return e
+	}
+
+	t.Run("RunsInWorkDir", func(t *testing.T) {
+		e := newDeployer(t)
+		output, err := e.runCommandOutput("pwd")
+		require.NoError(t, err)
+
+		expected, _ := filepath.EvalSymlinks(e.workDir)
+		actual, _ := filepath.EvalSymlinks(strings.TrimSpace(output))
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("ErrorIncludesStderr", func(t *testing.T) {
+		e := newDeployer(t)
+		_, err := e.runCommandOutput("sh", "-c", "echo boom >&2; exit 3")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 3")
+		assert.Contains(t, err.Error(), "boom")
+	})
+
+	t.Run("PipesStdin", func(t *testing.T) {
+		e := newDeployer(t)
+		output, err := e.execCommand(context.Background(), []byte("kind: ConfigMap\n"), "cat")
+		require.NoError(t, err)
+		assert.Equal(t, "kind: ConfigMap\n", output)
+	})
+
+	t.Run("DryRunSkipsExecution", func(t *testing.T) {
+		e := newDeployer(t)
+		e.SetDryRun(true)
+
+		marker := filepath.Join(e.workDir, "marker")
+		require.NoError(t, e.runCommand("touch", marker))
+		_, err := os.Stat(marker)
+		assert.True(t, os.IsNotExist(err))
+
+		assert.NoError(t, e.applyResource(map[string]interface{}{"kind": "ConfigMap"}))
+	})
+}
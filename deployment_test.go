@@ -0,0 +1,364 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKindUnit(slug, kind string) *Unit {
+	return &Unit{
+		UnitID: uuid.New(),
+		Slug:   slug,
+		Data:   fmt.Sprintf("apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, slug),
+	}
+}
+
+func unitSlugs(units []*Unit) []string {
+	slugs := make([]string, len(units))
+	for i, unit := range units {
+		slugs[i] = unit.Slug
+	}
+	return slugs
+}
+
+// Test dependency-ordered apply
+func TestTopologicalApplyOrder(t *testing.T) {
+	t.Run("KindPriority", func(t *testing.T) {
+		units := []*Unit{
+			newKindUnit("web-ingress", "Ingress"),
+			newKindUnit("web", "Deployment"),
+			newKindUnit("web-svc", "Service"),
+			newKindUnit("settings", "ConfigMap"),
+			newKindUnit("web-sa", "ServiceAccount"),
+			newKindUnit("widgets-crd", "CustomResourceDefinition"),
+			newKindUnit("apps", "Namespace"),
+		}
+
+		ordered, err := TopologicalApplyOrder(units)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"apps", "widgets-crd", "web-sa", "settings", "web-svc", "web", "web-ingress"}, unitSlugs(ordered))
+	})
+
+	t.Run("LinksOverrideKind", func(t *testing.T) {
+		db := newKindUnit("db", "StatefulSet")
+		api := newKindUnit("api", "Deployment")
+		config := newKindUnit("api-config", "ConfigMap")
+		links := []*Link{
+			{FromUnitID: api.UnitID, ToUnitID: db.UnitID},
+			{FromUnitID: config.UnitID, ToUnitID: db.UnitID}, // Config is rendered from the db's outputs
+			{FromUnitID: api.UnitID, ToUnitID: uuid.New()},   // Target outside the list is ignored
+		}
+
+		ordered, err := TopologicalApplyOrderWithLinks([]*Unit{api, config, db}, links)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db", "api-config", "api"}, unitSlugs(ordered))
+	})
+
+	t.Run("Phases", func(t *testing.T) {
+		db := newKindUnit("db", "StatefulSet")
+		api := newKindUnit("api", "Deployment")
+		worker := newKindUnit("worker", "Deployment")
+		units := []*Unit{
+			api, worker, db,
+			newKindUnit("settings", "ConfigMap"),
+			newKindUnit("apps", "Namespace"),
+			newKindUnit("widgets-crd", "CustomResourceDefinition"),
+			newKindUnit("secrets", "Secret"),
+		}
+		links := []*Link{{FromUnitID: api.UnitID, ToUnitID: db.UnitID}}
+
+		phases, err := TopologicalApplyPhases(units, links)
+		require.NoError(t, err)
+		var slugs [][]string
+		for _, phase := range phases {
+			slugs = append(slugs, unitSlugs(phase))
+		}
+		assert.Equal(t, [][]string{{"apps"}, {"widgets-crd"}, {"settings", "secrets"}, {"worker", "db"}, {"api"}}, slugs)
+
+		_, err = TopologicalApplyPhases([]*Unit{api, db}, append(links, &Link{FromUnitID: db.UnitID, ToUnitID: api.UnitID}))
+		assert.ErrorContains(t, err, "api, db")
+	})
+
+	t.Run("PhasesKeepKindAheadOfLinks", func(t *testing.T) {
+		// The namespace waits on a provisioner through a link; units ready
+		// earlier must still not be applied before the namespace
+		provisioner := newKindUnit("provisioner", "Deployment")
+		broker := newKindUnit("broker", "StatefulSet")
+		namespace := newKindUnit("apps", "Namespace")
+		units := []*Unit{
+			newKindUnit("settings", "ConfigMap"),
+			newKindUnit("web", "Deployment"),
+			namespace, provisioner, broker,
+		}
+		links := []*Link{
+			{FromUnitID: namespace.UnitID, ToUnitID: provisioner.UnitID},
+			{FromUnitID: provisioner.UnitID, ToUnitID: broker.UnitID},
+		}
+
+		phases, err := TopologicalApplyPhases(units, links)
+		require.NoError(t, err)
+		var slugs [][]string
+		for _, phase := range phases {
+			slugs = append(slugs, unitSlugs(phase))
+		}
+		assert.Equal(t, [][]string{{"broker"}, {"provisioner"}, {"apps"}, {"settings"}, {"web"}}, slugs)
+	})
+
+	t.Run("OrderMatchesPhases", func(t *testing.T) {
+		namespace := newKindUnit("ns", "Namespace")
+		operator := newKindUnit("operator", "Deployment")
+		units := []*Unit{namespace, newKindUnit("app", "Deployment"), operator}
+		links := []*Link{{FromUnitID: namespace.UnitID, ToUnitID: operator.UnitID}}
+
+		ordered, err := TopologicalApplyOrderWithLinks(units, links)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"operator", "ns", "app"}, unitSlugs(ordered), "the namespace still goes ahead of the ready app")
+
+		phases, err := TopologicalApplyPhases(units, links)
+		require.NoError(t, err)
+		var flattened []*Unit
+		for _, phase := range phases {
+			flattened = append(flattened, phase...)
+		}
+		assert.Equal(t, unitSlugs(flattened), unitSlugs(ordered))
+	})
+
+	t.Run("CycleNamesUnits", func(t *testing.T) {
+		a := newKindUnit("a", "Deployment")
+		b := newKindUnit("b", "Deployment")
+		c := newKindUnit("c", "Service")
+		downstream := newKindUnit("downstream", "Ingress")
+		links := []*Link{
+			{FromUnitID: a.UnitID, ToUnitID: b.UnitID},
+			{FromUnitID: b.UnitID, ToUnitID: c.UnitID},
+			{FromUnitID: c.UnitID, ToUnitID: a.UnitID},
+			{FromUnitID: downstream.UnitID, ToUnitID: a.UnitID},
+		}
+
+		_, err := TopologicalApplyOrderWithLinks([]*Unit{a, b, c, downstream}, links)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a, b, c")
+		assert.NotContains(t, err.Error(), "downstream")
+	})
+}
+
+// Test reviewing a promotion before push-upgrading it
+func TestPromoteEnvironmentWithDiff(t *testing.T) {
+	deployment := func(image string, replicas int) string {
+		return fmt.Sprintf("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: %d\n  template:\n    spec:\n      containers:\n      - name: web\n        image: %s\n", replicas, image)
+	}
+	stagingID, prodID := uuid.New(), uuid.New()
+	web, api, worker := uuid.New(), uuid.New(), uuid.New()
+	units := map[uuid.UUID][]Unit{
+		stagingID: {
+			{UnitID: web, Slug: "web", Data: deployment("web:v2", 2)},
+			{UnitID: api, Slug: "api", Data: deployment("api:v5", 2)},
+			{UnitID: worker, Slug: "worker", Data: "kind: ConfigMap\ndata:\n  mode: fast\n"},
+		},
+		prodID: {
+			{UnitID: uuid.New(), Slug: "web", Data: deployment("web:v1", 2), UpstreamUnitID: &web},
+			{UnitID: uuid.New(), Slug: "api", Data: deployment("api:v5", 2), UpstreamUnitID: &api},
+			{UnitID: uuid.New(), Slug: "worker", Data: "kind: ConfigMap\ndata:\n  mode: fast\n  debug: \"true\"\n", UpstreamUnitID: &worker},
+		},
+	}
+
+	var patches []BulkPatchParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/space":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Space": Space{SpaceID: stagingID, Slug: "shop-staging"}},
+				{"Space": Space{SpaceID: prodID, Slug: "shop-prod"}},
+			})
+		case r.Method == http.MethodPatch:
+			var params BulkPatchParams
+			json.NewDecoder(r.Body).Decode(&params)
+			patches = append(patches, params)
+			w.Write([]byte(`{}`))
+		default:
+			spaceID := uuid.MustParse(strings.Split(r.URL.Path, "/")[2])
+			var list []map[string]interface{}
+			for _, unit := range units[spaceID] {
+				list = append(list, map[string]interface{}{"Unit": unit})
+			}
+			json.NewEncoder(w).Encode(list)
+		}
+	}))
+	defer server.Close()
+	helper := &DeploymentHelper{Cub: NewConfigHubClient(server.URL, "test-token"), ProjectName: "shop"}
+
+	t.Run("ReviewThenExecute", func(t *testing.T) {
+		patches = nil
+		plan, err := helper.PromoteEnvironmentWithDiff("staging", "prod")
+		require.NoError(t, err)
+		require.True(t, plan.HasChanges())
+		require.Len(t, plan.Units, 2, "unchanged api is left out")
+		assert.Empty(t, patches, "planning writes nothing")
+
+		assert.Equal(t, "web", plan.Units[0].UnitSlug)
+		assert.Equal(t, web, plan.Units[0].UpstreamUnitID)
+		assert.Equal(t, []FieldChange{{Path: "spec.template.spec.containers[0].image", Type: "changed", Live: "web:v1", Desired: "web:v2"}}, plan.Units[0].Changes)
+		assert.Equal(t, "worker", plan.Units[1].UnitSlug)
+		assert.Equal(t, []FieldChange{{Path: "data.debug", Type: "removed", Live: "true"}}, plan.Units[1].Changes)
+
+		table := RenderPromotionPlanTable(plan)
+		assert.Contains(t, table, "prod")
+		assert.Contains(t, table, "web:v1")
+		assert.Contains(t, table, "web:v2")
+
+		require.NoError(t, helper.ExecutePromotion(plan))
+		require.Len(t, patches, 1)
+		assert.Equal(t, prodID, patches[0].SpaceID)
+		assert.True(t, patches[0].Upgrade)
+		assert.Contains(t, patches[0].Where, fmt.Sprintf("UpstreamSpaceID = '%s'", stagingID))
+		assert.Contains(t, patches[0].Where, plan.Units[0].UnitID.String())
+		assert.Contains(t, patches[0].Where, plan.Units[1].UnitID.String())
+	})
+
+	t.Run("UnitSubset", func(t *testing.T) {
+		patches = nil
+		plan, err := helper.PromoteEnvironmentWithDiff("staging", "prod", "web", "api")
+		require.NoError(t, err)
+		require.Len(t, plan.Units, 1)
+		assert.Equal(t, "web", plan.Units[0].UnitSlug)
+
+		require.NoError(t, helper.ExecutePromotion(plan))
+		require.Len(t, patches, 1)
+		assert.NotContains(t, patches[0].Where, units[prodID][2].UnitID.String(), "worker is not promoted")
+	})
+
+	t.Run("UnknownUnit", func(t *testing.T) {
+		_, err := helper.PromoteEnvironmentWithDiff("staging", "prod", "billing")
+		assert.EqualError(t, err, "unit billing not found in prod")
+	})
+
+	t.Run("NothingToPromote", func(t *testing.T) {
+		patches = nil
+		plan, err := helper.PromoteEnvironmentWithDiff("staging", "prod", "api")
+		require.NoError(t, err)
+		assert.False(t, plan.HasChanges())
+		require.NoError(t, helper.ExecutePromotion(plan))
+		assert.Empty(t, patches)
+	})
+}
+
+// Test applying only the app's units to an environment, in dependency order
+func TestApplyToEnvironment(t *testing.T) {
+	devID := uuid.New()
+	web := map[string]string{"app": "web"}
+	units := []*Unit{
+		{UnitID: uuid.New(), Slug: "web-deployment", Labels: web, Data: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n"},
+		{UnitID: uuid.New(), Slug: "namespace", Labels: web, Data: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: shop\n"},
+		{UnitID: uuid.New(), Slug: "billing-deployment", Labels: map[string]string{"app": "billing"}, Data: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: billing\n"},
+	}
+	slugs := make(map[string]string)
+	for _, unit := range units {
+		slugs[unit.UnitID.String()] = unit.Slug
+	}
+
+	var applied []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/space":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"Space": Space{SpaceID: devID, Slug: "shop-dev"}}})
+		case strings.HasSuffix(r.URL.Path, "/link"):
+			w.Write([]byte("[]"))
+		case strings.HasSuffix(r.URL.Path, "/apply"):
+			parts := strings.Split(r.URL.Path, "/")
+			applied = append(applied, slugs[parts[len(parts)-2]])
+		default:
+			assert.Equal(t, "Labels.app = 'web'", r.URL.Query().Get("where"))
+			var list []map[string]interface{}
+			for _, unit := range units {
+				if unit.Labels["app"] == "web" {
+					list = append(list, map[string]interface{}{"Unit": unit})
+				}
+			}
+			json.NewEncoder(w).Encode(list)
+		}
+	}))
+	defer server.Close()
+
+	helper := &DeploymentHelper{Cub: NewConfigHubClient(server.URL, "test-token"), ProjectName: "shop", AppName: "web"}
+	require.NoError(t, helper.ApplyToEnvironment("dev"))
+	assert.Equal(t, []string{"namespace", "web-deployment"}, applied)
+}
+
+// Test cloning units into a space that already has some of them
+func TestCloneUnitsFromUpstream(t *testing.T) {
+	upstreamID, downstreamID := uuid.New(), uuid.New()
+	var (
+		upstream   []string
+		downstream []string
+		requested  []string
+		concurrent bool // Another clone creates the units our batch fails on
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/bulk-create") {
+			var reqs []CreateUnitRequest
+			json.NewDecoder(r.Body).Decode(&reqs)
+			var results []map[string]interface{}
+			for _, req := range reqs {
+				requested = append(requested, req.Slug)
+				if req.Slug == "worker" {
+					if concurrent {
+						downstream = append(downstream, req.Slug)
+					}
+					results = append(results, map[string]interface{}{"Error": "duplicate key"})
+					continue
+				}
+				downstream = append(downstream, req.Slug)
+				results = append(results, map[string]interface{}{"Unit": Unit{Slug: req.Slug}})
+			}
+			json.NewEncoder(w).Encode(results)
+			return
+		}
+
+		slugs := upstream
+		if strings.Contains(r.URL.Path, downstreamID.String()) {
+			slugs = downstream
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		list := []map[string]interface{}{}
+		for _, slug := range slugs[min(offset, len(slugs)):min(offset+limit, len(slugs))] {
+			list = append(list, map[string]interface{}{"Unit": Unit{UnitID: uuid.New(), Slug: slug}})
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+	defer server.Close()
+	helper := &DeploymentHelper{Cub: NewConfigHubClient(server.URL, "test-token"), ProjectName: "shop"}
+
+	t.Run("SkipsExistingAndConcurrentUnits", func(t *testing.T) {
+		upstream = []string{"web", "api", "worker"}
+		downstream, requested, concurrent = []string{"web"}, nil, true
+		require.NoError(t, helper.cloneUnitsFromUpstream(upstreamID, downstreamID, "dev"))
+		assert.Equal(t, []string{"api", "worker"}, requested)
+	})
+
+	t.Run("ReportsRealFailures", func(t *testing.T) {
+		upstream = []string{"web", "api", "worker"}
+		downstream, requested, concurrent = nil, nil, false
+		err := helper.cloneUnitsFromUpstream(upstreamID, downstreamID, "dev")
+		assert.EqualError(t, err, "clone unit worker: duplicate key")
+	})
+
+	t.Run("LargeUpstream", func(t *testing.T) {
+		upstream = nil
+		for i := 0; i < defaultUnitPageSize+20; i++ {
+			upstream = append(upstream, fmt.Sprintf("svc-%03d", i))
+		}
+		downstream, requested, concurrent = nil, nil, false
+		require.NoError(t, helper.cloneUnitsFromUpstream(upstreamID, downstreamID, "dev"))
+		assert.Equal(t, upstream, requested, "units past the first page are cloned")
+	})
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // HealthServer provides health and metrics endpoints
@@ -128,3 +131,85 @@ func (h *HealthServer) metricsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(h.metrics)
 }
+
+// DefaultWorkerMaxStale is how long a worker may go without checking in
+// before it is considered stale
+const DefaultWorkerMaxStale = 5 * time.Minute
+
+// Worker health statuses, from best to worst
+const (
+	WorkerHealthHealthy  = "HEALTHY"  // Every worker is connected and checking in
+	WorkerHealthWarning  = "WARNING"  // Some workers are stale, at least one can still deploy
+	WorkerHealthCritical = "CRITICAL" // No worker can deploy
+)
+
+// WorkerHealth is the health of a space's workers. Without a live worker
+// nothing ConfigHub applies reaches the cluster.
+type WorkerHealth struct {
+	Status  string   // WorkerHealthHealthy, WorkerHealthWarning or WorkerHealthCritical
+	Healthy int      // Workers connected and seen within the staleness limit
+	Stale   []string // Slugs of workers that are disconnected, erroring or stale
+	Message string
+}
+
+// IsWorkerHealthy reports whether a worker is connected and checked in within
+// maxStale (DefaultWorkerMaxStale if zero). A worker that has never checked
+// in is not healthy.
+func IsWorkerHealthy(w *Worker, maxStale time.Duration) bool {
+	if w == nil || w.Condition == "Disconnected" || strings.EqualFold(w.Status, "Error") {
+		return false
+	}
+	if maxStale <= 0 {
+		maxStale = DefaultWorkerMaxStale
+	}
+	return !w.LastSeenAt.IsZero() && time.Since(w.LastSeenAt) <= maxStale
+}
+
+// EvaluateWorkers rates a space's workers: CRITICAL when none is healthy,
+// including when there are none, WARNING when some are stale
+func EvaluateWorkers(workers []*Worker, maxStale time.Duration) *WorkerHealth {
+	health := &WorkerHealth{}
+	for _, worker := range workers {
+		if IsWorkerHealthy(worker, maxStale) {
+			health.Healthy++
+		} else if worker != nil {
+			health.Stale = append(health.Stale, worker.Slug)
+		}
+	}
+
+	switch {
+	case len(workers) == 0:
+		health.Status = WorkerHealthCritical
+		health.Message = "no workers; nothing can be deployed"
+	case health.Healthy == 0:
+		health.Status = WorkerHealthCritical
+		health.Message = fmt.Sprintf("no healthy workers; stale: %s", strings.Join(health.Stale, ", "))
+	case len(health.Stale) > 0:
+		health.Status = WorkerHealthWarning
+		health.Message = fmt.Sprintf("%d healthy workers; stale: %s", health.Healthy, strings.Join(health.Stale, ", "))
+	default:
+		health.Status = WorkerHealthHealthy
+		health.Message = fmt.Sprintf("%d healthy workers", health.Healthy)
+	}
+	return health
+}
+
+// CheckWorkers evaluates the workers of a space and marks the server
+// unhealthy when their health is CRITICAL. The worker counts are published
+// as the workers_healthy and workers_stale metrics.
+func (h *HealthServer) CheckWorkers(spaceID uuid.UUID, maxStale time.Duration) (*WorkerHealth, error) {
+	workers, err := h.app.Cub.ListWorkers(spaceID)
+	if err != nil {
+		h.SetHealthy(false, fmt.Sprintf("failed to list workers: %v", err))
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+
+	health := EvaluateWorkers(workers, maxStale)
+	h.UpdateMetric("workers_healthy", health.Healthy)
+	h.UpdateMetric("workers_stale", len(health.Stale))
+	h.SetHealthy(health.Status != WorkerHealthCritical, "workers "+strings.ToLower(health.Status)+": "+health.Message)
+	if health.Status != WorkerHealthHealthy {
+		h.app.Logger.Printf("⚠️  Workers in space %s are %s: %s", spaceID, health.Status, health.Message)
+	}
+	return health, nil
+}
@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test worker liveness from condition and last check-in
+func TestWorkerHealth(t *testing.T) {
+	now := time.Now()
+	live := &Worker{Slug: "live", Condition: "Ready", LastSeenAt: now.Add(-time.Minute)}
+	stale := &Worker{Slug: "stale", Condition: "Ready", LastSeenAt: now.Add(-6 * time.Minute)}
+	disconnected := &Worker{Slug: "gone", Condition: "Disconnected", LastSeenAt: now}
+	neverSeen := &Worker{Slug: "new", Condition: "Ready"}
+
+	t.Run("IsWorkerHealthy", func(t *testing.T) {
+		assert.True(t, IsWorkerHealthy(live, 0))
+		assert.False(t, IsWorkerHealthy(stale, 0), "not seen for over DefaultWorkerMaxStale")
+		assert.True(t, IsWorkerHealthy(stale, 10*time.Minute))
+		assert.False(t, IsWorkerHealthy(disconnected, 0))
+		assert.False(t, IsWorkerHealthy(neverSeen, 0))
+		assert.False(t, IsWorkerHealthy(&Worker{Status: "Error", LastSeenAt: now}, 0))
+		assert.False(t, IsWorkerHealthy(nil, 0))
+	})
+
+	t.Run("EvaluateWorkers", func(t *testing.T) {
+		assert.Equal(t, WorkerHealthHealthy, EvaluateWorkers([]*Worker{live}, 0).Status)
+		assert.Equal(t, WorkerHealthCritical, EvaluateWorkers(nil, 0).Status)
+
+		health := EvaluateWorkers([]*Worker{stale}, 0)
+		assert.Equal(t, WorkerHealthCritical, health.Status, "an existing but stale worker can't deploy")
+		assert.Equal(t, []string{"stale"}, health.Stale)
+
+		health = EvaluateWorkers([]*Worker{live, stale, disconnected}, 0)
+		assert.Equal(t, WorkerHealthWarning, health.Status)
+		assert.Equal(t, 1, health.Healthy)
+		assert.Equal(t, []string{"stale", "gone"}, health.Stale)
+	})
+
+	t.Run("CheckWorkers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]*Worker{stale})
+		}))
+		defer server.Close()
+		app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+		h := NewHealthServer(0, app)
+
+		health, err := h.CheckWorkers(uuid.New(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, WorkerHealthCritical, health.Status)
+		assert.False(t, h.healthy)
+		assert.Contains(t, h.message, "stale")
+		assert.Equal(t, 1, h.metrics["workers_stale"])
+	})
+
+	t.Run("RenderWorkersTable", func(t *testing.T) {
+		table := RenderWorkersTable([]*Worker{live, stale, disconnected}, 0)
+		assert.Contains(t, table, "live")
+		assert.Contains(t, table, "✗ stale")
+		assert.Contains(t, table, "✗ disconnected")
+		assert.Contains(t, table, "6m ago")
+	})
+}
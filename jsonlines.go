@@ -0,0 +1,192 @@
+// jsonlines.go - Newline-delimited JSON output for the DevOps SDK
+//
+// The box-drawing reports are for people. Machine consumers such as jq or a
+// log pipeline get one compact JSON object per unit instead, with stable
+// snake_case field names. Amounts are USD and, like every number, are written
+// in plain decimal notation. Fields that don't apply to a unit (replicas of a
+// LoadBalancer Service, storage of a Deployment, run fraction of anything but
+// a Job or CronJob) are omitted rather than written as zero.
+//
+// Example:
+//
+//	analysis, _ := costAnalyzer.AnalyzeSpace()
+//	WriteJSONLines(os.Stdout, analysis) // | jq 'select(.monthly_cost_usd > 100)'
+//	WriteWasteJSONLines(os.Stdout, wasteAnalysis)
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// jsonDecimal is a number written with two decimals, never in exponent form
+type jsonDecimal float64
+
+// MarshalJSON implements json.Marshaler
+func (d jsonDecimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(d), 'f', 2, 64)), nil
+}
+
+// jsonFraction is a number written at full precision, never in exponent form
+type jsonFraction float64
+
+// MarshalJSON implements json.Marshaler
+func (f jsonFraction) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(f), 'f', -1, 64)), nil
+}
+
+// jsonDecimalPtr returns a pointer for fields that are omitted when unset
+// but meaningful at zero
+func jsonDecimalPtr(v float64) *jsonDecimal {
+	d := jsonDecimal(v)
+	return &d
+}
+
+// costLine is one unit of a SpaceCostAnalysis in WriteJSONLines output
+type costLine struct {
+	SpaceID     string            `json:"space_id"`
+	UnitID      string            `json:"unit_id"`
+	Unit        string            `json:"unit"`
+	Type        string            `json:"type"`
+	NodeClass   string            `json:"node_class,omitempty"`
+	Replicas    int32             `json:"replicas,omitempty"`
+	CPU         string            `json:"cpu,omitempty"`     // Per pod
+	Memory      string            `json:"memory,omitempty"`  // Per pod
+	Storage     string            `json:"storage,omitempty"` // Per pod
+	RunFraction *jsonFraction     `json:"run_fraction,omitempty"`
+	Workloads   int               `json:"workloads,omitempty"` // Documents of a multi-workload unit
+	Labels      map[string]string `json:"labels,omitempty"`
+
+	MonthlyCost      jsonDecimal `json:"monthly_cost_usd"`
+	CPUCost          jsonDecimal `json:"cpu_cost_usd,omitempty"`
+	MemoryCost       jsonDecimal `json:"memory_cost_usd,omitempty"`
+	StorageCost      jsonDecimal `json:"storage_cost_usd,omitempty"`
+	NetworkCost      jsonDecimal `json:"network_cost_usd,omitempty"`
+	LoadBalancerCost jsonDecimal `json:"load_balancer_cost_usd,omitempty"`
+	CommittedCost    jsonDecimal `json:"committed_cost_usd,omitempty"`
+}
+
+// WriteJSONLines writes one compact JSON object per unit of analysis to w,
+// each on its own line
+func WriteJSONLines(w io.Writer, analysis *SpaceCostAnalysis) error {
+	encoder := json.NewEncoder(w)
+	for _, unit := range analysis.Units {
+		line := costLine{
+			SpaceID:          analysis.SpaceID,
+			UnitID:           unit.UnitID,
+			Unit:             unit.UnitName,
+			Type:             unit.Type,
+			NodeClass:        unit.NodeClass,
+			Replicas:         unit.Replicas,
+			CPU:              unit.CPU.String(),
+			Memory:           unit.Memory.String(),
+			Storage:          unit.Storage.String(),
+			Labels:           unit.Labels,
+			MonthlyCost:      jsonDecimal(unit.MonthlyCost),
+			CPUCost:          jsonDecimal(unit.Breakdown.CPUCost),
+			MemoryCost:       jsonDecimal(unit.Breakdown.MemoryCost),
+			StorageCost:      jsonDecimal(unit.Breakdown.StorageCost),
+			NetworkCost:      jsonDecimal(unit.Breakdown.NetworkCost),
+			LoadBalancerCost: jsonDecimal(unit.Breakdown.LoadBalancerCost),
+			CommittedCost:    jsonDecimal(unit.Breakdown.CommittedCost),
+		}
+		if unit.Type == LoadBalancerServiceType {
+			line.Replicas = 0 // A Service has no pods
+		}
+		if unit.Type == "Job" || unit.Type == "CronJob" {
+			runFraction := jsonFraction(unit.RunFraction)
+			line.RunFraction = &runFraction
+		}
+		if len(unit.Workloads) > 1 {
+			line.Workloads = len(unit.Workloads)
+		}
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to write cost of %s: %v", unit.UnitName, err)
+		}
+	}
+	return nil
+}
+
+// wasteLine is one unit of a SpaceWasteAnalysis in WriteWasteJSONLines output
+type wasteLine struct {
+	SpaceID    string            `json:"space_id"`
+	UnitID     string            `json:"unit_id"`
+	Unit       string            `json:"unit"`
+	Type       string            `json:"type"`
+	Schedule   WorkloadSchedule  `json:"schedule,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	AnalyzedAt time.Time         `json:"analyzed_at"`
+
+	EstimatedMonthlyCost jsonDecimal `json:"estimated_monthly_cost_usd"`
+	ActualMonthlyCost    jsonDecimal `json:"actual_monthly_cost_usd"`
+	WastedMonthlyCost    jsonDecimal `json:"wasted_monthly_cost_usd"`
+	WasteScore           jsonDecimal `json:"waste_score"`
+	WasteSeverity        string      `json:"waste_severity,omitempty"`
+	WasteCategories      []string    `json:"waste_categories,omitempty"`
+
+	// Utilization is omitted for resources the unit doesn't allocate
+	CPUUtilization     *jsonDecimal `json:"cpu_utilization_percent,omitempty"`
+	MemoryUtilization  *jsonDecimal `json:"memory_utilization_percent,omitempty"`
+	StorageUtilization *jsonDecimal `json:"storage_utilization_percent,omitempty"`
+	IdleReplicas       jsonDecimal  `json:"idle_replicas,omitempty"`
+
+	Recommendations      []string    `json:"recommendations,omitempty"` // Recommendation types, e.g. "resize"
+	PotentialSavings     jsonDecimal `json:"potential_savings_usd"`
+	PotentialSavingsLow  jsonDecimal `json:"potential_savings_low_usd"`
+	PotentialSavingsHigh jsonDecimal `json:"potential_savings_high_usd"`
+
+	DataQuality      string `json:"data_quality,omitempty"`
+	Protected        bool   `json:"protected,omitempty"`
+	ProtectionReason string `json:"protection_reason,omitempty"`
+}
+
+// WriteWasteJSONLines writes one compact JSON object per analyzed unit of
+// analysis to w, each on its own line
+func WriteWasteJSONLines(w io.Writer, analysis *SpaceWasteAnalysis) error {
+	encoder := json.NewEncoder(w)
+	for _, detection := range analysis.UnitWasteDetections {
+		line := wasteLine{
+			SpaceID:              analysis.SpaceID,
+			UnitID:               detection.UnitID,
+			Unit:                 detection.UnitName,
+			Type:                 detection.Type,
+			Schedule:             detection.Schedule,
+			Labels:               detection.Labels,
+			AnalyzedAt:           detection.AnalyzedAt,
+			EstimatedMonthlyCost: jsonDecimal(detection.EstimatedMonthlyCost),
+			ActualMonthlyCost:    jsonDecimal(detection.ActualMonthlyCost),
+			WastedMonthlyCost:    jsonDecimal(detection.WastedMonthlyCost),
+			WasteScore:           jsonDecimal(detection.WasteScore),
+			WasteSeverity:        detection.WasteSeverity,
+			IdleReplicas:         jsonDecimal(detection.ReplicaWaste.IdleReplicas),
+			PotentialSavings:     jsonDecimal(detection.PotentialSavings),
+			PotentialSavingsLow:  jsonDecimal(detection.PotentialSavingsLow),
+			PotentialSavingsHigh: jsonDecimal(detection.PotentialSavingsHigh),
+			DataQuality:          detection.DataQuality,
+			Protected:            detection.Protected,
+			ProtectionReason:     detection.ProtectionReason,
+		}
+		if detection.CPUWaste.Allocated != "" {
+			line.CPUUtilization = jsonDecimalPtr(detection.CPUWaste.UtilizationPercent)
+		}
+		if detection.MemoryWaste.Allocated != "" {
+			line.MemoryUtilization = jsonDecimalPtr(detection.MemoryWaste.UtilizationPercent)
+		}
+		if detection.StorageWaste.Allocated != "" {
+			line.StorageUtilization = jsonDecimalPtr(detection.StorageWaste.UtilizationPercent)
+		}
+		for _, category := range detection.WasteCategories {
+			line.WasteCategories = append(line.WasteCategories, category.Type)
+		}
+		for _, recommendation := range detection.Recommendations {
+			line.Recommendations = append(line.Recommendations, recommendation.Type)
+		}
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to write waste of %s: %v", detection.UnitName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test newline-delimited JSON output of cost and waste analyses
+func TestWriteJSONLines(t *testing.T) {
+	t.Run("Cost", func(t *testing.T) {
+		analysis := &SpaceCostAnalysis{SpaceID: "space-1", Units: []UnitCostEstimate{
+			{UnitID: "u1", UnitName: "web", Type: "Deployment", Replicas: 3, CPU: ParseQuantity("500m"), Memory: ParseQuantity("1Gi"),
+				MonthlyCost: 123456789012345678901234.5, Breakdown: CostBreakdown{CPUCost: 0.0000001, MemoryCost: 20}},
+			{UnitID: "u2", UnitName: "ingress", Type: LoadBalancerServiceType, Replicas: 1, MonthlyCost: 18.25,
+				Breakdown: CostBreakdown{LoadBalancerCost: 18.25}},
+			{UnitID: "u3", UnitName: "backup", Type: "CronJob", Replicas: 1, CPU: ParseQuantity("1"), RunFraction: 0.0000025},
+		}}
+
+		var out bytes.Buffer
+		require.NoError(t, WriteJSONLines(&out, analysis))
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 3)
+		assert.NotRegexp(t, `\d[eE][+-]?\d`, out.String(), "no scientific notation")
+
+		var web, ingress, backup map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &web))
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &ingress))
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &backup))
+
+		assert.Equal(t, "space-1", web["space_id"])
+		assert.Equal(t, "500m", web["cpu"])
+		assert.EqualValues(t, 3, web["replicas"])
+		assert.Contains(t, lines[0], `"cpu_cost_usd":0.00`)
+		assert.NotContains(t, web, "storage")
+		assert.NotContains(t, web, "run_fraction")
+		assert.NotContains(t, web, "load_balancer_cost_usd")
+
+		assert.NotContains(t, ingress, "replicas", "a Service has no pods")
+		assert.NotContains(t, ingress, "cpu")
+		assert.EqualValues(t, 18.25, ingress["load_balancer_cost_usd"])
+
+		assert.Contains(t, lines[2], `"run_fraction":0.0000025`)
+		assert.Contains(t, lines[2], `"monthly_cost_usd":0.00`)
+	})
+
+	t.Run("Waste", func(t *testing.T) {
+		analysis := &SpaceWasteAnalysis{SpaceID: "space-1", UnitWasteDetections: []WasteDetection{{
+			UnitID: "u1", UnitName: "web", Type: "Deployment",
+			EstimatedMonthlyCost: 100, ActualMonthlyCost: 40, WastedMonthlyCost: 60, WasteScore: 72.5, WasteSeverity: "HIGH",
+			WasteCategories:  []WasteCategory{{Type: "over-provisioned"}},
+			CPUWaste:         ResourceWaste{Allocated: "2.00 cores", UtilizationPercent: 0},
+			Recommendations:  []WasteRecommendation{{Type: "resize"}},
+			PotentialSavings: 55,
+		}}}
+
+		var out bytes.Buffer
+		require.NoError(t, WriteWasteJSONLines(&out, analysis))
+		require.Equal(t, 1, strings.Count(out.String(), "\n"))
+
+		var web map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &web))
+		assert.EqualValues(t, 60, web["wasted_monthly_cost_usd"])
+		assert.EqualValues(t, 0, web["cpu_utilization_percent"], "allocated but unused CPU is reported")
+		assert.NotContains(t, web, "memory_utilization_percent", "unallocated memory is omitted")
+		assert.NotContains(t, web, "idle_replicas")
+		assert.NotContains(t, web, "protected")
+		assert.Equal(t, []interface{}{"over-provisioned"}, web["waste_categories"])
+		assert.Equal(t, []interface{}{"resize"}, web["recommendations"])
+	})
+}
@@ -0,0 +1,278 @@
+// monitor.go - Post-optimization monitoring module for the DevOps SDK
+//
+// This module watches a workload after an optimization is applied and decides
+// whether the change held up, closing the loop on memory right-sizing.
+//
+// Features:
+// - OOMKill detection from container statuses and Kubernetes events
+// - Readiness regression detection against the starting ready pods or optimized replicas
+// - Stable / regressed verdict after a configurable window
+// - Optional automatic rollback through the optimization's RollbackPlan
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MonitorVerdict is the outcome of watching an applied optimization
+type MonitorVerdict string
+
+const (
+	VerdictStable    MonitorVerdict = "stable"
+	VerdictRegressed MonitorVerdict = "regressed"
+)
+
+// PostOptimizationMonitor watches the workload of an applied optimization for
+// OOMKills and readiness regressions
+type PostOptimizationMonitor struct {
+	app          *DevOpsApp
+	spaceID      uuid.UUID
+	engine       *OptimizationEngine
+	window       time.Duration
+	pollInterval time.Duration
+	autoRollback bool
+}
+
+// MonitorResult reports what happened while an optimization was monitored
+type MonitorResult struct {
+	Verdict              MonitorVerdict `json:"verdict"`
+	Workload             string         `json:"workload"`                       // Kind/name
+	OOMKills             []string       `json:"oomKills,omitempty"`             // pod/container names
+	ReadinessRegressions []string       `json:"readinessRegressions,omitempty"` // What dropped, e.g. "ready pods dropped from 3 to 1"
+	RolledBack           bool           `json:"rolledBack"`
+	StartedAt            time.Time      `json:"startedAt"`
+	EndedAt              time.Time      `json:"endedAt"`
+}
+
+// oomKillReasons are Pod event reasons Kubernetes uses for out-of-memory kills.
+// The kernel OOM killer's OOMKilling events are reported against the Node, not
+// the Pod, so those kills are found through container statuses instead.
+var oomKillReasons = map[string]bool{"OOMKilled": true}
+
+// NewPostOptimizationMonitor creates a monitor for optimizations in spaceID.
+// It watches for 10 minutes, checking every 30 seconds, and does not roll
+// back on its own.
+func NewPostOptimizationMonitor(app *DevOpsApp, spaceID uuid.UUID) *PostOptimizationMonitor {
+	return &PostOptimizationMonitor{
+		app:          app,
+		spaceID:      spaceID,
+		engine:       NewOptimizationEngine(app, spaceID),
+		window:       10 * time.Minute,
+		pollInterval: 30 * time.Second,
+	}
+}
+
+// SetWindow sets how long a workload must stay healthy to be judged stable
+func (m *PostOptimizationMonitor) SetWindow(window time.Duration) {
+	m.window = window
+}
+
+// SetPollInterval sets how often pods and events are checked
+func (m *PostOptimizationMonitor) SetPollInterval(interval time.Duration) {
+	m.pollInterval = interval
+}
+
+// SetAutoRollback applies the optimization's RollbackPlan when it regresses
+func (m *PostOptimizationMonitor) SetAutoRollback(enabled bool) {
+	m.autoRollback = enabled
+}
+
+// Monitor watches the optimized unit's pods until the window passes or a
+// regression is found. Any OOMKill after monitoring starts is a regression, as
+// is the number of ready pods staying below its starting value for two checks
+// in a row. If ctx is canceled first, what was seen so far is returned with
+// ctx's error.
+func (m *PostOptimizationMonitor) Monitor(ctx context.Context, config *OptimizedConfiguration) (*MonitorResult, error) {
+	if m.app.K8s == nil || m.app.K8s.Clientset == nil {
+		return nil, fmt.Errorf("kubernetes client required to monitor optimizations")
+	}
+	return m.monitor(ctx, m.app.K8s.Clientset, config)
+}
+
+func (m *PostOptimizationMonitor) monitor(ctx context.Context, client kubernetes.Interface, config *OptimizedConfiguration) (*MonitorResult, error) {
+	if config == nil || config.OptimizedUnit == nil {
+		return nil, fmt.Errorf("no optimized unit to monitor")
+	}
+	manifest, err := config.OptimizedUnit.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	kind, _ := manifest["kind"].(string)
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+	if namespace == "" {
+		namespace = m.app.Namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	selector := workloadPodSelector(manifest)
+	if selector == "" {
+		return nil, fmt.Errorf("cannot find pod labels for %s/%s", kind, name)
+	}
+
+	result := &MonitorResult{
+		Verdict:   VerdictStable,
+		Workload:  fmt.Sprintf("%s/%s", kind, name),
+		StartedAt: time.Now(),
+	}
+	m.app.Logger.Printf("👀 Monitoring %s for %v after optimization", result.Workload, m.window)
+
+	deadline := time.NewTimer(m.window)
+	defer deadline.Stop()
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	// An optimization that lowers replicas is expected to end with fewer ready pods
+	desiredReplicas := -1
+	if spec, ok := manifest["spec"].(map[string]interface{}); ok {
+		switch v := spec["replicas"].(type) {
+		case int:
+			desiredReplicas = v
+		case float64:
+			desiredReplicas = int(v)
+		}
+	}
+
+	baselineReady, belowBaseline := -1, 0
+watch:
+	for {
+		oomKills, ready, err := m.inspectPods(ctx, client, namespace, selector, result.StartedAt)
+		if err != nil {
+			m.app.Logger.Printf("⚠️  Could not inspect pods of %s: %v", result.Workload, err)
+		} else {
+			if baselineReady < 0 {
+				baselineReady = ready
+				if desiredReplicas >= 0 && desiredReplicas < baselineReady {
+					baselineReady = desiredReplicas
+				}
+			}
+			if ready < baselineReady {
+				belowBaseline++
+			} else {
+				belowBaseline = 0
+			}
+
+			result.OOMKills = oomKills
+			if belowBaseline >= 2 {
+				result.ReadinessRegressions = append(result.ReadinessRegressions,
+					fmt.Sprintf("ready pods dropped from %d to %d", baselineReady, ready))
+			}
+			if len(result.OOMKills) > 0 || len(result.ReadinessRegressions) > 0 {
+				result.Verdict = VerdictRegressed
+				break watch
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			result.EndedAt = time.Now()
+			return result, ctx.Err()
+		case <-deadline.C:
+			break watch
+		case <-ticker.C:
+		}
+	}
+	result.EndedAt = time.Now()
+
+	if result.Verdict == VerdictStable {
+		m.app.Logger.Printf("✅ %s stable after optimization", result.Workload)
+		return result, nil
+	}
+
+	m.app.Logger.Printf("⚠️  %s regressed after optimization: OOMKills %v, readiness %v",
+		result.Workload, result.OOMKills, result.ReadinessRegressions)
+	if m.autoRollback {
+		if err := m.engine.ApplyRollback(config); err != nil {
+			return result, fmt.Errorf("failed to roll back %s: %v", result.Workload, err)
+		}
+		result.RolledBack = true
+	}
+
+	return result, nil
+}
+
+// inspectPods returns the OOMKills since the given time and the number of ready
+// pods matching selector
+func (m *PostOptimizationMonitor) inspectPods(ctx context.Context, client kubernetes.Interface, namespace, selector string, since time.Time) ([]string, int, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	kills := make(map[string]bool)
+	podNames := make(map[string]bool, len(pods.Items))
+	ready := 0
+	for _, pod := range pods.Items {
+		podNames[pod.Name] = true
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				ready++
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if terminated != nil && terminated.Reason == "OOMKilled" && terminated.FinishedAt.Time.After(since) {
+					kills[fmt.Sprintf("%s/%s", pod.Name, status.Name)] = true
+				}
+			}
+		}
+	}
+
+	// Only the workload's pods' events, not every event in the namespace
+	for podName := range podNames {
+		events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("involvedObject.name", podName).String(),
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list events: %v", err)
+		}
+		for _, event := range events.Items {
+			if !oomKillReasons[event.Reason] || !event.LastTimestamp.Time.After(since) && !event.EventTime.Time.After(since) {
+				continue
+			}
+			if event.InvolvedObject.Kind == "Pod" && event.InvolvedObject.Name == podName {
+				kills[podName] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(kills))
+	for name := range kills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, ready, nil
+}
+
+// workloadPodSelector builds a label selector for the workload's pods from
+// spec.selector.matchLabels, falling back to the pod template labels
+func workloadPodSelector(manifest map[string]interface{}) string {
+	spec, _ := manifest["spec"].(map[string]interface{})
+	selector, _ := spec["selector"].(map[string]interface{})
+	labels, _ := selector["matchLabels"].(map[string]interface{})
+	if len(labels) == 0 {
+		template, _ := spec["template"].(map[string]interface{})
+		metadata, _ := template["metadata"].(map[string]interface{})
+		labels, _ = metadata["labels"].(map[string]interface{})
+	}
+
+	terms := make([]string, 0, len(labels))
+	for k, v := range labels {
+		terms = append(terms, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, ",")
+}
@@ -0,0 +1,220 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func monitoredPod(name string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", Labels: map[string]string{"app": "web"}},
+		Status: corev1.PodStatus{
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "web", Ready: ready}},
+		},
+	}
+}
+
+// Test watching an applied optimization for OOMKills and readiness regressions
+func TestPostOptimizationMonitor(t *testing.T) {
+	optimized := &Unit{
+		Slug: "web-optimized",
+		Data: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: apps\nspec:\n  selector:\n    matchLabels:\n      app: web\n",
+	}
+	config := &OptimizedConfiguration{OptimizedUnit: optimized}
+	app := &DevOpsApp{Logger: log.New(io.Discard, "", 0)}
+
+	newMonitor := func() *PostOptimizationMonitor {
+		monitor := NewPostOptimizationMonitor(app, uuid.New())
+		monitor.SetWindow(150 * time.Millisecond)
+		monitor.SetPollInterval(10 * time.Millisecond)
+		return monitor
+	}
+
+	t.Run("Stable", func(t *testing.T) {
+		client := fake.NewSimpleClientset(monitoredPod("web-1", true), monitoredPod("web-2", true))
+		result, err := newMonitor().monitor(context.Background(), client, config)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictStable, result.Verdict)
+		assert.Equal(t, "Deployment/web", result.Workload)
+		assert.Empty(t, result.OOMKills)
+		assert.False(t, result.RolledBack)
+	})
+
+	t.Run("EarlierOOMKillIgnored", func(t *testing.T) {
+		pod := monitoredPod("web-1", true)
+		pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+			Reason:     "OOMKilled",
+			FinishedAt: metav1.NewTime(time.Now().Add(-time.Hour)),
+		}
+		result, err := newMonitor().monitor(context.Background(), fake.NewSimpleClientset(pod), config)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictStable, result.Verdict)
+	})
+
+	t.Run("OOMKilledContainer", func(t *testing.T) {
+		client := fake.NewSimpleClientset(monitoredPod("web-1", true))
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			pod := monitoredPod("web-1", true)
+			pod.Status.ContainerStatuses[0].RestartCount = 1
+			pod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+				Reason:     "OOMKilled",
+				FinishedAt: metav1.Now(),
+			}
+			client.CoreV1().Pods("apps").UpdateStatus(context.Background(), pod, metav1.UpdateOptions{})
+		}()
+
+		result, err := newMonitor().monitor(context.Background(), client, config)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictRegressed, result.Verdict)
+		assert.Equal(t, []string{"web-1/web"}, result.OOMKills)
+		assert.Less(t, result.EndedAt.Sub(result.StartedAt), 150*time.Millisecond, "stops at the first regression")
+	})
+
+	t.Run("OOMKillEvent", func(t *testing.T) {
+		client := fake.NewSimpleClientset(monitoredPod("web-1", true))
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			client.CoreV1().Events("apps").Create(context.Background(), &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{Name: "web-1.oom", Namespace: "apps"},
+				InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-1", Namespace: "apps"},
+				Reason:         "OOMKilled",
+				LastTimestamp:  metav1.Now(),
+			}, metav1.CreateOptions{})
+		}()
+
+		result, err := newMonitor().monitor(context.Background(), client, config)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictRegressed, result.Verdict)
+		assert.Equal(t, []string{"web-1"}, result.OOMKills)
+	})
+
+	t.Run("ReadinessRegression", func(t *testing.T) {
+		client := fake.NewSimpleClientset(monitoredPod("web-1", true), monitoredPod("web-2", true))
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			client.CoreV1().Pods("apps").UpdateStatus(context.Background(), monitoredPod("web-2", false), metav1.UpdateOptions{})
+		}()
+
+		result, err := newMonitor().monitor(context.Background(), client, config)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictRegressed, result.Verdict)
+		assert.Equal(t, []string{"ready pods dropped from 2 to 1"}, result.ReadinessRegressions)
+	})
+
+	t.Run("ReplicaReduction", func(t *testing.T) {
+		reduced := &OptimizedConfiguration{OptimizedUnit: &Unit{
+			Slug: "web-optimized",
+			Data: optimized.Data + "  replicas: 2\n",
+		}}
+		client := fake.NewSimpleClientset(monitoredPod("web-1", true), monitoredPod("web-2", true), monitoredPod("web-3", true))
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			client.CoreV1().Pods("apps").Delete(context.Background(), "web-3", metav1.DeleteOptions{})
+		}()
+
+		result, err := newMonitor().monitor(context.Background(), client, reduced)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictStable, result.Verdict, "scaling down to the optimized count is not a regression")
+
+		client = fake.NewSimpleClientset(monitoredPod("web-1", true), monitoredPod("web-2", true), monitoredPod("web-3", true))
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			client.CoreV1().Pods("apps").Delete(context.Background(), "web-3", metav1.DeleteOptions{})
+			client.CoreV1().Pods("apps").UpdateStatus(context.Background(), monitoredPod("web-2", false), metav1.UpdateOptions{})
+		}()
+
+		result, err = newMonitor().monitor(context.Background(), client, reduced)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictRegressed, result.Verdict)
+		assert.Equal(t, []string{"ready pods dropped from 2 to 1"}, result.ReadinessRegressions)
+	})
+
+	t.Run("AutoRollback", func(t *testing.T) {
+		var updates int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				atomic.AddInt32(&updates, 1)
+				json.NewEncoder(w).Encode(Unit{})
+				return
+			}
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Unit": Unit{UnitID: uuid.New(), Slug: "web-optimized", Data: optimized.Data + "  template:\n    spec:\n      containers:\n      - name: web\n        resources:\n          limits:\n            memory: 64Mi\n"}},
+			})
+		}))
+		defer server.Close()
+
+		rollbackApp := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+		monitor := NewPostOptimizationMonitor(rollbackApp, uuid.New())
+		monitor.SetWindow(time.Second)
+		monitor.SetPollInterval(10 * time.Millisecond)
+		monitor.SetAutoRollback(true)
+
+		pod := monitoredPod("web-1", true)
+		pod.Status.ContainerStatuses[0].State.Terminated = &corev1.ContainerStateTerminated{Reason: "OOMKilled", FinishedAt: metav1.NewTime(time.Now().Add(time.Second))}
+		rollback := &OptimizedConfiguration{
+			OptimizedUnit: optimized,
+			RollbackPlan: &RollbackPlan{
+				OptimizedUnitSlug: "web-optimized",
+				Steps: []RollbackStep{{Type: "memory", Resources: map[string]map[string]interface{}{
+					"web": {"limits": map[string]interface{}{"memory": "256Mi"}},
+				}}},
+			},
+		}
+
+		result, err := monitor.monitor(context.Background(), fake.NewSimpleClientset(pod), rollback)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictRegressed, result.Verdict)
+		assert.True(t, result.RolledBack)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&updates))
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := newMonitor().monitor(ctx, fake.NewSimpleClientset(), config)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// A cancellation mid-window keeps what was already seen
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		result, err := newMonitor().monitor(ctx, fake.NewSimpleClientset(monitoredPod("web-1", true)), config)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NotNil(t, result)
+		assert.Equal(t, "Deployment/web", result.Workload)
+		assert.False(t, result.EndedAt.IsZero())
+	})
+
+	t.Run("NodeOOMKillingEventIgnored", func(t *testing.T) {
+		// The kernel OOM killer reports against the Node; its kills show up
+		// in container statuses instead
+		client := fake.NewSimpleClientset(monitoredPod("web-1", true), &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "node-1.oom", Namespace: "apps"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "web-1"},
+			Reason:         "OOMKilling",
+			LastTimestamp:  metav1.NewTime(time.Now().Add(time.Second)),
+		})
+		result, err := newMonitor().monitor(context.Background(), client, config)
+		require.NoError(t, err)
+		assert.Equal(t, VerdictStable, result.Verdict)
+	})
+}
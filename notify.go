@@ -0,0 +1,176 @@
+// notify.go - Alert notification module for the DevOps SDK
+//
+// This module lets analyzers push alerts instead of waiting for someone to
+// read a report. Notifications are optional: analyzers without a Notifier
+// send nothing.
+//
+// Features:
+// - Notifier interface for custom destinations
+// - Slack incoming webhook and generic JSON webhook notifiers
+// - Alerts with severity, a TableWriter summary and a deep link
+// - Hooks for over-budget cost analyses and waste above a threshold
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertSeverity ranks how urgent an alert is
+type AlertSeverity string
+
+const (
+	AlertInfo     AlertSeverity = "info"
+	AlertWarning  AlertSeverity = "warning"
+	AlertCritical AlertSeverity = "critical"
+)
+
+// Alert is a notification raised by an analyzer
+type Alert struct {
+	Severity  AlertSeverity `json:"severity"`
+	Title     string        `json:"title"`
+	Summary   string        `json:"summary"` // Table rendered with TableWriter
+	Link      string        `json:"link,omitempty"`
+	SpaceID   string        `json:"spaceId,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Notifier delivers alerts, e.g. to Slack or an incident system
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// WebhookNotifier posts each alert as JSON to a URL
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string // Extra request headers, e.g. Authorization
+	Client  *http.Client      // Default: 10s timeout
+}
+
+// NewWebhookNotifier creates a notifier posting alerts to url
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify posts the alert as JSON
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.Client, n.URL, n.Headers, alert)
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // Default: 10s timeout
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook URL
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{WebhookURL: webhookURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify posts the alert as a Slack message, with the summary table in a code block
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("*[%s] %s*", strings.ToUpper(string(alert.Severity)), alert.Title))
+	if alert.Summary != "" {
+		text.WriteString("\n```\n" + strings.TrimRight(alert.Summary, "\n") + "\n```")
+	}
+	if alert.Link != "" {
+		text.WriteString(fmt.Sprintf("\n<%s|Open in ConfigHub>", alert.Link))
+	}
+
+	return postJSON(ctx, n.Client, n.WebhookURL, nil, map[string]string{"text": text.String()})
+}
+
+// postJSON posts body as JSON and fails on non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("send alert: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// spaceLink is the ConfigHub UI link for a space on the client's host
+func spaceLink(cub *ConfigHubClient, spaceID uuid.UUID) string {
+	if cub == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/space/%s", strings.TrimSuffix(strings.TrimSuffix(cub.baseURL, "/"), "/api"), spaceID)
+}
+
+// budgetAlert builds the alert for budgets an analysis is over
+func budgetAlert(cub *ConfigHubClient, spaceID uuid.UUID, analysis *SpaceCostAnalysis, over []*BudgetStatus) Alert {
+	table := NewTable("Scope", "Budget", "Cost", "Used", "Over")
+	table.SetAlignment(AlignRight, 1, 2, 3, 4)
+	for _, status := range over {
+		table.AddRow(status.Scope,
+			fmt.Sprintf("$%.2f", status.MonthlyBudget),
+			fmt.Sprintf("$%.2f", status.MonthlyCost),
+			fmt.Sprintf("%.0f%%", status.PercentConsumed),
+			fmt.Sprintf("$%.2f", status.ProjectedOverage))
+	}
+
+	return Alert{
+		Severity:  AlertCritical,
+		Title:     fmt.Sprintf("Space %s is over budget", analysis.SpaceName),
+		Summary:   table.Render(),
+		Link:      spaceLink(cub, spaceID),
+		SpaceID:   spaceID.String(),
+		Timestamp: time.Now(),
+	}
+}
+
+// wasteAlert builds the alert for a waste analysis above the threshold,
+// listing the units wasting the most
+func wasteAlert(cub *ConfigHubClient, spaceID uuid.UUID, analysis *SpaceWasteAnalysis) Alert {
+	table := NewTable("Unit", "Severity", "Wasted/mo")
+	table.SetAlignment(AlignRight, 2)
+	for _, detection := range analysis.UnitWasteDetections {
+		if detection.WastedMonthlyCost > 0 {
+			table.AddRow(detection.UnitName, detection.WasteSeverity, fmt.Sprintf("$%.2f", detection.WastedMonthlyCost))
+		}
+	}
+	table.SortBy(2, true, true)
+	table.AddFooter("Total", fmt.Sprintf("%.1f%%", analysis.WastePercent), fmt.Sprintf("$%.2f", analysis.TotalWastedCost))
+
+	return Alert{
+		Severity:  AlertWarning,
+		Title:     fmt.Sprintf("Space %s is wasting $%.2f/month", analysis.SpaceName, analysis.TotalWastedCost),
+		Summary:   table.Render(),
+		Link:      spaceLink(cub, spaceID),
+		SpaceID:   spaceID.String(),
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,138 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier keeps every alert it is sent
+type recordingNotifier struct {
+	alerts []Alert
+	err    error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return n.err
+}
+
+// Test the Slack and webhook notifiers
+func TestNotifiers(t *testing.T) {
+	alert := Alert{Severity: AlertCritical, Title: "Space shop is over budget", Summary: "| a | b |\n", Link: "https://hub.example.com/space/1"}
+
+	t.Run("Webhook", func(t *testing.T) {
+		var got Alert
+		var auth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+		defer server.Close()
+
+		notifier := NewWebhookNotifier(server.URL)
+		notifier.Headers = map[string]string{"Authorization": "Bearer secret"}
+		require.NoError(t, notifier.Notify(context.Background(), alert))
+		assert.Equal(t, alert.Title, got.Title)
+		assert.Equal(t, AlertCritical, got.Severity)
+		assert.Equal(t, "Bearer secret", auth)
+	})
+
+	t.Run("Slack", func(t *testing.T) {
+		var got map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+		defer server.Close()
+
+		require.NoError(t, NewSlackNotifier(server.URL).Notify(context.Background(), alert))
+		assert.Equal(t, "*[CRITICAL] Space shop is over budget*\n```\n| a | b |\n```\n<https://hub.example.com/space/1|Open in ConfigHub>", got["text"])
+	})
+
+	t.Run("ErrorStatus", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := NewSlackNotifier(server.URL).Notify(context.Background(), alert)
+		assert.EqualError(t, err, "send alert: status 403: invalid_token")
+	})
+}
+
+// Test analyzers raising budget and waste alerts
+func TestAnalyzerAlerts(t *testing.T) {
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 2\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n"
+	unitID := uuid.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Unit": Unit{UnitID: unitID, Slug: "web", Data: deployment}},
+		})
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL+"/api", "test-token"), Logger: log.New(io.Discard, "", 0)}
+	spaceID := uuid.New()
+
+	t.Run("OverBudget", func(t *testing.T) {
+		notifier := &recordingNotifier{}
+		ca := NewCostAnalyzer(app, spaceID)
+		ca.SetBudgetAlert(notifier)
+
+		_, err := ca.AnalyzeSpace()
+		require.NoError(t, err)
+		assert.Empty(t, notifier.alerts, "no budget, no alert")
+
+		ca.SetBudget(1)
+		_, err = ca.AnalyzeSpace()
+		require.NoError(t, err)
+		require.Len(t, notifier.alerts, 1)
+		alert := notifier.alerts[0]
+		assert.Equal(t, AlertCritical, alert.Severity)
+		assert.Contains(t, alert.Title, "over budget")
+		assert.Contains(t, alert.Summary, "space")
+		assert.Equal(t, server.URL+"/space/"+spaceID.String(), alert.Link)
+	})
+
+	t.Run("NotifierErrorIgnored", func(t *testing.T) {
+		ca := NewCostAnalyzer(app, spaceID)
+		ca.SetBudget(1)
+		ca.SetBudgetAlert(&recordingNotifier{err: errors.New("slack down")})
+		_, err := ca.AnalyzeSpace()
+		assert.NoError(t, err)
+	})
+
+	t.Run("WasteThreshold", func(t *testing.T) {
+		usage := usageByUnitID{unitID.String(): {
+			UnitID:                   unitID.String(),
+			CPUUtilizationPercent:    10,
+			MemoryUtilizationPercent: 20,
+			AverageReplicas:          2,
+			UptimePercent:            100,
+		}}
+
+		notifier := &recordingNotifier{}
+		wa := NewWasteAnalyzer(app, spaceID)
+		wa.SetWasteAlert(notifier, 1e6)
+		analysis, err := wa.AnalyzeWasteFromSource(context.Background(), usage)
+		require.NoError(t, err)
+		require.Greater(t, analysis.TotalWastedCost, 0.0)
+		assert.Empty(t, notifier.alerts, "waste below the threshold")
+
+		wa.SetWasteAlert(notifier, analysis.TotalWastedCost/2)
+		_, err = wa.AnalyzeWasteFromSource(context.Background(), usage)
+		require.NoError(t, err)
+		require.Len(t, notifier.alerts, 1)
+		assert.Equal(t, AlertWarning, notifier.alerts[0].Severity)
+		assert.Contains(t, notifier.alerts[0].Summary, "web")
+		assert.Contains(t, notifier.alerts[0].Summary, "Total")
+	})
+}
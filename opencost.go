@@ -0,0 +1,375 @@
+// opencost.go - OpenCost connector for the DevOps SDK
+//
+// This module fetches actual cost data from an OpenCost deployment and
+// utilization data from Prometheus, and turns them into ActualUsageMetrics
+// that the WasteAnalyzer can compare against ConfigHub estimates.
+//
+// Features:
+// - Query the OpenCost /allocation API aggregated by controller
+// - Normalize allocation costs to a monthly figure
+// - Query Prometheus for average and peak CPU/memory usage per workload
+// - UsageSource adapters so the WasteAnalyzer can pull usage lazily per unit
+// - One-call waste analysis backed by real cost data
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenCostProvider fetches actual workload costs from OpenCost
+type OpenCostProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// openCostAllocationResponse is the response shape of GET /allocation
+type openCostAllocationResponse struct {
+	Code    int                             `json:"code"`
+	Message string                          `json:"message,omitempty"`
+	Data    []map[string]openCostAllocation `json:"data"`
+}
+
+// openCostAllocation is a single allocation entry
+type openCostAllocation struct {
+	Name      string  `json:"name"`
+	Minutes   float64 `json:"minutes"`
+	TotalCost float64 `json:"totalCost"`
+}
+
+// NewOpenCostProvider creates a provider for the OpenCost API at baseURL
+// (e.g. "http://opencost.opencost.svc:9003")
+func NewOpenCostProvider(baseURL string) *OpenCostProvider {
+	return &OpenCostProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// FetchAllocations returns the actual cost of each workload over the given
+// window, normalized to a monthly (30 day) figure and keyed by workload name
+func (p *OpenCostProvider) FetchAllocations(window time.Duration) (map[string]float64, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("allocation window must be positive")
+	}
+
+	query := url.Values{}
+	query.Set("window", formatOpenCostWindow(window))
+	query.Set("aggregate", "controller")
+	query.Set("accumulate", "true")
+
+	resp, err := p.client.Get(p.baseURL + "/allocation?" + query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OpenCost: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenCost response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OpenCost error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result openCostAllocationResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenCost response: %w", err)
+	}
+
+	monthlyMinutes := DefaultHoursPerMonth * 60
+	costs := make(map[string]float64)
+	for _, set := range result.Data {
+		for key, allocation := range set {
+			// Unallocated and idle buckets are not workloads
+			if strings.HasPrefix(key, "__") {
+				continue
+			}
+
+			minutes := allocation.Minutes
+			if minutes <= 0 {
+				minutes = window.Minutes()
+			}
+
+			costs[openCostWorkloadName(key)] += allocation.TotalCost * (monthlyMinutes / minutes)
+		}
+	}
+
+	return costs, nil
+}
+
+// formatOpenCostWindow formats a duration as an OpenCost window (e.g. "7d", "36h")
+func formatOpenCostWindow(window time.Duration) string {
+	if window%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", int(window.Hours()/24))
+	}
+	if window%time.Hour == 0 {
+		return fmt.Sprintf("%dh", int(window.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(window.Minutes()))
+}
+
+// openCostWorkloadName strips the controller kind from keys like "deployment:frontend"
+func openCostWorkloadName(key string) string {
+	if idx := strings.LastIndex(key, ":"); idx >= 0 {
+		return key[idx+1:]
+	}
+	return key
+}
+
+// prometheusQueryResponse is the response shape of GET /api/v1/query
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   struct {
+		Result []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryPrometheusScalar runs an instant query and returns the first sample value
+func queryPrometheusScalar(ctx context.Context, client *http.Client, baseURL, promQL string) (float64, error) {
+	query := url.Values{}
+	query.Set("query", promQL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(baseURL, "/")+"/api/v1/query?"+query.Encode(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read Prometheus response: %w", err)
+	}
+
+	var result prometheusQueryResponse
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Bad queries and timeouts still carry the Prometheus error message
+		if json.Unmarshal(body, &result) == nil && result.Error != "" {
+			return 0, fmt.Errorf("Prometheus error %d: %s", resp.StatusCode, result.Error)
+		}
+		return 0, fmt.Errorf("Prometheus error %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse Prometheus response: %w", err)
+	}
+
+	if result.Status != "success" {
+		return 0, fmt.Errorf("Prometheus query failed: %s", result.Error)
+	}
+
+	if len(result.Data.Result) == 0 || len(result.Data.Result[0].Value) < 2 {
+		return 0, nil // No samples
+	}
+
+	valueStr, _ := result.Data.Result[0].Value[1].(string)
+	return strconv.ParseFloat(valueStr, 64)
+}
+
+// prometheusPodPattern matches the pod names of a workload: <name>-<replicaset
+// hash>-<pod hash> for Deployments, <name>-<pod hash> for DaemonSets and Jobs,
+// and <name>-<ordinal> for StatefulSets. Anchoring to the hash suffix keeps
+// unit "api" from matching the pods of "api-gateway".
+func prometheusPodPattern(name string) string {
+	const hash = "[bcdfghjklmnpqrstvwxz2456789]"
+	pattern := fmt.Sprintf("%s-(%s{6,10}-)?(%s{5}|[0-9]+)", regexp.QuoteMeta(name), hash, hash)
+	// PromQL string literals use Go escapes, so QuoteMeta's backslashes are doubled
+	return strings.ReplaceAll(pattern, `\`, `\\`)
+}
+
+// fetchPrometheusUsage builds utilization metrics for a workload from Prometheus
+func fetchPrometheusUsage(ctx context.Context, client *http.Client, prometheusURL string, estimate UnitCostEstimate, window time.Duration) (ActualUsageMetrics, error) {
+	now := time.Now()
+	usage := ActualUsageMetrics{
+		UnitID:         estimate.UnitID,
+		UnitName:       estimate.UnitName,
+		Space:          estimate.Space,
+		TimeRangeStart: now.Add(-window),
+		TimeRangeEnd:   now,
+		CostWindow:     window,
+	}
+
+	podPattern := prometheusPodPattern(estimate.UnitName)
+	pods := fmt.Sprintf(`pod=~"%s",container!=""`, podPattern)
+	promWindow := formatOpenCostWindow(window)
+
+	queries := map[string]string{
+		"cpu-avg":  fmt.Sprintf(`avg_over_time(sum(rate(container_cpu_usage_seconds_total{%s}[5m]))[%s:5m])`, pods, promWindow),
+		"cpu-max":  fmt.Sprintf(`max_over_time(sum(rate(container_cpu_usage_seconds_total{%s}[5m]))[%s:5m])`, pods, promWindow),
+		"mem-avg":  fmt.Sprintf(`avg_over_time(sum(container_memory_working_set_bytes{%s})[%s:5m])`, pods, promWindow),
+		"mem-max":  fmt.Sprintf(`max_over_time(sum(container_memory_working_set_bytes{%s})[%s:5m])`, pods, promWindow),
+		"replicas": fmt.Sprintf(`avg_over_time(count(count by (pod)(container_memory_working_set_bytes{%s}))[%s:5m])`, pods, promWindow),
+		"egress":   fmt.Sprintf(`sum(increase(container_network_transmit_bytes_total{pod=~"%s"}[%s]))`, podPattern, promWindow),
+	}
+	for _, q := range []string{"0.5", "0.95", "0.99"} {
+		queries["cpu-p"+q] = fmt.Sprintf(`quantile_over_time(%s, sum(rate(container_cpu_usage_seconds_total{%s}[5m]))[%s:5m])`, q, pods, promWindow)
+		queries["mem-p"+q] = fmt.Sprintf(`quantile_over_time(%s, sum(container_memory_working_set_bytes{%s})[%s:5m])`, q, pods, promWindow)
+	}
+
+	values := make(map[string]float64)
+	for name, promQL := range queries {
+		value, err := queryPrometheusScalar(ctx, client, prometheusURL, promQL)
+		if err != nil {
+			return usage, fmt.Errorf("%s query: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	// Prometheus sums across all pods; waste analysis compares per-pod figures
+	replicas := values["replicas"]
+	if replicas <= 0 {
+		replicas = float64(estimate.Replicas)
+	}
+	usage.AverageReplicas = replicas
+	if estimate.Replicas > 0 {
+		usage.UptimePercent = math.Min(replicas/float64(estimate.Replicas)*100, 100)
+	}
+
+	perPod := math.Max(replicas, 1)
+	usage.CPUCoresUsed = values["cpu-avg"] / perPod
+	usage.MemoryBytesUsed = int64(values["mem-avg"] / perPod)
+	usage.NetworkBytesTotal = int64(values["egress"])
+
+	if allocatedCores := float64(estimate.CPU.MilliValue()) / 1000.0; allocatedCores > 0 {
+		usage.CPUUtilizationPercent = usage.CPUCoresUsed / allocatedCores * 100
+		usage.CPUPeakPercent = values["cpu-max"] / perPod / allocatedCores * 100
+		usage.CPUP50 = values["cpu-p0.5"] / perPod / allocatedCores * 100
+		usage.CPUP95 = values["cpu-p0.95"] / perPod / allocatedCores * 100
+		usage.CPUP99 = values["cpu-p0.99"] / perPod / allocatedCores * 100
+	}
+	if allocatedBytes := float64(estimate.Memory.BytesValue()); allocatedBytes > 0 {
+		usage.MemoryUtilizationPercent = float64(usage.MemoryBytesUsed) / allocatedBytes * 100
+		usage.MemoryPeakPercent = values["mem-max"] / perPod / allocatedBytes * 100
+		usage.MemoryP50 = values["mem-p0.5"] / perPod / allocatedBytes * 100
+		usage.MemoryP95 = values["mem-p0.95"] / perPod / allocatedBytes * 100
+		usage.MemoryP99 = values["mem-p0.99"] / perPod / allocatedBytes * 100
+	}
+
+	return usage, nil
+}
+
+// PrometheusUsageSource is a UsageSource that queries Prometheus for each
+// unit's utilization over a trailing window
+type PrometheusUsageSource struct {
+	baseURL string
+	window  time.Duration
+	client  *http.Client
+}
+
+// NewPrometheusUsageSource creates a usage source for the Prometheus API at
+// baseURL (e.g. "http://prometheus-server.monitoring.svc")
+func NewPrometheusUsageSource(baseURL string, window time.Duration) *PrometheusUsageSource {
+	return &PrometheusUsageSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		window:  window,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Usage returns the unit's utilization, or ok=false if Prometheus has no
+// samples for its pods
+func (s *PrometheusUsageSource) Usage(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+	usage, err := fetchPrometheusUsage(ctx, s.client, s.baseURL, estimate, s.window)
+	if err != nil {
+		return usage, false, err
+	}
+	return usage, usage.CPUCoresUsed > 0 || usage.MemoryBytesUsed > 0, nil
+}
+
+// OpenCostUsageSource is a UsageSource combining actual costs from OpenCost
+// with utilization from Prometheus. Allocations are fetched once, on first use.
+type OpenCostUsageSource struct {
+	provider   *OpenCostProvider
+	prometheus *PrometheusUsageSource
+	window     time.Duration
+
+	mu          sync.Mutex
+	allocations map[string]float64
+}
+
+// NewOpenCostUsageSource creates a usage source for the OpenCost API at
+// openCostURL and the Prometheus API at prometheusURL
+func NewOpenCostUsageSource(openCostURL, prometheusURL string, window time.Duration) *OpenCostUsageSource {
+	return &OpenCostUsageSource{
+		provider:   NewOpenCostProvider(openCostURL),
+		prometheus: NewPrometheusUsageSource(prometheusURL, window),
+		window:     window,
+	}
+}
+
+// Usage returns the unit's utilization and actual monthly cost, or ok=false
+// if OpenCost has no allocation for it
+func (s *OpenCostUsageSource) Usage(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+	allocations, err := s.loadAllocations()
+	if err != nil {
+		return ActualUsageMetrics{}, false, err
+	}
+	actualCost, ok := allocations[estimate.UnitName]
+	if !ok {
+		return ActualUsageMetrics{}, false, nil
+	}
+
+	usage, err := fetchPrometheusUsage(ctx, s.prometheus.client, s.prometheus.baseURL, estimate, s.window)
+	if err != nil {
+		return usage, false, fmt.Errorf("failed to fetch Prometheus usage: %w", err)
+	}
+	usage.ActualMonthlyCost = actualCost
+	return usage, true, nil
+}
+
+// loadAllocations fetches OpenCost allocations once and caches them
+func (s *OpenCostUsageSource) loadAllocations() (map[string]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.allocations == nil {
+		allocations, err := s.provider.FetchAllocations(s.window)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch OpenCost allocations: %w", err)
+		}
+		s.allocations = allocations
+	}
+	return s.allocations, nil
+}
+
+// AnalyzeWasteWithOpenCost runs waste analysis for a space using actual costs
+// from OpenCost and utilization from Prometheus (PROMETHEUS_URL)
+func AnalyzeWasteWithOpenCost(app *DevOpsApp, spaceSlug, openCostURL string) (*SpaceWasteAnalysis, error) {
+	space, err := app.Cub.GetSpaceBySlug(spaceSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find space %s: %v", spaceSlug, err)
+	}
+
+	window := GetEnvDuration("OPENCOST_WINDOW", 7*24*time.Hour)
+	prometheusURL := GetEnvOrDefault("PROMETHEUS_URL", "http://prometheus-server.monitoring.svc")
+
+	// Fetch allocations up front so an unreachable OpenCost fails the analysis
+	source := NewOpenCostUsageSource(openCostURL, prometheusURL, window)
+	if _, err := source.loadAllocations(); err != nil {
+		return nil, err
+	}
+
+	analysis, err := NewWasteAnalyzer(app, space.SpaceID).AnalyzeWasteFromSource(context.Background(), source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze waste: %v", err)
+	}
+
+	return analysis, nil
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test OpenCost allocations normalized to a monthly cost
+func TestFetchAllocations(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/allocation", r.URL.Path)
+		assert.Equal(t, "7d", r.URL.Query().Get("window"))
+		assert.Equal(t, "controller", r.URL.Query().Get("aggregate"))
+		fmt.Fprint(w, `{"code":200,"data":[{
+			"deployment:web": {"name":"deployment:web","minutes":10080,"totalCost":7},
+			"statefulset:db": {"name":"statefulset:db","minutes":0,"totalCost":14},
+			"__idle__": {"name":"__idle__","minutes":10080,"totalCost":100}
+		}]}`)
+	}))
+	defer server.Close()
+
+	costs, err := NewOpenCostProvider(server.URL + "/").FetchAllocations(7 * 24 * time.Hour)
+	require.NoError(t, err)
+	weeks := DefaultHoursPerMonth / (7 * 24)
+	assert.Len(t, costs, 2, "idle costs are not a workload")
+	assert.InDelta(t, 7*weeks, costs["web"], 0.0001)
+	assert.InDelta(t, 14*weeks, costs["db"], 0.0001, "missing minutes fall back to the window")
+
+	_, err = NewOpenCostProvider(server.URL).FetchAllocations(0)
+	assert.Error(t, err)
+
+	t.Run("ServerError", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "aggregation failed", http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+		_, err := NewOpenCostProvider(failing.URL).FetchAllocations(time.Hour)
+		assert.ErrorContains(t, err, "OpenCost error 500: aggregation failed")
+	})
+
+	t.Run("UsageSource", func(t *testing.T) {
+		calls = 0
+		prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"status":"success","data":{"result":[{"value":[0,"1"]}]}}`)
+		}))
+		defer prometheus.Close()
+
+		source := NewOpenCostUsageSource(server.URL, prometheus.URL, 7*24*time.Hour)
+		usage, ok, err := source.Usage(context.Background(), UnitCostEstimate{UnitName: "web", Replicas: 1})
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.InDelta(t, 7*weeks, usage.ActualMonthlyCost, 0.0001)
+
+		_, ok, err = source.Usage(context.Background(), UnitCostEstimate{UnitName: "cache"})
+		require.NoError(t, err)
+		assert.False(t, ok, "no allocation for the unit")
+		assert.Equal(t, 1, calls, "allocations are fetched once")
+	})
+}
+
+// Test the Prometheus queries and their error handling
+func TestPrometheusUsage(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query().Get("query"))
+		mu.Unlock()
+		fmt.Fprint(w, `{"status":"success","data":{"result":[{"value":[0,"2"]}]}}`)
+	}))
+	defer server.Close()
+
+	estimate := UnitCostEstimate{UnitName: "api", Replicas: 2, CPU: ParseQuantity("1"), Memory: ParseQuantity("1Gi")}
+	_, err := fetchPrometheusUsage(context.Background(), server.Client(), server.URL, estimate, 24*time.Hour)
+	require.NoError(t, err)
+
+	var replicas string
+	for _, query := range queries {
+		if strings.HasPrefix(query, "avg_over_time(count(") {
+			replicas = query
+		}
+	}
+	assert.Contains(t, replicas, "count(count by (pod)(container_memory_working_set_bytes{", "replicas count pods, not containers")
+
+	t.Run("PodPattern", func(t *testing.T) {
+		// PromQL unquotes the pattern and anchors it at both ends
+		compile := func(name string) *regexp.Regexp {
+			pattern, err := strconv.Unquote(`"` + prometheusPodPattern(name) + `"`)
+			require.NoError(t, err)
+			return regexp.MustCompile("^(?:" + pattern + ")$")
+		}
+
+		api := compile("api")
+		assert.True(t, api.MatchString("api-7d4b9c8f6d-x2xkz"), "Deployment pod")
+		assert.True(t, api.MatchString("api-x2xkz"), "DaemonSet pod")
+		assert.True(t, api.MatchString("api-0"), "StatefulSet pod")
+		assert.False(t, api.MatchString("api-gateway-7d4b9c8f6d-x2xkz"), "another workload with the same prefix")
+		assert.False(t, api.MatchString("api"))
+
+		dotted := compile("web.v2")
+		assert.True(t, dotted.MatchString("web.v2-0"))
+		assert.False(t, dotted.MatchString("webxv2-0"), "dots are escaped")
+	})
+
+	t.Run("ErrorStatus", func(t *testing.T) {
+		status, body := http.StatusBadRequest, `{"status":"error","errorType":"bad_data","error":"parse error at char 5"}`
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}))
+		defer failing.Close()
+
+		_, err := queryPrometheusScalar(context.Background(), failing.Client(), failing.URL, "up")
+		assert.ErrorContains(t, err, "Prometheus error 400: parse error at char 5")
+
+		status, body = http.StatusBadGateway, "upstream unavailable"
+		_, err = queryPrometheusScalar(context.Background(), failing.Client(), failing.URL, "up")
+		assert.ErrorContains(t, err, "Prometheus error 502: upstream unavailable")
+
+		_, _, err = NewPrometheusUsageSource(failing.URL, time.Hour).Usage(context.Background(), estimate)
+		assert.Error(t, err)
+	})
+}
@@ -18,34 +18,56 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
-	"gopkg.in/yaml.v3"
 )
 
 // OptimizationEngine provides intelligent configuration optimization
 type OptimizationEngine struct {
-	app          *DevOpsApp
-	spaceID      uuid.UUID
-	costAnalyzer *CostAnalyzer
-	safetyConfig *SafetyConfiguration
+	app             *DevOpsApp
+	spaceID         uuid.UUID
+	costAnalyzer    *CostAnalyzer
+	safetyConfig    *SafetyConfiguration
+	tierLabel       string                          // Unit label selecting a tier's safety configuration
+	tierSafety      map[string]*SafetyConfiguration // Keyed by tierLabel value; other units use safetyConfig
+	replicaStrategy ReplicaStrategy
+	concurrency     int    // Max units optimized in parallel
+	applyInPlace    bool   // Patch the original unit instead of creating a -optimized copy
+	policy          string // CEL expression optimized manifests must satisfy before applying
+	aggressiveAI    bool   // Let Claude lower risk ratings and safety settings, not only raise them
+	overrideGuard   bool   // Write to prod even when the risk assessment recommends a lower phase
 }
 
+// ReplicaStrategy controls how replica optimizations are applied
+type ReplicaStrategy int
+
+const (
+	StrategyStaticReplicas ReplicaStrategy = iota // Lower spec.replicas directly
+	StrategyHPA                                   // Emit a HorizontalPodAutoscaler instead of changing spec.replicas
+)
+
 // SafetyConfiguration defines safety margins and risk thresholds
 type SafetyConfiguration struct {
 	CPUSafetyMargin     float64 // Additional CPU buffer (e.g., 0.2 = 20%)
 	MemorySafetyMargin  float64 // Additional memory buffer
+	StorageSafetyMargin float64 // Additional buffer on recommended PVC sizes
 	MinCPUCores         float64 // Minimum CPU allocation
 	MinMemoryGB         float64 // Minimum memory allocation
 	MinReplicas         int32   // Minimum replica count
 	MaxReplicaReduction float64 // Maximum replica reduction ratio
+	GeneratePDB         bool    // Emit a PodDisruptionBudget when reducing replicas
+	PreserveQoSClass    bool    // Keep requests == limits for containers that had them equal
 	RiskThresholds      RiskThresholds
 }
 
@@ -61,10 +83,12 @@ type RiskThresholds struct {
 var DefaultSafetyConfiguration = &SafetyConfiguration{
 	CPUSafetyMargin:     0.20,  // 20% safety margin
 	MemorySafetyMargin:  0.15,  // 15% safety margin
+	StorageSafetyMargin: 0.25,  // 25% headroom for volume growth
 	MinCPUCores:         0.1,   // 100m minimum
 	MinMemoryGB:         0.128, // 128Mi minimum
 	MinReplicas:         1,
 	MaxReplicaReduction: 0.5, // Don't reduce replicas by more than 50%
+	PreserveQoSClass:    true,
 	RiskThresholds: RiskThresholds{
 		LowRiskCPUReduction:     0.30,
 		LowRiskMemoryReduction:  0.25,
@@ -81,8 +105,31 @@ type OptimizedConfiguration struct {
 	EstimatedSavings CostSavings            `json:"estimatedSavings"`
 	RiskAssessment   OptimizationRisk       `json:"riskAssessment"`
 	AppliedSafety    SafetyMargins          `json:"appliedSafety"`
+	AdditionalUnits  []*Unit                `json:"additionalUnits,omitempty"` // Sibling units (e.g., HPA) to create alongside
+	RollbackPlan     *RollbackPlan          `json:"rollbackPlan,omitempty"`
+	AppliedInPlace   bool                   `json:"appliedInPlace"`             // Original unit patched rather than a new unit created
+	ChangeSetID      *uuid.UUID             `json:"changeSetId,omitempty"`      // ChangeSet holding the in-place patches
+	AIRecommendation *AIRecommendation      `json:"aiRecommendation,omitempty"` // Set by OptimizeSpaceWithAI
+}
+
+// AIRecommendation is Claude's structured review of an optimized configuration
+type AIRecommendation struct {
+	RiskLevel               string   `json:"riskLevel"` // LOW, MEDIUM, HIGH as rated by Claude
+	RiskRationale           string   `json:"riskRationale"`
+	AdditionalOptimizations []string `json:"additionalOptimizations"`
+	Monitoring              []string `json:"monitoring"`
+	Rollback                []string `json:"rollback"`
+
+	// Suggested safety settings; nil keeps the engine's. Margins are fractions
+	// (0.3 = 30%) bounded to [0, MaxAISafetyMargin].
+	CPUSafetyMargin    *float64 `json:"cpuSafetyMargin,omitempty"`
+	MemorySafetyMargin *float64 `json:"memorySafetyMargin,omitempty"`
+	MinReplicas        *int32   `json:"minReplicas,omitempty"`
 }
 
+// MaxAISafetyMargin bounds the safety margins Claude may suggest
+const MaxAISafetyMargin = 1.0
+
 // ResourceOptimization describes a specific optimization applied
 type ResourceOptimization struct {
 	Type             string  `json:"type"` // cpu, memory, replicas, storage
@@ -90,7 +137,8 @@ type ResourceOptimization struct {
 	OptimizedValue   string  `json:"optimizedValue"`
 	ReductionPercent float64 `json:"reductionPercent"`
 	Reasoning        string  `json:"reasoning"`
-	Risk             string  `json:"risk"` // LOW, MEDIUM, HIGH
+	Risk             string  `json:"risk"`             // LOW, MEDIUM, HIGH
+	Target           string  `json:"target,omitempty"` // Volume claim template name for storage
 }
 
 // CostSavings represents estimated cost savings
@@ -116,10 +164,15 @@ type OptimizationRisk struct {
 	Mitigations      []string `json:"mitigations"`
 	Confidence       float64  `json:"confidence"`       // 0.0 to 1.0
 	RecommendedPhase string   `json:"recommendedPhase"` // dev, staging, prod
+
+	// AdditionalOpportunities are further optimizations suggested by Claude
+	// for a human to evaluate; they are not applied
+	AdditionalOpportunities []string `json:"additionalOpportunities,omitempty"`
 }
 
 // SafetyMargins shows applied safety margins
 type SafetyMargins struct {
+	Profile             string  `json:"profile"` // "<label>=<value>" of the tier configuration used, or DefaultSafetyProfile
 	CPUMarginApplied    bool    `json:"cpuMarginApplied"`
 	MemoryMarginApplied bool    `json:"memoryMarginApplied"`
 	ReplicaFloorApplied bool    `json:"replicaFloorApplied"`
@@ -127,6 +180,10 @@ type SafetyMargins struct {
 	ActualMemoryMargin  float64 `json:"actualMemoryMargin"`
 }
 
+// DefaultSafetyProfile is SafetyMargins.Profile for units optimized with the
+// engine's default safety configuration
+const DefaultSafetyProfile = "default"
+
 // WasteMetrics represents detected waste (placeholder for future waste.go integration)
 type WasteMetrics struct {
 	CPUWastePercent     float64       `json:"cpuWastePercent"`
@@ -145,6 +202,7 @@ func NewOptimizationEngine(app *DevOpsApp, spaceID uuid.UUID) *OptimizationEngin
 		spaceID:      spaceID,
 		costAnalyzer: NewCostAnalyzer(app, spaceID),
 		safetyConfig: DefaultSafetyConfiguration,
+		concurrency:  runtime.NumCPU(),
 	}
 }
 
@@ -153,16 +211,130 @@ func (oe *OptimizationEngine) SetSafetyConfiguration(config *SafetyConfiguration
 	oe.safetyConfig = config
 }
 
-// GenerateOptimizedUnit creates an optimized version of a ConfigHub unit
+// SetTierSafetyConfigurations sets safety configurations per value of a unit
+// label, e.g. SetTierSafetyConfigurations("tier", map[string]*SafetyConfiguration{
+// "critical": critical, "batch": batch}). Units without a matching label value
+// use the configuration from SetSafetyConfiguration.
+func (oe *OptimizationEngine) SetTierSafetyConfigurations(labelKey string, tiers map[string]*SafetyConfiguration) {
+	oe.tierLabel = labelKey
+	oe.tierSafety = tiers
+}
+
+// forUnit returns the engine to optimize unit with and the name of its safety
+// profile. A tier match gets a copy of the engine using the tier's
+// configuration, so concurrent optimizations don't share it.
+func (oe *OptimizationEngine) forUnit(unit *Unit) (*OptimizationEngine, string) {
+	if oe.tierLabel == "" {
+		return oe, DefaultSafetyProfile
+	}
+	value := unit.Labels[oe.tierLabel]
+	config := oe.tierSafety[value]
+	if config == nil {
+		return oe, DefaultSafetyProfile
+	}
+	tiered := *oe
+	tiered.safetyConfig = config
+	return &tiered, fmt.Sprintf("%s=%s", oe.tierLabel, value)
+}
+
+// SetConcurrency sets how many units BulkOptimizeUnits optimizes in parallel
+func (oe *OptimizationEngine) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	oe.concurrency = n
+}
+
+// SetApplyInPlace makes CreateOptimizedUnitInConfigHub patch the original unit's
+// resources and replicas with ConfigHub functions, grouped in a ChangeSet,
+// instead of creating a -optimized copy. The space's environment label is
+// checked by the same prod guard as new units.
+func (oe *OptimizationEngine) SetApplyInPlace(inPlace bool) {
+	oe.applyInPlace = inPlace
+}
+
+// SetPolicy sets a CEL expression, e.g. resources.requests.cpu >= "100m", that
+// every optimized manifest must satisfy before it is applied. An empty
+// expression disables the check.
+func (oe *OptimizationEngine) SetPolicy(celExpr string) {
+	oe.policy = celExpr
+}
+
+// SetAllowAggressiveAI lets OptimizeSpaceWithAI apply Claude's suggestions
+// that lower a risk rating, safety margin or replica floor. By default only
+// suggestions that make an optimization more conservative are applied.
+func (oe *OptimizationEngine) SetAllowAggressiveAI(allow bool) {
+	oe.aggressiveAI = allow
+}
+
+// SetOverrideProdGuard lets CreateOptimizedUnitInConfigHub and
+// ApplyConfigurationsAsChangeSet write optimizations to a prod space (including
+// in-place patches) even when their risk assessment recommends
+// validating them in dev or staging first
+func (oe *OptimizationEngine) SetOverrideProdGuard(override bool) {
+	oe.overrideGuard = override
+}
+
+// SetReplicaStrategy selects how replica optimizations are applied
+func (oe *OptimizationEngine) SetReplicaStrategy(strategy ReplicaStrategy) {
+	oe.replicaStrategy = strategy
+}
+
+// SetPricing sets the pricing model used for savings estimates, e.g. one with
+// a committed-use discount (see CostAnalyzer.SetCommitment)
+func (oe *OptimizationEngine) SetPricing(pricing *PricingModel) {
+	oe.costAnalyzer.SetPricing(pricing)
+}
+
+// GenerateOptimizedUnit creates an optimized version of a ConfigHub unit.
+// The generated manifests are re-parsed and validated; a *ManifestValidationError
+// names the field at fault if the optimization produced an invalid manifest.
 func (oe *OptimizationEngine) GenerateOptimizedUnit(unit *Unit, wasteMetrics *WasteMetrics) (*OptimizedConfiguration, error) {
+	_, span := startSpan(context.Background(), "OptimizationEngine.GenerateOptimizedUnit")
+	defer span.End()
+	span.SetAttribute("space.id", oe.spaceID.String())
+	span.SetAttribute("unit.slug", unit.Slug)
+
 	oe.app.Logger.Printf("🔧 Optimizing unit: %s", unit.Slug)
 
-	// Parse the Kubernetes manifest
-	var manifest map[string]interface{}
-	if err := yaml.Unmarshal([]byte(unit.Data), &manifest); err != nil {
+	engine, profile := oe.forUnit(unit)
+	span.SetAttribute("safety.profile", profile)
+	return engine.generateOptimizedUnit(unit, wasteMetrics, profile)
+}
+
+// generateOptimizedUnit optimizes unit with the engine's safety configuration,
+// recording profile as the safety profile used
+func (oe *OptimizationEngine) generateOptimizedUnit(unit *Unit, wasteMetrics *WasteMetrics, profile string) (*OptimizedConfiguration, error) {
+	// Parse the Kubernetes manifests
+	manifests, err := unit.Manifests()
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse manifest: %v", err)
 	}
 
+	var config *OptimizedConfiguration
+	if len(manifests) > 1 {
+		config, err = oe.optimizeDocuments(unit, manifests, wasteMetrics)
+	} else {
+		var manifest map[string]interface{}
+		if len(manifests) == 1 {
+			manifest = manifests[0]
+		}
+		config, err = oe.optimizeManifest(unit, manifest, wasteMetrics)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, generated := range append([]*Unit{config.OptimizedUnit}, config.AdditionalUnits...) {
+		if err := validateOptimizedManifests(generated); err != nil {
+			return nil, err
+		}
+	}
+	config.AppliedSafety.Profile = profile
+	return config, nil
+}
+
+// optimizeManifest optimizes a single workload document of unit
+func (oe *OptimizationEngine) optimizeManifest(unit *Unit, manifest map[string]interface{}, wasteMetrics *WasteMetrics) (*OptimizedConfiguration, error) {
 	kind, _ := manifest["kind"].(string)
 
 	switch kind {
@@ -177,10 +349,128 @@ func (oe *OptimizationEngine) GenerateOptimizedUnit(unit *Unit, wasteMetrics *Wa
 	}
 }
 
+// optimizeDocuments optimizes every Deployment, StatefulSet and DaemonSet in a
+// multi-document unit with the unit's waste metrics, leaving Services, HPAs
+// and other documents untouched, and merges the results into one optimized
+// unit holding all documents
+func (oe *OptimizationEngine) optimizeDocuments(unit *Unit, manifests []map[string]interface{}, wasteMetrics *WasteMetrics) (*OptimizedConfiguration, error) {
+	optimizedManifests := make([]map[string]interface{}, len(manifests))
+	var merged *OptimizedConfiguration
+	var rollbackSteps []RollbackStep
+	var documentAnnotations []map[string]string
+	var mitigations []string
+	documentSavings := CostSavings{}
+
+	for i, manifest := range manifests {
+		optimizedManifests[i] = manifest
+		kind, _ := manifest["kind"].(string)
+		if kind != "Deployment" && kind != "StatefulSet" && kind != "DaemonSet" {
+			continue
+		}
+
+		// Optimize the document as if it were a unit of its own; generated
+		// siblings of later workloads get the workload name in their slug
+		metadata, _ := manifest["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		document := *unit
+		if merged != nil {
+			document.Slug = fmt.Sprintf("%s-%s", unit.Slug, name)
+		}
+		if err := document.setManifest(manifest, false); err != nil {
+			return nil, fmt.Errorf("failed to marshal manifest: %v", err)
+		}
+		config, err := oe.optimizeManifest(&document, manifest, wasteMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to optimize %s %s: %v", kind, document.Slug, err)
+		}
+
+		if optimizedManifests[i], err = config.OptimizedUnit.Manifest(); err != nil {
+			return nil, fmt.Errorf("failed to parse optimized manifest: %v", err)
+		}
+		for _, step := range oe.buildRollbackPlan(unit, manifest, unit.Slug+"-optimized", config.Optimizations, config.AdditionalUnits).Steps {
+			if step.Resources != nil {
+				step.Workload = fmt.Sprintf("%s/%s", kind, name)
+			}
+			rollbackSteps = append(rollbackSteps, step)
+		}
+		documentAnnotations = append(documentAnnotations, config.OptimizedUnit.Annotations)
+		documentSavings.CurrentMonthlyCost += config.EstimatedSavings.CurrentMonthlyCost
+		documentSavings.OptimizedMonthlyCost += config.EstimatedSavings.OptimizedMonthlyCost
+		documentSavings.MonthlySavings += config.EstimatedSavings.MonthlySavings
+		documentSavings.Breakdown.CPUSavings += config.EstimatedSavings.Breakdown.CPUSavings
+		documentSavings.Breakdown.MemorySavings += config.EstimatedSavings.Breakdown.MemorySavings
+		documentSavings.Breakdown.StorageSavings += config.EstimatedSavings.Breakdown.StorageSavings
+
+		if merged == nil {
+			merged = config
+			mitigations = config.RiskAssessment.Mitigations
+			continue
+		}
+		merged.Optimizations = append(merged.Optimizations, config.Optimizations...)
+		merged.AdditionalUnits = append(merged.AdditionalUnits, config.AdditionalUnits...)
+		mitigations = append(mitigations, config.RiskAssessment.Mitigations...)
+		merged.AppliedSafety.CPUMarginApplied = merged.AppliedSafety.CPUMarginApplied || config.AppliedSafety.CPUMarginApplied
+		merged.AppliedSafety.MemoryMarginApplied = merged.AppliedSafety.MemoryMarginApplied || config.AppliedSafety.MemoryMarginApplied
+		merged.AppliedSafety.ReplicaFloorApplied = merged.AppliedSafety.ReplicaFloorApplied || config.AppliedSafety.ReplicaFloorApplied
+	}
+	if merged == nil {
+		return nil, fmt.Errorf("unsupported resource type for optimization: no Deployment, StatefulSet or DaemonSet in %s", unit.Slug)
+	}
+
+	// Rebuild the optimized unit around every document
+	optimizedUnit := merged.OptimizedUnit
+	optimizedUnit.UpstreamUnitID = &unit.UnitID
+	if err := optimizedUnit.setManifests(optimizedManifests, isBase64UnitData(unit.Data)); err != nil {
+		return nil, fmt.Errorf("failed to marshal optimized manifest: %v", err)
+	}
+	optimizedUnit.Annotations = oe.createOptimizedAnnotations(unit.Annotations, merged.Optimizations)
+	for _, annotations := range documentAnnotations {
+		for k, v := range annotations {
+			if strings.HasPrefix(k, "optimizer.io/storage-recommendation-") {
+				optimizedUnit.Annotations[k] = v
+			}
+		}
+	}
+	optimizedUnit.Annotations["optimizer.io/qos-class-original"] = documentAnnotations[0]["optimizer.io/qos-class-original"]
+	optimizedUnit.Annotations["optimizer.io/qos-class-optimized"] = documentAnnotations[0]["optimizer.io/qos-class-optimized"]
+
+	rollbackPlan := oe.buildRollbackPlan(unit, nil, optimizedUnit.Slug, nil, nil)
+	rollbackPlan.Steps = append(rollbackPlan.Steps, rollbackSteps...)
+	encodedPlan, err := EncodeRollbackPlan(rollbackPlan)
+	if err != nil {
+		return nil, err
+	}
+	optimizedUnit.Annotations[RollbackPlanAnnotation] = encodedPlan
+
+	if documentSavings.CurrentMonthlyCost > 0 {
+		documentSavings.SavingsPercent = documentSavings.MonthlySavings / documentSavings.CurrentMonthlyCost * 100
+	}
+	// Reassess risk over all optimizations, keeping per-document mitigations
+	// such as generated PodDisruptionBudgets
+	merged.RiskAssessment = oe.assessOptimizationRisk(merged.Optimizations, wasteMetrics.WasteConfidence)
+	seen := make(map[string]bool)
+	for _, mitigation := range merged.RiskAssessment.Mitigations {
+		seen[mitigation] = true
+	}
+	for _, mitigation := range mitigations {
+		if !seen[mitigation] {
+			seen[mitigation] = true
+			merged.RiskAssessment.Mitigations = append(merged.RiskAssessment.Mitigations, mitigation)
+		}
+	}
+
+	merged.OriginalUnit = unit
+	merged.EstimatedSavings = documentSavings
+	merged.RollbackPlan = rollbackPlan
+	return merged, nil
+}
+
 // optimizeDeployment optimizes a Deployment resource
 func (oe *OptimizationEngine) optimizeDeployment(unit *Unit, manifest map[string]interface{}, waste *WasteMetrics) (*OptimizedConfiguration, error) {
 	optimizations := []ResourceOptimization{}
 	appliedSafety := SafetyMargins{}
+	var additionalUnits []*Unit
+	var newReplicas int32 // Replica floor after optimization, 0 if unchanged
 
 	// Create a deep copy of the manifest for optimization
 	optimizedManifest := copyManifest(manifest)
@@ -191,63 +481,111 @@ func (oe *OptimizationEngine) optimizeDeployment(unit *Unit, manifest map[string
 		return nil, fmt.Errorf("no resource specifications found")
 	}
 
+	// Size replicas and per-pod resources together against observed demand
+	plan := oe.planCapacity(currentResources, waste)
+
 	// Optimize CPU
-	if waste.CPUWastePercent > 0.1 { // Only optimize if >10% waste
-		cpuOpt := oe.optimizeCPU(currentResources.CPU, waste.CPUWastePercent, waste.WasteConfidence)
-		if cpuOpt != nil {
-			optimizations = append(optimizations, *cpuOpt)
-			oe.applyCPUOptimization(optimizedManifest, cpuOpt.OptimizedValue)
-			appliedSafety.CPUMarginApplied = true
-			appliedSafety.ActualCPUMargin = oe.safetyConfig.CPUSafetyMargin
-		}
+	cpuOpt := oe.resizeCPU(currentResources.CPU, plan.CPUMillis,
+		fmt.Sprintf("Observed peak of %.0fm CPU across replicas, sized %d replicas with %.1f%% safety margin",
+			plan.DemandCPUMillis, plan.Replicas, oe.safetyConfig.CPUSafetyMargin*100))
+	if cpuOpt != nil {
+		optimizations = append(optimizations, *cpuOpt)
+		oe.applyCPUOptimization(optimizedManifest, cpuOpt.OptimizedValue)
+		appliedSafety.CPUMarginApplied = true
+		appliedSafety.ActualCPUMargin = oe.safetyConfig.CPUSafetyMargin
+	} else {
+		plan.CPUMillis = float64(currentResources.CPU.MilliValue()) // Too small a change; requests stay as they are
 	}
 
 	// Optimize Memory
-	if waste.MemoryWastePercent > 0.1 { // Only optimize if >10% waste
-		memOpt := oe.optimizeMemory(currentResources.Memory, waste.MemoryWastePercent, waste.WasteConfidence)
-		if memOpt != nil {
-			optimizations = append(optimizations, *memOpt)
-			oe.applyMemoryOptimization(optimizedManifest, memOpt.OptimizedValue)
-			appliedSafety.MemoryMarginApplied = true
-			appliedSafety.ActualMemoryMargin = oe.safetyConfig.MemorySafetyMargin
-		}
+	memOpt := oe.resizeMemory(currentResources.Memory, plan.MemoryBytes,
+		fmt.Sprintf("Observed peak of %.0fMi memory across replicas, sized %d replicas with %.1f%% safety margin",
+			plan.DemandMemoryBytes/(1024*1024), plan.Replicas, oe.safetyConfig.MemorySafetyMargin*100))
+	if memOpt != nil {
+		optimizations = append(optimizations, *memOpt)
+		oe.applyMemoryOptimization(optimizedManifest, memOpt.OptimizedValue)
+		appliedSafety.MemoryMarginApplied = true
+		appliedSafety.ActualMemoryMargin = oe.safetyConfig.MemorySafetyMargin
 	}
 
 	// Optimize Replicas
-	if waste.IdleReplicas > 0 {
-		replicaOpt := oe.optimizeReplicas(currentResources.Replicas, waste.IdleReplicas)
-		if replicaOpt != nil {
+	replicaOpt := oe.optimizeReplicas(currentResources.Replicas, plan.Replicas,
+		fmt.Sprintf("Detected %d idle replicas, %d replicas carry observed demand with a minimum of %d replicas",
+			waste.IdleReplicas, plan.Replicas, oe.safetyConfig.MinReplicas))
+	if replicaOpt != nil {
+		newReplicas = plan.Replicas
+		if oe.replicaStrategy == StrategyHPA {
+			// Let an autoscaler reclaim idle replicas instead of hardcoding a lower count
+			hpaUnit, hpaOpt, err := oe.generateHPAUnit(unit, manifest, currentResources.Replicas, replicaOpt, plan)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate HPA: %v", err)
+			}
+			optimizations = append(optimizations, *hpaOpt)
+			additionalUnits = append(additionalUnits, hpaUnit)
+		} else {
 			optimizations = append(optimizations, *replicaOpt)
 			oe.applyReplicaOptimization(optimizedManifest, replicaOpt.OptimizedValue)
-			if currentResources.Replicas <= oe.safetyConfig.MinReplicas {
+			if plan.Replicas <= oe.safetyConfig.MinReplicas {
 				appliedSafety.ReplicaFloorApplied = true
 			}
 		}
 	}
 
-	// Create optimized unit
-	optimizedData, err := yaml.Marshal(optimizedManifest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal optimized manifest: %v", err)
+	// Recommend smaller volumeClaimTemplates; the manifest keeps the current size
+	var storageOpts []ResourceOptimization
+	if waste.StorageWastePercent > 0.1 {
+		storageOpts = oe.optimizeStorage(manifest, waste.StorageWastePercent, waste.WasteConfidence)
+		optimizations = append(optimizations, storageOpts...)
 	}
 
+	// Create optimized unit
 	optimizedUnit := &Unit{
 		UnitID:         uuid.New(),
 		SpaceID:        unit.SpaceID,
 		Slug:           unit.Slug + "-optimized",
 		DisplayName:    unit.DisplayName + " (Optimized)",
-		Data:           string(optimizedData),
 		Labels:         oe.createOptimizedLabels(unit.Labels),
 		Annotations:    oe.createOptimizedAnnotations(unit.Annotations, optimizations),
 		UpstreamUnitID: &unit.UnitID, // Maintain upstream relationship
 	}
+	if err := optimizedUnit.setManifest(optimizedManifest, isBase64UnitData(unit.Data)); err != nil {
+		return nil, fmt.Errorf("failed to marshal optimized manifest: %v", err)
+	}
+	optimizedUnit.Annotations["optimizer.io/qos-class-original"] = podQoSClass(manifest)
+	optimizedUnit.Annotations["optimizer.io/qos-class-optimized"] = podQoSClass(optimizedManifest)
+	for _, opt := range storageOpts {
+		optimizedUnit.Annotations["optimizer.io/storage-recommendation-"+opt.Target] =
+			fmt.Sprintf("%s -> %s (requires PVC migration)", opt.OriginalValue, opt.OptimizedValue)
+	}
 
 	// Calculate cost savings
 	costSavings := oe.calculateCostSavings(unit, optimizedUnit)
+	oe.addStorageSavings(&costSavings, storageOpts, currentResources.Replicas)
 
 	// Assess risk
 	riskAssessment := oe.assessOptimizationRisk(optimizations, waste.WasteConfidence)
 
+	// Protect availability during node drains when running fewer replicas
+	if oe.safetyConfig.GeneratePDB && newReplicas > 1 {
+		pdbUnit, minAvailable, err := oe.generatePDBUnit(unit, manifest, newReplicas)
+		if err != nil {
+			oe.app.Logger.Printf("⚠️  Skipping PodDisruptionBudget for %s: %v", unit.Slug, err)
+		} else {
+			additionalUnits = append(additionalUnits, pdbUnit)
+			riskAssessment.Mitigations = append(riskAssessment.Mitigations,
+				fmt.Sprintf("PodDisruptionBudget added (minAvailable: %d) to protect availability during node drains", minAvailable))
+		}
+	}
+
+	// Embed the rollback plan so it survives loss of the in-memory result;
+	// built last so it covers every generated unit
+	rollbackPlan := oe.buildRollbackPlan(unit, manifest, optimizedUnit.Slug, optimizations, additionalUnits)
+	encodedPlan, err := EncodeRollbackPlan(rollbackPlan)
+	if err != nil {
+		return nil, err
+	}
+	optimizedUnit.Annotations[RollbackPlanAnnotation] = encodedPlan
+
 	return &OptimizedConfiguration{
 		OriginalUnit:     unit,
 		OptimizedUnit:    optimizedUnit,
@@ -255,6 +593,8 @@ func (oe *OptimizationEngine) optimizeDeployment(unit *Unit, manifest map[string
 		EstimatedSavings: costSavings,
 		RiskAssessment:   riskAssessment,
 		AppliedSafety:    appliedSafety,
+		AdditionalUnits:  additionalUnits,
+		RollbackPlan:     rollbackPlan,
 	}, nil
 }
 
@@ -306,21 +646,28 @@ func (oe *OptimizationEngine) optimizeDaemonSet(unit *Unit, manifest map[string]
 	}
 
 	// Create optimized unit (similar to deployment)
-	optimizedData, err := yaml.Marshal(optimizedManifest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal optimized manifest: %v", err)
-	}
-
 	optimizedUnit := &Unit{
 		UnitID:         uuid.New(),
 		SpaceID:        unit.SpaceID,
 		Slug:           unit.Slug + "-optimized",
 		DisplayName:    unit.DisplayName + " (Optimized)",
-		Data:           string(optimizedData),
 		Labels:         oe.createOptimizedLabels(unit.Labels),
 		Annotations:    oe.createOptimizedAnnotations(unit.Annotations, optimizations),
 		UpstreamUnitID: &unit.UnitID,
 	}
+	if err := optimizedUnit.setManifest(optimizedManifest, isBase64UnitData(unit.Data)); err != nil {
+		return nil, fmt.Errorf("failed to marshal optimized manifest: %v", err)
+	}
+	optimizedUnit.Annotations["optimizer.io/qos-class-original"] = podQoSClass(manifest)
+	optimizedUnit.Annotations["optimizer.io/qos-class-optimized"] = podQoSClass(optimizedManifest)
+
+	// Embed the rollback plan so it survives loss of the in-memory result
+	rollbackPlan := oe.buildRollbackPlan(unit, manifest, optimizedUnit.Slug, optimizations, nil)
+	encodedPlan, err := EncodeRollbackPlan(rollbackPlan)
+	if err != nil {
+		return nil, err
+	}
+	optimizedUnit.Annotations[RollbackPlanAnnotation] = encodedPlan
 
 	costSavings := oe.calculateCostSavings(unit, optimizedUnit)
 	riskAssessment := oe.assessOptimizationRisk(optimizations, waste.WasteConfidence)
@@ -332,6 +679,7 @@ func (oe *OptimizationEngine) optimizeDaemonSet(unit *Unit, manifest map[string]
 		EstimatedSavings: costSavings,
 		RiskAssessment:   riskAssessment,
 		AppliedSafety:    appliedSafety,
+		RollbackPlan:     rollbackPlan,
 	}, nil
 }
 
@@ -545,6 +893,18 @@ func (oe *OptimizationEngine) optimizeCPU(current ResourceQuantity, wastePercent
 	// Apply safety margin
 	optimizedMillis = optimizedMillis * (1 + oe.safetyConfig.CPUSafetyMargin)
 
+	return oe.resizeCPU(current, optimizedMillis,
+		fmt.Sprintf("Detected %.1f%% CPU waste with %.1f%% confidence, applied %.1f%% safety margin", wastePercent*100, confidence*100, oe.safetyConfig.CPUSafetyMargin*100))
+}
+
+// resizeCPU recommends optimizedMillis of CPU per pod, raised to the minimum
+// allocation; nil if that saves less than 5%
+func (oe *OptimizationEngine) resizeCPU(current ResourceQuantity, optimizedMillis float64, reasoning string) *ResourceOptimization {
+	currentMillis := float64(current.MilliValue())
+	if currentMillis == 0 {
+		return nil
+	}
+
 	// Enforce minimum
 	minMillis := oe.safetyConfig.MinCPUCores * 1000
 	if optimizedMillis < minMillis {
@@ -565,7 +925,7 @@ func (oe *OptimizationEngine) optimizeCPU(current ResourceQuantity, wastePercent
 		OriginalValue:    current.String(),
 		OptimizedValue:   optimizedValue,
 		ReductionPercent: finalReduction * 100,
-		Reasoning:        fmt.Sprintf("Detected %.1f%% CPU waste with %.1f%% confidence, applied %.1f%% safety margin", wastePercent*100, confidence*100, oe.safetyConfig.CPUSafetyMargin*100),
+		Reasoning:        reasoning,
 		Risk:             risk,
 	}
 }
@@ -589,6 +949,18 @@ func (oe *OptimizationEngine) optimizeMemory(current ResourceQuantity, wastePerc
 	// Apply safety margin
 	optimizedBytes = optimizedBytes * (1 + oe.safetyConfig.MemorySafetyMargin)
 
+	return oe.resizeMemory(current, optimizedBytes,
+		fmt.Sprintf("Detected %.1f%% memory waste with %.1f%% confidence, applied %.1f%% safety margin", wastePercent*100, confidence*100, oe.safetyConfig.MemorySafetyMargin*100))
+}
+
+// resizeMemory recommends optimizedBytes of memory per pod, raised to the
+// minimum allocation; nil if that saves less than 5%
+func (oe *OptimizationEngine) resizeMemory(current ResourceQuantity, optimizedBytes float64, reasoning string) *ResourceOptimization {
+	currentBytes := float64(current.BytesValue())
+	if currentBytes == 0 {
+		return nil
+	}
+
 	// Enforce minimum
 	minBytes := oe.safetyConfig.MinMemoryGB * 1024 * 1024 * 1024
 	if optimizedBytes < minBytes {
@@ -610,27 +982,114 @@ func (oe *OptimizationEngine) optimizeMemory(current ResourceQuantity, wastePerc
 		OriginalValue:    current.String(),
 		OptimizedValue:   optimizedValue,
 		ReductionPercent: finalReduction * 100,
-		Reasoning:        fmt.Sprintf("Detected %.1f%% memory waste with %.1f%% confidence, applied %.1f%% safety margin", wastePercent*100, confidence*100, oe.safetyConfig.MemorySafetyMargin*100),
+		Reasoning:        reasoning,
 		Risk:             risk,
 	}
 }
 
-// optimizeReplicas generates replica optimization recommendation
-func (oe *OptimizationEngine) optimizeReplicas(current, idle int32) *ResourceOptimization {
-	if idle <= 0 || current <= oe.safetyConfig.MinReplicas {
-		return nil
+// capacityPlan is the replica count and per-pod requests a workload is
+// resized to, with the aggregate demand they were sized for
+type capacityPlan struct {
+	Replicas    int32
+	CPUMillis   float64 // Per pod; 0 when the pods request no CPU
+	MemoryBytes float64 // Per pod; 0 when the pods request no memory
+
+	DemandCPUMillis   float64 // Observed peak over all replicas
+	DemandMemoryBytes float64
+}
+
+// planCapacity sizes replicas and per-pod requests together instead of one
+// axis at a time, which double-counts savings: idle replicas' headroom is
+// also part of the measured CPU and memory waste.
+//
+// Observed peak demand is the current aggregate request less the measured
+// waste, discounted by confidence and capped like single-axis reductions;
+// waste below 10% or at under 50% confidence counts as demand. The plan
+// minimizes monthly cost
+//
+//	replicas × (cpu × CPUHourly + memory × MemoryHourly)
+//
+// subject to
+//
+//	replicas × cpu    ≥ peak CPU demand × (1 + CPUSafetyMargin)
+//	replicas × memory ≥ peak memory demand × (1 + MemorySafetyMargin)
+//
+// with replicas between the safe floor (MinReplicas, idle replicas removed,
+// at most MaxReplicaReduction) and the current count, and per-pod requests
+// between their minimums and the current requests. Ties keep fewer replicas.
+// The current shape is always a candidate, so a plan never lowers capacity
+// below demand plus margin.
+func (oe *OptimizationEngine) planCapacity(current *ResourceSpecs, waste *WasteMetrics) capacityPlan {
+	currentCPU := float64(current.CPU.MilliValue())
+	currentMemory := float64(current.Memory.BytesValue())
+	replicas := current.Replicas
+	if replicas < 1 {
+		replicas = 1
+	}
+
+	observed := func(wastePercent, maxReduction float64) float64 {
+		if wastePercent <= 0.1 || waste.WasteConfidence < 0.5 {
+			return 1
+		}
+		return 1 - math.Min(wastePercent*waste.WasteConfidence, maxReduction)
+	}
+	plan := capacityPlan{
+		Replicas:          replicas,
+		CPUMillis:         currentCPU,
+		MemoryBytes:       currentMemory,
+		DemandCPUMillis:   currentCPU * float64(replicas) * observed(waste.CPUWastePercent, 0.7), // Same caps as optimizeCPU
+		DemandMemoryBytes: currentMemory * float64(replicas) * observed(waste.MemoryWastePercent, 0.6),
+	}
+	requiredCPU := plan.DemandCPUMillis * (1 + oe.safetyConfig.CPUSafetyMargin)
+	requiredMemory := plan.DemandMemoryBytes * (1 + oe.safetyConfig.MemorySafetyMargin)
+
+	// perPod is the smallest per-pod request giving n replicas the required
+	// capacity; ok is false if it exceeds the current request
+	perPod := func(required, currentPerPod, minimum float64, n int32) (float64, bool) {
+		if currentPerPod == 0 {
+			return 0, true // Nothing requested, nothing to size
+		}
+		needed := required / float64(n)
+		if needed > currentPerPod && n != replicas {
+			return 0, false
+		}
+		return math.Min(math.Max(needed, minimum), currentPerPod), true
 	}
 
-	optimized := current - idle
-	if optimized < oe.safetyConfig.MinReplicas {
-		optimized = oe.safetyConfig.MinReplicas
+	floor := replicas
+	if waste.IdleReplicas > 0 && replicas > oe.safetyConfig.MinReplicas {
+		floor = replicas - waste.IdleReplicas
+		if limit := replicas - int32(float64(replicas)*oe.safetyConfig.MaxReplicaReduction); floor < limit {
+			floor = limit
+		}
+		if floor < oe.safetyConfig.MinReplicas {
+			floor = oe.safetyConfig.MinReplicas
+		}
 	}
 
-	reductionRatio := float64(current-optimized) / float64(current)
-	if reductionRatio > oe.safetyConfig.MaxReplicaReduction {
-		optimized = current - int32(float64(current)*oe.safetyConfig.MaxReplicaReduction)
+	pricing := oe.costAnalyzer.Pricing()
+	bestCost := math.Inf(1)
+	for n := floor; n <= replicas; n++ {
+		cpu, cpuOK := perPod(requiredCPU, currentCPU, oe.safetyConfig.MinCPUCores*1000, n)
+		memory, memOK := perPod(requiredMemory, currentMemory, oe.safetyConfig.MinMemoryGB*1024*1024*1024, n)
+		if !cpuOK || !memOK {
+			continue
+		}
+		cost := float64(n) * (cpu/1000*pricing.CPUHourly + memory/(1024*1024*1024)*pricing.MemoryHourly)
+		if cost < bestCost*(1-1e-9) { // Ties keep fewer replicas
+			bestCost = cost
+			plan.Replicas, plan.CPUMillis, plan.MemoryBytes = n, cpu, memory
+		}
 	}
 
+	// Round up to whole millicores and MiB so formatting never drops capacity
+	plan.CPUMillis = math.Min(math.Ceil(plan.CPUMillis), currentCPU)
+	plan.MemoryBytes = math.Min(math.Ceil(plan.MemoryBytes/(1024*1024))*1024*1024, currentMemory)
+	return plan
+}
+
+// optimizeReplicas recommends running optimized instead of current replicas
+func (oe *OptimizationEngine) optimizeReplicas(current, optimized int32, reasoning string) *ResourceOptimization {
 	if optimized >= current {
 		return nil // No optimization possible
 	}
@@ -646,11 +1105,85 @@ func (oe *OptimizationEngine) optimizeReplicas(current, idle int32) *ResourceOpt
 		OriginalValue:    fmt.Sprintf("%d", current),
 		OptimizedValue:   fmt.Sprintf("%d", optimized),
 		ReductionPercent: finalReduction * 100,
-		Reasoning:        fmt.Sprintf("Detected %d idle replicas, maintaining minimum of %d replicas", idle, oe.safetyConfig.MinReplicas),
+		Reasoning:        reasoning,
 		Risk:             risk,
 	}
 }
 
+// optimizeStorage recommends smaller requests for each volumeClaimTemplate.
+// PVCs can't shrink in place, so these are recommendations only: the manifest
+// is left unchanged and the new size needs a planned data migration.
+func (oe *OptimizationEngine) optimizeStorage(manifest map[string]interface{}, wastePercent, confidence float64) []ResourceOptimization {
+	if wastePercent <= 0.1 || confidence < 0.5 {
+		return nil
+	}
+
+	spec, _ := manifest["spec"].(map[string]interface{})
+	templates, _ := spec["volumeClaimTemplates"].([]interface{})
+
+	var optimizations []ResourceOptimization
+	for _, t := range templates {
+		template, _ := t.(map[string]interface{})
+		metadata, _ := template["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+
+		var current ResourceSpecs
+		oe.extractStorageSpecs(template, &current)
+		currentBytes := float64(current.Storage.BytesValue())
+		if currentBytes == 0 {
+			continue
+		}
+
+		// Keep headroom above actual usage and round up to whole Gi
+		usedBytes := currentBytes * (1 - math.Min(wastePercent*confidence, 0.8))
+		optimizedGi := math.Ceil(usedBytes*(1+oe.safetyConfig.StorageSafetyMargin)/(1024*1024*1024) - 1e-9)
+		optimizedBytes := optimizedGi * 1024 * 1024 * 1024
+
+		finalReduction := (currentBytes - optimizedBytes) / currentBytes
+		if finalReduction < 0.1 { // Not worth a volume migration
+			continue
+		}
+
+		risk := "MEDIUM" // Shrinking a volume always needs a data migration
+		if finalReduction > 0.5 {
+			risk = "HIGH"
+		}
+
+		optimizations = append(optimizations, ResourceOptimization{
+			Type:             "storage",
+			OriginalValue:    current.Storage.String(),
+			OptimizedValue:   fmt.Sprintf("%.0fGi", optimizedGi),
+			ReductionPercent: finalReduction * 100,
+			Reasoning: fmt.Sprintf("Detected %.1f%% storage waste in %s with %.1f%% confidence, applied %.1f%% safety margin; PVCs can't shrink in place, so migrate data to a new volume",
+				wastePercent*100, name, confidence*100, oe.safetyConfig.StorageSafetyMargin*100),
+			Risk:   risk,
+			Target: name,
+		})
+	}
+
+	return optimizations
+}
+
+// addStorageSavings folds recommended PVC reductions into the cost savings.
+// Each replica owns one PVC per template, so savings scale with replicas.
+func (oe *OptimizationEngine) addStorageSavings(savings *CostSavings, storageOpts []ResourceOptimization, replicas int32) {
+	var storageSavings float64
+	for _, opt := range storageOpts {
+		reclaimed := ParseQuantity(opt.OriginalValue).BytesValue() - ParseQuantity(opt.OptimizedValue).BytesValue()
+		storageSavings += float64(reclaimed) / (1024 * 1024 * 1024) * oe.costAnalyzer.pricing.StorageGB * float64(replicas)
+	}
+	if storageSavings <= 0 {
+		return
+	}
+
+	savings.Breakdown.StorageSavings += storageSavings
+	savings.MonthlySavings += storageSavings
+	savings.OptimizedMonthlyCost -= storageSavings
+	if savings.CurrentMonthlyCost > 0 {
+		savings.SavingsPercent = (savings.MonthlySavings / savings.CurrentMonthlyCost) * 100
+	}
+}
+
 // categorizeRisk categorizes optimization risk based on reduction percentage
 func (oe *OptimizationEngine) categorizeRisk(reductionPercent, lowThreshold, highThreshold float64) string {
 	if reductionPercent < lowThreshold {
@@ -832,7 +1365,8 @@ func (oe *OptimizationEngine) distributeEquallyAmongContainers(containers []inte
 	}
 }
 
-// setContainerResourceSafely sets a resource value in a container spec with proper requests/limits handling
+// setContainerResourceSafely sets a resource value in a container spec with proper requests/limits handling.
+// A container that sets only a request or only a limit for the resource keeps that shape.
 func (oe *OptimizationEngine) setContainerResourceSafely(container map[string]interface{}, resourceType, requestValue string) {
 	// Calculate appropriate limit value (typically 20-50% higher than request)
 	var limitValue string
@@ -851,19 +1385,40 @@ func (oe *OptimizationEngine) setContainerResourceSafely(container map[string]in
 		limitValue = requestValue
 	}
 
+	// Guaranteed pods (requests == limits) must stay Guaranteed
+	if oe.safetyConfig.PreserveQoSClass && hasEqualRequestsAndLimits(container, resourceType) {
+		limitValue = requestValue
+	}
+
 	if resources, ok := container["resources"].(map[string]interface{}); ok {
+		// Keep the container's shape: a container with only a limit (or only a
+		// request) for this resource must not gain the other, which would
+		// change how it is scheduled and its QoS class
+		requests, _ := resources["requests"].(map[string]interface{})
+		limits, _ := resources["limits"].(map[string]interface{})
+		_, hasRequest := requests[resourceType]
+		_, hasLimit := limits[resourceType]
+		setRequest, setLimit := true, true
+		if hasRequest != hasLimit {
+			setRequest, setLimit = hasRequest, hasLimit
+		}
+
 		// Update requests
-		if requests, ok := resources["requests"].(map[string]interface{}); ok {
-			requests[resourceType] = requestValue
-		} else {
-			resources["requests"] = map[string]interface{}{resourceType: requestValue}
+		if setRequest {
+			if requests != nil {
+				requests[resourceType] = requestValue
+			} else {
+				resources["requests"] = map[string]interface{}{resourceType: requestValue}
+			}
 		}
 
-		// Update limits - don't set them equal to requests
-		if limits, ok := resources["limits"].(map[string]interface{}); ok {
-			limits[resourceType] = limitValue
-		} else {
-			resources["limits"] = map[string]interface{}{resourceType: limitValue}
+		// Update limits - only equal to requests when preserving Guaranteed QoS
+		if setLimit {
+			if limits != nil {
+				limits[resourceType] = limitValue
+			} else {
+				resources["limits"] = map[string]interface{}{resourceType: limitValue}
+			}
 		}
 	} else {
 		container["resources"] = map[string]interface{}{
@@ -873,6 +1428,69 @@ func (oe *OptimizationEngine) setContainerResourceSafely(container map[string]in
 	}
 }
 
+// hasEqualRequestsAndLimits reports whether a container's request for a resource
+// equals its limit (a missing request defaults to the limit in Kubernetes)
+func hasEqualRequestsAndLimits(container map[string]interface{}, resourceType string) bool {
+	resources, ok := container["resources"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	limits, _ := resources["limits"].(map[string]interface{})
+	limit, ok := limits[resourceType]
+	if !ok {
+		return false
+	}
+	requests, _ := resources["requests"].(map[string]interface{})
+	request, ok := requests[resourceType]
+	if !ok {
+		return true
+	}
+
+	requestQuantity := ParseQuantity(fmt.Sprintf("%v", request))
+	limitQuantity := ParseQuantity(fmt.Sprintf("%v", limit))
+	if resourceType == "memory" {
+		return requestQuantity.BytesValue() == limitQuantity.BytesValue()
+	}
+	return requestQuantity.MilliValue() == limitQuantity.MilliValue()
+}
+
+// podQoSClass determines the Kubernetes QoS class (Guaranteed, Burstable, BestEffort)
+// of a workload manifest's pod template
+func podQoSClass(manifest map[string]interface{}) string {
+	spec, _ := manifest["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+
+	guaranteed := len(containers) > 0
+	hasAnyResources := false
+	for _, container := range containers {
+		c, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if resources, ok := c["resources"].(map[string]interface{}); ok {
+			requests, _ := resources["requests"].(map[string]interface{})
+			limits, _ := resources["limits"].(map[string]interface{})
+			if len(requests) > 0 || len(limits) > 0 {
+				hasAnyResources = true
+			}
+		}
+		if !hasEqualRequestsAndLimits(c, "cpu") || !hasEqualRequestsAndLimits(c, "memory") {
+			guaranteed = false
+		}
+	}
+
+	switch {
+	case guaranteed:
+		return "Guaranteed"
+	case hasAnyResources:
+		return "Burstable"
+	default:
+		return "BestEffort"
+	}
+}
+
 // applyReplicaOptimization applies replica optimization to manifest
 func (oe *OptimizationEngine) applyReplicaOptimization(manifest map[string]interface{}, optimizedValue string) {
 	if spec, ok := manifest["spec"].(map[string]interface{}); ok {
@@ -882,11 +1500,157 @@ func (oe *OptimizationEngine) applyReplicaOptimization(manifest map[string]inter
 	}
 }
 
+// generateHPAUnit builds a HorizontalPodAutoscaler unit that targets the workload,
+// scaling between the safe replica floor and the current replica count
+func (oe *OptimizationEngine) generateHPAUnit(unit *Unit, manifest map[string]interface{}, current int32, replicaOpt *ResourceOptimization, plan capacityPlan) (*Unit, *ResourceOptimization, error) {
+	minReplicas, err := strconv.Atoi(replicaOpt.OptimizedValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid replica floor %q: %v", replicaOpt.OptimizedValue, err)
+	}
+
+	apiVersion, _ := manifest["apiVersion"].(string)
+	kind, _ := manifest["kind"].(string)
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		name = unit.Slug
+	}
+
+	// Target the utilization the planned pods would run at when shrunk to
+	// the floor, kept within a sane autoscaling band
+	targetCPU := 80
+	if plan.CPUMillis > 0 {
+		targetCPU = int(math.Round(plan.DemandCPUMillis / (float64(minReplicas) * plan.CPUMillis) * 100))
+	}
+	if targetCPU < 50 {
+		targetCPU = 50
+	} else if targetCPU > 80 {
+		targetCPU = 80
+	}
+
+	hpaMetadata := map[string]interface{}{
+		"name": name,
+	}
+	if namespace, ok := metadata["namespace"].(string); ok && namespace != "" {
+		hpaMetadata["namespace"] = namespace
+	}
+
+	hpa := map[string]interface{}{
+		"apiVersion": "autoscaling/v2",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   hpaMetadata,
+		"spec": map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"name":       name,
+			},
+			"minReplicas": minReplicas,
+			"maxReplicas": int(current),
+			"metrics": []interface{}{
+				map[string]interface{}{
+					"type": "Resource",
+					"resource": map[string]interface{}{
+						"name": "cpu",
+						"target": map[string]interface{}{
+							"type":               "Utilization",
+							"averageUtilization": targetCPU,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	hpaOpt := &ResourceOptimization{
+		Type:             "hpa",
+		OriginalValue:    fmt.Sprintf("%d", current),
+		OptimizedValue:   fmt.Sprintf("%d-%d", minReplicas, current),
+		ReductionPercent: replicaOpt.ReductionPercent,
+		Reasoning:        fmt.Sprintf("%s; autoscaling between %d and %d replicas at %d%% CPU target", replicaOpt.Reasoning, minReplicas, current, targetCPU),
+		Risk:             "LOW", // Capacity is still available up to the current count
+	}
+
+	hpaUnit := &Unit{
+		UnitID:         uuid.New(),
+		SpaceID:        unit.SpaceID,
+		Slug:           unit.Slug + "-hpa",
+		DisplayName:    unit.DisplayName + " (HPA)",
+		Labels:         oe.createOptimizedLabels(unit.Labels),
+		Annotations:    oe.createOptimizedAnnotations(unit.Annotations, []ResourceOptimization{*hpaOpt}),
+		UpstreamUnitID: &unit.UnitID,
+	}
+	if err := hpaUnit.setManifest(hpa, isBase64UnitData(unit.Data)); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal HPA manifest: %v", err)
+	}
+
+	return hpaUnit, hpaOpt, nil
+}
+
+// generatePDBUnit builds a PodDisruptionBudget unit for the workload's pods,
+// keeping all but one of the optimized replicas available
+func (oe *OptimizationEngine) generatePDBUnit(unit *Unit, manifest map[string]interface{}, replicas int32) (*Unit, int32, error) {
+	spec, _ := manifest["spec"].(map[string]interface{})
+	selector, _ := spec["selector"].(map[string]interface{})
+	matchLabels, ok := selector["matchLabels"].(map[string]interface{})
+	if !ok || len(matchLabels) == 0 {
+		return nil, 0, fmt.Errorf("no spec.selector.matchLabels found")
+	}
+
+	minAvailable := replicas - 1
+	if minAvailable < 1 {
+		minAvailable = 1
+	}
+
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		name = unit.Slug
+	}
+
+	pdbMetadata := map[string]interface{}{
+		"name": name,
+	}
+	if namespace, ok := metadata["namespace"].(string); ok && namespace != "" {
+		pdbMetadata["namespace"] = namespace
+	}
+
+	pdb := map[string]interface{}{
+		"apiVersion": "policy/v1",
+		"kind":       "PodDisruptionBudget",
+		"metadata":   pdbMetadata,
+		"spec": map[string]interface{}{
+			"minAvailable": int(minAvailable),
+			"selector": map[string]interface{}{
+				"matchLabels": matchLabels,
+			},
+		},
+	}
+
+	annotations := oe.createOptimizedAnnotations(unit.Annotations, nil)
+	annotations["optimizer.io/pdb-min-available"] = fmt.Sprintf("%d", minAvailable)
+
+	pdbUnit := &Unit{
+		UnitID:         uuid.New(),
+		SpaceID:        unit.SpaceID,
+		Slug:           unit.Slug + "-pdb",
+		DisplayName:    unit.DisplayName + " (PDB)",
+		Labels:         oe.createOptimizedLabels(unit.Labels),
+		Annotations:    annotations,
+		UpstreamUnitID: &unit.UnitID,
+	}
+	if err := pdbUnit.setManifest(pdb, isBase64UnitData(unit.Data)); err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal PDB manifest: %v", err)
+	}
+
+	return pdbUnit, minAvailable, nil
+}
+
 // calculateCostSavings calculates estimated cost savings
 func (oe *OptimizationEngine) calculateCostSavings(original, optimized *Unit) CostSavings {
 	// Analyze costs for both units
-	originalEstimate, _ := oe.costAnalyzer.analyzeUnit(*original)
-	optimizedEstimate, _ := oe.costAnalyzer.analyzeUnit(*optimized)
+	originalEstimate, _ := oe.costAnalyzer.AnalyzeUnit(*original)
+	optimizedEstimate, _ := oe.costAnalyzer.AnalyzeUnit(*optimized)
 
 	if originalEstimate == nil || optimizedEstimate == nil {
 		return CostSavings{} // No cost data available
@@ -946,6 +1710,10 @@ func (oe *OptimizationEngine) assessOptimizationRisk(optimizations []ResourceOpt
 			mitigations = append(mitigations, "Watch for OOMKilled events and memory pressure")
 		case "replicas":
 			mitigations = append(mitigations, "Set up HPA for automatic scaling if needed")
+		case "storage":
+			mitigations = append(mitigations, fmt.Sprintf("Volume %s is not resized automatically; snapshot and migrate to a %s PVC during a maintenance window", opt.Target, opt.OptimizedValue))
+		case "hpa":
+			mitigations = append(mitigations, fmt.Sprintf("HorizontalPodAutoscaler now scales replicas (%s); review HPA scaling events after deployment", opt.OptimizedValue))
 		}
 	}
 
@@ -1018,25 +1786,570 @@ func (oe *OptimizationEngine) createOptimizedAnnotations(originalAnnotations map
 	return annotations
 }
 
-// CreateOptimizedUnitInConfigHub creates the optimized unit in ConfigHub
+// CreateOptimizedUnitInConfigHub creates the optimized unit in ConfigHub,
+// refusing manifests that fail validation with a *ManifestValidationError.
+// If the engine's space is labeled environment=prod, optimizations whose risk
+// assessment recommends dev or staging first are refused with a
+// *ProdGuardError unless SetOverrideProdGuard is set.
 func (oe *OptimizationEngine) CreateOptimizedUnitInConfigHub(config *OptimizedConfiguration) (*Unit, error) {
-	oe.app.Logger.Printf("💾 Creating optimized unit in ConfigHub: %s", config.OptimizedUnit.Slug)
-
-	unit, err := oe.app.Cub.CreateUnit(oe.spaceID, CreateUnitRequest{
-		Slug:           config.OptimizedUnit.Slug,
-		DisplayName:    config.OptimizedUnit.DisplayName,
-		Data:           config.OptimizedUnit.Data,
-		Labels:         config.OptimizedUnit.Labels,
-		Annotations:    config.OptimizedUnit.Annotations,
-		UpstreamUnitID: config.OptimizedUnit.UpstreamUnitID,
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create optimized unit: %v", err)
+	if err := oe.enforcePolicy(config); err != nil {
+		return nil, err
 	}
-
-	oe.app.Logger.Printf("✅ Optimized unit created: %s (savings: $%.2f/month)", unit.Slug, config.EstimatedSavings.MonthlySavings)
-	return unit, nil
+	// Refuse to write a manifest that was corrupted after it was generated
+	for _, generated := range append([]*Unit{config.OptimizedUnit}, config.AdditionalUnits...) {
+		if err := validateOptimizedManifests(generated); err != nil {
+			return nil, err
+		}
+	}
+	if err := oe.prodGuard()(config); err != nil {
+		return nil, err
+	}
+
+	var unit *Unit
+	var err error
+	if oe.applyInPlace {
+		unit, err = oe.patchOriginalUnit(config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		oe.app.Logger.Printf("💾 Creating optimized unit in ConfigHub: %s", config.OptimizedUnit.Slug)
+
+		unit, err = oe.app.Cub.CreateUnit(oe.spaceID, CreateUnitRequest{
+			Slug:           config.OptimizedUnit.Slug,
+			DisplayName:    config.OptimizedUnit.DisplayName,
+			Data:           config.OptimizedUnit.Data,
+			Labels:         config.OptimizedUnit.Labels,
+			Annotations:    config.OptimizedUnit.Annotations,
+			UpstreamUnitID: config.OptimizedUnit.UpstreamUnitID,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to create optimized unit: %v", err)
+		}
+
+		oe.app.Logger.Printf("✅ Optimized unit created: %s (savings: $%.2f/month)", unit.Slug, config.EstimatedSavings.MonthlySavings)
+	}
+
+	// Create sibling units (e.g., HPA) generated by the optimization
+	for _, additional := range config.AdditionalUnits {
+		_, err := oe.app.Cub.CreateUnit(oe.spaceID, CreateUnitRequest{
+			Slug:           additional.Slug,
+			DisplayName:    additional.DisplayName,
+			Data:           additional.Data,
+			Labels:         additional.Labels,
+			Annotations:    additional.Annotations,
+			UpstreamUnitID: additional.UpstreamUnitID,
+		})
+		if err != nil {
+			return unit, fmt.Errorf("failed to create unit %s: %v", additional.Slug, err)
+		}
+		oe.app.Logger.Printf("✅ Created %s alongside %s", additional.Slug, unit.Slug)
+	}
+
+	return unit, nil
+}
+
+// patchOriginalUnit applies the optimized container resources and replica count
+// to the original unit with ConfigHub functions, leaving the rest untouched.
+// All patches share one ChangeSet so they can be applied or reverted together.
+func (oe *OptimizationEngine) patchOriginalUnit(config *OptimizedConfiguration) (*Unit, error) {
+	original := config.OriginalUnit
+
+	oe.app.Logger.Printf("🩹 Patching unit in place: %s", original.Slug)
+
+	manifest, err := config.OptimizedUnit.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse optimized manifest: %v", err)
+	}
+
+	changeSet, err := oe.app.Cub.CreateChangeSet(oe.spaceID, CreateChangeSetRequest{
+		DisplayName: fmt.Sprintf("Optimize %s", original.Slug),
+		Description: fmt.Sprintf("Right-size %s (savings: $%.2f/month)", original.Slug, config.EstimatedSavings.MonthlySavings),
+		Labels:      map[string]string{"optimizer.io/optimized": "true"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create changeset: %v", err)
+	}
+
+	where := NewWhere().Eq("UnitID", original.UnitID).MustBuild()
+	var requests []FunctionInvocationRequest
+	for _, container := range podContainers(manifest) {
+		name, _ := container["name"].(string)
+		resources, _ := container["resources"].(map[string]interface{})
+		req, ok := setResourcesRequest(where, name, resourceStrings(resources["requests"]), resourceStrings(resources["limits"]))
+		if ok {
+			requests = append(requests, req)
+		}
+	}
+	for _, opt := range config.Optimizations {
+		if opt.Type != "replicas" {
+			continue
+		}
+		replicas, err := strconv.Atoi(opt.OptimizedValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replica count %q: %v", opt.OptimizedValue, err)
+		}
+		requests = append(requests, FunctionInvocationRequest{
+			FunctionName:  "set-replicas",
+			ToolchainType: "Kubernetes/YAML",
+			Where:         where,
+			Arguments: []FunctionArgument{
+				{ParameterName: "replicas", Value: replicas},
+			},
+		})
+	}
+
+	for _, req := range requests {
+		req.ChangeSetID = &changeSet.ChangeSetID
+		if _, err := oe.app.Cub.ExecuteFunction(oe.spaceID, req); err != nil {
+			return nil, fmt.Errorf("failed to run %s on %s: %v", req.FunctionName, original.Slug, err)
+		}
+	}
+
+	// Mark the original unit as optimized without touching its configuration
+	if err := oe.app.Cub.BulkSetLabels(oe.spaceID, where, oe.createOptimizedLabels(nil)); err != nil {
+		oe.app.Logger.Printf("⚠️  Failed to label %s as optimized: %v", original.Slug, err)
+	}
+
+	// The rollback plan now targets the original unit
+	if plan := config.RollbackPlan; plan != nil {
+		plan.OptimizedUnitSlug = original.Slug
+		for _, step := range plan.Steps {
+			if step.Function != nil {
+				step.Function.Where = NewWhere().Eq("Slug", original.Slug).MustBuild()
+			}
+		}
+	}
+
+	unit, err := oe.app.Cub.GetUnit(oe.spaceID, original.UnitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patched unit: %v", err)
+	}
+
+	config.AppliedInPlace = true
+	config.ChangeSetID = &changeSet.ChangeSetID
+
+	oe.app.Logger.Printf("✅ Unit patched in place: %s in changeset %s (savings: $%.2f/month)",
+		unit.Slug, changeSet.ChangeSetID, config.EstimatedSavings.MonthlySavings)
+	return unit, nil
+}
+
+// ProdGuardError reports an optimization that was refused because its risk
+// assessment recommends a lower phase than the prod space it targets
+type ProdGuardError struct {
+	Unit        string // Original unit slug
+	Environment string // Environment label of the target space
+	Risk        OptimizationRisk
+}
+
+func (e *ProdGuardError) Error() string {
+	msg := fmt.Sprintf("refusing to apply optimization of %s to %s: %s risk at %.0f%% confidence is recommended for %s first",
+		e.Unit, e.Environment, e.Risk.OverallRisk, e.Risk.Confidence*100, e.Risk.RecommendedPhase)
+	if len(e.Risk.RiskFactors) > 0 {
+		msg += fmt.Sprintf(" (%s)", strings.Join(e.Risk.RiskFactors, "; "))
+	}
+	return msg + "; validate it there or use SetOverrideProdGuard"
+}
+
+// prodGuard returns a check that refuses configurations recommended for a
+// lower phase when the engine's space is labeled as prod. The space is looked
+// up at most once, and only if a configuration needs it.
+func (oe *OptimizationEngine) prodGuard() func(config *OptimizedConfiguration) error {
+	var env string
+	var lookupErr error
+	looked := false
+
+	return func(config *OptimizedConfiguration) error {
+		risk := config.RiskAssessment
+		if oe.overrideGuard || phaseRank(risk.RecommendedPhase) == phaseRank("prod") {
+			return nil
+		}
+		if !looked {
+			looked = true
+			space, err := oe.app.Cub.GetSpace(oe.spaceID)
+			if err != nil {
+				lookupErr = fmt.Errorf("cannot determine environment of space %s for the prod guard: %v", oe.spaceID, err)
+			} else {
+				env = space.Labels[EnvironmentLabel]
+			}
+		}
+		if lookupErr != nil {
+			return lookupErr
+		}
+		if !isProdEnvironment(env) {
+			return nil
+		}
+
+		slug := config.OptimizedUnit.Slug
+		if config.OriginalUnit != nil {
+			slug = config.OriginalUnit.Slug
+		}
+		oe.app.Logger.Printf("🛑 Prod guard refused %s: recommended for %s first", slug, risk.RecommendedPhase)
+		return &ProdGuardError{Unit: slug, Environment: env, Risk: risk}
+	}
+}
+
+// isProdEnvironment reports whether an environment label names production
+func isProdEnvironment(env string) bool {
+	switch strings.ToLower(env) {
+	case "prod", "production":
+		return true
+	}
+	return false
+}
+
+// phaseRank orders deployment phases from least to most critical
+func phaseRank(phase string) int {
+	switch strings.ToLower(phase) {
+	case "dev", "development":
+		return 0
+	case "staging", "stage", "qa":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// resourceStrings converts a requests or limits block to quantity strings
+func resourceStrings(block interface{}) map[string]string {
+	m, _ := block.(map[string]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for name, value := range m {
+		result[name] = fmt.Sprintf("%v", value)
+	}
+	return result
+}
+
+// ChangeSetApplyError reports which units kept a ChangeSet from being applied
+type ChangeSetApplyError struct {
+	ChangeSetID *uuid.UUID       // Nil if validation failed before the ChangeSet was created
+	Failures    map[string]error // Unit slug -> error
+}
+
+func (e *ChangeSetApplyError) Error() string {
+	slugs := make([]string, 0, len(e.Failures))
+	for slug := range e.Failures {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	messages := make([]string, 0, len(slugs))
+	for _, slug := range slugs {
+		messages = append(messages, fmt.Sprintf("%s: %v", slug, e.Failures[slug]))
+	}
+	return fmt.Sprintf("changeset not applied, %d unit(s) failed: %s", len(e.Failures), strings.Join(messages, "; "))
+}
+
+// ApplyConfigurationsAsChangeSet writes every optimized manifest back to its
+// original unit inside one ChangeSet and applies it, so the batch deploys
+// together. Configurations are validated first, including against the policy
+// set with SetPolicy and, in a prod space, against their recommended phase
+// (see SetOverrideProdGuard); if any fails validation or its update fails, the
+// ChangeSet is not applied and a *ChangeSetApplyError lists the units involved.
+func (oe *OptimizationEngine) ApplyConfigurationsAsChangeSet(configs []*OptimizedConfiguration, displayName string) (*ChangeSet, error) {
+	oe.app.Logger.Printf("📦 Applying %d optimizations as changeset: %s", len(configs), displayName)
+
+	failures := make(map[string]error)
+	guard := oe.prodGuard()
+	for i, config := range configs {
+		if err := validateOptimizedConfiguration(config); err != nil {
+			failures[configLabel(config, i)] = err
+		} else if err := oe.enforcePolicy(config); err != nil {
+			failures[configLabel(config, i)] = err
+		} else if err := guard(config); err != nil {
+			failures[configLabel(config, i)] = err
+		}
+	}
+	if len(failures) > 0 {
+		return nil, &ChangeSetApplyError{Failures: failures}
+	}
+
+	var totalSavings float64
+	for _, config := range configs {
+		totalSavings += config.EstimatedSavings.MonthlySavings
+	}
+
+	changeSet, err := oe.app.Cub.CreateChangeSet(oe.spaceID, CreateChangeSetRequest{
+		DisplayName: displayName,
+		Description: fmt.Sprintf("Right-size %d units (savings: $%.2f/month)", len(configs), totalSavings),
+		Labels:      map[string]string{"optimizer.io/optimized": "true"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create changeset: %v", err)
+	}
+
+	for _, config := range configs {
+		// Send the whole unit; an update replaces its slug, labels and annotations
+		original := config.OriginalUnit
+		annotations := make(map[string]string, len(original.Annotations)+len(config.OptimizedUnit.Annotations))
+		for k, v := range original.Annotations {
+			annotations[k] = v
+		}
+		for k, v := range config.OptimizedUnit.Annotations {
+			annotations[k] = v
+		}
+		_, err := oe.app.Cub.UpdateUnit(oe.spaceID, original.UnitID, CreateUnitRequest{
+			Slug:           original.Slug,
+			DisplayName:    original.DisplayName,
+			Data:           config.OptimizedUnit.Data,
+			Labels:         original.Labels,
+			Annotations:    annotations,
+			UpstreamUnitID: original.UpstreamUnitID,
+			ChangeSetID:    &changeSet.ChangeSetID,
+		})
+		if err != nil {
+			failures[original.Slug] = err
+			continue
+		}
+
+		for _, additional := range config.AdditionalUnits {
+			_, err := oe.app.Cub.CreateUnit(oe.spaceID, CreateUnitRequest{
+				Slug:           additional.Slug,
+				DisplayName:    additional.DisplayName,
+				Data:           additional.Data,
+				Labels:         additional.Labels,
+				Annotations:    additional.Annotations,
+				UpstreamUnitID: additional.UpstreamUnitID,
+				ChangeSetID:    &changeSet.ChangeSetID,
+			})
+			if err != nil {
+				failures[additional.Slug] = err
+			}
+		}
+
+		config.AppliedInPlace = true
+		config.ChangeSetID = &changeSet.ChangeSetID
+	}
+
+	if len(failures) > 0 {
+		return changeSet, &ChangeSetApplyError{ChangeSetID: &changeSet.ChangeSetID, Failures: failures}
+	}
+
+	if err := oe.app.Cub.ApplyChangeSet(oe.spaceID, changeSet.ChangeSetID); err != nil {
+		return changeSet, fmt.Errorf("failed to apply changeset: %v", err)
+	}
+
+	oe.app.Logger.Printf("✅ Changeset %s applied: %d units (savings: $%.2f/month)", changeSet.ChangeSetID, len(configs), totalSavings)
+	return changeSet, nil
+}
+
+// validateOptimizedConfiguration checks a configuration can be written back
+func validateOptimizedConfiguration(config *OptimizedConfiguration) error {
+	if config.OriginalUnit == nil || config.OriginalUnit.UnitID == uuid.Nil {
+		return fmt.Errorf("missing original unit")
+	}
+	if config.OptimizedUnit == nil || config.OptimizedUnit.Data == "" {
+		return fmt.Errorf("missing optimized manifest")
+	}
+	return validateOptimizedManifests(config.OptimizedUnit)
+}
+
+// ManifestValidationError reports the field of an optimized manifest that
+// Kubernetes would reject, such as a resource value that isn't a quantity
+type ManifestValidationError struct {
+	Unit   string // Optimized unit slug
+	Field  string // e.g. spec.template.spec.containers[0].resources.limits.cpu
+	Reason string
+}
+
+func (e *ManifestValidationError) Error() string {
+	return fmt.Sprintf("invalid optimized manifest %s: %s %s", e.Unit, e.Field, e.Reason)
+}
+
+// validateOptimizedManifests re-parses a generated unit's YAML and checks
+// every document kept apiVersion, kind, metadata and, for workloads, spec,
+// and that each container resource value parses with ParseQuantity and no
+// request exceeds its limit. It returns a *ManifestValidationError naming the
+// first field at fault.
+func validateOptimizedManifests(unit *Unit) error {
+	manifests, err := unit.Manifests()
+	if err != nil {
+		return &ManifestValidationError{Unit: unit.Slug, Field: "Data", Reason: fmt.Sprintf("does not parse: %v", err)}
+	}
+	if len(manifests) == 0 {
+		return &ManifestValidationError{Unit: unit.Slug, Field: "Data", Reason: "is empty"}
+	}
+
+	for i, manifest := range manifests {
+		field, reason := validateManifestDocument(manifest)
+		if field == "" {
+			continue
+		}
+		if len(manifests) > 1 {
+			field = fmt.Sprintf("document[%d].%s", i, field)
+		}
+		return &ManifestValidationError{Unit: unit.Slug, Field: field, Reason: reason}
+	}
+	return nil
+}
+
+// validateManifestDocument returns the path of the first invalid field in a
+// single document and why it is invalid, or "" if the document is valid
+func validateManifestDocument(manifest map[string]interface{}) (string, string) {
+	for _, field := range []string{"apiVersion", "kind"} {
+		if value, _ := manifest[field].(string); value == "" {
+			return field, "is missing"
+		}
+	}
+	if _, ok := manifest["metadata"].(map[string]interface{}); !ok {
+		return "metadata", "is missing"
+	}
+
+	spec, hasSpec := manifest["spec"].(map[string]interface{})
+	switch manifest["kind"] {
+	case "Deployment", "StatefulSet", "DaemonSet", "HorizontalPodAutoscaler", "PodDisruptionBudget":
+		if !hasSpec {
+			return "spec", "is missing"
+		}
+	}
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+
+	for _, list := range []string{"containers", "initContainers"} {
+		containers, _ := podSpec[list].([]interface{})
+		for i, container := range containers {
+			c, _ := container.(map[string]interface{})
+			resources, _ := c["resources"].(map[string]interface{})
+			path := fmt.Sprintf("spec.template.spec.%s[%d].resources", list, i)
+			if field, reason := validateContainerResources(resources, path); field != "" {
+				return field, reason
+			}
+		}
+	}
+	return "", ""
+}
+
+// validateContainerResources checks a container's requests and limits
+func validateContainerResources(resources map[string]interface{}, path string) (string, string) {
+	values := make(map[string]map[string]ResourceQuantity)
+	for _, block := range []string{"requests", "limits"} {
+		entries, ok := resources[block]
+		if !ok {
+			continue
+		}
+		blockMap, ok := entries.(map[string]interface{})
+		if !ok {
+			return path + "." + block, "is not a map"
+		}
+		values[block] = make(map[string]ResourceQuantity)
+		for name, raw := range blockMap {
+			value := fmt.Sprintf("%v", raw)
+			if raw == nil || !isValidQuantity(value) {
+				return fmt.Sprintf("%s.%s.%s", path, block, name), fmt.Sprintf("is not a valid quantity: %q", value)
+			}
+			values[block][name] = ParseQuantity(value)
+		}
+	}
+
+	for name, request := range values["requests"] {
+		limit, ok := values["limits"][name]
+		if !ok {
+			continue
+		}
+		exceeds := request.MilliValue() > limit.MilliValue() && limit.MilliValue() > 0
+		if name == "memory" || name == "ephemeral-storage" {
+			exceeds = request.BytesValue() > limit.BytesValue() && limit.BytesValue() > 0
+		}
+		if exceeds {
+			return fmt.Sprintf("%s.requests.%s", path, name),
+				fmt.Sprintf("(%s) exceeds limit (%s)", request.Value, limit.Value)
+		}
+	}
+	return "", ""
+}
+
+// isValidQuantity reports whether ParseQuantity understands value. ParseQuantity
+// returns zero for anything it can't parse, so a zero result is only valid for
+// a literal zero such as "0" or "0Mi".
+func isValidQuantity(value string) bool {
+	quantity := ParseQuantity(value)
+	if quantity.MilliValue() < 0 || quantity.BytesValue() < 0 {
+		return false
+	}
+	if quantity.MilliValue() > 0 || quantity.BytesValue() > 0 {
+		return true
+	}
+	number, err := strconv.ParseFloat(strings.TrimRight(value, "mKMGTPEi"), 64)
+	return err == nil && number == 0
+}
+
+// configLabel names a configuration in errors, falling back to its position
+func configLabel(config *OptimizedConfiguration, i int) string {
+	if config.OriginalUnit != nil && config.OriginalUnit.Slug != "" {
+		return config.OriginalUnit.Slug
+	}
+	return fmt.Sprintf("config[%d]", i)
+}
+
+// PolicyViolationError reports units whose optimized manifests fail the CEL policy
+type PolicyViolationError struct {
+	Expression string
+	Units      []string // Original unit slugs
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("policy %q not satisfied by: %s", e.Expression, strings.Join(e.Units, ", "))
+}
+
+// ValidateWithPolicy runs a CEL expression against the optimized manifest.
+// ConfigHub functions only operate on stored units, so the manifest is written
+// to a temporary unit that is deleted afterwards. It returns whether the
+// manifest passed and the slugs of the original units that failed.
+func (oe *OptimizationEngine) ValidateWithPolicy(config *OptimizedConfiguration, celExpr string) (bool, []string, error) {
+	if err := validateOptimizedConfiguration(config); err != nil {
+		return false, nil, err
+	}
+	original := config.OriginalUnit
+
+	temp, err := oe.app.Cub.CreateUnit(oe.spaceID, CreateUnitRequest{
+		Slug:        fmt.Sprintf("%s-policy-check-%s", original.Slug, uuid.New().String()[:8]),
+		DisplayName: fmt.Sprintf("Policy check for %s", original.Slug),
+		Data:        config.OptimizedUnit.Data,
+		Labels:      map[string]string{"optimizer.io/policy-check": "true"},
+	})
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to create policy check unit: %v", err)
+	}
+	defer func() {
+		if err := oe.app.Cub.DeleteUnit(oe.spaceID, temp.UnitID); err != nil {
+			oe.app.Logger.Printf("⚠️  Failed to delete policy check unit %s: %v", temp.Slug, err)
+		}
+	}()
+
+	results, err := oe.app.Cub.ValidateCEL(oe.spaceID, NewWhere().Eq("UnitID", temp.UnitID).MustBuild(), celExpr)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to validate policy: %v", err)
+	}
+	if len(results) == 0 {
+		return false, nil, fmt.Errorf("policy check returned no results for %s", original.Slug)
+	}
+
+	for _, result := range results {
+		if !result.Success || !result.Passed {
+			return false, []string{original.Slug}, nil
+		}
+	}
+	return true, nil, nil
+}
+
+// enforcePolicy refuses configurations that fail the engine's CEL policy
+func (oe *OptimizationEngine) enforcePolicy(config *OptimizedConfiguration) error {
+	if oe.policy == "" {
+		return nil
+	}
+	passed, failing, err := oe.ValidateWithPolicy(config, oe.policy)
+	if err != nil {
+		return err
+	}
+	if !passed {
+		oe.app.Logger.Printf("🚫 Policy rejected optimization of %s", strings.Join(failing, ", "))
+		return &PolicyViolationError{Expression: oe.policy, Units: failing}
+	}
+	return nil
 }
 
 // BulkOptimizeUnits optimizes multiple units using ConfigHub Sets/Filters
@@ -1044,35 +2357,62 @@ func (oe *OptimizationEngine) BulkOptimizeUnits(setSlug string, wasteMetrics map
 	oe.app.Logger.Printf("🔧 Bulk optimizing units in set: %s", setSlug)
 
 	// Get units in the set
-	units, err := oe.app.Cub.ListUnits(ListUnitsParams{
+	units, err := oe.app.Cub.ListAllUnits(ListUnitsParams{
 		SpaceID: oe.spaceID,
-		Where:   fmt.Sprintf("Sets.Slug = '%s'", setSlug),
+		Where:   NewWhere().Eq("Sets.Slug", setSlug).MustBuild(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list units in set: %v", err)
 	}
 
-	var configs []*OptimizedConfiguration
-	for _, unit := range units {
+	configs := oe.optimizeUnits(units, wasteMetrics)
+
+	oe.app.Logger.Printf("✅ Bulk optimization complete: %d units optimized", len(configs))
+	return configs, nil
+}
+
+// optimizeUnits optimizes units with a bounded worker pool, skipping units
+// without waste metrics or that fail to optimize. Results keep the input order.
+func (oe *OptimizationEngine) optimizeUnits(units []*Unit, wasteMetrics map[string]*WasteMetrics) []*OptimizedConfiguration {
+	results := make([]*OptimizedConfiguration, len(units))
+
+	workers := oe.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for i, unit := range units {
 		waste := wasteMetrics[unit.Slug]
 		if waste == nil {
 			oe.app.Logger.Printf("⚠️  No waste metrics for unit %s, skipping", unit.Slug)
 			continue
 		}
 
-		config, err := oe.GenerateOptimizedUnit(unit, waste)
-		if err != nil {
-			oe.app.Logger.Printf("⚠️  Failed to optimize unit %s: %v", unit.Slug, err)
-			continue
-		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, unit *Unit, waste *WasteMetrics) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-		if len(config.Optimizations) > 0 {
+			config, err := oe.GenerateOptimizedUnit(unit, waste)
+			if err != nil {
+				oe.app.Logger.Printf("⚠️  Failed to optimize unit %s: %v", unit.Slug, err)
+				return
+			}
+			results[i] = config
+		}(i, unit, waste)
+	}
+	wg.Wait()
+
+	var configs []*OptimizedConfiguration
+	for _, config := range results {
+		if config != nil && len(config.Optimizations) > 0 {
 			configs = append(configs, config)
 		}
 	}
-
-	oe.app.Logger.Printf("✅ Bulk optimization complete: %d units optimized", len(configs))
-	return configs, nil
+	return configs
 }
 
 // GenerateOptimizationReport creates a comprehensive optimization report
@@ -1134,6 +2474,27 @@ func (oe *OptimizationEngine) GenerateOptimizationReport(configs []*OptimizedCon
 		}
 	}
 
+	// Scheduled scalers from GenerateScheduledScaler, attached as additional units
+	var scheduled strings.Builder
+	scheduledSavings := 0.0
+	for _, config := range configs {
+		for _, additional := range config.AdditionalUnits {
+			savings, err := strconv.ParseFloat(additional.Annotations[ScheduledScalerSavingsAnnotation], 64)
+			if err != nil {
+				continue
+			}
+			scheduledSavings += savings
+			scheduled.WriteString(fmt.Sprintf("%-30s %s $%.2f/mo savings\n",
+				config.OriginalUnit.Slug, additional.Annotations[ScheduledScalerScheduleAnnotation], savings))
+		}
+	}
+	if scheduled.Len() > 0 {
+		report.WriteString("\n\nScheduled Scaling (zero replicas outside business hours):\n")
+		report.WriteString("─────────────────────────────────────────────\n")
+		report.WriteString(scheduled.String())
+		report.WriteString(fmt.Sprintf("Total: $%.2f/mo\n", scheduledSavings))
+	}
+
 	report.WriteString("\n\nDeployment Recommendations:\n")
 	report.WriteString("─────────────────────────────────────────────\n")
 	report.WriteString("• Deploy LOW risk optimizations to production immediately\n")
@@ -1200,76 +2561,195 @@ func copyValue(original interface{}) interface{} {
 	}
 }
 
-// OptimizeSpaceWithAI uses Claude AI to enhance optimization decisions
+// OptimizeSpaceWithAI optimizes a space and has Claude review each result,
+// returning the augmented configurations. AI is optional: without app.Claude,
+// or when a review fails, the rule-based configuration is returned unchanged.
+//
+// Claude's safety margin and replica floor suggestions re-run the unit's
+// optimization with the adjusted SafetyConfiguration; its risk rating,
+// monitoring and rollback advice and additional opportunities are recorded
+// in RiskAssessment, and the review itself in AIRecommendation. Only changes
+// that make an optimization more conservative are applied unless
+// SetAllowAggressiveAI is set; ignored suggestions are listed as risk factors.
 func (oe *OptimizationEngine) OptimizeSpaceWithAI(spaceSlug string, wasteMetrics map[string]*WasteMetrics) ([]*OptimizedConfiguration, error) {
-	if oe.app.Claude == nil {
-		return nil, fmt.Errorf("Claude AI not available")
-	}
-
-	oe.app.Logger.Printf("🤖 Using Claude AI for intelligent optimization of space: %s", spaceSlug)
-
 	// Get basic optimization recommendations
 	configs, err := oe.BulkOptimizeUnits(spaceSlug, wasteMetrics)
 	if err != nil {
 		return nil, err
 	}
 
+	if oe.app.Claude == nil {
+		oe.app.Logger.Printf("⚠️  Claude AI not configured; returning rule-based optimizations for space: %s", spaceSlug)
+		return configs, nil
+	}
+	oe.app.Logger.Printf("🤖 Using Claude AI for intelligent optimization of space: %s", spaceSlug)
+
 	// Enhance with AI analysis
-	for _, config := range configs {
-		aiRecommendation, err := oe.getAIOptimizationRecommendation(config)
+	for i, config := range configs {
+		recommendation, err := oe.getAIOptimizationRecommendation(config)
 		if err != nil {
 			oe.app.Logger.Printf("⚠️  Claude AI analysis failed for %s: %v", config.OriginalUnit.Slug, err)
 			continue
 		}
-
-		// Apply AI recommendations (this would integrate with Claude's suggestions)
-		oe.app.Logger.Printf("🤖 Claude AI recommendation for %s: %s", config.OriginalUnit.Slug, aiRecommendation)
+		augmented, err := oe.applyAIRecommendation(config, wasteMetrics[config.OriginalUnit.Slug], recommendation)
+		if err != nil {
+			oe.app.Logger.Printf("⚠️  Failed to apply Claude AI recommendation for %s: %v", config.OriginalUnit.Slug, err)
+			continue
+		}
+		configs[i] = augmented
+		oe.app.Logger.Printf("🤖 Claude AI rates %s %s risk with %d additional optimization(s)",
+			config.OriginalUnit.Slug, recommendation.RiskLevel, len(recommendation.AdditionalOptimizations))
 	}
 
+	usage := oe.app.Claude.Usage()
+	oe.app.Logger.Printf("🤖 Claude AI usage: %d tokens ($%.4f)", usage.TotalTokens(), usage.CostUSD)
 	return configs, nil
 }
 
-// getAIOptimizationRecommendation gets AI-powered optimization advice
-func (oe *OptimizationEngine) getAIOptimizationRecommendation(config *OptimizedConfiguration) (string, error) {
-	prompt := fmt.Sprintf(`
-Analyze this Kubernetes workload optimization:
-
-Unit: %s
-Type: Extract from manifest
-Current Resources: %s
-Optimizations Applied: %d
-Estimated Savings: $%.2f/month (%.1f%%)
-Risk Assessment: %s
-
-Waste Metrics Available: CPU, Memory, Storage usage patterns
-Safety Margins: CPU %.1f%%, Memory %.1f%%
-
-Please provide:
-1. Risk assessment validation
-2. Additional optimization opportunities
-3. Monitoring recommendations
-4. Rollback strategy
+// aiOptimizationPrompt asks Claude to review one optimized configuration
+var aiOptimizationPrompt = MustPromptTemplate("ai-optimization", `Analyze this Kubernetes workload optimization:
+
+Unit: {{.Unit}}
+Type: {{.Kind}}
+Optimizations Applied:
+{{range .Optimizations}}- {{.Type}}: {{.OriginalValue}} -> {{.OptimizedValue}} ({{printf "%.1f" .ReductionPercent}}% reduction, {{.Risk}} risk)
+{{end}}Estimated Savings: ${{printf "%.2f" .Savings.MonthlySavings}}/month ({{printf "%.1f" .Savings.SavingsPercent}}%)
+Risk Assessment: {{.Risk}}
+Safety Margins: CPU {{printf "%.1f" .CPUMargin}}%, Memory {{printf "%.1f" .MemoryMargin}}%, minimum {{.MinReplicas}} replica(s)
+
+Validate the risk assessment and suggest additional optimizations, monitoring
+and a rollback strategy. If the safety margins or replica floor should change,
+give the new values (margins as fractions, e.g. 0.3 for 30%); otherwise omit
+them. Respond with only a JSON object of this form:
+{"riskLevel": "LOW|MEDIUM|HIGH", "riskRationale": "...", "additionalOptimizations": ["..."], "monitoring": ["..."], "rollback": ["..."], "cpuSafetyMargin": 0.3, "memorySafetyMargin": 0.25, "minReplicas": 2}
 
 Manifest:
-%s
-`,
-		config.OriginalUnit.Slug,
-		"Extract from analysis", // This would be computed
-		len(config.Optimizations),
-		config.EstimatedSavings.MonthlySavings,
-		config.EstimatedSavings.SavingsPercent,
-		config.RiskAssessment.OverallRisk,
-		oe.safetyConfig.CPUSafetyMargin*100,
-		oe.safetyConfig.MemorySafetyMargin*100,
-		config.OriginalUnit.Data,
-	)
-
-	response, err := oe.app.Claude.Complete(prompt)
+{{.Manifest}}
+`)
+
+// getAIOptimizationRecommendation gets AI-powered optimization advice
+func (oe *OptimizationEngine) getAIOptimizationRecommendation(config *OptimizedConfiguration) (*AIRecommendation, error) {
+	kind := ""
+	if manifest, err := config.OriginalUnit.Manifest(); err == nil {
+		kind, _ = manifest["kind"].(string)
+	}
+
+	// Claude reviews the manifest text, not its base64 encoding
+	data, err := decodeUnitData(*config.OriginalUnit)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	engine, _ := oe.forUnit(config.OriginalUnit)
+	prompt, err := aiOptimizationPrompt.Render(map[string]interface{}{
+		"Unit":          config.OriginalUnit.Slug,
+		"Kind":          kind,
+		"Optimizations": config.Optimizations,
+		"Savings":       config.EstimatedSavings,
+		"Risk":          config.RiskAssessment.OverallRisk,
+		"CPUMargin":     engine.safetyConfig.CPUSafetyMargin * 100,
+		"MemoryMargin":  engine.safetyConfig.MemorySafetyMargin * 100,
+		"MinReplicas":   engine.safetyConfig.MinReplicas,
+		"Manifest":      string(data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var recommendation AIRecommendation
+	if err := oe.app.Claude.CompleteJSON(prompt, &recommendation); err != nil {
+		return nil, err
+	}
+	recommendation.RiskLevel = strings.ToUpper(strings.TrimSpace(recommendation.RiskLevel))
+	return &recommendation, nil
+}
+
+// applyAIRecommendation returns config augmented with Claude's review. Safety
+// suggestions within the guardrails re-run the optimization with an adjusted
+// SafetyConfiguration; the rest of the review is recorded in RiskAssessment.
+func (oe *OptimizationEngine) applyAIRecommendation(config *OptimizedConfiguration, waste *WasteMetrics, recommendation *AIRecommendation) (*OptimizedConfiguration, error) {
+	engine, profile := oe.forUnit(config.OriginalUnit)
+	safety := *engine.safetyConfig
+	var applied, ignored []string
+
+	adjustMargin := func(name string, current *float64, suggested *float64) {
+		if suggested == nil {
+			return
+		}
+		value := math.Max(0, math.Min(*suggested, MaxAISafetyMargin))
+		switch {
+		case value == *current:
+			// Unchanged
+		case value > *current || oe.aggressiveAI:
+			applied = append(applied, fmt.Sprintf("%s safety margin %.0f%% -> %.0f%%", name, *current*100, value*100))
+			*current = value
+		default:
+			ignored = append(ignored, fmt.Sprintf("lower %s safety margin to %.0f%%", name, value*100))
+		}
+	}
+	adjustMargin("CPU", &safety.CPUSafetyMargin, recommendation.CPUSafetyMargin)
+	adjustMargin("memory", &safety.MemorySafetyMargin, recommendation.MemorySafetyMargin)
+
+	if suggested := recommendation.MinReplicas; suggested != nil {
+		value := *suggested
+		if value < 1 {
+			value = 1
+		}
+		switch {
+		case value == safety.MinReplicas:
+			// Unchanged
+		case value > safety.MinReplicas || oe.aggressiveAI:
+			applied = append(applied, fmt.Sprintf("minimum replicas %d -> %d", safety.MinReplicas, value))
+			safety.MinReplicas = value
+		default:
+			ignored = append(ignored, fmt.Sprintf("lower minimum replicas to %d", value))
+		}
+	}
+
+	// Re-run the optimization with the adjusted margins
+	augmented := config
+	if len(applied) > 0 && waste != nil {
+		adjusted := *engine
+		adjusted.safetyConfig = &safety
+		regenerated, err := adjusted.generateOptimizedUnit(config.OriginalUnit, waste, profile)
+		if err != nil {
+			return nil, err
+		}
+		augmented = regenerated
+	}
+	augmented.AIRecommendation = recommendation
+
+	riskRank := map[string]int{"LOW": 1, "MEDIUM": 2, "HIGH": 3}
+	risk := &augmented.RiskAssessment
+	if rank := riskRank[recommendation.RiskLevel]; rank > 0 && rank != riskRank[risk.OverallRisk] {
+		if rank > riskRank[risk.OverallRisk] || oe.aggressiveAI {
+			risk.RiskFactors = append(risk.RiskFactors, fmt.Sprintf("Claude AI rates this change %s risk: %s",
+				recommendation.RiskLevel, recommendation.RiskRationale))
+			risk.OverallRisk = recommendation.RiskLevel
+			if risk.OverallRisk == "HIGH" && risk.RecommendedPhase == "prod" {
+				risk.RecommendedPhase = "staging"
+			}
+		} else {
+			ignored = append(ignored, fmt.Sprintf("lower risk to %s", recommendation.RiskLevel))
+		}
+	}
+
+	for _, change := range applied {
+		risk.Mitigations = append(risk.Mitigations, "Claude AI adjusted "+change)
+	}
+	if len(ignored) > 0 {
+		risk.RiskFactors = append(risk.RiskFactors, fmt.Sprintf("Claude AI suggested less conservative settings, not applied (see SetAllowAggressiveAI): %s",
+			strings.Join(ignored, ", ")))
+	}
+	for _, item := range recommendation.Monitoring {
+		risk.Mitigations = append(risk.Mitigations, "Monitor: "+item)
+	}
+	for _, item := range recommendation.Rollback {
+		risk.Mitigations = append(risk.Mitigations, "Rollback: "+item)
 	}
+	risk.AdditionalOpportunities = append(risk.AdditionalOpportunities, recommendation.AdditionalOptimizations...)
 
-	return response, nil
+	return augmented, nil
 }
 
 // CreateOptimizedSet creates a ConfigHub Set containing all optimized units
@@ -0,0 +1,1124 @@
+package sdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBenchUnits builds synthetic deployment units with matching waste metrics
+func newBenchUnits(n int) ([]*Unit, map[string]*WasteMetrics) {
+	units := make([]*Unit, n)
+	waste := make(map[string]*WasteMetrics, n)
+	for i := 0; i < n; i++ {
+		slug := fmt.Sprintf("svc-%03d", i)
+		units[i] = &Unit{
+			UnitID: uuid.New(),
+			Slug:   slug,
+			Data: fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+spec:
+  replicas: 4
+  selector:
+    matchLabels:
+      app: %s
+  template:
+    metadata:
+      labels:
+        app: %s
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.25
+        resources:
+          requests:
+            cpu: 1000m
+            memory: 1Gi
+          limits:
+            cpu: 2000m
+            memory: 2Gi
+`, slug, slug, slug),
+		}
+		waste[slug] = &WasteMetrics{
+			CPUWastePercent:    0.6,
+			MemoryWastePercent: 0.5,
+			IdleReplicas:       2,
+			WasteConfidence:    0.9,
+		}
+	}
+	return units, waste
+}
+
+func newBenchEngine(concurrency int) *OptimizationEngine {
+	app := &DevOpsApp{Logger: log.New(io.Discard, "", 0)}
+	engine := NewOptimizationEngine(app, uuid.New())
+	engine.SetConcurrency(concurrency)
+	return engine
+}
+
+// Test bounded concurrent bulk optimization
+func TestOptimizeUnitsConcurrently(t *testing.T) {
+	t.Run("PreservesOrder", func(t *testing.T) {
+		units, waste := newBenchUnits(50)
+		configs := newBenchEngine(8).optimizeUnits(units, waste)
+
+		require.Len(t, configs, len(units))
+		for i, config := range configs {
+			assert.Equal(t, units[i].Slug, config.OriginalUnit.Slug)
+		}
+	})
+
+	t.Run("SkipsUnitsWithoutMetrics", func(t *testing.T) {
+		units, waste := newBenchUnits(10)
+		delete(waste, units[3].Slug)
+		units[5].Data = "kind: ConfigMap"
+
+		configs := newBenchEngine(4).optimizeUnits(units, waste)
+		assert.Len(t, configs, 8)
+	})
+
+	t.Run("MatchesSequential", func(t *testing.T) {
+		units, waste := newBenchUnits(20)
+		sequential := newBenchEngine(1).optimizeUnits(units, waste)
+		parallel := newBenchEngine(8).optimizeUnits(units, waste)
+
+		require.Len(t, parallel, len(sequential))
+		for i := range sequential {
+			assert.Equal(t, sequential[i].OptimizedUnit.Data, parallel[i].OptimizedUnit.Data)
+		}
+	})
+}
+
+// Test per-tier safety configurations selected by unit label
+func TestTierSafetyConfigurations(t *testing.T) {
+	critical := *DefaultSafetyConfiguration
+	critical.CPUSafetyMargin = 0.4
+	critical.MinReplicas = 3
+	batch := *DefaultSafetyConfiguration
+	batch.CPUSafetyMargin = 0.1
+	batch.MinReplicas = 1
+
+	units, waste := newBenchUnits(30)
+	tiers := []string{"critical", "batch", "", "unknown"}
+	for i, unit := range units {
+		if tier := tiers[i%len(tiers)]; tier != "" {
+			unit.Labels = map[string]string{"tier": tier}
+		}
+	}
+	engine := newBenchEngine(8)
+	engine.SetTierSafetyConfigurations("tier", map[string]*SafetyConfiguration{"critical": &critical, "batch": &batch})
+
+	configs := engine.optimizeUnits(units, waste)
+	require.Len(t, configs, len(units))
+	for i, config := range configs {
+		replicas := map[string]string{}
+		for _, opt := range config.Optimizations {
+			replicas[opt.Type] = opt.OptimizedValue
+		}
+
+		switch tiers[i%len(tiers)] {
+		case "critical":
+			assert.Equal(t, "tier=critical", config.AppliedSafety.Profile)
+			assert.Equal(t, 0.4, config.AppliedSafety.ActualCPUMargin)
+			assert.Equal(t, "3", replicas["replicas"], "critical keeps MinReplicas: 3")
+		case "batch":
+			assert.Equal(t, "tier=batch", config.AppliedSafety.Profile)
+			assert.Equal(t, 0.1, config.AppliedSafety.ActualCPUMargin)
+			assert.Equal(t, "3", replicas["replicas"], "two replicas can't carry observed demand at current requests")
+		default:
+			assert.Equal(t, DefaultSafetyProfile, config.AppliedSafety.Profile, config.OriginalUnit.Slug)
+			assert.Equal(t, DefaultSafetyConfiguration.CPUSafetyMargin, config.AppliedSafety.ActualCPUMargin)
+		}
+	}
+	assert.Same(t, DefaultSafetyConfiguration, engine.safetyConfig)
+}
+
+func BenchmarkOptimizeUnits(b *testing.B) {
+	units, waste := newBenchUnits(100)
+
+	for _, workers := range []int{1, runtime.NumCPU()} {
+		engine := newBenchEngine(workers)
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				engine.optimizeUnits(units, waste)
+			}
+		})
+	}
+}
+
+// Test patching the original unit instead of creating a copy
+func TestOptimizationEngineApplyInPlace(t *testing.T) {
+	changeSetID := uuid.New()
+	// newServer fakes ConfigHub with the engine's space labeled environment=env
+	newServer := func(env string, invocations *[]FunctionInvocationRequest, created *int) *httptest.Server {
+		var mu sync.Mutex
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case r.Method == "GET" && !strings.Contains(r.URL.Path, "/unit"):
+				json.NewEncoder(w).Encode(Space{Labels: map[string]string{"environment": env}})
+			case strings.HasSuffix(r.URL.Path, "/changeset"):
+				json.NewEncoder(w).Encode(ChangeSet{ChangeSetID: changeSetID})
+			case strings.HasSuffix(r.URL.Path, "/function/invoke"):
+				var req FunctionInvocationRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				*invocations = append(*invocations, req)
+				json.NewEncoder(w).Encode(FunctionInvocationResponse{})
+			case r.Method == "POST":
+				*created++
+				json.NewEncoder(w).Encode(Unit{})
+			default:
+				json.NewEncoder(w).Encode(Unit{Slug: "svc-000"})
+			}
+		}))
+	}
+
+	t.Run("PatchesInChangeSet", func(t *testing.T) {
+		var invocations []FunctionInvocationRequest
+		var created int
+		server := newServer("", &invocations, &created)
+		defer server.Close()
+
+		units, waste := newBenchUnits(1)
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		engine.SetApplyInPlace(true)
+
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+		config.RiskAssessment.RecommendedPhase = "prod"
+
+		unit, err := engine.CreateOptimizedUnitInConfigHub(config)
+		require.NoError(t, err)
+		assert.Equal(t, "svc-000", unit.Slug)
+		assert.Zero(t, created, "no -optimized unit should be created")
+		assert.True(t, config.AppliedInPlace)
+		assert.Equal(t, &changeSetID, config.ChangeSetID)
+
+		require.Len(t, invocations, 2)
+		assert.Equal(t, "set-container-resources", invocations[0].FunctionName)
+		assert.Equal(t, fmt.Sprintf("UnitID = '%s'", units[0].UnitID), invocations[0].Where)
+		assert.Equal(t, "container-name", invocations[0].Arguments[0].ParameterName)
+		assert.Equal(t, "app", invocations[0].Arguments[0].Value)
+		assert.Equal(t, "requests.cpu", invocations[0].Arguments[1].ParameterName)
+		assert.Equal(t, "set-replicas", invocations[1].FunctionName)
+		for _, inv := range invocations {
+			assert.Equal(t, &changeSetID, inv.ChangeSetID)
+		}
+
+		assert.Equal(t, "svc-000", config.RollbackPlan.OptimizedUnitSlug)
+	})
+
+	t.Run("SpaceEnvironmentGuards", func(t *testing.T) {
+		var invocations []FunctionInvocationRequest
+		var created int
+		prod := newServer("prod", &invocations, &created)
+		defer prod.Close()
+
+		units, waste := newBenchUnits(1)
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(prod.URL, "test-token")
+		engine.SetApplyInPlace(true)
+
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+		config.RiskAssessment.RecommendedPhase = "staging"
+		_, err = engine.CreateOptimizedUnitInConfigHub(config)
+		var guardErr *ProdGuardError
+		require.ErrorAs(t, err, &guardErr)
+		assert.Contains(t, err.Error(), "recommended for staging")
+		assert.False(t, config.AppliedInPlace)
+		assert.Empty(t, invocations)
+
+		// The unit has no environment label; the dev space decides
+		dev := newServer("dev", &invocations, &created)
+		defer dev.Close()
+		engine.app.Cub = NewConfigHubClient(dev.URL, "test-token")
+		_, err = engine.CreateOptimizedUnitInConfigHub(config)
+		require.NoError(t, err)
+		assert.True(t, config.AppliedInPlace)
+		assert.NotEmpty(t, invocations)
+	})
+}
+
+// Test grouping optimizations into one ChangeSet
+func TestApplyConfigurationsAsChangeSet(t *testing.T) {
+	changeSetID := uuid.New()
+	newServer := func(failSlug string, applied *bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/changeset"):
+				json.NewEncoder(w).Encode(ChangeSet{ChangeSetID: changeSetID})
+			case strings.HasSuffix(r.URL.Path, "/apply"):
+				*applied = true
+			case r.Method == "GET":
+				json.NewEncoder(w).Encode(Space{Labels: map[string]string{"environment": "staging"}})
+			case r.Method == "PUT" && failSlug != "" && strings.Contains(r.URL.Path, failSlug):
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("schema validation failed"))
+			default:
+				var req CreateUnitRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				assert.Equal(t, &changeSetID, req.ChangeSetID)
+				json.NewEncoder(w).Encode(Unit{})
+			}
+		}))
+	}
+
+	optimize := func(t *testing.T, engine *OptimizationEngine, n int) []*OptimizedConfiguration {
+		units, waste := newBenchUnits(n)
+		configs := engine.optimizeUnits(units, waste)
+		require.Len(t, configs, n)
+		return configs
+	}
+
+	t.Run("AppliesAtomically", func(t *testing.T) {
+		var applied bool
+		server := newServer("", &applied)
+		defer server.Close()
+
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		configs := optimize(t, engine, 3)
+
+		changeSet, err := engine.ApplyConfigurationsAsChangeSet(configs, "weekly right-sizing")
+		require.NoError(t, err)
+		assert.Equal(t, changeSetID, changeSet.ChangeSetID)
+		assert.True(t, applied)
+		for _, config := range configs {
+			assert.True(t, config.AppliedInPlace)
+		}
+	})
+
+	t.Run("KeepsUnitMetadata", func(t *testing.T) {
+		var updates []CreateUnitRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/changeset"):
+				json.NewEncoder(w).Encode(ChangeSet{ChangeSetID: changeSetID})
+			case r.Method == "PUT":
+				var req CreateUnitRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				updates = append(updates, req)
+				json.NewEncoder(w).Encode(Unit{})
+			case r.Method == "GET":
+				json.NewEncoder(w).Encode(Space{})
+			default:
+				json.NewEncoder(w).Encode(Unit{})
+			}
+		}))
+		defer server.Close()
+
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		units, waste := newBenchUnits(1)
+		upstreamID := uuid.New()
+		units[0].DisplayName = "Service 0"
+		units[0].Labels = map[string]string{"team": "payments"}
+		units[0].Annotations = map[string]string{"owner": "alice"}
+		units[0].UpstreamUnitID = &upstreamID
+		configs := engine.optimizeUnits(units, waste)
+		require.Len(t, configs, 1)
+
+		_, err := engine.ApplyConfigurationsAsChangeSet(configs, "weekly right-sizing")
+		require.NoError(t, err)
+		require.Len(t, updates, 1)
+		update := updates[0]
+		assert.Equal(t, "svc-000", update.Slug)
+		assert.Equal(t, "Service 0", update.DisplayName)
+		assert.Equal(t, map[string]string{"team": "payments"}, update.Labels)
+		assert.Equal(t, "alice", update.Annotations["owner"])
+		assert.NotEmpty(t, update.Annotations[RollbackPlanAnnotation])
+		assert.Equal(t, &upstreamID, update.UpstreamUnitID)
+		assert.Equal(t, &changeSetID, update.ChangeSetID)
+		assert.Equal(t, configs[0].OptimizedUnit.Data, update.Data)
+	})
+
+	t.Run("FailedUpdateBlocksApply", func(t *testing.T) {
+		var applied bool
+		engine := newBenchEngine(1)
+		configs := optimize(t, engine, 3)
+
+		server := newServer(configs[1].OriginalUnit.UnitID.String(), &applied)
+		defer server.Close()
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+
+		_, err := engine.ApplyConfigurationsAsChangeSet(configs, "weekly right-sizing")
+		var csErr *ChangeSetApplyError
+		require.ErrorAs(t, err, &csErr)
+		assert.Equal(t, &changeSetID, csErr.ChangeSetID)
+		assert.Len(t, csErr.Failures, 1)
+		assert.Contains(t, err.Error(), "svc-001")
+		assert.False(t, applied)
+	})
+
+	t.Run("InvalidConfigurationSkipsChangeSet", func(t *testing.T) {
+		engine := newBenchEngine(1)
+		configs := optimize(t, engine, 2)
+		configs[0].OptimizedUnit.Data = "replicas: [unclosed"
+
+		_, err := engine.ApplyConfigurationsAsChangeSet(configs, "weekly right-sizing")
+		var csErr *ChangeSetApplyError
+		require.ErrorAs(t, err, &csErr)
+		assert.Nil(t, csErr.ChangeSetID)
+		assert.Contains(t, err.Error(), "svc-000: invalid optimized manifest")
+	})
+}
+
+// Test prod spaces refuse optimizations recommended for a lower phase
+func TestProdGuard(t *testing.T) {
+	newServer := func(env string, writes *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "GET":
+				json.NewEncoder(w).Encode(Space{Labels: map[string]string{"environment": env}})
+			case strings.HasSuffix(r.URL.Path, "/changeset"):
+				json.NewEncoder(w).Encode(ChangeSet{ChangeSetID: uuid.New()})
+			default:
+				*writes++
+				json.NewEncoder(w).Encode(Unit{Slug: "svc-000-optimized"})
+			}
+		}))
+	}
+	optimize := func(t *testing.T, engine *OptimizationEngine, phase string) *OptimizedConfiguration {
+		units, waste := newBenchUnits(1)
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+		config.RiskAssessment.OverallRisk = "HIGH"
+		config.RiskAssessment.RecommendedPhase = phase
+		return config
+	}
+
+	t.Run("RefusesProd", func(t *testing.T) {
+		var writes int
+		server := newServer("prod", &writes)
+		defer server.Close()
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+
+		_, err := engine.CreateOptimizedUnitInConfigHub(optimize(t, engine, "staging"))
+		var guardErr *ProdGuardError
+		require.ErrorAs(t, err, &guardErr)
+		assert.Equal(t, "svc-000", guardErr.Unit)
+		assert.Equal(t, "prod", guardErr.Environment)
+		assert.Contains(t, err.Error(), "HIGH risk")
+		assert.Contains(t, err.Error(), "recommended for staging first")
+		assert.Zero(t, writes)
+
+		_, err = engine.ApplyConfigurationsAsChangeSet([]*OptimizedConfiguration{optimize(t, engine, "dev")}, "weekly right-sizing")
+		var csErr *ChangeSetApplyError
+		require.ErrorAs(t, err, &csErr)
+		assert.Nil(t, csErr.ChangeSetID)
+		assert.Contains(t, err.Error(), "refusing to apply optimization of svc-000 to prod")
+		assert.Zero(t, writes)
+
+		_, err = engine.CreateOptimizedUnitInConfigHub(optimize(t, engine, "prod"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, writes)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		var writes int
+		server := newServer("production", &writes)
+		defer server.Close()
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		engine.SetOverrideProdGuard(true)
+
+		_, err := engine.CreateOptimizedUnitInConfigHub(optimize(t, engine, "dev"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, writes)
+	})
+
+	t.Run("LowerEnvironments", func(t *testing.T) {
+		var writes int
+		server := newServer("staging", &writes)
+		defer server.Close()
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+
+		_, err := engine.CreateOptimizedUnitInConfigHub(optimize(t, engine, "dev"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, writes)
+	})
+}
+
+// Test CEL policy guardrails before applying
+func TestValidateWithPolicy(t *testing.T) {
+	newServer := func(passed bool, deleted *[]string, created *int) *httptest.Server {
+		tempID := uuid.New()
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/function/invoke"):
+				var req FunctionInvocationRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				assert.Equal(t, "cel-validate", req.FunctionName)
+				assert.Equal(t, fmt.Sprintf("UnitID = '%s'", tempID), req.Where)
+				json.NewEncoder(w).Encode(FunctionInvocationResponse{Results: []FunctionResult{
+					{UnitID: tempID, Success: true, Passed: passed},
+				}})
+			case r.Method == "DELETE":
+				*deleted = append(*deleted, r.URL.Path)
+			case r.Method == "POST":
+				var req CreateUnitRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				if strings.Contains(req.Slug, "-policy-check-") {
+					json.NewEncoder(w).Encode(Unit{UnitID: tempID, Slug: req.Slug})
+					return
+				}
+				*created++
+				json.NewEncoder(w).Encode(Unit{Slug: req.Slug})
+			}
+		}))
+	}
+
+	t.Run("Passes", func(t *testing.T) {
+		var deleted []string
+		var created int
+		server := newServer(true, &deleted, &created)
+		defer server.Close()
+
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		units, waste := newBenchUnits(1)
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+
+		passed, failing, err := engine.ValidateWithPolicy(config, `resources.requests.cpu >= "100m"`)
+		require.NoError(t, err)
+		assert.True(t, passed)
+		assert.Empty(t, failing)
+		assert.Len(t, deleted, 1, "temporary unit should be deleted")
+	})
+
+	t.Run("FailingPolicyBlocksApply", func(t *testing.T) {
+		var deleted []string
+		var created int
+		server := newServer(false, &deleted, &created)
+		defer server.Close()
+
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		engine.SetPolicy(`resources.requests.cpu >= "2"`)
+		units, waste := newBenchUnits(1)
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+
+		_, err = engine.CreateOptimizedUnitInConfigHub(config)
+		var policyErr *PolicyViolationError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []string{"svc-000"}, policyErr.Units)
+		assert.Zero(t, created)
+		assert.Len(t, deleted, 1)
+
+		_, err = engine.ApplyConfigurationsAsChangeSet([]*OptimizedConfiguration{config}, "weekly right-sizing")
+		var csErr *ChangeSetApplyError
+		require.ErrorAs(t, err, &csErr)
+		assert.Nil(t, csErr.ChangeSetID)
+		assert.Contains(t, csErr.Failures, "svc-000")
+	})
+}
+
+// Test PVC right-sizing recommendations for StatefulSets
+func TestOptimizeStatefulSetStorage(t *testing.T) {
+	unit := &Unit{
+		UnitID: uuid.New(),
+		Slug:   "postgres",
+		Data: `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: postgres
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: postgres
+        image: postgres:16
+        resources:
+          requests:
+            cpu: 500m
+            memory: 1Gi
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      resources:
+        requests:
+          storage: 100Gi
+  - metadata:
+      name: wal
+    spec:
+      resources:
+        requests:
+          storage: 1Gi
+`,
+	}
+
+	engine := newBenchEngine(1)
+	config, err := engine.GenerateOptimizedUnit(unit, &WasteMetrics{
+		StorageWastePercent: 0.7,
+		WasteConfidence:     1.0,
+	})
+	require.NoError(t, err)
+
+	var storage []ResourceOptimization
+	for _, opt := range config.Optimizations {
+		if opt.Type == "storage" {
+			storage = append(storage, opt)
+		}
+	}
+	require.Len(t, storage, 1, "1Gi volume rounds back up to 1Gi and is skipped")
+	assert.Equal(t, "data", storage[0].Target)
+	assert.Equal(t, "100Gi", storage[0].OriginalValue)
+	assert.Equal(t, "55Gi", storage[0].OptimizedValue) // 70% waste * 0.8 confidence, +25% headroom
+	assert.Equal(t, "MEDIUM", storage[0].Risk)
+
+	assert.Contains(t, config.OptimizedUnit.Data, "storage: 100Gi", "PVCs are not shrunk in place")
+	assert.Equal(t, "100Gi -> 55Gi (requires PVC migration)", config.OptimizedUnit.Annotations["optimizer.io/storage-recommendation-data"])
+	assert.InDelta(t, 45*0.10*3, config.EstimatedSavings.Breakdown.StorageSavings, 0.001)
+	assert.Equal(t, "MEDIUM", config.RiskAssessment.OverallRisk)
+	assert.Contains(t, strings.Join(config.RiskAssessment.Mitigations, "\n"), "migrate to a 55Gi PVC")
+}
+
+// Test a base64-encoded unit flows through cost, waste and optimization and
+// stays base64-encoded
+func TestBase64UnitData(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 4
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 1000m
+            memory: 1Gi
+          limits:
+            cpu: 2000m
+            memory: 2Gi
+`
+	unit := Unit{UnitID: uuid.New(), Slug: "web", Data: base64.StdEncoding.EncodeToString([]byte(manifest))}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"Unit": unit}})
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+	spaceID := uuid.New()
+
+	estimate, err := NewCostAnalyzer(app, spaceID).AnalyzeUnit(unit)
+	require.NoError(t, err)
+	require.NotNil(t, estimate)
+	assert.Equal(t, int32(4), estimate.Replicas)
+	assert.Greater(t, estimate.MonthlyCost, 0.0)
+
+	source := usageSourceFunc(func(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+		return ActualUsageMetrics{
+			UnitID:                   estimate.UnitID,
+			CPUUtilizationPercent:    10,
+			MemoryUtilizationPercent: 20,
+			AverageReplicas:          4,
+			UptimePercent:            100,
+		}, true, nil
+	})
+	waste, err := NewWasteAnalyzer(app, spaceID).AnalyzeWasteFromSource(context.Background(), source)
+	require.NoError(t, err)
+	require.Len(t, waste.UnitWasteDetections, 1)
+	detection := waste.UnitWasteDetections[0]
+	assert.Greater(t, detection.WastedMonthlyCost, 0.0)
+
+	config, err := NewOptimizationEngine(app, spaceID).GenerateOptimizedUnit(&unit, &WasteMetrics{
+		CPUWastePercent:    detection.CPUWaste.WastePercent / 100,
+		MemoryWastePercent: detection.MemoryWaste.WastePercent / 100,
+		WasteConfidence:    0.9,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, config.Optimizations)
+
+	assert.True(t, isBase64UnitData(config.OptimizedUnit.Data), "optimized unit keeps the original encoding")
+	optimized, err := config.OptimizedUnit.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", optimized["kind"])
+	assert.Equal(t, manifest, config.RollbackPlan.OriginalManifest, "rollback manifest is ready to apply")
+
+	// SetManifest re-encodes like the unit it writes to
+	require.NoError(t, unit.SetManifest(optimized))
+	assert.True(t, isBase64UnitData(unit.Data))
+	plain := Unit{Data: manifest}
+	require.NoError(t, plain.SetManifest(optimized))
+	assert.False(t, isBase64UnitData(plain.Data))
+}
+
+// Test optimized manifests are validated before they are written
+func TestManifestValidation(t *testing.T) {
+	units, waste := newBenchUnits(1)
+
+	t.Run("OptimizedManifestPasses", func(t *testing.T) {
+		config, err := newBenchEngine(1).GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+		assert.NoError(t, validateOptimizedManifests(config.OptimizedUnit))
+	})
+
+	t.Run("FieldAtFault", func(t *testing.T) {
+		deployment := func(resources string) string {
+			return "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n      - name: web\n        resources:\n" + resources
+		}
+		cases := []struct {
+			name, data, field string
+		}{
+			{"MissingKind", "apiVersion: v1\nmetadata:\n  name: web\n", "kind"},
+			{"MissingMetadata", "apiVersion: apps/v1\nkind: Deployment\nspec: {}\n", "metadata"},
+			{"MissingSpec", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n", "spec"},
+			{"BadQuantity", deployment("          limits:\n            cpu: 1.5.2\n"), "spec.template.spec.containers[0].resources.limits.cpu"},
+			{"RequestAboveLimit", deployment("          requests:\n            memory: 2Gi\n          limits:\n            memory: 1Gi\n"), "spec.template.spec.containers[0].resources.requests.memory"},
+			{"SecondDocument", "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n---\n" + deployment("          requests:\n            cpu: fast\n"), "document[1].spec.template.spec.containers[0].resources.requests.cpu"},
+		}
+		for _, tc := range cases {
+			err := validateOptimizedManifests(&Unit{Slug: "web-optimized", Data: tc.data})
+			var validationErr *ManifestValidationError
+			require.ErrorAs(t, err, &validationErr, tc.name)
+			assert.Equal(t, tc.field, validationErr.Field, tc.name)
+			assert.Equal(t, "web-optimized", validationErr.Unit, tc.name)
+		}
+
+		assert.NoError(t, validateOptimizedManifests(&Unit{Data: deployment("          requests:\n            cpu: 0\n            memory: 0Mi\n")}), "zero is a valid quantity")
+		assert.NoError(t, validateOptimizedManifests(&Unit{Data: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n"}), "spec is only required for workloads")
+	})
+
+	t.Run("CorruptedManifestIsNotCreated", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(Unit{UnitID: uuid.New(), Slug: "svc-000-optimized"})
+		}))
+		defer server.Close()
+
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+		config.OptimizedUnit.Data = strings.Replace(config.OptimizedUnit.Data, "memory: ", "memory: x", 1)
+
+		_, err = engine.CreateOptimizedUnitInConfigHub(config)
+		var validationErr *ManifestValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Field, ".resources.")
+		assert.Contains(t, validationErr.Field, ".memory")
+		assert.Zero(t, calls)
+	})
+}
+
+// Test optimization keeps each container's requests/limits shape
+func TestOptimizePreservesResourceShape(t *testing.T) {
+	unit := &Unit{
+		UnitID: uuid.New(),
+		Slug:   "mixed",
+		Data: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: mixed
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 1000m
+            memory: 1Gi
+      - name: sidecar
+        resources:
+          limits:
+            cpu: 500m
+            memory: 512Mi
+`,
+	}
+
+	config, err := newBenchEngine(1).GenerateOptimizedUnit(unit, &WasteMetrics{
+		CPUWastePercent:    0.6,
+		MemoryWastePercent: 0.5,
+		WasteConfidence:    0.9,
+	})
+	require.NoError(t, err)
+
+	manifest, err := config.OptimizedUnit.Manifest()
+	require.NoError(t, err)
+	containers := manifest["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	resources := func(i int) map[string]interface{} {
+		return containers[i].(map[string]interface{})["resources"].(map[string]interface{})
+	}
+
+	app := resources(0)
+	assert.NotContains(t, app, "limits", "requests-only container gains no limits")
+	requests := app["requests"].(map[string]interface{})
+	assert.NotEqual(t, "1000m", requests["cpu"])
+	assert.NotEqual(t, "1Gi", requests["memory"])
+
+	sidecar := resources(1)
+	assert.NotContains(t, sidecar, "requests", "limits-only container gains no requests")
+	limits := sidecar["limits"].(map[string]interface{})
+	assert.NotEqual(t, "500m", limits["cpu"])
+	assert.NotEqual(t, "512Mi", limits["memory"])
+
+	assert.Equal(t, config.OptimizedUnit.Annotations["optimizer.io/qos-class-original"], config.OptimizedUnit.Annotations["optimizer.io/qos-class-optimized"])
+}
+
+// Test Claude's structured review is applied, and that AI is optional
+func TestOptimizeSpaceWithAI(t *testing.T) {
+	units, waste := newBenchUnits(2)
+	cub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response []map[string]*Unit
+		for _, unit := range units {
+			response = append(response, map[string]*Unit{"Unit": unit})
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer cub.Close()
+
+	newEngine := func() *OptimizationEngine {
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(cub.URL, "test-token")
+		return engine
+	}
+
+	t.Run("WithoutClaude", func(t *testing.T) {
+		configs, err := newEngine().OptimizeSpaceWithAI("right-size", waste)
+		require.NoError(t, err)
+		require.Len(t, configs, 2)
+		assert.Nil(t, configs[0].AIRecommendation)
+	})
+
+	t.Run("StructuredRecommendations", func(t *testing.T) {
+		calls := 0
+		claude := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 2 {
+				claudeResponse(w, "I can't review this one.", 100, 10)
+				return
+			}
+			claudeResponse(w, `{"riskLevel": "high", "riskRationale": "latency-sensitive", "additionalOptimizations": ["enable HPA"], "monitoring": ["p99 latency"], "rollback": ["revert to svc-000"]}`, 500, 100)
+		}))
+		defer claude.Close()
+
+		engine := newEngine()
+		engine.app.Claude = newTestClaudeClient(claude)
+		configs, err := engine.OptimizeSpaceWithAI("right-size", waste)
+		require.NoError(t, err)
+		require.Len(t, configs, 2)
+
+		reviewed := configs[0]
+		require.NotNil(t, reviewed.AIRecommendation)
+		assert.Equal(t, "HIGH", reviewed.AIRecommendation.RiskLevel)
+		assert.Equal(t, []string{"enable HPA"}, reviewed.AIRecommendation.AdditionalOptimizations)
+		assert.Equal(t, "HIGH", reviewed.RiskAssessment.OverallRisk, "Claude's higher rating raises the risk")
+		assert.NotEqual(t, "prod", reviewed.RiskAssessment.RecommendedPhase)
+		assert.Contains(t, reviewed.RiskAssessment.Mitigations, "Monitor: p99 latency")
+		assert.Contains(t, reviewed.RiskAssessment.Mitigations, "Rollback: revert to svc-000")
+
+		assert.Equal(t, []string{"enable HPA"}, reviewed.RiskAssessment.AdditionalOpportunities)
+
+		assert.Nil(t, configs[1].AIRecommendation, "unparseable review leaves the configuration unchanged")
+		assert.Equal(t, int64(2), engine.app.Claude.Usage().Requests)
+	})
+
+	t.Run("DecodedManifest", func(t *testing.T) {
+		var prompt string
+		claude := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			prompt = string(body)
+			claudeResponse(w, `{"riskLevel": "LOW"}`, 100, 10)
+		}))
+		defer claude.Close()
+
+		engine := newEngine()
+		engine.app.Claude = newTestClaudeClient(claude)
+		encoded := *units[0]
+		encoded.Data = base64.StdEncoding.EncodeToString([]byte(units[0].Data))
+		config, err := engine.GenerateOptimizedUnit(&encoded, waste[encoded.Slug])
+		require.NoError(t, err)
+		_, err = engine.getAIOptimizationRecommendation(config)
+		require.NoError(t, err)
+		assert.Contains(t, prompt, "kind: Deployment")
+		assert.NotContains(t, prompt, encoded.Data)
+	})
+
+	optimizedValue := func(config *OptimizedConfiguration, kind string) string {
+		for _, opt := range config.Optimizations {
+			if opt.Type == kind {
+				return opt.OptimizedValue
+			}
+		}
+		return ""
+	}
+	baseline, err := newEngine().OptimizeSpaceWithAI("right-size", waste)
+	require.NoError(t, err)
+	baselineCPU := ParseQuantity(optimizedValue(baseline[0], "cpu")).MilliValue()
+	require.Equal(t, "3", optimizedValue(baseline[0], "replicas"))
+	totalCPU := func(config *OptimizedConfiguration) int64 {
+		replicas, err := strconv.Atoi(optimizedValue(config, "replicas"))
+		require.NoError(t, err)
+		return int64(replicas) * ParseQuantity(optimizedValue(config, "cpu")).MilliValue()
+	}
+
+	reviewWith := func(t *testing.T, review string, aggressive bool) *OptimizedConfiguration {
+		claude := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claudeResponse(w, review, 100, 10)
+		}))
+		defer claude.Close()
+
+		engine := newEngine()
+		engine.app.Claude = newTestClaudeClient(claude)
+		engine.SetAllowAggressiveAI(aggressive)
+		configs, err := engine.OptimizeSpaceWithAI("right-size", waste)
+		require.NoError(t, err)
+		return configs[0]
+	}
+
+	t.Run("ConservativeAdjustmentsReoptimize", func(t *testing.T) {
+		config := reviewWith(t, `{"riskLevel": "MEDIUM", "cpuSafetyMargin": 0.5, "minReplicas": 3}`, false)
+		assert.Greater(t, ParseQuantity(optimizedValue(config, "cpu")).MilliValue(), baselineCPU)
+		assert.Equal(t, "3", optimizedValue(config, "replicas"))
+		assert.Contains(t, config.RiskAssessment.Mitigations, "Claude AI adjusted CPU safety margin 20% -> 50%")
+		assert.Contains(t, config.RiskAssessment.Mitigations, "Claude AI adjusted minimum replicas 1 -> 3")
+		assert.NotNil(t, config.AIRecommendation)
+	})
+
+	t.Run("MarginsAreBounded", func(t *testing.T) {
+		config := reviewWith(t, `{"riskLevel": "MEDIUM", "cpuSafetyMargin": 7}`, false)
+		assert.Contains(t, config.RiskAssessment.Mitigations, "Claude AI adjusted CPU safety margin 20% -> 100%")
+	})
+
+	t.Run("AggressiveSuggestionsIgnored", func(t *testing.T) {
+		config := reviewWith(t, `{"riskLevel": "LOW", "cpuSafetyMargin": 0.05}`, false)
+		assert.Equal(t, baselineCPU, ParseQuantity(optimizedValue(config, "cpu")).MilliValue())
+		assert.Equal(t, baseline[0].RiskAssessment.OverallRisk, config.RiskAssessment.OverallRisk)
+		assert.Contains(t, strings.Join(config.RiskAssessment.RiskFactors, "\n"), "not applied (see SetAllowAggressiveAI): lower CPU safety margin to 5%, lower risk to LOW")
+	})
+
+	t.Run("AllowAggressiveAI", func(t *testing.T) {
+		config := reviewWith(t, `{"riskLevel": "LOW", "cpuSafetyMargin": 0.05}`, true)
+		assert.Less(t, totalCPU(config), totalCPU(baseline[0]))
+		assert.Equal(t, "LOW", config.RiskAssessment.OverallRisk)
+	})
+}
+
+// Test that sizing replicas and requests together never drops aggregate
+// capacity below observed demand, and doesn't double-count idle replicas
+func TestOptimizePreservesAggregateCapacity(t *testing.T) {
+	units, _ := newBenchUnits(1)
+	manifest, err := units[0].Manifest()
+	require.NoError(t, err)
+	const replicas, cpuMillis, memoryBytes = 4, 1000, 1 << 30
+
+	for _, cpuWaste := range []float64{0, 0.2, 0.5, 0.8} {
+		for _, memoryWaste := range []float64{0, 0.3, 0.6} {
+			for _, idle := range []int32{0, 1, 2, 3} {
+				for _, confidence := range []float64{0.4, 0.7, 1} {
+					waste := &WasteMetrics{
+						CPUWastePercent:    cpuWaste,
+						MemoryWastePercent: memoryWaste,
+						IdleReplicas:       idle,
+						WasteConfidence:    confidence,
+					}
+					name := fmt.Sprintf("cpu=%.1f/memory=%.1f/idle=%d/confidence=%.1f", cpuWaste, memoryWaste, idle, confidence)
+
+					config, err := newBenchEngine(1).optimizeDeployment(units[0], manifest, waste)
+					require.NoError(t, err, name)
+					optimized, err := config.OptimizedUnit.Manifest()
+					require.NoError(t, err, name)
+					specs := newBenchEngine(1).extractResourceSpecs(optimized)
+
+					assert.GreaterOrEqual(t, float64(specs.Replicas)*float64(specs.CPU.MilliValue()),
+						replicas*cpuMillis*(1-cpuWaste), name)
+					assert.GreaterOrEqual(t, float64(specs.Replicas)*float64(specs.Memory.BytesValue()),
+						replicas*memoryBytes*(1-memoryWaste), name)
+					assert.GreaterOrEqual(t, specs.Replicas, DefaultSafetyConfiguration.MinReplicas, name)
+				}
+			}
+		}
+	}
+
+	// 60% CPU and 50% memory waste with 2 idle replicas: removing the idle
+	// replicas and also shrinking every pod by the waste would leave 2 pods
+	// of 552m for 1840m of demand
+	config, err := newBenchEngine(1).optimizeDeployment(units[0], manifest, &WasteMetrics{
+		CPUWastePercent: 0.6, MemoryWastePercent: 0.5, IdleReplicas: 2, WasteConfidence: 0.9,
+	})
+	require.NoError(t, err)
+	optimized := map[string]string{}
+	for _, opt := range config.Optimizations {
+		optimized[opt.Type] = opt.OptimizedValue
+	}
+	assert.Equal(t, "3", optimized["replicas"])
+	assert.Equal(t, "736m", optimized["cpu"], "1840m demand plus 20% over 3 replicas")
+}
+
+// Test replica optimizations emitted as a HorizontalPodAutoscaler
+func TestGenerateHPAUnit(t *testing.T) {
+	units, waste := newBenchUnits(1)
+	engine := newBenchEngine(1)
+	engine.SetReplicaStrategy(StrategyHPA)
+
+	config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+	require.NoError(t, err)
+	optimized, err := config.OptimizedUnit.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, 4, optimized["spec"].(map[string]interface{})["replicas"], "spec.replicas is left to the HPA")
+
+	require.NotEmpty(t, config.AdditionalUnits)
+	hpaUnit := config.AdditionalUnits[0]
+	assert.Equal(t, "svc-000-hpa", hpaUnit.Slug)
+	assert.Equal(t, &units[0].UnitID, hpaUnit.UpstreamUnitID)
+	hpa, err := hpaUnit.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, "HorizontalPodAutoscaler", hpa["kind"])
+	spec := hpa["spec"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "svc-000"}, spec["scaleTargetRef"])
+	assert.Equal(t, 3, spec["minReplicas"], "the capacity plan's replica floor")
+	assert.Equal(t, 4, spec["maxReplicas"], "capacity up to the current count")
+
+	var hpaOpt *ResourceOptimization
+	for i := range config.Optimizations {
+		if config.Optimizations[i].Type == "hpa" {
+			hpaOpt = &config.Optimizations[i]
+		}
+	}
+	require.NotNil(t, hpaOpt)
+	assert.Equal(t, "3-4", hpaOpt.OptimizedValue)
+
+	t.Run("CPUTarget", func(t *testing.T) {
+		manifest, err := units[0].Manifest()
+		require.NoError(t, err)
+		floor := &ResourceOptimization{Type: "replicas", OptimizedValue: "2"}
+
+		target := func(plan capacityPlan) interface{} {
+			hpaUnit, _, err := engine.generateHPAUnit(units[0], manifest, 4, floor, plan)
+			require.NoError(t, err)
+			hpa, err := hpaUnit.Manifest()
+			require.NoError(t, err)
+			metric := hpa["spec"].(map[string]interface{})["metrics"].([]interface{})[0].(map[string]interface{})
+			return metric["resource"].(map[string]interface{})["target"].(map[string]interface{})["averageUtilization"]
+		}
+		// 600m of demand on 2 pods of 500m
+		assert.Equal(t, 60, target(capacityPlan{CPUMillis: 500, DemandCPUMillis: 600}))
+		assert.Equal(t, 50, target(capacityPlan{CPUMillis: 500, DemandCPUMillis: 100}), "kept at or above 50%")
+		assert.Equal(t, 80, target(capacityPlan{CPUMillis: 500, DemandCPUMillis: 950}), "kept at or below 80%")
+		assert.Equal(t, 80, target(capacityPlan{}), "no CPU requests")
+
+		_, _, err = engine.generateHPAUnit(units[0], manifest, 4, &ResourceOptimization{OptimizedValue: "two"}, capacityPlan{})
+		assert.ErrorContains(t, err, "invalid replica floor")
+	})
+}
+
+// Test PodDisruptionBudgets generated for reduced replica counts
+func TestGeneratePDB(t *testing.T) {
+	units, waste := newBenchUnits(1)
+	engine := newBenchEngine(1)
+	safety := *DefaultSafetyConfiguration
+	safety.GeneratePDB = true
+	engine.SetSafetyConfiguration(&safety)
+
+	config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+	require.NoError(t, err)
+	require.Len(t, config.AdditionalUnits, 1)
+	pdbUnit := config.AdditionalUnits[0]
+	assert.Equal(t, "svc-000-pdb", pdbUnit.Slug)
+	pdb, err := pdbUnit.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, "PodDisruptionBudget", pdb["kind"])
+	spec := pdb["spec"].(map[string]interface{})
+	assert.Equal(t, 2, spec["minAvailable"], "all but one of the 3 optimized replicas")
+	assert.Equal(t, map[string]interface{}{"matchLabels": map[string]interface{}{"app": "svc-000"}}, spec["selector"])
+	assert.Contains(t, config.RiskAssessment.Mitigations, "PodDisruptionBudget added (minAvailable: 2) to protect availability during node drains")
+
+	manifest, err := units[0].Manifest()
+	require.NoError(t, err)
+	for replicas, want := range map[int32]int32{1: 1, 2: 1, 5: 4} {
+		_, minAvailable, err := engine.generatePDBUnit(units[0], manifest, replicas)
+		require.NoError(t, err)
+		assert.Equal(t, want, minAvailable, "%d replicas", replicas)
+	}
+
+	delete(manifest["spec"].(map[string]interface{}), "selector")
+	_, _, err = engine.generatePDBUnit(units[0], manifest, 3)
+	assert.ErrorContains(t, err, "no spec.selector.matchLabels")
+}
+
+// Test QoS classes and that Guaranteed pods stay Guaranteed when optimized
+func TestPreserveQoSClass(t *testing.T) {
+	deployment := func(resources string) string {
+		return "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 1\n  template:\n    spec:\n      containers:\n      - name: app\n" + resources
+	}
+	guaranteed := deployment("        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n          limits:\n            cpu: 1000m\n            memory: 1024Mi\n")
+	burstable := deployment("        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n          limits:\n            cpu: \"2\"\n            memory: 2Gi\n")
+	limitsOnly := deployment("        resources:\n          limits:\n            cpu: \"1\"\n            memory: 1Gi\n")
+	bestEffort := deployment("        image: nginx:1.25\n")
+
+	for data, want := range map[string]string{
+		guaranteed: "Guaranteed",
+		limitsOnly: "Guaranteed", // Requests default to the limits
+		burstable:  "Burstable",
+		bestEffort: "BestEffort",
+	} {
+		unit := Unit{Slug: "web", Data: data}
+		manifest, err := unit.Manifest()
+		require.NoError(t, err)
+		assert.Equal(t, want, podQoSClass(manifest), data)
+	}
+
+	waste := &WasteMetrics{CPUWastePercent: 0.6, MemoryWastePercent: 0.5, WasteConfidence: 0.9}
+	optimize := func(t *testing.T, preserve bool, data string) *OptimizedConfiguration {
+		engine := newBenchEngine(1)
+		safety := *DefaultSafetyConfiguration
+		safety.PreserveQoSClass = preserve
+		engine.SetSafetyConfiguration(&safety)
+		config, err := engine.GenerateOptimizedUnit(&Unit{UnitID: uuid.New(), Slug: "web", Data: data}, waste)
+		require.NoError(t, err)
+		return config
+	}
+
+	t.Run("Guaranteed", func(t *testing.T) {
+		config := optimize(t, true, guaranteed)
+		assert.Equal(t, "Guaranteed", config.OptimizedUnit.Annotations["optimizer.io/qos-class-original"])
+		assert.Equal(t, "Guaranteed", config.OptimizedUnit.Annotations["optimizer.io/qos-class-optimized"])
+	})
+
+	t.Run("Burstable", func(t *testing.T) {
+		config := optimize(t, true, burstable)
+		assert.Equal(t, "Burstable", config.OptimizedUnit.Annotations["optimizer.io/qos-class-optimized"])
+	})
+
+	t.Run("BestEffort", func(t *testing.T) {
+		config := optimize(t, true, bestEffort)
+		assert.Empty(t, config.Optimizations, "nothing to right-size without requests or limits")
+		assert.Equal(t, "BestEffort", config.OptimizedUnit.Annotations["optimizer.io/qos-class-optimized"])
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		config := optimize(t, false, guaranteed)
+		assert.Equal(t, "Burstable", config.OptimizedUnit.Annotations["optimizer.io/qos-class-optimized"], "limits get burst headroom")
+	})
+}
@@ -2,6 +2,8 @@ package sdk
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,15 +35,18 @@ type PackageOptions struct {
 
 // PackageManifest represents the package manifest structure
 type PackageManifest struct {
-	Version     string       `json:"version,omitempty"`
-	CreatedAt   time.Time    `json:"created_at"`
-	Description string       `json:"description,omitempty"`
-	Spaces      []SpaceEntry `json:"spaces"`
-	Units       []UnitEntry  `json:"units"`
-	Links       []LinkEntry  `json:"links,omitempty"`
-	Filters     []FilterEntry `json:"filters,omitempty"`
-	Workers     []WorkerEntry `json:"workers,omitempty"`
-	Targets     []TargetEntry `json:"targets,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Description string            `json:"description,omitempty"`
+	Spaces      []SpaceEntry      `json:"spaces"`
+	Units       []UnitEntry       `json:"units"`
+	Links       []LinkEntry       `json:"links,omitempty"`
+	Filters     []FilterEntry     `json:"filters,omitempty"`
+	Workers     []WorkerEntry     `json:"workers,omitempty"`
+	Targets     []TargetEntry     `json:"targets,omitempty"`
+	Checksums   map[string]string `json:"checksums,omitempty"` // File location -> SHA-256 for details files
+
+	dir string // Package directory the manifest was loaded from, for reading data files
 }
 
 // SpaceEntry represents a space in the manifest
@@ -55,6 +61,7 @@ type UnitEntry struct {
 	SpaceSlug   string `json:"space_slug"`
 	DetailsLoc  string `json:"details_loc"`
 	UnitDataLoc string `json:"unit_data_loc"`
+	SHA256      string `json:"sha256,omitempty"` // Checksum of the unit_data file
 }
 
 // LinkEntry represents a link in the manifest
@@ -96,7 +103,8 @@ func NewPackageHelper(cub *ConfigHubClient) *PackageHelper {
 }
 
 // CreatePackage exports ConfigHub resources to a package directory
-// This wraps the `cub package create` command
+// This wraps the `cub package create` command; see CreatePackageNative for an
+// implementation that only needs the API
 func (p *PackageHelper) CreatePackage(dir string, opts PackageOptions) error {
 	// Ensure experimental features are enabled
 	env := append(os.Environ(), "CONFIGHUB_EXPERIMENTAL=1")
@@ -138,6 +146,188 @@ func (p *PackageHelper) CreatePackage(dir string, opts PackageOptions) error {
 	return nil
 }
 
+// CreatePackageNative exports ConfigHub resources to a package directory using
+// only the API, so neither the cub CLI nor CONFIGHUB_EXPERIMENTAL is needed.
+// It writes the same layout as `cub package create`:
+//
+//	manifest.json
+//	details/spaces/<space>.json
+//	details/units/<space>/<unit>.json
+//	details/links/<space>/<link>.json
+//	details/filters/<space>/<filter>.json
+//	details/workers/<space>/<worker>.json
+//	details/targets/<space>/<target>.json
+//	unit_data/<space>/<unit>.yaml
+func (p *PackageHelper) CreatePackageNative(dir string, opts PackageOptions) error {
+	if opts.SpaceID == uuid.Nil {
+		return fmt.Errorf("package create requires a space")
+	}
+
+	space, err := p.cub.GetSpace(opts.SpaceID)
+	if err != nil {
+		return fmt.Errorf("get space: %w", err)
+	}
+
+	where := opts.Where
+	if where == "" && opts.Filter != "" {
+		filter, err := p.findFilter(opts.SpaceID, opts.Filter)
+		if err != nil {
+			return err
+		}
+		where = filter.Where
+	}
+
+	units, err := p.cub.ListAllUnits(ListUnitsParams{SpaceID: opts.SpaceID, Where: where})
+	if err != nil {
+		return fmt.Errorf("list units: %w", err)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Slug < units[j].Slug })
+
+	links, err := p.cub.ListLinks(opts.SpaceID)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("list links: %w", err)
+	}
+	filters, err := p.cub.ListFilters(opts.SpaceID)
+	if err != nil {
+		return fmt.Errorf("list filters: %w", err)
+	}
+	workers, err := p.cub.ListWorkers(opts.SpaceID)
+	if err != nil {
+		return fmt.Errorf("list workers: %w", err)
+	}
+	targets, err := p.cub.ListTargets(opts.SpaceID)
+	if err != nil {
+		return fmt.Errorf("list targets: %w", err)
+	}
+
+	manifest := &PackageManifest{
+		CreatedAt:   time.Now().UTC(),
+		Description: fmt.Sprintf("Package exported from space %s", space.Slug),
+		Spaces:      []SpaceEntry{},
+		Units:       []UnitEntry{},
+	}
+
+	spaceLoc := filepath.Join("details", "spaces", space.Slug+".json")
+	if err := writePackageJSON(dir, spaceLoc, space); err != nil {
+		return err
+	}
+	manifest.Spaces = append(manifest.Spaces, SpaceEntry{Slug: space.Slug, DetailsLoc: spaceLoc})
+
+	unitSlugs := make(map[uuid.UUID]string, len(units))
+	for _, unit := range units {
+		unitSlugs[unit.UnitID] = unit.Slug
+
+		// unit_data files hold the manifest text, whatever the Data encoding
+		data, err := decodeUnitData(*unit)
+		if err != nil {
+			return err
+		}
+		dataLoc := filepath.Join("unit_data", space.Slug, unit.Slug+".yaml")
+		if err := writePackageFile(dir, dataLoc, data); err != nil {
+			return err
+		}
+
+		// Data lives in unit_data, not in the details file
+		details := *unit
+		details.Data = ""
+		detailsLoc := filepath.Join("details", "units", space.Slug, unit.Slug+".json")
+		if err := writePackageJSON(dir, detailsLoc, details); err != nil {
+			return err
+		}
+
+		manifest.Units = append(manifest.Units, UnitEntry{
+			Slug:        unit.Slug,
+			SpaceSlug:   space.Slug,
+			DetailsLoc:  detailsLoc,
+			UnitDataLoc: dataLoc,
+		})
+	}
+
+	for _, link := range links {
+		from, okFrom := unitSlugs[link.FromUnitID]
+		to, okTo := unitSlugs[link.ToUnitID]
+		if !okFrom || !okTo {
+			continue // Link to a unit outside the package
+		}
+		loc := filepath.Join("details", "links", space.Slug, link.Slug+".json")
+		if err := writePackageJSON(dir, loc, link); err != nil {
+			return err
+		}
+		manifest.Links = append(manifest.Links, LinkEntry{
+			Slug:       link.Slug,
+			SpaceSlug:  space.Slug,
+			FromUnit:   from,
+			ToUnit:     to,
+			DetailsLoc: loc,
+		})
+	}
+
+	for _, filter := range filters {
+		loc := filepath.Join("details", "filters", space.Slug, filter.Slug+".json")
+		if err := writePackageJSON(dir, loc, filter); err != nil {
+			return err
+		}
+		manifest.Filters = append(manifest.Filters, FilterEntry{Slug: filter.Slug, SpaceSlug: space.Slug, DetailsLoc: loc})
+	}
+
+	for _, worker := range workers {
+		loc := filepath.Join("details", "workers", space.Slug, worker.Slug+".json")
+		if err := writePackageJSON(dir, loc, worker); err != nil {
+			return err
+		}
+		manifest.Workers = append(manifest.Workers, WorkerEntry{Slug: worker.Slug, SpaceSlug: space.Slug, DetailsLoc: loc})
+	}
+
+	for _, target := range targets {
+		loc := filepath.Join("details", "targets", space.Slug, target.Slug+".json")
+		if err := writePackageJSON(dir, loc, target); err != nil {
+			return err
+		}
+		manifest.Targets = append(manifest.Targets, TargetEntry{Slug: target.Slug, SpaceSlug: space.Slug, DetailsLoc: loc})
+	}
+
+	if err := recordPackageChecksums(dir, manifest); err != nil {
+		return err
+	}
+
+	return writePackageJSON(dir, "manifest.json", manifest)
+}
+
+// findFilter looks up a filter by slug in a space
+func (p *PackageHelper) findFilter(spaceID uuid.UUID, slug string) (*Filter, error) {
+	filters, err := p.cub.ListFilters(spaceID)
+	if err != nil {
+		return nil, fmt.Errorf("list filters: %w", err)
+	}
+	for _, filter := range filters {
+		if filter.Slug == slug {
+			return filter, nil
+		}
+	}
+	return nil, fmt.Errorf("filter %s not found", slug)
+}
+
+// writePackageJSON writes v as indented JSON at loc inside the package directory
+func writePackageJSON(dir, loc string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", loc, err)
+	}
+	return writePackageFile(dir, loc, data)
+}
+
+// writePackageFile writes data at loc inside the package directory
+func writePackageFile(dir, loc string, data []byte) error {
+	path := filepath.Join(dir, loc)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", loc, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", loc, err)
+	}
+	return nil
+}
+
 // LoadPackage imports a package from directory or URL
 // This wraps the `cub package load` command
 func (p *PackageHelper) LoadPackage(source string, prefix string) error {
@@ -195,11 +385,91 @@ func (p *PackageHelper) ValidatePackage(dir string) error {
 		}
 	}
 
+	// Verify checksums where recorded; older packages have none
+	for _, unit := range manifest.Units {
+		if unit.SHA256 == "" {
+			continue
+		}
+		if err := verifyPackageChecksum(dir, unit.UnitDataLoc, unit.SHA256); err != nil {
+			return err
+		}
+	}
+	for _, loc := range sortedKeys(manifest.Checksums) {
+		if err := verifyPackageChecksum(dir, loc, manifest.Checksums[loc]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyPackageChecksum recomputes the SHA-256 of a package file
+func verifyPackageChecksum(dir, loc, expected string) error {
+	data, err := os.ReadFile(filepath.Join(dir, loc))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", loc, err)
+	}
+	if actual := sha256Hex(data); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: manifest has %s, file has %s", loc, expected, actual)
+	}
+	return nil
+}
+
+// recordPackageChecksums stores the SHA-256 of every file the manifest references
+func recordPackageChecksums(dir string, manifest *PackageManifest) error {
+	hashFile := func(loc string) (string, error) {
+		data, err := os.ReadFile(filepath.Join(dir, loc))
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", loc, err)
+		}
+		return sha256Hex(data), nil
+	}
+
+	var detailsLocs []string
+	for _, space := range manifest.Spaces {
+		detailsLocs = append(detailsLocs, space.DetailsLoc)
+	}
+	for i, unit := range manifest.Units {
+		hash, err := hashFile(unit.UnitDataLoc)
+		if err != nil {
+			return err
+		}
+		manifest.Units[i].SHA256 = hash
+		detailsLocs = append(detailsLocs, unit.DetailsLoc)
+	}
+	for _, link := range manifest.Links {
+		detailsLocs = append(detailsLocs, link.DetailsLoc)
+	}
+	for _, filter := range manifest.Filters {
+		detailsLocs = append(detailsLocs, filter.DetailsLoc)
+	}
+	for _, worker := range manifest.Workers {
+		detailsLocs = append(detailsLocs, worker.DetailsLoc)
+	}
+	for _, target := range manifest.Targets {
+		detailsLocs = append(detailsLocs, target.DetailsLoc)
+	}
+
+	manifest.Checksums = make(map[string]string, len(detailsLocs))
+	for _, loc := range detailsLocs {
+		if loc == "" {
+			continue
+		}
+		hash, err := hashFile(loc)
+		if err != nil {
+			return err
+		}
+		manifest.Checksums[loc] = hash
+	}
 	return nil
 }
 
 // LoadManifest loads a package manifest from file
 func (p *PackageHelper) LoadManifest(path string) (*PackageManifest, error) {
+	return loadPackageManifest(path)
+}
+
+func loadPackageManifest(path string) (*PackageManifest, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -209,6 +479,7 @@ func (p *PackageHelper) LoadManifest(path string) (*PackageManifest, error) {
 	if err := json.Unmarshal(data, &manifest); err != nil {
 		return nil, err
 	}
+	manifest.dir = filepath.Dir(path)
 
 	return &manifest, nil
 }
@@ -230,6 +501,10 @@ func (p *PackageHelper) enhanceManifest(manifestPath string, opts PackageOptions
 		manifest.Description = fmt.Sprintf("Package exported from space %s", opts.SpaceID)
 	}
 
+	if err := recordPackageChecksums(filepath.Dir(manifestPath), manifest); err != nil {
+		return err
+	}
+
 	// Write back
 	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
@@ -415,4 +690,196 @@ func (p *PackageHelper) FetchRemoteManifest(url string) (*PackageManifest, error
 	}
 
 	return &manifest, nil
-}
\ No newline at end of file
+}
+
+// PackageDiff lists what loading package B would change relative to package A
+type PackageDiff struct {
+	Changes []PackageChange
+}
+
+// PackageChange is a single unit, filter or target difference between packages
+type PackageChange struct {
+	Type    string // unit, filter, target
+	Key     string // Slug, or space/slug for multi-space packages
+	Change  string // added, removed, modified
+	OldHash string // SHA-256 of the compared content in A
+	NewHash string // SHA-256 of the compared content in B
+}
+
+// HasChanges reports whether the packages differ
+func (pd *PackageDiff) HasChanges() bool {
+	return len(pd.Changes) > 0
+}
+
+// DiffPackageDirs loads the manifests in two package directories and diffs them
+func DiffPackageDirs(dirA, dirB string) (*PackageDiff, error) {
+	a, err := loadPackageManifest(filepath.Join(dirA, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", dirA, err)
+	}
+	b, err := loadPackageManifest(filepath.Join(dirB, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", dirB, err)
+	}
+	return DiffPackages(a, b)
+}
+
+// DiffPackages compares two loaded packages. Units are compared by the SHA-256
+// of their unit_data files; filters by From/Where/Select and targets by type and
+// config, so IDs and timestamps that differ between environments are ignored.
+// Entries are matched by slug, or by space/slug when a package has several
+// spaces, which lets packages exported from differently named spaces be compared.
+// Both manifests must have been loaded from disk so their data files can be read.
+func DiffPackages(a, b *PackageManifest) (*PackageDiff, error) {
+	if a.dir == "" || b.dir == "" {
+		return nil, fmt.Errorf("package manifests must be loaded from a directory")
+	}
+
+	diff := &PackageDiff{Changes: []PackageChange{}}
+
+	for _, kind := range []struct {
+		name string
+		hash func(m *PackageManifest) (map[string]string, error)
+	}{
+		{"unit", hashPackageUnits},
+		{"filter", hashPackageFilters},
+		{"target", hashPackageTargets},
+	} {
+		hashesA, err := kind.hash(a)
+		if err != nil {
+			return nil, err
+		}
+		hashesB, err := kind.hash(b)
+		if err != nil {
+			return nil, err
+		}
+		diff.Changes = append(diff.Changes, diffHashes(kind.name, hashesA, hashesB)...)
+	}
+
+	return diff, nil
+}
+
+// diffHashes compares key -> hash maps, sorted by key
+func diffHashes(kind string, a, b map[string]string) []PackageChange {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []PackageChange
+	for _, k := range sorted {
+		oldHash, inA := a[k]
+		newHash, inB := b[k]
+		switch {
+		case !inA:
+			changes = append(changes, PackageChange{Type: kind, Key: k, Change: "added", NewHash: newHash})
+		case !inB:
+			changes = append(changes, PackageChange{Type: kind, Key: k, Change: "removed", OldHash: oldHash})
+		case oldHash != newHash:
+			changes = append(changes, PackageChange{Type: kind, Key: k, Change: "modified", OldHash: oldHash, NewHash: newHash})
+		}
+	}
+	return changes
+}
+
+// packageKey identifies an entry across packages
+func packageKey(m *PackageManifest, spaceSlug, slug string) string {
+	if len(m.Spaces) > 1 {
+		return spaceSlug + "/" + slug
+	}
+	return slug
+}
+
+func hashPackageUnits(m *PackageManifest) (map[string]string, error) {
+	hashes := make(map[string]string, len(m.Units))
+	for _, unit := range m.Units {
+		data, err := os.ReadFile(filepath.Join(m.dir, unit.UnitDataLoc))
+		if err != nil {
+			return nil, fmt.Errorf("read unit data for %s: %w", unit.Slug, err)
+		}
+		hashes[packageKey(m, unit.SpaceSlug, unit.Slug)] = sha256Hex(data)
+	}
+	return hashes, nil
+}
+
+func hashPackageFilters(m *PackageManifest) (map[string]string, error) {
+	hashes := make(map[string]string, len(m.Filters))
+	for _, entry := range m.Filters {
+		var filter Filter
+		if err := readPackageDetails(m, entry.DetailsLoc, &filter); err != nil {
+			return nil, err
+		}
+		hash, err := hashJSON(struct {
+			From   string
+			Where  string
+			Select []string
+		}{filter.From, filter.Where, filter.Select})
+		if err != nil {
+			return nil, err
+		}
+		hashes[packageKey(m, entry.SpaceSlug, entry.Slug)] = hash
+	}
+	return hashes, nil
+}
+
+func hashPackageTargets(m *PackageManifest) (map[string]string, error) {
+	hashes := make(map[string]string, len(m.Targets))
+	for _, entry := range m.Targets {
+		var target Target
+		if err := readPackageDetails(m, entry.DetailsLoc, &target); err != nil {
+			return nil, err
+		}
+		hash, err := hashJSON(struct {
+			TargetType string
+			Config     map[string]string
+			Worker     string
+		}{target.TargetType, target.Config, entry.Worker})
+		if err != nil {
+			return nil, err
+		}
+		hashes[packageKey(m, entry.SpaceSlug, entry.Slug)] = hash
+	}
+	return hashes, nil
+}
+
+// readPackageDetails decodes a details file referenced by the manifest
+func readPackageDetails(m *PackageManifest, loc string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(m.dir, loc))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", loc, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", loc, err)
+	}
+	return nil
+}
+
+func hashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(data), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
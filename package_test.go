@@ -0,0 +1,177 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test package export without the cub CLI
+func TestCreatePackageNative(t *testing.T) {
+	spaceID := uuid.New()
+	webID, dbID := uuid.New(), uuid.New()
+
+	var unitsQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/unit"):
+			unitsQuery = r.URL.Query().Get("where")
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Unit": Unit{UnitID: webID, Slug: "web", Data: "kind: Deployment\n"}},
+				{"Unit": Unit{UnitID: dbID, Slug: "db", Data: base64.StdEncoding.EncodeToString([]byte("kind: StatefulSet\n"))}},
+			})
+		case strings.HasSuffix(path, "/link"):
+			json.NewEncoder(w).Encode([]Link{
+				{Slug: "web-to-db", FromUnitID: webID, ToUnitID: dbID},
+				{Slug: "external", FromUnitID: webID, ToUnitID: uuid.New()},
+			})
+		case strings.HasSuffix(path, "/filter"):
+			json.NewEncoder(w).Encode([]Filter{{Slug: "backend", From: "Unit", Where: "Labels.tier = 'backend'"}})
+		case strings.HasSuffix(path, "/worker"), strings.HasSuffix(path, "/target"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			json.NewEncoder(w).Encode(Space{SpaceID: spaceID, Slug: "prod"})
+		}
+	}))
+	defer server.Close()
+
+	t.Run("WritesLayout", func(t *testing.T) {
+		dir := t.TempDir()
+		helper := NewPackageHelper(NewConfigHubClient(server.URL, "test-token"))
+		require.NoError(t, helper.CreatePackageNative(dir, PackageOptions{SpaceID: spaceID}))
+
+		manifest, err := helper.ListPackageContents(dir)
+		require.NoError(t, err)
+		require.Len(t, manifest.Spaces, 1)
+		assert.Equal(t, "prod", manifest.Spaces[0].Slug)
+		require.Len(t, manifest.Units, 2)
+		assert.Equal(t, "db", manifest.Units[0].Slug, "units are sorted for stable output")
+		require.Len(t, manifest.Links, 1)
+		assert.Equal(t, LinkEntry{
+			Slug:       "web-to-db",
+			SpaceSlug:  "prod",
+			FromUnit:   "web",
+			ToUnit:     "db",
+			DetailsLoc: filepath.Join("details", "links", "prod", "web-to-db.json"),
+		}, manifest.Links[0])
+		require.Len(t, manifest.Filters, 1)
+
+		require.NoError(t, helper.ValidatePackage(dir))
+
+		data, err := os.ReadFile(filepath.Join(dir, manifest.Units[1].UnitDataLoc))
+		require.NoError(t, err)
+		assert.Equal(t, "kind: Deployment\n", string(data))
+		data, err = os.ReadFile(filepath.Join(dir, manifest.Units[0].UnitDataLoc))
+		require.NoError(t, err)
+		assert.Equal(t, "kind: StatefulSet\n", string(data), "base64 Data is decoded")
+
+		details, err := os.ReadFile(filepath.Join(dir, manifest.Units[1].DetailsLoc))
+		require.NoError(t, err)
+		assert.NotContains(t, string(details), `"Data"`)
+	})
+
+	t.Run("ChecksumsDetectCorruption", func(t *testing.T) {
+		dir := t.TempDir()
+		helper := NewPackageHelper(NewConfigHubClient(server.URL, "test-token"))
+		require.NoError(t, helper.CreatePackageNative(dir, PackageOptions{SpaceID: spaceID}))
+
+		manifest, err := helper.ListPackageContents(dir)
+		require.NoError(t, err)
+		assert.Len(t, manifest.Units[0].SHA256, 64)
+		assert.Contains(t, manifest.Checksums, manifest.Spaces[0].DetailsLoc)
+		require.NoError(t, helper.ValidatePackage(dir))
+
+		dataLoc := manifest.Units[0].UnitDataLoc
+		require.NoError(t, os.WriteFile(filepath.Join(dir, dataLoc), []byte("kind: Stat"), 0644))
+		err = helper.ValidatePackage(dir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), dataLoc)
+		assert.Contains(t, err.Error(), manifest.Units[0].SHA256)
+
+		// Packages without checksums still validate
+		manifest.Units[0].SHA256 = ""
+		manifest.Checksums = nil
+		require.NoError(t, writePackageJSON(dir, "manifest.json", manifest))
+		assert.NoError(t, helper.ValidatePackage(dir))
+	})
+
+	t.Run("FilterSelectsUnits", func(t *testing.T) {
+		helper := NewPackageHelper(NewConfigHubClient(server.URL, "test-token"))
+		require.NoError(t, helper.CreatePackageNative(t.TempDir(), PackageOptions{SpaceID: spaceID, Filter: "backend"}))
+		assert.Equal(t, "Labels.tier = 'backend'", unitsQuery)
+
+		err := helper.CreatePackageNative(t.TempDir(), PackageOptions{SpaceID: spaceID, Filter: "missing"})
+		assert.Error(t, err)
+	})
+}
+
+// writeTestPackage writes a single-space package with the given unit data and filters
+func writeTestPackage(t *testing.T, space string, units map[string]string, filters map[string]string) string {
+	dir := t.TempDir()
+	manifest := PackageManifest{Spaces: []SpaceEntry{{Slug: space}}}
+	for slug, data := range units {
+		loc := filepath.Join("unit_data", space, slug+".yaml")
+		require.NoError(t, writePackageFile(dir, loc, []byte(data)))
+		manifest.Units = append(manifest.Units, UnitEntry{Slug: slug, SpaceSlug: space, UnitDataLoc: loc})
+	}
+	for slug, where := range filters {
+		loc := filepath.Join("details", "filters", space, slug+".json")
+		require.NoError(t, writePackageJSON(dir, loc, Filter{FilterID: uuid.New(), Slug: slug, From: "Unit", Where: where}))
+		manifest.Filters = append(manifest.Filters, FilterEntry{Slug: slug, SpaceSlug: space, DetailsLoc: loc})
+	}
+	require.NoError(t, writePackageJSON(dir, "manifest.json", manifest))
+	return dir
+}
+
+// Test package diffing between environments
+func TestDiffPackages(t *testing.T) {
+	t.Run("UnitsAndFilters", func(t *testing.T) {
+		dirA := writeTestPackage(t, "app-staging",
+			map[string]string{"web": "replicas: 2\n", "db": "kind: StatefulSet\n", "cache": "kind: Deployment\n"},
+			map[string]string{"backend": "Labels.tier = 'backend'", "all": "Slug != ''"})
+		dirB := writeTestPackage(t, "app-prod",
+			map[string]string{"web": "replicas: 5\n", "db": "kind: StatefulSet\n", "queue": "kind: Deployment\n"},
+			map[string]string{"backend": "Labels.tier = 'db'", "all": "Slug != ''"})
+
+		diff, err := DiffPackageDirs(dirA, dirB)
+		require.NoError(t, err)
+		require.True(t, diff.HasChanges())
+
+		var summary []string
+		for _, change := range diff.Changes {
+			summary = append(summary, change.Type+":"+change.Key+":"+change.Change)
+		}
+		assert.Equal(t, []string{
+			"unit:cache:removed",
+			"unit:queue:added",
+			"unit:web:modified",
+			"filter:backend:modified",
+		}, summary, "filter IDs differ but are not reported")
+
+		output := RenderPackageDiffTable(diff)
+		assert.Contains(t, output, "web")
+		assert.Contains(t, output, diff.Changes[2].OldHash[:12]+" → "+diff.Changes[2].NewHash[:12])
+	})
+
+	t.Run("IdenticalPackages", func(t *testing.T) {
+		units := map[string]string{"web": "replicas: 2\n"}
+		diff, err := DiffPackageDirs(writeTestPackage(t, "a", units, nil), writeTestPackage(t, "b", units, nil))
+		require.NoError(t, err)
+		assert.False(t, diff.HasChanges())
+	})
+
+	t.Run("RequiresLoadedManifests", func(t *testing.T) {
+		_, err := DiffPackages(&PackageManifest{}, &PackageManifest{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,283 @@
+// rollback.go - Optimization rollback module for the DevOps SDK
+//
+// This module records how to revert every change the OptimizationEngine makes,
+// so a misbehaving optimization can be undone without reconstructing the
+// original values by hand.
+//
+// Features:
+// - Rollback plans with original requests/limits and replica counts
+// - Ready-to-apply ConfigHub function calls for each revert
+// - Base64 plan annotation that survives loss of the in-memory result
+// - One-call rollback against ConfigHub
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// RollbackPlanAnnotation is the unit annotation holding the base64-encoded plan
+const RollbackPlanAnnotation = "optimizer.io/rollback-plan"
+
+// RollbackPlan describes how to revert an optimized unit to its original state
+type RollbackPlan struct {
+	OriginalUnitSlug  string         `json:"originalUnitSlug"`
+	OptimizedUnitSlug string         `json:"optimizedUnitSlug"`
+	OriginalManifest  string         `json:"originalManifest"` // Ready-to-apply original manifest
+	Steps             []RollbackStep `json:"steps"`
+}
+
+// RollbackStep reverts a single optimization
+type RollbackStep struct {
+	Type        string                            `json:"type"` // cpu, memory, replicas, generated-unit
+	Description string                            `json:"description"`
+	Resources   map[string]map[string]interface{} `json:"resources,omitempty"` // Container name -> original resources block
+	Function    *FunctionInvocationRequest        `json:"function,omitempty"`  // ConfigHub function call that reverts the change
+	UnitSlug    string                            `json:"unitSlug,omitempty"`  // Sibling unit to destroy (e.g., HPA)
+	Workload    string                            `json:"workload,omitempty"`  // Kind/name of the document Resources belong to in a multi-document unit
+}
+
+// buildRollbackPlan records the original values touched by the given optimizations
+func (oe *OptimizationEngine) buildRollbackPlan(unit *Unit, manifest map[string]interface{}, optimizedSlug string, optimizations []ResourceOptimization, additionalUnits []*Unit) *RollbackPlan {
+	plan := &RollbackPlan{
+		OriginalUnitSlug:  unit.Slug,
+		OptimizedUnitSlug: optimizedSlug,
+		OriginalManifest:  unit.Data,
+		Steps:             []RollbackStep{},
+	}
+	if data, err := decodeUnitData(*unit); err == nil {
+		plan.OriginalManifest = string(data) // Ready to apply even if Data is base64
+	}
+
+	originalResources := containerResourcesByName(manifest)
+
+	for _, opt := range optimizations {
+		switch opt.Type {
+		case "cpu", "memory":
+			plan.Steps = append(plan.Steps, RollbackStep{
+				Type:        opt.Type,
+				Description: fmt.Sprintf("Restore original %s requests/limits (total %s)", opt.Type, opt.OriginalValue),
+				Resources:   originalResources,
+			})
+		case "replicas":
+			replicas, err := strconv.Atoi(opt.OriginalValue)
+			if err != nil {
+				continue
+			}
+			plan.Steps = append(plan.Steps, RollbackStep{
+				Type:        opt.Type,
+				Description: fmt.Sprintf("Restore replicas to %d", replicas),
+				Function: &FunctionInvocationRequest{
+					FunctionName:  "set-replicas",
+					ToolchainType: "Kubernetes/YAML",
+					Where:         NewWhere().Eq("Slug", optimizedSlug).MustBuild(),
+					Arguments: []FunctionArgument{
+						{ParameterName: "replicas", Value: replicas},
+					},
+				},
+			})
+		}
+	}
+
+	// Generated siblings (HPA, PDB) are reverted by destroying them
+	for _, additional := range additionalUnits {
+		plan.Steps = append(plan.Steps, RollbackStep{
+			Type:        "generated-unit",
+			Description: fmt.Sprintf("Destroy generated unit %s", additional.Slug),
+			UnitSlug:    additional.Slug,
+		})
+	}
+
+	return plan
+}
+
+// containerResourcesByName returns a copy of each container's resources block
+func containerResourcesByName(manifest map[string]interface{}) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+
+	spec, _ := manifest["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+
+	for _, container := range containers {
+		c, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := c["name"].(string)
+		resources, _ := c["resources"].(map[string]interface{})
+		result[name] = copyManifest(resources)
+	}
+
+	return result
+}
+
+// EncodeRollbackPlan serializes a plan for the rollback annotation
+func EncodeRollbackPlan(plan *RollbackPlan) (string, error) {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rollback plan: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecodeRollbackPlan reads the rollback plan embedded in a unit's annotations
+func DecodeRollbackPlan(unit *Unit) (*RollbackPlan, error) {
+	encoded, ok := unit.Annotations[RollbackPlanAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("unit %s has no rollback plan", unit.Slug)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode rollback plan: %v", err)
+	}
+
+	var plan RollbackPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse rollback plan: %v", err)
+	}
+	return &plan, nil
+}
+
+// ApplyRollback reverts an optimization in ConfigHub. The plan is taken from
+// config.RollbackPlan, or decoded from the optimized unit's annotation if unset.
+func (oe *OptimizationEngine) ApplyRollback(config *OptimizedConfiguration) error {
+	plan := config.RollbackPlan
+	if plan == nil {
+		if config.OptimizedUnit == nil {
+			return fmt.Errorf("no rollback plan available")
+		}
+		decoded, err := DecodeRollbackPlan(config.OptimizedUnit)
+		if err != nil {
+			return err
+		}
+		plan = decoded
+	}
+
+	oe.app.Logger.Printf("⏪ Rolling back optimization: %s", plan.OptimizedUnitSlug)
+
+	unit, err := oe.findUnitBySlug(plan.OptimizedUnitSlug)
+	if err != nil {
+		return err
+	}
+
+	// Restore container resources in a single update
+	restored := false
+	manifests, err := unit.Manifests()
+	if err != nil {
+		return fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	for _, step := range plan.Steps {
+		if step.Resources == nil {
+			continue
+		}
+		var manifest map[string]interface{}
+		if step.Workload != "" {
+			manifest = findWorkloadManifest(manifests, step.Workload)
+		} else if len(manifests) > 0 {
+			manifest = manifests[primaryManifest(manifests)]
+		}
+		if manifest == nil {
+			oe.app.Logger.Printf("⚠️  No %s in %s, skipping: %s", step.Workload, unit.Slug, step.Description)
+			continue
+		}
+		restoreContainerResources(manifest, step.Resources)
+		restored = true
+	}
+	if restored {
+		if err := unit.SetManifests(manifests); err != nil {
+			return fmt.Errorf("failed to marshal manifest: %v", err)
+		}
+		_, err = oe.app.Cub.UpdateUnit(oe.spaceID, unit.UnitID, CreateUnitRequest{
+			Slug:           unit.Slug,
+			DisplayName:    unit.DisplayName,
+			Data:           unit.Data,
+			Labels:         unit.Labels,
+			Annotations:    unit.Annotations,
+			UpstreamUnitID: unit.UpstreamUnitID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore resources: %v", err)
+		}
+		oe.app.Logger.Printf("✅ Restored original resources on %s", unit.Slug)
+	}
+
+	for _, step := range plan.Steps {
+		switch {
+		case step.Function != nil:
+			if _, err := oe.app.Cub.ExecuteFunction(oe.spaceID, *step.Function); err != nil {
+				return fmt.Errorf("rollback step %q failed: %v", step.Description, err)
+			}
+			oe.app.Logger.Printf("✅ %s", step.Description)
+		case step.UnitSlug != "":
+			sibling, err := oe.findUnitBySlug(step.UnitSlug)
+			if err != nil {
+				oe.app.Logger.Printf("⚠️  %v", err)
+				continue
+			}
+			if err := oe.app.Cub.DestroyUnit(oe.spaceID, sibling.UnitID); err != nil {
+				return fmt.Errorf("failed to destroy %s: %v", step.UnitSlug, err)
+			}
+			oe.app.Logger.Printf("✅ %s", step.Description)
+		}
+	}
+
+	return nil
+}
+
+// findUnitBySlug looks up a unit in the engine's space
+func (oe *OptimizationEngine) findUnitBySlug(slug string) (*Unit, error) {
+	units, err := oe.app.Cub.ListUnits(ListUnitsParams{
+		SpaceID: oe.spaceID,
+		Where:   NewWhere().Eq("Slug", slug).MustBuild(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unit %s: %v", slug, err)
+	}
+	if len(units) == 0 {
+		return nil, fmt.Errorf("unit %s not found", slug)
+	}
+	return units[0], nil
+}
+
+// findWorkloadManifest returns the document named by a Kind/name workload
+func findWorkloadManifest(manifests []map[string]interface{}, workload string) map[string]interface{} {
+	for _, manifest := range manifests {
+		kind, _ := manifest["kind"].(string)
+		metadata, _ := manifest["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		if fmt.Sprintf("%s/%s", kind, name) == workload {
+			return manifest
+		}
+	}
+	return nil
+}
+
+// restoreContainerResources puts the original resources blocks back on matching containers
+func restoreContainerResources(manifest map[string]interface{}, original map[string]map[string]interface{}) {
+	spec, _ := manifest["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+
+	for _, container := range containers {
+		c, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := c["name"].(string)
+		resources, ok := original[name]
+		if !ok {
+			continue
+		}
+		if resources == nil {
+			delete(c, "resources")
+		} else {
+			c["resources"] = copyManifest(resources)
+		}
+	}
+}
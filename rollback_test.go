@@ -0,0 +1,125 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test reverting an optimization in ConfigHub
+func TestApplyRollback(t *testing.T) {
+	units, waste := newBenchUnits(1)
+	engine := newBenchEngine(1)
+	safety := *DefaultSafetyConfiguration
+	safety.GeneratePDB = true
+	engine.SetSafetyConfiguration(&safety)
+	config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+	require.NoError(t, err)
+	require.Len(t, config.AdditionalUnits, 1)
+	pdb := config.AdditionalUnits[0]
+
+	// rollbackServer fakes ConfigHub holding the optimized unit and its PDB;
+	// failPath makes requests whose path ends with it fail
+	type recorded struct {
+		updates   []CreateUnitRequest
+		functions []FunctionInvocationRequest
+		destroyed []string
+	}
+	rollbackServer := func(t *testing.T, stored []*Unit, failPath string) (*OptimizationEngine, *recorded) {
+		rec := &recorded{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failPath != "" && strings.HasSuffix(r.URL.Path, failPath) {
+				http.Error(w, "rejected", http.StatusBadRequest)
+				return
+			}
+			switch {
+			case r.Method == http.MethodGet:
+				slug := strings.TrimSuffix(strings.TrimPrefix(r.URL.Query().Get("where"), "Slug = '"), "'")
+				var list []map[string]*Unit
+				for _, unit := range stored {
+					if unit.Slug == slug {
+						list = append(list, map[string]*Unit{"Unit": unit})
+					}
+				}
+				json.NewEncoder(w).Encode(list)
+			case r.Method == http.MethodPut:
+				var req CreateUnitRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				rec.updates = append(rec.updates, req)
+				json.NewEncoder(w).Encode(Unit{Slug: req.Slug})
+			case strings.HasSuffix(r.URL.Path, "/destroy"):
+				parts := strings.Split(r.URL.Path, "/")
+				rec.destroyed = append(rec.destroyed, parts[len(parts)-2])
+			case r.Method == http.MethodPost:
+				var req FunctionInvocationRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				rec.functions = append(rec.functions, req)
+				w.Write([]byte(`{}`))
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		engine := newBenchEngine(1)
+		engine.app.Cub = NewConfigHubClient(server.URL, "test-token")
+		return engine, rec
+	}
+
+	t.Run("Applied", func(t *testing.T) {
+		engine, rec := rollbackServer(t, []*Unit{config.OptimizedUnit, pdb}, "")
+		require.NoError(t, engine.ApplyRollback(config))
+
+		require.Len(t, rec.updates, 1, "resources are restored in a single update")
+		assert.Equal(t, config.OptimizedUnit.Slug, rec.updates[0].Slug)
+		assert.Equal(t, config.OptimizedUnit.Labels, rec.updates[0].Labels)
+		restored := &Unit{Data: rec.updates[0].Data}
+		manifest, err := restored.Manifest()
+		require.NoError(t, err)
+		original, err := units[0].Manifest()
+		require.NoError(t, err)
+		assert.Equal(t, containerResourcesByName(original), containerResourcesByName(manifest))
+
+		require.Len(t, rec.functions, 1)
+		assert.Equal(t, "set-replicas", rec.functions[0].FunctionName)
+		assert.EqualValues(t, 4, rec.functions[0].Arguments[0].Value)
+		assert.Equal(t, []string{pdb.UnitID.String()}, rec.destroyed)
+	})
+
+	t.Run("PlanFromAnnotation", func(t *testing.T) {
+		engine, rec := rollbackServer(t, []*Unit{config.OptimizedUnit, pdb}, "")
+		require.NoError(t, engine.ApplyRollback(&OptimizedConfiguration{OptimizedUnit: config.OptimizedUnit}))
+		assert.Len(t, rec.updates, 1)
+		assert.Len(t, rec.functions, 1)
+		assert.Len(t, rec.destroyed, 1)
+	})
+
+	t.Run("MissingSiblingSkipped", func(t *testing.T) {
+		engine, rec := rollbackServer(t, []*Unit{config.OptimizedUnit}, "")
+		require.NoError(t, engine.ApplyRollback(config))
+		assert.Len(t, rec.functions, 1)
+		assert.Empty(t, rec.destroyed)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		engine, _ := rollbackServer(t, []*Unit{config.OptimizedUnit, pdb}, "")
+		assert.ErrorContains(t, engine.ApplyRollback(&OptimizedConfiguration{}), "no rollback plan available")
+		assert.ErrorContains(t, engine.ApplyRollback(&OptimizedConfiguration{OptimizedUnit: units[0]}), "has no rollback plan")
+
+		engine, _ = rollbackServer(t, nil, "")
+		assert.ErrorContains(t, engine.ApplyRollback(config), "unit svc-000-optimized not found")
+
+		engine, _ = rollbackServer(t, []*Unit{config.OptimizedUnit, pdb}, config.OptimizedUnit.UnitID.String())
+		assert.ErrorContains(t, engine.ApplyRollback(config), "failed to restore resources")
+
+		engine, rec := rollbackServer(t, []*Unit{config.OptimizedUnit, pdb}, "/function/invoke")
+		assert.ErrorContains(t, engine.ApplyRollback(config), `rollback step "Restore replicas to 4" failed`)
+		assert.Empty(t, rec.destroyed, "later steps are not run")
+
+		engine, _ = rollbackServer(t, []*Unit{config.OptimizedUnit, pdb}, pdb.UnitID.String()+"/destroy")
+		assert.ErrorContains(t, engine.ApplyRollback(config), "failed to destroy svc-000-pdb")
+	})
+}
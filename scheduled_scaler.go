@@ -0,0 +1,426 @@
+// scheduled_scaler.go - Scheduled scale-to-zero for the DevOps SDK
+//
+// Dev and staging workloads are often idle nights and weekends. Shrinking
+// their requests saves a little around the clock; scaling them to zero
+// outside business hours saves their whole compute cost for those hours.
+// This module generates the ConfigHub unit that does so.
+//
+// Features:
+//   - Business-hour windows per weekday, in any IANA timezone
+//   - Warm-up so replicas are back before the window opens
+//   - CronJobs running kubectl scale (with a ServiceAccount and Role), or a
+//     KEDA ScaledObject with cron triggers
+//   - Savings estimated as idle hours × the workload's hourly compute cost
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultScalerImage runs kubectl in the scale-up and scale-down CronJobs. It
+// is the upstream Kubernetes image, pinned to a release still in support; set
+// ScaleSchedule.Image to match your cluster or use a mirrored registry.
+const DefaultScalerImage = "registry.k8s.io/kubectl:v1.34.0"
+
+// DefaultScaleWarmup is how long before a window opens replicas are scaled up
+const DefaultScaleWarmup = 15 * time.Minute
+
+// Annotations recording a scheduled scaler's estimate on its unit
+const (
+	ScheduledScalerSavingsAnnotation  = "optimizer.io/scheduled-scaler-savings" // USD per month
+	ScheduledScalerScheduleAnnotation = "optimizer.io/scheduled-scaler-schedule"
+)
+
+const minutesPerWeek = 7 * 24 * 60
+
+// ScaleWindow is a business-hour window the workload runs in
+type ScaleWindow struct {
+	Days  []time.Weekday // Default Monday to Friday
+	Start int            // Hour the window opens, 0-23
+	End   int            // Hour the window closes, 1-24
+}
+
+// ScaleSchedule describes when GenerateScheduledScaler keeps a workload up.
+// Outside its windows the workload is scaled to zero.
+type ScaleSchedule struct {
+	Timezone string        // IANA name, e.g. "Europe/Berlin"; default UTC
+	Windows  []ScaleWindow // Default weekdays during business hours (09:00-18:00)
+	Warmup   time.Duration // Scale up this long before a window opens; default DefaultScaleWarmup, negative for none
+	Replicas int32         // Replicas inside a window; default the workload's current count
+	UseKEDA  bool          // Emit a KEDA ScaledObject instead of CronJobs
+	Image    string        // kubectl image for the CronJobs; default DefaultScalerImage
+}
+
+// withDefaults fills in unset fields and validates the schedule
+func (s ScaleSchedule) withDefaults() (ScaleSchedule, error) {
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return s, fmt.Errorf("invalid timezone %q: %v", s.Timezone, err)
+	}
+	if len(s.Windows) == 0 {
+		s.Windows = []ScaleWindow{{Start: businessHoursStart, End: businessHoursEnd}}
+	}
+	windows := make([]ScaleWindow, len(s.Windows))
+	for i, window := range s.Windows {
+		if window.Start < 0 || window.Start > 23 || window.End < 1 || window.End > 24 || window.Start >= window.End {
+			return s, fmt.Errorf("invalid window %02d:00-%02d:00: hours must satisfy 0 <= start < end <= 24", window.Start, window.End)
+		}
+		if len(window.Days) == 0 {
+			window.Days = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		}
+		windows[i] = window
+	}
+	s.Windows = windows
+	if s.Warmup == 0 {
+		s.Warmup = DefaultScaleWarmup
+	} else if s.Warmup < 0 {
+		s.Warmup = 0
+	}
+	if s.Warmup >= 24*time.Hour {
+		return s, fmt.Errorf("warmup %s must be under 24h", s.Warmup)
+	}
+	if s.Replicas < 0 {
+		return s, fmt.Errorf("invalid replica count %d", s.Replicas)
+	}
+	if s.Image == "" {
+		s.Image = DefaultScalerImage
+	}
+	return s, nil
+}
+
+// upFraction returns the share of the week the workload is scaled up,
+// including warm-up. Overlapping windows are counted once.
+func (s ScaleSchedule) upFraction() float64 {
+	var up [minutesPerWeek]bool
+	warmup := int(s.Warmup / time.Minute)
+	for _, window := range s.Windows {
+		for _, day := range window.Days {
+			start := int(day)*24*60 + window.Start*60 - warmup
+			end := int(day)*24*60 + window.End*60
+			for minute := start; minute < end; minute++ {
+				up[(minute+minutesPerWeek)%minutesPerWeek] = true
+			}
+		}
+	}
+	var count int
+	for _, isUp := range up {
+		if isUp {
+			count++
+		}
+	}
+	return float64(count) / minutesPerWeek
+}
+
+// String describes the schedule, e.g. "Mon-Fri 09:00-18:00 Europe/Berlin"
+func (s ScaleSchedule) String() string {
+	windows := make([]string, 0, len(s.Windows))
+	for _, window := range s.Windows {
+		windows = append(windows, fmt.Sprintf("%s %02d:00-%02d:00", formatWeekdays(window.Days), window.Start, window.End))
+	}
+	return strings.Join(windows, ", ") + " " + s.Timezone
+}
+
+// windowCrons returns the cron expressions scaling a window up (warm-up
+// included) and down. Times that cross midnight move to the adjacent day.
+func (s ScaleSchedule) windowCrons(window ScaleWindow) (string, string) {
+	up := window.Start*60 - int(s.Warmup/time.Minute)
+	upShift := 0
+	if up < 0 {
+		up += 24 * 60
+		upShift = -1
+	}
+	down := window.End * 60
+	downShift := 0
+	if down == 24*60 {
+		down = 0
+		downShift = 1
+	}
+	return fmt.Sprintf("%d %d * * %s", up%60, up/60, cronWeekdays(window.Days, upShift)),
+		fmt.Sprintf("%d %d * * %s", down%60, down/60, cronWeekdays(window.Days, downShift))
+}
+
+// cronWeekdays formats days, moved by shift days, as a cron day-of-week field
+func cronWeekdays(days []time.Weekday, shift int) string {
+	seen := make(map[int]bool)
+	var numbers []int
+	for _, day := range days {
+		n := (int(day) + shift + 7) % 7
+		if !seen[n] {
+			seen[n] = true
+			numbers = append(numbers, n)
+		}
+	}
+	if len(numbers) == 7 {
+		return "*"
+	}
+	sort.Ints(numbers)
+	fields := make([]string, len(numbers))
+	for i, n := range numbers {
+		fields[i] = strconv.Itoa(n)
+	}
+	return strings.Join(fields, ",")
+}
+
+// formatWeekdays formats days as e.g. "Mon-Fri" or "Sat,Sun"
+func formatWeekdays(days []time.Weekday) string {
+	sorted := append([]time.Weekday(nil), days...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	contiguous := len(sorted) > 2
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] != sorted[i-1]+1 {
+			contiguous = false
+		}
+	}
+	if contiguous {
+		return sorted[0].String()[:3] + "-" + sorted[len(sorted)-1].String()[:3]
+	}
+	names := make([]string, len(sorted))
+	for i, day := range sorted {
+		names[i] = day.String()[:3]
+	}
+	return strings.Join(names, ",")
+}
+
+// GenerateScheduledScaler builds a unit that scales the unit's Deployment or
+// StatefulSet to zero outside the schedule's windows and back up before they
+// open: CronJobs running kubectl scale, with the ServiceAccount and Role they
+// need, or with UseKEDA a ScaledObject with a cron trigger per window. The
+// objects go in the workload's namespace, "default" if it has none.
+//
+// Estimated savings (idle hours × the workload's hourly compute cost; storage
+// is kept while scaled down) are recorded in the unit's annotations. Append
+// the unit to an OptimizedConfiguration's AdditionalUnits to create it with
+// CreateOptimizedUnitInConfigHub and list it in GenerateOptimizationReport.
+// Units labeled environment=prod are refused unless SetOverrideProdGuard is set.
+func (oe *OptimizationEngine) GenerateScheduledScaler(unit *Unit, schedule ScaleSchedule) (*Unit, error) {
+	if isProdEnvironment(unit.Labels[EnvironmentLabel]) && !oe.overrideGuard {
+		return nil, fmt.Errorf("refusing to schedule %s to scale to zero in %s; use SetOverrideProdGuard to proceed",
+			unit.Slug, unit.Labels[EnvironmentLabel])
+	}
+
+	schedule, err := schedule.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := unit.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	apiVersion, _ := manifest["apiVersion"].(string)
+	kind, _ := manifest["kind"].(string)
+	if kind != "Deployment" && kind != "StatefulSet" {
+		return nil, fmt.Errorf("cannot schedule scaling of %s: %q has no replicas to scale", unit.Slug, kind)
+	}
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		name = unit.Slug
+	}
+	namespace, _ := metadata["namespace"].(string)
+	if namespace == "" {
+		namespace = "default" // RoleBinding subjects need one
+	}
+
+	if schedule.Replicas == 0 {
+		schedule.Replicas = oe.extractResourceSpecs(manifest).Replicas
+		if schedule.Replicas < 1 {
+			schedule.Replicas = 1
+		}
+	}
+
+	var manifests []map[string]interface{}
+	if schedule.UseKEDA {
+		manifests = scaledObjectManifests(schedule, apiVersion, kind, name, namespace)
+	} else {
+		manifests = scaleCronJobManifests(schedule, kind, name, namespace)
+	}
+
+	// Savings are the compute cost of the hours spent at zero replicas
+	idleFraction := 1 - schedule.upFraction()
+	var monthlySavings float64
+	if estimate, err := oe.costAnalyzer.AnalyzeUnit(*unit); err == nil && estimate != nil {
+		hoursPerMonth := oe.costAnalyzer.pricingFor(estimate).hoursPerMonth()
+		hourlyCost := (estimate.Breakdown.CPUCost + estimate.Breakdown.MemoryCost) / hoursPerMonth
+		monthlySavings = idleFraction * hoursPerMonth * hourlyCost
+	}
+
+	opt := ResourceOptimization{
+		Type:             "schedule",
+		OriginalValue:    "always-on",
+		OptimizedValue:   schedule.String(),
+		ReductionPercent: idleFraction * 100,
+		Reasoning:        fmt.Sprintf("Scaled to zero %.0f hours a week outside business hours", idleFraction*168),
+		Risk:             "LOW", // Capacity is unchanged inside the windows
+	}
+	annotations := oe.createOptimizedAnnotations(unit.Annotations, []ResourceOptimization{opt})
+	annotations[ScheduledScalerSavingsAnnotation] = fmt.Sprintf("%.2f", monthlySavings)
+	annotations[ScheduledScalerScheduleAnnotation] = schedule.String()
+
+	scalerUnit := &Unit{
+		UnitID:         uuid.New(),
+		SpaceID:        unit.SpaceID,
+		Slug:           unit.Slug + "-scheduled-scaler",
+		DisplayName:    unit.DisplayName + " (Scheduled Scaler)",
+		Labels:         oe.createOptimizedLabels(unit.Labels),
+		Annotations:    annotations,
+		UpstreamUnitID: &unit.UnitID,
+	}
+	if err := scalerUnit.setManifests(manifests, isBase64UnitData(unit.Data)); err != nil {
+		return nil, fmt.Errorf("failed to marshal scheduled scaler manifests: %v", err)
+	}
+
+	oe.app.Logger.Printf("🕘 Scheduled scaler for %s: %s (savings: $%.2f/month)", unit.Slug, schedule, monthlySavings)
+	return scalerUnit, nil
+}
+
+// scaledObjectManifests builds a KEDA ScaledObject holding the workload at
+// zero replicas outside the schedule's windows
+func scaledObjectManifests(schedule ScaleSchedule, apiVersion, kind, name, namespace string) []map[string]interface{} {
+	triggers := make([]interface{}, 0, len(schedule.Windows))
+	for _, window := range schedule.Windows {
+		start, end := schedule.windowCrons(window)
+		triggers = append(triggers, map[string]interface{}{
+			"type": "cron",
+			"metadata": map[string]interface{}{
+				"timezone":        schedule.Timezone,
+				"start":           start,
+				"end":             end,
+				"desiredReplicas": strconv.Itoa(int(schedule.Replicas)),
+			},
+		})
+	}
+
+	return []map[string]interface{}{{
+		"apiVersion": "keda.sh/v1alpha1",
+		"kind":       "ScaledObject",
+		"metadata":   scalerMetadata(name+"-schedule", namespace),
+		"spec": map[string]interface{}{
+			"scaleTargetRef": map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"name":       name,
+			},
+			"minReplicaCount": 0,
+			"maxReplicaCount": int(schedule.Replicas),
+			"triggers":        triggers,
+		},
+	}}
+}
+
+// scaleCronJobManifests builds CronJobs scaling the workload up and down for
+// each window, and the ServiceAccount, Role and RoleBinding they run as
+func scaleCronJobManifests(schedule ScaleSchedule, kind, name, namespace string) []map[string]interface{} {
+	serviceAccount := name + "-scheduled-scaler"
+	resource := strings.ToLower(kind) + "s"
+
+	manifests := []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+			"metadata":   scalerMetadata(serviceAccount, namespace),
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "Role",
+			"metadata":   scalerMetadata(serviceAccount, namespace),
+			"rules": []interface{}{
+				map[string]interface{}{
+					"apiGroups":     []interface{}{"apps"},
+					"resources":     []interface{}{resource, resource + "/scale"},
+					"resourceNames": []interface{}{name},
+					"verbs":         []interface{}{"get", "patch", "update"},
+				},
+			},
+		},
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "RoleBinding",
+			"metadata":   scalerMetadata(serviceAccount, namespace),
+			"roleRef": map[string]interface{}{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "Role",
+				"name":     serviceAccount,
+			},
+			"subjects": []interface{}{
+				map[string]interface{}{
+					"kind":      "ServiceAccount",
+					"name":      serviceAccount,
+					"namespace": namespace,
+				},
+			},
+		},
+	}
+
+	for i, window := range schedule.Windows {
+		suffix := ""
+		if len(schedule.Windows) > 1 {
+			suffix = fmt.Sprintf("-%d", i+1)
+		}
+		up, down := schedule.windowCrons(window)
+		manifests = append(manifests,
+			scaleCronJob(schedule, fmt.Sprintf("%s-scale-up%s", name, suffix), up, serviceAccount, resource, name, namespace, schedule.Replicas),
+			scaleCronJob(schedule, fmt.Sprintf("%s-scale-down%s", name, suffix), down, serviceAccount, resource, name, namespace, 0),
+		)
+	}
+	return manifests
+}
+
+// scaleCronJob builds a CronJob running kubectl scale on cron
+func scaleCronJob(schedule ScaleSchedule, jobName, cron, serviceAccount, resource, name, namespace string, replicas int32) map[string]interface{} {
+	command := []interface{}{"kubectl", "scale", resource + "/" + name, fmt.Sprintf("--replicas=%d", replicas), "--namespace=" + namespace}
+
+	return map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata":   scalerMetadata(jobName, namespace),
+		"spec": map[string]interface{}{
+			"schedule":                   cron,
+			"timeZone":                   schedule.Timezone,
+			"concurrencyPolicy":          "Forbid",
+			"successfulJobsHistoryLimit": 1,
+			"failedJobsHistoryLimit":     3,
+			"jobTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"backoffLimit": 3,
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"serviceAccountName": serviceAccount,
+							"restartPolicy":      "OnFailure",
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":    "kubectl",
+									"image":   schedule.Image,
+									"command": command,
+									"resources": map[string]interface{}{
+										"requests": map[string]interface{}{"cpu": "10m", "memory": "32Mi"},
+										"limits":   map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// scalerMetadata returns metadata for a generated scaler object
+func scalerMetadata(name, namespace string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+		"labels": map[string]interface{}{
+			"optimizer.io/engine": "devops-sdk",
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test scale-to-zero outside business hours
+func TestGenerateScheduledScaler(t *testing.T) {
+	units, _ := newBenchUnits(1)
+	unit := units[0]
+	unit.Labels = map[string]string{"environment": "dev"}
+
+	t.Run("CronJobs", func(t *testing.T) {
+		engine := newBenchEngine(1)
+		scaler, err := engine.GenerateScheduledScaler(unit, ScaleSchedule{Timezone: "Europe/Berlin"})
+		require.NoError(t, err)
+		assert.Equal(t, "svc-000-scheduled-scaler", scaler.Slug)
+		assert.Equal(t, &unit.UnitID, scaler.UpstreamUnitID)
+		require.NoError(t, validateOptimizedManifests(scaler))
+
+		manifests, err := scaler.Manifests()
+		require.NoError(t, err)
+		kinds := make([]string, len(manifests))
+		for i, manifest := range manifests {
+			kinds[i] = manifest["kind"].(string)
+		}
+		assert.Equal(t, []string{"ServiceAccount", "Role", "RoleBinding", "CronJob", "CronJob"}, kinds)
+
+		up := manifests[3]["spec"].(map[string]interface{})
+		assert.Equal(t, "45 8 * * 1,2,3,4,5", up["schedule"])
+		assert.Equal(t, "Europe/Berlin", up["timeZone"])
+		podSpec := up["jobTemplate"].(map[string]interface{})["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+		assert.Equal(t, "svc-000-scheduled-scaler", podSpec["serviceAccountName"])
+		command := podSpec["containers"].([]interface{})[0].(map[string]interface{})["command"]
+		assert.Equal(t, []interface{}{"kubectl", "scale", "deployments/svc-000", "--replicas=4", "--namespace=default"}, command)
+		image := podSpec["containers"].([]interface{})[0].(map[string]interface{})["image"]
+		assert.Equal(t, "registry.k8s.io/kubectl:v1.34.0", image)
+		assert.Equal(t, DefaultScalerImage, image)
+
+		mirrored, err := engine.GenerateScheduledScaler(unit, ScaleSchedule{Image: "mirror.example.com/kubectl:v1.33.4"})
+		require.NoError(t, err)
+		mirroredManifests, err := mirrored.Manifests()
+		require.NoError(t, err)
+		for _, cronJob := range mirroredManifests[3:] {
+			podSpec := cronJob["spec"].(map[string]interface{})["jobTemplate"].(map[string]interface{})["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+			assert.Equal(t, "mirror.example.com/kubectl:v1.33.4", podSpec["containers"].([]interface{})[0].(map[string]interface{})["image"])
+		}
+
+		down := manifests[4]["spec"].(map[string]interface{})
+		assert.Equal(t, "0 18 * * 1,2,3,4,5", down["schedule"])
+
+		// Up 5 × 9h15m of the 168h week; savings cover compute only
+		idle := 1 - 5*9.25/168
+		estimate, err := engine.costAnalyzer.AnalyzeUnit(*unit)
+		require.NoError(t, err)
+		savings, err := strconv.ParseFloat(scaler.Annotations[ScheduledScalerSavingsAnnotation], 64)
+		require.NoError(t, err)
+		assert.InDelta(t, idle*(estimate.Breakdown.CPUCost+estimate.Breakdown.MemoryCost), savings, 0.01)
+		assert.Equal(t, "Mon-Fri 09:00-18:00 Europe/Berlin", scaler.Annotations[ScheduledScalerScheduleAnnotation])
+
+		units, waste := newBenchUnits(1)
+		config, err := engine.GenerateOptimizedUnit(units[0], waste[units[0].Slug])
+		require.NoError(t, err)
+		config.AdditionalUnits = append(config.AdditionalUnits, scaler)
+		report := engine.GenerateOptimizationReport([]*OptimizedConfiguration{config})
+		assert.Contains(t, report, "Scheduled Scaling")
+		assert.Contains(t, report, "Mon-Fri 09:00-18:00 Europe/Berlin $"+scaler.Annotations[ScheduledScalerSavingsAnnotation])
+	})
+
+	t.Run("KEDA", func(t *testing.T) {
+		scaler, err := newBenchEngine(1).GenerateScheduledScaler(unit, ScaleSchedule{
+			Windows: []ScaleWindow{
+				{Start: 0, End: 24, Days: []time.Weekday{time.Saturday}},
+				{Start: 7, End: 19},
+			},
+			Warmup:   30 * time.Minute,
+			Replicas: 2,
+			UseKEDA:  true,
+		})
+		require.NoError(t, err)
+		manifests, err := scaler.Manifests()
+		require.NoError(t, err)
+		require.Len(t, manifests, 1)
+		assert.Equal(t, "ScaledObject", manifests[0]["kind"])
+
+		spec := manifests[0]["spec"].(map[string]interface{})
+		assert.Equal(t, 0, spec["minReplicaCount"])
+		assert.Equal(t, 2, spec["maxReplicaCount"])
+		triggers := spec["triggers"].([]interface{})
+		require.Len(t, triggers, 2)
+		saturday := triggers[0].(map[string]interface{})["metadata"].(map[string]interface{})
+		assert.Equal(t, "30 23 * * 5", saturday["start"], "warm-up before midnight moves to Friday")
+		assert.Equal(t, "0 0 * * 0", saturday["end"], "midnight close moves to Sunday")
+		assert.Equal(t, "UTC", saturday["timezone"])
+		assert.Equal(t, "2", saturday["desiredReplicas"])
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		engine := newBenchEngine(1)
+		_, err := engine.GenerateScheduledScaler(unit, ScaleSchedule{Windows: []ScaleWindow{{Start: 18, End: 9}}})
+		assert.ErrorContains(t, err, "invalid window")
+		_, err = engine.GenerateScheduledScaler(unit, ScaleSchedule{Timezone: "Mars/Olympus"})
+		assert.ErrorContains(t, err, "invalid timezone")
+
+		prod := *unit
+		prod.Labels = map[string]string{"environment": "prod"}
+		_, err = engine.GenerateScheduledScaler(&prod, ScaleSchedule{})
+		assert.ErrorContains(t, err, "SetOverrideProdGuard")
+		engine.SetOverrideProdGuard(true)
+		_, err = engine.GenerateScheduledScaler(&prod, ScaleSchedule{})
+		assert.NoError(t, err)
+
+		job := *unit
+		job.Data = strings.Replace(job.Data, "kind: Deployment", "kind: DaemonSet", 1)
+		_, err = engine.GenerateScheduledScaler(&job, ScaleSchedule{})
+		assert.ErrorContains(t, err, "no replicas to scale")
+	})
+}
@@ -1,23 +1,35 @@
 package sdk
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
+	"golang.org/x/text/width"
 )
 
 // TableWriter provides ASCII table formatting for CLI output
 type TableWriter struct {
-	headers       []string
-	rows          [][]string
-	columnWidths  []int
-	borderStyle   BorderStyle
-	alignments    []Alignment
-	showBorder    bool
-	showHeader    bool
-	compactMode   bool
+	headers      []string
+	rows         [][]string
+	columnWidths []int
+	borderStyle  BorderStyle
+	alignments   []Alignment
+	showBorder   bool
+	showHeader   bool
+	compactMode  bool
+	footer       []string
+	sortColumn   int // -1 when unsorted
+	sortNumeric  bool
+	sortDesc     bool
+	maxWidths    map[int]int  // Column index -> max content width
+	wrapColumns  map[int]bool // Column index -> wrap instead of truncate
 }
 
 // BorderStyle defines the table border characters
@@ -85,6 +97,9 @@ func NewTable(headers ...string) *TableWriter {
 		showBorder:  true,
 		showHeader:  true,
 		compactMode: false,
+		sortColumn:  -1,
+		maxWidths:   make(map[int]int),
+		wrapColumns: make(map[int]bool),
 	}
 }
 
@@ -102,6 +117,110 @@ func (t *TableWriter) AddRow(cells ...string) {
 	t.rows = append(t.rows, cells)
 }
 
+// AddFooter sets a footer row (e.g. totals), rendered below the data rows
+// and separated from them by a border line when borders are on
+func (t *TableWriter) AddFooter(cells ...string) {
+	t.footer = cells
+}
+
+// SortBy sorts data rows by a column when rendering. Numeric sorting understands
+// values like "$103.68", "45%", "500m" and "2Gi", falling back to string
+// comparison for cells that don't parse.
+func (t *TableWriter) SortBy(col int, numeric bool, descending bool) {
+	t.sortColumn = col
+	t.sortNumeric = numeric
+	t.sortDesc = descending
+}
+
+// sortRows applies the SortBy settings to the data rows
+func (t *TableWriter) sortRows() {
+	if t.sortColumn < 0 {
+		return
+	}
+
+	col := t.sortColumn
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		a, b := "", ""
+		if col < len(t.rows[i]) {
+			a = t.rows[i][col]
+		}
+		if col < len(t.rows[j]) {
+			b = t.rows[j][col]
+		}
+
+		if t.sortNumeric {
+			av, aok := parseSortValue(a)
+			bv, bok := parseSortValue(b)
+			if aok && bok && av != bv {
+				if t.sortDesc {
+					return av > bv
+				}
+				return av < bv
+			}
+			if aok && bok {
+				return false
+			}
+		}
+
+		if t.sortDesc {
+			return a > b
+		}
+		return a < b
+	})
+}
+
+// parseSortValue parses a cell as a number, accepting currency, percentages,
+// and Kubernetes quantities (CPU in cores, memory/storage in bytes)
+func parseSortValue(cell string) (float64, bool) {
+	s := strings.TrimSpace(cell)
+	s = strings.TrimLeftFunc(s, func(r rune) bool { return unicode.Is(unicode.Sc, r) }) // $, €, £, ...
+	s = strings.TrimSuffix(s, "%")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, false
+	}
+
+	if val, err := strconv.ParseFloat(s, 64); err == nil {
+		return val, true
+	}
+
+	// Only treat it as a quantity if the numeric part is valid
+	number := strings.TrimRight(s, "mKMGTPEi")
+	if _, err := strconv.ParseFloat(number, 64); err != nil || number == s {
+		return 0, false
+	}
+
+	quantity := ParseQuantity(s)
+	if quantity.BytesValue() != 0 {
+		return float64(quantity.BytesValue()), true
+	}
+	return float64(quantity.MilliValue()) / 1000.0, true
+}
+
+// SetMaxWidth limits a column's content width; longer cells are truncated
+// with "..." unless wrapping is enabled for the column
+func (t *TableWriter) SetMaxWidth(col, width int) {
+	t.maxWidths[col] = width
+}
+
+// SetWrap makes cells in a column that exceed its max width wrap onto
+// multiple lines within the same row instead of being truncated
+func (t *TableWriter) SetWrap(col int, wrap bool) {
+	t.wrapColumns[col] = wrap
+}
+
+// cellLines splits a cell into the physical lines it renders as
+func (t *TableWriter) cellLines(col int, cell string) []string {
+	maxWidth, ok := t.maxWidths[col]
+	if !ok || maxWidth <= 0 || displayWidth(cell) <= maxWidth {
+		return []string{cell}
+	}
+	if t.wrapColumns[col] {
+		return wrapText(cell, maxWidth)
+	}
+	return []string{truncate(cell, maxWidth)}
+}
+
 // SetAlignment sets column alignment (applies to all columns if indices not specified)
 func (t *TableWriter) SetAlignment(align Alignment, columnIndices ...int) {
 	if len(columnIndices) == 0 {
@@ -128,6 +247,7 @@ func (t *TableWriter) Render() string {
 		return ""
 	}
 
+	t.sortRows()
 	t.calculateColumnWidths()
 
 	var output strings.Builder
@@ -140,7 +260,7 @@ func (t *TableWriter) Render() string {
 
 	// Header
 	if t.showHeader {
-		output.WriteString(t.renderRow(t.headers, true))
+		output.WriteString(t.renderLogicalRow(t.headers, true))
 		output.WriteString("\n")
 
 		if t.showBorder {
@@ -151,8 +271,20 @@ func (t *TableWriter) Render() string {
 
 	// Data rows
 	for i, row := range t.rows {
-		output.WriteString(t.renderRow(row, false))
-		if i < len(t.rows)-1 || t.showBorder {
+		output.WriteString(t.renderLogicalRow(row, false))
+		if i < len(t.rows)-1 || t.showBorder || t.footer != nil {
+			output.WriteString("\n")
+		}
+	}
+
+	// Footer
+	if t.footer != nil {
+		if t.showBorder {
+			output.WriteString(t.renderMiddleBorder())
+			output.WriteString("\n")
+		}
+		output.WriteString(t.renderLogicalRow(t.footer, false))
+		if t.showBorder {
 			output.WriteString("\n")
 		}
 	}
@@ -176,18 +308,32 @@ func (t *TableWriter) calculateColumnWidths() {
 
 	// Check headers
 	for i, header := range t.headers {
-		t.columnWidths[i] = len(header)
+		t.columnWidths[i] = displayWidth(header)
 	}
 
 	// Check all rows
 	for _, row := range t.rows {
 		for i, cell := range row {
-			if i < len(t.columnWidths) && len(cell) > t.columnWidths[i] {
-				t.columnWidths[i] = len(cell)
+			if i < len(t.columnWidths) && displayWidth(cell) > t.columnWidths[i] {
+				t.columnWidths[i] = displayWidth(cell)
 			}
 		}
 	}
 
+	// Check footer
+	for i, cell := range t.footer {
+		if i < len(t.columnWidths) && displayWidth(cell) > t.columnWidths[i] {
+			t.columnWidths[i] = displayWidth(cell)
+		}
+	}
+
+	// Clamp to configured max widths
+	for i, maxWidth := range t.maxWidths {
+		if i < len(t.columnWidths) && maxWidth > 0 && t.columnWidths[i] > maxWidth {
+			t.columnWidths[i] = maxWidth
+		}
+	}
+
 	// Add padding
 	if !t.compactMode {
 		for i := range t.columnWidths {
@@ -196,6 +342,32 @@ func (t *TableWriter) calculateColumnWidths() {
 	}
 }
 
+// renderLogicalRow renders a row whose wrapped cells may span several lines;
+// shorter cells are top-aligned and padded
+func (t *TableWriter) renderLogicalRow(cells []string, isHeader bool) string {
+	cellLines := make([][]string, len(cells))
+	height := 1
+	for i, cell := range cells {
+		cellLines[i] = t.cellLines(i, cell)
+		if len(cellLines[i]) > height {
+			height = len(cellLines[i])
+		}
+	}
+
+	lines := make([]string, height)
+	for line := 0; line < height; line++ {
+		physical := make([]string, len(cells))
+		for i := range cells {
+			if line < len(cellLines[i]) {
+				physical[i] = cellLines[i][line]
+			}
+		}
+		lines[line] = t.renderRow(physical, isHeader)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // renderRow renders a single row with proper alignment
 func (t *TableWriter) renderRow(cells []string, isHeader bool) string {
 	var row strings.Builder
@@ -210,7 +382,7 @@ func (t *TableWriter) renderRow(cells []string, isHeader bool) string {
 		}
 
 		width := t.columnWidths[i]
-		padding := width - len(cell)
+		padding := width - displayWidth(cell)
 
 		if t.compactMode {
 			row.WriteString(cell)
@@ -292,6 +464,163 @@ func (t *TableWriter) renderBottomBorder() string {
 	return border.String()
 }
 
+// ============================================================================
+// STREAMING TABLES
+// ============================================================================
+
+// StreamingTableWriter writes rows to an io.Writer as they are added, so
+// memory stays bounded for very large result sets. Column widths come from
+// SetFixedWidths or from the first sampleSize rows, which are buffered until
+// the sample is complete.
+//
+// Tradeoff: later cells wider than their column are truncated with "..."
+// (or wrapped, with SetWrap), since widths can't change once rows are written.
+// Use SetFixedWidths when the widest values are known up front. Sorting is not
+// supported.
+type StreamingTableWriter struct {
+	table      *TableWriter // Holds headers, styling and widths; never holds rows
+	out        io.Writer
+	sampleSize int
+	sample     [][]string
+	started    bool
+	fixed      bool
+	err        error
+}
+
+// NewStreamingTable creates a streaming table that sizes columns from the
+// first sampleSize rows
+func NewStreamingTable(out io.Writer, sampleSize int, headers ...string) *StreamingTableWriter {
+	if sampleSize < 1 {
+		sampleSize = 1
+	}
+	return &StreamingTableWriter{
+		table:      NewTable(headers...),
+		out:        out,
+		sampleSize: sampleSize,
+	}
+}
+
+// SetFixedWidths sets each column's content width, skipping sampling entirely
+func (s *StreamingTableWriter) SetFixedWidths(widths []int) {
+	for i, width := range widths {
+		s.table.SetMaxWidth(i, width)
+	}
+	s.fixed = true
+}
+
+// SetAlignment sets column alignment, like TableWriter.SetAlignment
+func (s *StreamingTableWriter) SetAlignment(align Alignment, columnIndices ...int) {
+	s.table.SetAlignment(align, columnIndices...)
+}
+
+// SetBorderStyle changes the border style
+func (s *StreamingTableWriter) SetBorderStyle(style BorderStyle) {
+	s.table.SetBorderStyle(style)
+}
+
+// SetMaxWidth limits a column's content width
+func (s *StreamingTableWriter) SetMaxWidth(col, width int) {
+	s.table.SetMaxWidth(col, width)
+}
+
+// SetWrap wraps cells that exceed their column width instead of truncating
+func (s *StreamingTableWriter) SetWrap(col int, wrap bool) {
+	s.table.SetWrap(col, wrap)
+}
+
+// AddFooter sets a footer row, written by Close
+func (s *StreamingTableWriter) AddFooter(cells ...string) {
+	s.table.AddFooter(cells...)
+}
+
+// AddRow writes a row, or buffers it while column widths are being sampled
+func (s *StreamingTableWriter) AddRow(cells ...string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if !s.started {
+		s.sample = append(s.sample, cells)
+		if !s.fixed && len(s.sample) < s.sampleSize {
+			return nil
+		}
+		s.start()
+		return s.err
+	}
+	s.writeLine(s.table.renderLogicalRow(cells, false))
+	return s.err
+}
+
+// Close flushes any buffered rows and writes the footer and bottom border
+func (s *StreamingTableWriter) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if !s.started {
+		if len(s.sample) == 0 {
+			return nil // Like TableWriter, an empty table renders nothing
+		}
+		s.start()
+	}
+
+	t := s.table
+	if t.footer != nil {
+		if t.showBorder {
+			s.writeLine(t.renderMiddleBorder())
+		}
+		s.writeLine(t.renderLogicalRow(t.footer, false))
+	}
+	if t.showBorder {
+		s.writeLine(t.renderBottomBorder())
+	}
+	return s.err
+}
+
+// start fixes column widths from the sample, then writes the header and the
+// buffered rows
+func (s *StreamingTableWriter) start() {
+	t := s.table
+	s.started = true
+
+	t.rows = s.sample
+	t.calculateColumnWidths()
+	t.rows = nil
+
+	// Pin every column so later, wider cells are truncated or wrapped
+	padding := 2
+	if t.compactMode {
+		padding = 0
+	}
+	for i := range t.columnWidths {
+		if maxWidth, ok := t.maxWidths[i]; ok && s.fixed && maxWidth > 0 {
+			t.columnWidths[i] = maxWidth + padding
+		}
+		t.maxWidths[i] = t.columnWidths[i] - padding
+	}
+
+	if t.showBorder {
+		s.writeLine(t.renderTopBorder())
+	}
+	if t.showHeader {
+		s.writeLine(t.renderLogicalRow(t.headers, true))
+		if t.showBorder {
+			s.writeLine(t.renderMiddleBorder())
+		}
+	}
+
+	for _, row := range s.sample {
+		s.writeLine(t.renderLogicalRow(row, false))
+	}
+	s.sample = nil
+}
+
+// writeLine writes a line, remembering the first write error
+func (s *StreamingTableWriter) writeLine(line string) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = io.WriteString(s.out, line+"\n")
+}
+
 // ============================================================================
 // CONFIGHHUB-SPECIFIC TABLE FUNCTIONS
 // ============================================================================
@@ -395,6 +724,8 @@ func RenderSetsTable(sets []*Set) string {
 // RenderFiltersTable creates a table from ConfigHub filters
 func RenderFiltersTable(filters []*Filter) string {
 	table := NewTable("Filter", "From", "Where Clause", "Created")
+	table.SetMaxWidth(2, 40)
+	table.SetWrap(2, true) // Keep long WHERE clauses readable
 
 	for _, filter := range filters {
 		whereClause := filter.Where
@@ -407,7 +738,7 @@ func RenderFiltersTable(filters []*Filter) string {
 		table.AddRow(
 			filter.Slug,
 			filter.From,
-			truncate(whereClause, 40),
+			whereClause,
 			created,
 		)
 	}
@@ -415,6 +746,106 @@ func RenderFiltersTable(filters []*Filter) string {
 	return table.Render()
 }
 
+// RenderWorkersTable creates a table from ConfigHub workers, marking workers
+// that are disconnected or haven't checked in within maxStale
+// (DefaultWorkerMaxStale if zero)
+func RenderWorkersTable(workers []*Worker, maxStale time.Duration) string {
+	table := NewTable("Worker", "Status", "Condition", "Last Seen", "Health")
+	table.SetAlignment(AlignCenter, 4) // Health centered
+
+	for _, worker := range workers {
+		status := worker.Status
+		if status == "" {
+			status = "-"
+		}
+		condition := worker.Condition
+		if condition == "" {
+			condition = "-"
+		}
+		health := "✓"
+		if !IsWorkerHealthy(worker, maxStale) {
+			switch {
+			case worker.Condition == "Disconnected":
+				health = "✗ disconnected"
+			case strings.EqualFold(worker.Status, "Error"):
+				health = "✗ error"
+			default:
+				health = "✗ stale"
+			}
+		}
+
+		table.AddRow(
+			truncate(worker.Slug, 30),
+			status,
+			condition,
+			formatTimestamp(worker.LastSeenAt),
+			health,
+		)
+	}
+
+	return table.Render()
+}
+
+// RenderApplyResultsTable creates a table from ApplyUnitsInOrder results, in
+// apply order
+func RenderApplyResultsTable(results []UnitApplyResult) string {
+	table := NewTable("#", "Unit", "Status", "Attempts", "Detail")
+	table.SetAlignment(AlignRight, 0)
+	table.SetAlignment(AlignRight, 3)
+
+	for i, result := range results {
+		attempts := "-"
+		if result.Attempts > 0 {
+			attempts = fmt.Sprintf("%d", result.Attempts)
+		}
+		detail := result.Reason
+		if result.Error != nil {
+			detail = result.Error.Error()
+		}
+		if detail == "" {
+			detail = "-"
+		}
+
+		table.AddRow(
+			fmt.Sprintf("%d", i+1),
+			truncate(result.Slug, 30),
+			result.Status,
+			attempts,
+			truncate(detail, 60),
+		)
+	}
+
+	return table.Render()
+}
+
+// RenderLinksTable creates a table from ConfigHub links. Units are optional and
+// used to show slugs instead of IDs for the link endpoints.
+func RenderLinksTable(links []*Link, units []*Unit) string {
+	table := NewTable("Link", "From Unit", "To Unit", "Created")
+
+	slugs := make(map[uuid.UUID]string, len(units))
+	for _, unit := range units {
+		slugs[unit.UnitID] = unit.Slug
+	}
+	unitName := func(id uuid.UUID) string {
+		if slug, ok := slugs[id]; ok {
+			return slug
+		}
+		return id.String()[:8] + "..."
+	}
+
+	for _, link := range links {
+		table.AddRow(
+			link.Slug,
+			truncate(unitName(link.FromUnitID), 30),
+			truncate(unitName(link.ToUnitID), 30),
+			formatTimestamp(link.CreatedAt),
+		)
+	}
+
+	return table.Render()
+}
+
 // ============================================================================
 // ACTIVITY / AUDIT LOG TABLES
 // ============================================================================
@@ -523,6 +954,241 @@ func RenderStateComparisonTable(resources []ResourceState) string {
 	return table.Render()
 }
 
+// RenderResourceDiffTable shows what applying a unit would change in the cluster
+func RenderResourceDiffTable(diff *ResourceDiff) string {
+	table := NewTable("", "Field", "Kubernetes", "ConfigHub")
+	table.SetAlignment(AlignCenter, 0)
+	table.SetMaxWidth(1, 50)
+	table.SetWrap(1, true) // Deep field paths get long
+	table.SetMaxWidth(2, 30)
+	table.SetMaxWidth(3, 30)
+
+	if !diff.Exists {
+		table.AddRow("+", fmt.Sprintf("%s/%s", diff.Kind, diff.Name), "(not deployed)", "(new)")
+	}
+
+	for _, change := range diff.Changes {
+		symbol := "~"
+		switch change.Type {
+		case "added":
+			symbol = "+"
+		case "removed":
+			symbol = "-"
+		}
+		table.AddRow(symbol, change.Path, formatDiffValue(change.Live), formatDiffValue(change.Desired))
+	}
+
+	return table.Render()
+}
+
+// RenderPromotionPlanTable shows the field changes a promotion would push downstream
+func RenderPromotionPlanTable(plan *PromotionPlan) string {
+	table := NewTable("", "Unit", "Field", plan.To, plan.From)
+	table.SetAlignment(AlignCenter, 0)
+	table.SetMaxWidth(2, 50)
+	table.SetWrap(2, true)
+	table.SetMaxWidth(3, 30)
+	table.SetMaxWidth(4, 30)
+
+	for _, unit := range plan.Units {
+		for _, change := range unit.Changes {
+			symbol := "~"
+			switch change.Type {
+			case "added":
+				symbol = "+"
+			case "removed":
+				symbol = "-"
+			}
+			table.AddRow(symbol, unit.UnitSlug, change.Path, formatDiffValue(change.Live), formatDiffValue(change.Desired))
+		}
+	}
+
+	return table.Render()
+}
+
+// RenderApplyPlanTable shows what a bulk apply would do to each unit, with
+// totals per action in the footer
+func RenderApplyPlanTable(plan *ApplyPlan) string {
+	table := NewTable("", "Unit", "Action", "Reason")
+	table.SetAlignment(AlignCenter, 0)
+	table.SetMaxWidth(3, 50)
+	table.SetWrap(3, true)
+
+	for _, unit := range plan.Units {
+		symbol := " "
+		switch unit.Action {
+		case ApplyCreate:
+			symbol = "+"
+		case ApplyUpdate:
+			symbol = "~"
+		}
+		table.AddRow(symbol, unit.Slug, string(unit.Action), unit.Reason)
+	}
+	table.AddFooter("", fmt.Sprintf("%d units", len(plan.Units)), "",
+		fmt.Sprintf("%d to create, %d to update, %d unchanged", plan.Count(ApplyCreate), plan.Count(ApplyUpdate), plan.Count(ApplyNoOp)))
+
+	return table.Render()
+}
+
+// RenderOptimizationDiff shows an optimization for review: the original and
+// optimized spec side by side, grouped by optimization with its risk and
+// reasoning. Reductions are marked ↓ with the percentage change.
+func RenderOptimizationDiff(config *OptimizedConfiguration) string {
+	if config == nil || config.OriginalUnit == nil || config.OptimizedUnit == nil {
+		return "No optimization to show\n"
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Optimization: %s → %s\n", config.OriginalUnit.Slug, config.OptimizedUnit.Slug))
+	out.WriteString(fmt.Sprintf("Estimated savings: $%.2f/month (%.1f%%)\n",
+		config.EstimatedSavings.MonthlySavings, config.EstimatedSavings.SavingsPercent))
+	if config.RiskAssessment.OverallRisk != "" {
+		out.WriteString(fmt.Sprintf("Overall risk: %s\n", config.RiskAssessment.OverallRisk))
+	}
+
+	original, err := config.OriginalUnit.Manifest()
+	if err != nil {
+		out.WriteString(fmt.Sprintf("Cannot parse original manifest: %v\n", err))
+		return out.String()
+	}
+	optimized, err := config.OptimizedUnit.Manifest()
+	if err != nil {
+		out.WriteString(fmt.Sprintf("Cannot parse optimized manifest: %v\n", err))
+		return out.String()
+	}
+
+	// Group spec changes into one hunk per optimization, in optimization order
+	hunks := make([][]FieldChange, len(config.Optimizations)+1)
+	for _, change := range diffFields("spec", original["spec"], optimized["spec"], true) {
+		hunk := len(config.Optimizations) // Changes no optimization explains go last
+		for i, opt := range config.Optimizations {
+			if optimizationDiffType(change.Path) == opt.Type {
+				hunk = i
+				break
+			}
+		}
+		hunks[hunk] = append(hunks[hunk], change)
+	}
+
+	table := NewTable("", "Field", "Original", "Optimized", "Risk", "Reasoning")
+	table.SetAlignment(AlignCenter, 0)
+	table.SetMaxWidth(1, 50)
+	table.SetWrap(1, true)
+	table.SetMaxWidth(5, 40)
+	table.SetWrap(5, true)
+
+	for i, changes := range hunks {
+		risk, reasoning := "", ""
+		if i < len(config.Optimizations) {
+			risk, reasoning = config.Optimizations[i].Risk, config.Optimizations[i].Reasoning
+		}
+		for j, change := range changes {
+			symbol, optimizedValue := "~", formatDiffValue(change.Desired)
+			switch change.Type {
+			case "added":
+				symbol = "+"
+			case "removed":
+				symbol = "-"
+			default:
+				from, fromOK := parseSortValue(formatDiffValue(change.Live))
+				to, toOK := parseSortValue(optimizedValue)
+				if fromOK && toOK && from > 0 {
+					pct := (to - from) / from * 100
+					optimizedValue = fmt.Sprintf("%s (%+.0f%%)", optimizedValue, pct)
+					if to < from {
+						symbol = "↓"
+					} else if to > from {
+						symbol = "↑"
+					}
+				}
+			}
+			if j > 0 {
+				risk, reasoning = "", "" // Once per hunk
+			}
+			table.AddRow(symbol, change.Path, formatDiffValue(change.Live), optimizedValue, risk, reasoning)
+		}
+	}
+	for _, unit := range config.AdditionalUnits {
+		kind := ""
+		if manifest, err := unit.Manifest(); err == nil {
+			kind, _ = manifest["kind"].(string)
+		}
+		table.AddRow("+", fmt.Sprintf("%s (%s)", unit.Slug, kind), "-", "(new unit)", "", "")
+	}
+
+	out.WriteString(table.Render())
+	return out.String()
+}
+
+// optimizationDiffType maps a changed spec path to the ResourceOptimization
+// type that explains it
+func optimizationDiffType(path string) string {
+	switch {
+	case path == "spec.replicas":
+		return "replicas"
+	case strings.Contains(path, "volumeClaimTemplates") && strings.HasSuffix(path, ".storage"):
+		return "storage"
+	case strings.Contains(path, ".resources.") && strings.HasSuffix(path, ".cpu"):
+		return "cpu"
+	case strings.Contains(path, ".resources.") && strings.HasSuffix(path, ".memory"):
+		return "memory"
+	}
+	return ""
+}
+
+// formatDiffValue renders a diff value on a single line
+func formatDiffValue(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// RenderPackageDiffTable shows unit, filter and target changes between two packages
+func RenderPackageDiffTable(diff *PackageDiff) string {
+	table := NewTable("", "Type", "Name", "Change", "SHA-256")
+	table.SetAlignment(AlignCenter, 0)
+
+	for _, change := range diff.Changes {
+		symbol := "~"
+		switch change.Change {
+		case "added":
+			symbol = "+"
+		case "removed":
+			symbol = "-"
+		}
+
+		hash := ""
+		switch change.Change {
+		case "added":
+			hash = shortHash(change.NewHash)
+		case "removed":
+			hash = shortHash(change.OldHash)
+		default:
+			hash = fmt.Sprintf("%s → %s", shortHash(change.OldHash), shortHash(change.NewHash))
+		}
+
+		table.AddRow(symbol, change.Type, change.Key, change.Change, hash)
+	}
+
+	return table.Render()
+}
+
+// shortHash abbreviates a hex digest for display
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
 // RenderKubectlTable formats kubectl output as a table
 func RenderKubectlTable(headers []string, rows [][]string) string {
 	table := NewTable(headers...)
@@ -582,38 +1248,77 @@ func RenderEnvironmentHierarchyTable(envs []EnvironmentInfo) string {
 // COST ANALYSIS TABLE
 // ============================================================================
 
-// RenderCostAnalysisTable shows cost breakdown
+// RenderCostAnalysisTable shows cost breakdown in USD
 func RenderCostAnalysisTable(units []UnitCostEstimate) string {
+	return RenderCostAnalysisTableWithPricing(units, DefaultPricing)
+}
+
+// RenderCostAnalysisTableWithPricing shows cost breakdown in the pricing
+// model's display currency (see CostAnalyzer.ConvertCurrency and Pricing)
+func RenderCostAnalysisTableWithPricing(units []UnitCostEstimate, pricing *PricingModel) string {
 	table := NewTable("Unit", "Replicas", "CPU Cost", "Memory Cost", "Storage Cost", "Total/Month")
 	table.SetAlignment(AlignRight, 1, 2, 3, 4, 5) // All numeric columns right-aligned
 
+	table.SortBy(5, true, true) // Most expensive first
+
 	var totalCost float64
 
 	for _, unit := range units {
 		table.AddRow(
 			truncate(unit.UnitName, 30),
 			fmt.Sprintf("%d", unit.Replicas),
-			fmt.Sprintf("$%.2f", unit.Breakdown.CPUCost),
-			fmt.Sprintf("$%.2f", unit.Breakdown.MemoryCost),
-			fmt.Sprintf("$%.2f", unit.Breakdown.StorageCost),
-			fmt.Sprintf("$%.2f", unit.MonthlyCost),
+			pricing.FormatAmount(unit.Breakdown.CPUCost),
+			pricing.FormatAmount(unit.Breakdown.MemoryCost),
+			pricing.FormatAmount(unit.Breakdown.StorageCost),
+			pricing.FormatAmount(unit.MonthlyCost),
 		)
 		totalCost += unit.MonthlyCost
 	}
 
-	// Add total row
-	table.AddRow(
+	table.AddFooter(
 		"TOTAL",
 		"",
 		"",
 		"",
 		"",
-		fmt.Sprintf("$%.2f", totalCost),
+		pricing.FormatAmount(totalCost),
 	)
 
 	return table.Render()
 }
 
+// RenderCostGroupTable shows cost per label value from GroupCostBy, most
+// expensive first
+func RenderCostGroupTable(labelKey string, groups map[string]*CostGroup) string {
+	table := NewTable(labelKey, "Units", "Total/Month", "Share")
+	table.SetAlignment(AlignRight, 1, 2, 3)
+	table.SortBy(2, true, true)
+
+	var totalCost float64
+	var totalUnits int
+	for _, group := range groups {
+		totalCost += group.TotalMonthlyCost
+		totalUnits += group.UnitCount
+	}
+
+	for _, group := range groups {
+		share := 0.0
+		if totalCost > 0 {
+			share = group.TotalMonthlyCost / totalCost * 100
+		}
+		table.AddRow(
+			truncate(group.Value, 30),
+			fmt.Sprintf("%d", group.UnitCount),
+			DefaultPricing.FormatAmount(group.TotalMonthlyCost),
+			fmt.Sprintf("%.1f%%", share),
+		)
+	}
+
+	table.AddFooter("TOTAL", fmt.Sprintf("%d", totalUnits), DefaultPricing.FormatAmount(totalCost), "")
+
+	return table.Render()
+}
+
 // ============================================================================
 // UTILITY FUNCTIONS
 // ============================================================================
@@ -654,15 +1359,94 @@ func formatTimestamp(t time.Time) string {
 	return t.Format("2006-01-02")
 }
 
-// truncate truncates a string to a maximum length
+// truncate truncates a string to a maximum display width, cutting on rune boundaries
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if displayWidth(s) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return truncateToWidth(s, maxLen)
+	}
+	return truncateToWidth(s, maxLen-3) + "..."
+}
+
+// truncateToWidth returns the longest prefix of s that fits in maxWidth cells
+func truncateToWidth(s string, maxWidth int) string {
+	used := 0
+	for i, r := range s {
+		w := runeWidth(r)
+		if used+w > maxWidth {
+			return s[:i]
+		}
+		used += w
+	}
+	return s
+}
+
+// wrapText breaks a string into lines of at most maxWidth cells, splitting on
+// spaces where possible and hard-breaking words that are too long
+func wrapText(s string, maxWidth int) []string {
+	if maxWidth <= 0 {
+		return []string{s}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(s) {
+		// Hard-break words that can never fit on one line
+		for displayWidth(word) > maxWidth {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			head := truncateToWidth(word, maxWidth)
+			if head == "" {
+				head = string([]rune(word)[:1]) // Wide rune in a narrow column
+			}
+			lines = append(lines, head)
+			word = word[len(head):]
+		}
+		if word == "" {
+			continue
+		}
+
+		switch {
+		case current == "":
+			current = word
+		case displayWidth(current)+1+displayWidth(word) <= maxWidth:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+// displayWidth returns the number of terminal cells needed to display s
+func displayWidth(s string) int {
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r)
+	}
+	return total
+}
+
+// runeWidth returns the terminal cell width of a rune: 2 for East Asian wide
+// and fullwidth characters, 0 for combining marks and control characters, 1 otherwise
+func runeWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
 	}
-	return s[:maxLen-3] + "..."
+	return 1
 }
 
 // ============================================================================
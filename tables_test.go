@@ -0,0 +1,247 @@
+package sdk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test table rendering with multibyte cells
+func TestTableWriterMultibyte(t *testing.T) {
+	t.Run("DisplayWidth", func(t *testing.T) {
+		assert.Equal(t, 4, displayWidth("北京"))
+		assert.Equal(t, 9, displayWidth("✓ healthy"))
+		assert.Equal(t, 5, displayWidth("héllo"))
+	})
+
+	t.Run("AlignedOutput", func(t *testing.T) {
+		table := NewTable("City", "Status")
+		table.AddRow("北京", "✓ healthy")
+		table.AddRow("Berlin", "⚠ degraded")
+
+		lines := strings.Split(table.Render(), "\n")
+		assert.Len(t, lines, 6)
+
+		expected := displayWidth(lines[0])
+		for _, line := range lines {
+			assert.Equal(t, expected, displayWidth(line), "misaligned line: %q", line)
+		}
+	})
+
+	t.Run("TruncateOnRuneBoundary", func(t *testing.T) {
+		assert.Equal(t, "北京", truncate("北京", 4))
+		assert.Equal(t, "北...", truncate("北京市朝阳区", 6))
+		assert.Equal(t, "✓ he...", truncate("✓ healthy", 7))
+		assert.Equal(t, "北", truncate("北京", 3))
+	})
+}
+
+// Test row sorting and footer rendering
+func TestTableWriterSortAndFooter(t *testing.T) {
+	t.Run("ParseSortValue", func(t *testing.T) {
+		testCases := []struct {
+			input    string
+			expected float64
+			ok       bool
+		}{
+			{"$103.68", 103.68, true},
+			{"$1,024.50", 1024.50, true},
+			{"€95.38", 95.38, true},
+			{"45%", 45, true},
+			{"2Gi", 2 * 1024 * 1024 * 1024, true},
+			{"500m", 0.5, true},
+			{"frontend", 0, false},
+			{"", 0, false},
+		}
+
+		for _, tc := range testCases {
+			value, ok := parseSortValue(tc.input)
+			assert.Equal(t, tc.ok, ok, "input: %s", tc.input)
+			assert.InDelta(t, tc.expected, value, 0.001, "input: %s", tc.input)
+		}
+	})
+
+	t.Run("CostTableSortedWithFooter", func(t *testing.T) {
+		output := RenderCostAnalysisTable([]UnitCostEstimate{
+			{UnitName: "cheap", Replicas: 1, MonthlyCost: 9.50},
+			{UnitName: "pricey", Replicas: 3, MonthlyCost: 103.68},
+			{UnitName: "middle", Replicas: 2, MonthlyCost: 20.00},
+		})
+
+		lines := strings.Split(output, "\n")
+		assert.Len(t, lines, 9)
+		assert.Contains(t, lines[3], "pricey")
+		assert.Contains(t, lines[4], "middle")
+		assert.Contains(t, lines[5], "cheap")
+		assert.True(t, strings.HasPrefix(lines[6], DefaultBorder.LeftCross), "footer separator: %q", lines[6])
+		assert.Contains(t, lines[7], "TOTAL")
+		assert.Contains(t, lines[7], "$133.18")
+	})
+
+	t.Run("CostTableInCurrency", func(t *testing.T) {
+		ca := NewCostAnalyzer(&DevOpsApp{}, uuid.New())
+		ca.ConvertCurrency(0.5, "EUR", "€")
+		assert.Equal(t, "$", DefaultPricing.CurrencySymbol, "shared default must not change")
+
+		output := RenderCostAnalysisTableWithPricing([]UnitCostEstimate{
+			{UnitName: "cheap", Replicas: 1, MonthlyCost: 9.50},
+			{UnitName: "pricey", Replicas: 3, MonthlyCost: 103.68},
+		}, ca.Pricing())
+
+		lines := strings.Split(output, "\n")
+		assert.Contains(t, lines[3], "pricey")
+		assert.Contains(t, lines[3], "€51.84")
+		assert.Contains(t, output, "€56.59")
+		assert.NotContains(t, output, "$")
+
+		report := ca.GenerateReport(&SpaceCostAnalysis{TotalMonthlyCost: 113.18})
+		assert.Contains(t, report, "Estimated Monthly Cost: €56.59")
+	})
+}
+
+// Test column wrapping and max width
+func TestTableWriterWrap(t *testing.T) {
+	t.Run("WrapText", func(t *testing.T) {
+		assert.Equal(t, []string{"Labels.tier =", "'backend'"}, wrapText("Labels.tier = 'backend'", 13))
+		assert.Equal(t, []string{"abcde", "fgh"}, wrapText("abcdefgh", 5))
+		assert.Equal(t, []string{""}, wrapText("", 5))
+	})
+
+	t.Run("WrappedRowKeepsBorders", func(t *testing.T) {
+		output := RenderFiltersTable([]*Filter{
+			{Slug: "backend", From: "Unit", Where: "Labels.tier = 'backend' AND Labels.environment = 'production'"},
+		})
+
+		lines := strings.Split(output, "\n")
+		assert.Len(t, lines, 6) // top, header, separator, 2 wrapped lines, bottom
+		assert.Contains(t, lines[3], "backend")
+		assert.NotContains(t, output, "...")
+
+		expected := displayWidth(lines[0])
+		for _, line := range lines {
+			assert.Equal(t, expected, displayWidth(line), "misaligned line: %q", line)
+		}
+	})
+
+	t.Run("MaxWidthTruncates", func(t *testing.T) {
+		table := NewTable("Name")
+		table.SetMaxWidth(0, 6)
+		table.AddRow("very-long-name")
+		assert.Contains(t, table.Render(), "ver...")
+	})
+}
+
+// Test streaming rows with sampled and fixed widths
+func TestStreamingTableWriter(t *testing.T) {
+	t.Run("MatchesBufferedTable", func(t *testing.T) {
+		var out strings.Builder
+		stream := NewStreamingTable(&out, 10, "Name", "Cost")
+		stream.SetAlignment(AlignRight, 1)
+		table := NewTable("Name", "Cost")
+		table.SetAlignment(AlignRight, 1)
+
+		for _, row := range [][]string{{"web", "$12.00"}, {"database", "$140.50"}} {
+			require.NoError(t, stream.AddRow(row...))
+			table.AddRow(row...)
+		}
+		assert.Empty(t, out.String(), "rows are buffered until the sample is complete")
+
+		stream.AddFooter("TOTAL", "$152.50")
+		table.AddFooter("TOTAL", "$152.50")
+		require.NoError(t, stream.Close())
+		assert.Equal(t, table.Render()+"\n", out.String())
+	})
+
+	t.Run("WritesAfterSample", func(t *testing.T) {
+		var out strings.Builder
+		stream := NewStreamingTable(&out, 2, "Unit")
+		require.NoError(t, stream.AddRow("web"))
+		require.NoError(t, stream.AddRow("api"))
+		written := out.String()
+		assert.Contains(t, written, "api")
+
+		require.NoError(t, stream.AddRow("very-long-worker"))
+		assert.Greater(t, len(out.String()), len(written), "rows after the sample are written immediately")
+		require.NoError(t, stream.Close())
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		assert.Contains(t, lines[5], "v...", "later wide cells are truncated to the sampled width")
+		expected := displayWidth(lines[0])
+		for _, line := range lines {
+			assert.Equal(t, expected, displayWidth(line), "misaligned line: %q", line)
+		}
+	})
+
+	t.Run("FixedWidths", func(t *testing.T) {
+		var out strings.Builder
+		stream := NewStreamingTable(&out, 100, "Unit", "Status")
+		stream.SetFixedWidths([]int{8, 6})
+		require.NoError(t, stream.AddRow("web", "Ready"))
+		assert.Contains(t, out.String(), "│ web      │ Ready  │", "fixed widths skip sampling")
+
+		require.NoError(t, stream.AddRow("background-worker", "Pending"))
+		require.NoError(t, stream.Close())
+		assert.Contains(t, out.String(), "backg...")
+	})
+
+	t.Run("EmptyTable", func(t *testing.T) {
+		var out strings.Builder
+		require.NoError(t, NewStreamingTable(&out, 10, "Unit").Close())
+		assert.Empty(t, out.String())
+	})
+}
+
+// Test rendering an optimization as a reviewable diff
+func TestRenderOptimizationDiff(t *testing.T) {
+	manifest := func(replicas int, cpu, memory string) string {
+		return fmt.Sprintf("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: %d\n  template:\n    spec:\n      containers:\n      - name: web\n        image: web:v1\n        resources:\n          requests:\n            cpu: %s\n            memory: %s\n", replicas, cpu, memory)
+	}
+	optimized := &Unit{Slug: "web-optimized", Data: manifest(2, "500m", "512Mi"), Annotations: map[string]string{RollbackPlanAnnotation: "e30="}}
+	config := &OptimizedConfiguration{
+		OriginalUnit:  &Unit{Slug: "web", Data: manifest(3, "\"1\"", "1Gi")},
+		OptimizedUnit: optimized,
+		Optimizations: []ResourceOptimization{
+			{Type: "cpu", Risk: "LOW", Reasoning: "p95 CPU is 20% of requests"},
+			{Type: "memory", Risk: "MEDIUM", Reasoning: "watch for OOMKilled events"},
+			{Type: "replicas", Risk: "HIGH", Reasoning: "traffic fits in two replicas"},
+		},
+		EstimatedSavings: CostSavings{MonthlySavings: 42.5, SavingsPercent: 50},
+		RiskAssessment:   OptimizationRisk{OverallRisk: "MEDIUM"},
+		AdditionalUnits:  []*Unit{{Slug: "web-hpa", Data: "apiVersion: autoscaling/v2\nkind: HorizontalPodAutoscaler\n"}},
+	}
+
+	diff := RenderOptimizationDiff(config)
+	assert.Contains(t, diff, "Optimization: web → web-optimized")
+	assert.Contains(t, diff, "Estimated savings: $42.50/month (50.0%)")
+	assert.Contains(t, diff, "Overall risk: MEDIUM")
+
+	lines := strings.Split(diff, "\n")
+	row := func(cell string) string {
+		for _, line := range lines {
+			if strings.Contains(line, cell) {
+				return line
+			}
+		}
+		t.Fatalf("no row with %s in:\n%s", cell, diff)
+		return ""
+	}
+	cpu := row("500m")
+	assert.Contains(t, cpu, "↓")
+	assert.Contains(t, cpu, "500m (-50%)")
+	assert.Contains(t, cpu, "LOW")
+	assert.Contains(t, cpu, "p95 CPU is 20% of requests")
+	assert.Contains(t, row("512Mi"), "512Mi (-50%)")
+	assert.Contains(t, row("512Mi"), "watch for OOMKilled events")
+	replicas := row("spec.replicas")
+	assert.Contains(t, replicas, "2 (-33%)")
+	assert.Contains(t, replicas, "HIGH")
+	assert.Contains(t, row("web-hpa"), "HorizontalPodAutoscaler")
+	assert.NotContains(t, diff, "image", "unchanged fields are left out")
+	assert.NotContains(t, diff, RollbackPlanAnnotation, "metadata is not part of the diff")
+
+	require.Equal(t, "No optimization to show\n", RenderOptimizationDiff(&OptimizedConfiguration{}))
+}
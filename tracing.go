@@ -0,0 +1,96 @@
+// tracing.go - Optional tracing hooks for the DevOps SDK
+//
+// The SDK emits spans around every ConfigHub API call and the major analyzer
+// phases (AnalyzeSpace, AnalyzeWaste, GenerateOptimizedUnit) through the small
+// Tracer interface below. Nothing is traced until SetTracerProvider is called,
+// and the SDK depends on no tracing library. Adapting OpenTelemetry takes a
+// few lines:
+//
+//	type otelProvider struct{ trace.TracerProvider }
+//
+//	func (p otelProvider) Tracer(name string) sdk.Tracer {
+//		return otelTracer{p.TracerProvider.Tracer(name)}
+//	}
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, sdk.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value interface{}) {
+//		s.Span.SetAttributes(attribute.String(key, fmt.Sprint(value)))
+//	}
+//	func (s otelSpan) RecordError(err error) { s.Span.RecordError(err) }
+//	func (s otelSpan) End()                  { s.Span.End() }
+//
+//	sdk.SetTracerProvider(otelProvider{otel.GetTracerProvider()})
+package sdk
+
+import (
+	"context"
+	"sync"
+)
+
+// tracerName identifies the SDK's instrumentation to the tracer provider
+const tracerName = "github.com/monadic/devops-sdk"
+
+// TracerProvider hands out named tracers, like OpenTelemetry's TracerProvider
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+// Tracer starts spans; the returned context carries the span so nested calls
+// become its children
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+var (
+	tracerMu sync.RWMutex
+	tracer   Tracer = noopTracer{}
+)
+
+// SetTracerProvider enables tracing for all SDK clients and analyzers; nil
+// restores the default no-op tracer
+func SetTracerProvider(provider TracerProvider) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+
+	if provider == nil {
+		tracer = noopTracer{}
+		return
+	}
+	tracer = provider.Tracer(tracerName)
+}
+
+// startSpan starts a span with the configured tracer
+func startSpan(ctx context.Context, name string) (context.Context, Span) {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+	return t.Start(ctx, name)
+}
+
+// noopTracer is the default tracer; it records nothing
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
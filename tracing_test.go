@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedSpan is a span captured by recordingTracer
+type recordedSpan struct {
+	name       string
+	parent     string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordedSpan) RecordError(err error)                      { s.err = err }
+func (s *recordedSpan) End()                                       { s.ended = true }
+
+type spanKey struct{}
+
+// recordingTracer records every span, tracking parents through the context
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) Tracer(string) Tracer { return r }
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordedSpan{name: name, attributes: make(map[string]interface{})}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		span.parent = parent.name
+	}
+	r.mu.Lock()
+	r.spans = append(r.spans, span)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Test tracing hooks around ConfigHub calls and analyzers
+func TestTracing(t *testing.T) {
+	t.Cleanup(func() { SetTracerProvider(nil) })
+
+	t.Run("ConfigHubCalls", func(t *testing.T) {
+		recorder := &recordingTracer{}
+		SetTracerProvider(recorder)
+		defer SetTracerProvider(nil)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`[]`))
+		}))
+		defer server.Close()
+
+		client := NewConfigHubClient(server.URL, "test-token")
+		_, err := client.ListSpaces()
+		require.NoError(t, err)
+		assert.Error(t, client.DeleteSpace(uuid.New()))
+
+		require.Len(t, recorder.spans, 2)
+		list := recorder.spans[0]
+		assert.Equal(t, "ConfigHub GET", list.name)
+		assert.Equal(t, "/space", list.attributes["confighub.endpoint"])
+		assert.Equal(t, http.StatusOK, list.attributes["http.status_code"])
+		assert.NoError(t, list.err)
+		assert.True(t, list.ended)
+
+		del := recorder.spans[1]
+		assert.Equal(t, "ConfigHub DELETE", del.name)
+		assert.Equal(t, http.StatusBadRequest, del.attributes["http.status_code"])
+		assert.Error(t, del.err)
+		assert.True(t, del.ended)
+	})
+
+	t.Run("AnalyzerSpansNest", func(t *testing.T) {
+		recorder := &recordingTracer{}
+		SetTracerProvider(recorder)
+		defer SetTracerProvider(nil)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"Unit": Unit{UnitID: uuid.New(), Slug: "web", Data: "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 1\n"}},
+			})
+		}))
+		defer server.Close()
+		app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+		spaceID := uuid.New()
+
+		_, err := NewWasteAnalyzer(app, spaceID).AnalyzeWasteFromSource(context.Background(), usageByUnitID{})
+		require.NoError(t, err)
+
+		require.Len(t, recorder.spans, 3)
+		waste, cost, list := recorder.spans[0], recorder.spans[1], recorder.spans[2]
+		assert.Equal(t, "WasteAnalyzer.AnalyzeWaste", waste.name)
+		assert.Equal(t, spaceID.String(), waste.attributes["space.id"])
+		assert.Equal(t, 1, waste.attributes["unit.count"])
+		assert.Equal(t, "CostAnalyzer.AnalyzeSpace", cost.name)
+		assert.Equal(t, waste.name, cost.parent)
+		assert.Equal(t, 1, cost.attributes["unit.count"])
+		assert.Equal(t, "ConfigHub GET", list.name)
+		assert.Equal(t, cost.name, list.parent)
+		for _, span := range recorder.spans {
+			assert.True(t, span.ended, span.name)
+		}
+	})
+
+	t.Run("NilRestoresNoop", func(t *testing.T) {
+		SetTracerProvider(nil)
+		ctx := context.Background()
+		spanCtx, span := startSpan(ctx, "noop")
+		assert.Equal(t, ctx, spanCtx)
+		span.RecordError(errors.New("ignored"))
+		span.End()
+	})
+}
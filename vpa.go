@@ -0,0 +1,223 @@
+// vpa.go - Vertical Pod Autoscaler connector for the DevOps SDK
+//
+// This module imports recommendations from VerticalPodAutoscalers running in
+// recommendation mode, so the WasteAnalyzer can use the targets VPA already
+// computes instead of re-deriving them from raw Prometheus data.
+//
+// Features:
+// - Read .status.recommendation.containerRecommendations via the dynamic client
+// - Sum target/lowerBound/upperBound across all containers in the pod
+// - Map recommendations into ActualUsageMetrics as a UsageSource
+// - Fall back to another UsageSource for units without a VPA
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// vpaGVR identifies VerticalPodAutoscaler objects
+var vpaGVR = schema.GroupVersionResource{
+	Group:    "autoscaling.k8s.io",
+	Version:  "v1",
+	Resource: "verticalpodautoscalers",
+}
+
+// vpaHistoryWindow is the VPA recommender's default usage history length
+const vpaHistoryWindow = 8 * 24 * time.Hour
+
+// VPAContainerRecommendation is the VPA recommendation for one container
+type VPAContainerRecommendation struct {
+	ContainerName         string
+	TargetCPUCores        float64
+	LowerBoundCPUCores    float64
+	UpperBoundCPUCores    float64
+	TargetMemoryBytes     int64
+	LowerBoundMemoryBytes int64
+	UpperBoundMemoryBytes int64
+}
+
+// VPARecommendation is a VerticalPodAutoscaler's recommendation for a
+// workload. The pod-level fields sum all of its containers.
+type VPARecommendation struct {
+	VPAName    string
+	Containers []VPAContainerRecommendation
+
+	TargetCPUCores        float64
+	LowerBoundCPUCores    float64
+	UpperBoundCPUCores    float64
+	TargetMemoryBytes     int64
+	LowerBoundMemoryBytes int64
+	UpperBoundMemoryBytes int64
+}
+
+// VPAUsageProvider is a UsageSource backed by VerticalPodAutoscaler
+// recommendations. Units without a VPA recommendation are passed to the
+// fallback source, if any. VPAs are listed once, on first use.
+type VPAUsageProvider struct {
+	client    dynamic.Interface
+	namespace string
+	fallback  UsageSource
+
+	mu              sync.Mutex
+	recommendations map[string]*VPARecommendation // "Kind/name" of the target workload
+}
+
+// NewVPAUsageProvider creates a provider reading VPAs in namespace (empty for
+// all namespaces). fallback may be nil.
+func NewVPAUsageProvider(client dynamic.Interface, namespace string, fallback UsageSource) *VPAUsageProvider {
+	return &VPAUsageProvider{
+		client:    client,
+		namespace: namespace,
+		fallback:  fallback,
+	}
+}
+
+// Usage maps the unit's VPA recommendation into usage metrics, preferring it
+// over the fallback source
+func (p *VPAUsageProvider) Usage(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+	recommendations, err := p.loadRecommendations(ctx)
+	if err != nil {
+		return ActualUsageMetrics{}, false, err
+	}
+
+	recommendation, ok := recommendations[estimate.Type+"/"+estimate.UnitName]
+	if !ok {
+		if p.fallback == nil {
+			return ActualUsageMetrics{}, false, nil
+		}
+		return p.fallback.Usage(ctx, estimate)
+	}
+
+	return vpaUsage(estimate, recommendation), true, nil
+}
+
+// loadRecommendations lists VPAs once and caches their recommendations
+func (p *VPAUsageProvider) loadRecommendations(ctx context.Context) (map[string]*VPARecommendation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.recommendations != nil {
+		return p.recommendations, nil
+	}
+
+	list, err := p.client.Resource(vpaGVR).Namespace(p.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list VerticalPodAutoscalers: %w", err)
+	}
+
+	recommendations := make(map[string]*VPARecommendation)
+	for i := range list.Items {
+		vpa := &list.Items[i]
+		kind, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "kind")
+		name, _, _ := unstructured.NestedString(vpa.Object, "spec", "targetRef", "name")
+		if recommendation := parseVPARecommendation(vpa); recommendation != nil && name != "" {
+			recommendations[kind+"/"+name] = recommendation
+		}
+	}
+
+	p.recommendations = recommendations
+	return recommendations, nil
+}
+
+// parseVPARecommendation reads a VPA's container recommendations, or returns
+// nil if the recommender hasn't produced any yet
+func parseVPARecommendation(vpa *unstructured.Unstructured) *VPARecommendation {
+	containers, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	if len(containers) == 0 {
+		return nil
+	}
+
+	recommendation := &VPARecommendation{VPAName: vpa.GetName()}
+	for _, item := range containers {
+		container, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := container["containerName"].(string)
+		cpuTarget, memoryTarget := vpaQuantities(container, "target")
+		cpuLower, memoryLower := vpaQuantities(container, "lowerBound")
+		cpuUpper, memoryUpper := vpaQuantities(container, "upperBound")
+
+		recommendation.Containers = append(recommendation.Containers, VPAContainerRecommendation{
+			ContainerName:         name,
+			TargetCPUCores:        cpuTarget,
+			LowerBoundCPUCores:    cpuLower,
+			UpperBoundCPUCores:    cpuUpper,
+			TargetMemoryBytes:     memoryTarget,
+			LowerBoundMemoryBytes: memoryLower,
+			UpperBoundMemoryBytes: memoryUpper,
+		})
+		recommendation.TargetCPUCores += cpuTarget
+		recommendation.LowerBoundCPUCores += cpuLower
+		recommendation.UpperBoundCPUCores += cpuUpper
+		recommendation.TargetMemoryBytes += memoryTarget
+		recommendation.LowerBoundMemoryBytes += memoryLower
+		recommendation.UpperBoundMemoryBytes += memoryUpper
+	}
+
+	return recommendation
+}
+
+// vpaQuantities parses the cpu and memory of one recommendation field
+func vpaQuantities(container map[string]interface{}, field string) (cpuCores float64, memoryBytes int64) {
+	values, _ := container[field].(map[string]interface{})
+	if cpu, ok := values["cpu"].(string); ok {
+		if quantity, err := resource.ParseQuantity(cpu); err == nil {
+			cpuCores = float64(quantity.MilliValue()) / 1000
+		}
+	}
+	if memory, ok := values["memory"].(string); ok {
+		if quantity, err := resource.ParseQuantity(memory); err == nil {
+			memoryBytes = quantity.Value()
+		}
+	}
+	return cpuCores, memoryBytes
+}
+
+// vpaUsage treats the VPA target as the unit's typical usage and the upper
+// bound as its peak, and prices actual cost at the target
+func vpaUsage(estimate UnitCostEstimate, recommendation *VPARecommendation) ActualUsageMetrics {
+	now := time.Now()
+	usage := ActualUsageMetrics{
+		UnitID:          estimate.UnitID,
+		UnitName:        estimate.UnitName,
+		Space:           estimate.Space,
+		TimeRangeStart:  now.Add(-vpaHistoryWindow),
+		TimeRangeEnd:    now,
+		CPUCoresUsed:    recommendation.TargetCPUCores,
+		MemoryBytesUsed: recommendation.TargetMemoryBytes,
+		AverageReplicas: float64(estimate.Replicas),
+		UptimePercent:   100,
+		VPA:             recommendation,
+	}
+
+	cpuRatio, memoryRatio := 1.0, 1.0
+	if allocatedCores := float64(estimate.CPU.MilliValue()) / 1000.0; allocatedCores > 0 {
+		cpuRatio = recommendation.TargetCPUCores / allocatedCores
+		usage.CPUUtilizationPercent = cpuRatio * 100
+		usage.CPUP50, usage.CPUP95, usage.CPUP99 = usage.CPUUtilizationPercent, usage.CPUUtilizationPercent, usage.CPUUtilizationPercent
+		usage.CPUPeakPercent = recommendation.UpperBoundCPUCores / allocatedCores * 100
+	}
+	if allocatedBytes := float64(estimate.Memory.BytesValue()); allocatedBytes > 0 {
+		memoryRatio = float64(recommendation.TargetMemoryBytes) / allocatedBytes
+		usage.MemoryUtilizationPercent = memoryRatio * 100
+		usage.MemoryP50, usage.MemoryP95, usage.MemoryP99 = usage.MemoryUtilizationPercent, usage.MemoryUtilizationPercent, usage.MemoryUtilizationPercent
+		usage.MemoryPeakPercent = float64(recommendation.UpperBoundMemoryBytes) / allocatedBytes * 100
+	}
+
+	usage.ActualMonthlyCost = estimate.Breakdown.CPUCost*cpuRatio +
+		estimate.Breakdown.MemoryCost*memoryRatio +
+		estimate.Breakdown.StorageCost
+
+	return usage
+}
@@ -0,0 +1,113 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestVPA(name, targetKind, targetName string, containers ...interface{}) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": targetKind, "name": targetName},
+		},
+	}}
+	if len(containers) > 0 {
+		vpa.Object["status"] = map[string]interface{}{
+			"recommendation": map[string]interface{}{"containerRecommendations": containers},
+		}
+	}
+	return vpa
+}
+
+func vpaContainer(name, lowerCPU, targetCPU, upperCPU, lowerMemory, targetMemory, upperMemory string) interface{} {
+	return map[string]interface{}{
+		"containerName": name,
+		"lowerBound":    map[string]interface{}{"cpu": lowerCPU, "memory": lowerMemory},
+		"target":        map[string]interface{}{"cpu": targetCPU, "memory": targetMemory},
+		"upperBound":    map[string]interface{}{"cpu": upperCPU, "memory": upperMemory},
+	}
+}
+
+// Test importing VerticalPodAutoscaler recommendations
+func TestVPAUsageProvider(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(k8sruntime.NewScheme(),
+		map[schema.GroupVersionResource]string{vpaGVR: "VerticalPodAutoscalerList"},
+		newTestVPA("web-vpa", "Deployment", "web",
+			vpaContainer("app", "100m", "250m", "1", "256Mi", "512Mi", "1Gi"),
+			vpaContainer("sidecar", "10m", "50m", "100m", "32Mi", "64Mi", "128Mi"),
+		),
+		newTestVPA("pending-vpa", "Deployment", "pending"), // No recommendation yet
+	)
+
+	var fallbackCalls []string
+	fallback := usageSourceFunc(func(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+		fallbackCalls = append(fallbackCalls, estimate.UnitName)
+		return ActualUsageMetrics{}, false, nil
+	})
+	provider := NewVPAUsageProvider(client, "default", fallback)
+
+	web := UnitCostEstimate{
+		UnitID:      uuid.New().String(),
+		UnitName:    "web",
+		Type:        "Deployment",
+		Replicas:    3,
+		CPU:         ParseQuantity("2"),
+		Memory:      ParseQuantity("2Gi"),
+		MonthlyCost: 200,
+		Breakdown:   CostBreakdown{CPUCost: 150, MemoryCost: 50},
+	}
+
+	t.Run("SumsContainers", func(t *testing.T) {
+		usage, ok, err := provider.Usage(context.Background(), web)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NotNil(t, usage.VPA)
+		assert.Len(t, usage.VPA.Containers, 2)
+		assert.InDelta(t, 0.3, usage.VPA.TargetCPUCores, 0.0001)
+		assert.InDelta(t, 1.1, usage.VPA.UpperBoundCPUCores, 0.0001)
+		assert.Equal(t, int64(576*1024*1024), usage.VPA.TargetMemoryBytes)
+
+		assert.InDelta(t, 15, usage.CPUUtilizationPercent, 0.0001)
+		assert.InDelta(t, 55, usage.CPUPeakPercent, 0.0001)
+		assert.InDelta(t, 28.125, usage.MemoryUtilizationPercent, 0.0001)
+		assert.InDelta(t, 150*0.15+50*0.28125, usage.ActualMonthlyCost, 0.0001)
+		assert.Equal(t, 3.0, usage.AverageReplicas)
+	})
+
+	t.Run("FallsBackWithoutRecommendation", func(t *testing.T) {
+		for _, name := range []string{"pending", "api"} {
+			_, ok, err := provider.Usage(context.Background(), UnitCostEstimate{UnitName: name, Type: "Deployment"})
+			require.NoError(t, err)
+			assert.False(t, ok)
+		}
+		_, ok, err := provider.Usage(context.Background(), UnitCostEstimate{UnitName: "web", Type: "StatefulSet"})
+		require.NoError(t, err)
+		assert.False(t, ok, "target kind must match")
+		assert.Equal(t, []string{"pending", "api", "web"}, fallbackCalls)
+	})
+
+	t.Run("WasteAnalyzerPrefersVPA", func(t *testing.T) {
+		usage, _, err := provider.Usage(context.Background(), web)
+		require.NoError(t, err)
+
+		wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+		detection := wa.analyzeUnitWaste(web, usage, true)
+		require.NotNil(t, detection)
+		assert.Equal(t, "EXCELLENT", detection.DataQuality)
+		assert.Equal(t, "300m", detection.CPUWaste.Recommendation)
+		assert.Equal(t, "576Mi", detection.MemoryWaste.Recommendation)
+	})
+}
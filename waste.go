@@ -9,6 +9,7 @@
 // - Compare ConfigHub estimated costs vs actual usage metrics
 // - Detect over-provisioning by comparing requests vs actual utilization
 // - Identify idle resources with minimal usage
+// - Schedule-aware idle detection for business-hours and batch workloads
 // - Calculate waste scoring and severity levels
 // - Provide cost saving potential calculations
 // - Support for configurable waste detection thresholds
@@ -19,8 +20,10 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -30,10 +33,14 @@ import (
 
 // WasteAnalyzer detects waste by comparing estimated vs actual costs
 type WasteAnalyzer struct {
-	app          *DevOpsApp
-	spaceID      uuid.UUID
-	thresholds   *WasteThresholds
-	costAnalyzer *CostAnalyzer
+	app                 *DevOpsApp
+	spaceID             uuid.UUID
+	thresholds          *WasteThresholds
+	costAnalyzer        *CostAnalyzer
+	excludeLabels       map[string]string // Units with any of these labels are protected
+	excludeSlugPatterns []string          // Units whose slug matches any of these globs are protected
+	alerts              Notifier          // Notified when waste exceeds alertThreshold; nil sends nothing
+	alertThreshold      float64           // Monthly USD
 }
 
 // WasteThresholds defines when resources are considered wasteful
@@ -50,12 +57,44 @@ type WasteThresholds struct {
 
 	// Cost thresholds
 	MinMonthlyCostForAnalysis float64 // Only analyze resources above this cost (default: $1.00)
+	EgressToComputeRatio      float64 // Egress cost above this multiple of CPU+memory cost = high-egress (default: 0.5)
 	WasteScoreHighThreshold   float64 // Above this score = HIGH waste (default: 80.0)
 	WasteScoreMediumThreshold float64 // Above this score = MEDIUM waste (default: 50.0)
 
 	// Time-based thresholds
 	IdleDurationDays          int // Days of idle usage to flag as waste (default: 7)
 	UnderutilizedDurationDays int // Days of underutilization to flag (default: 14)
+
+	// Rightsizing targets. Only p50, p95 and p99 are measured, so other values
+	// round up to the next one (75 and 90 size against p95, 96 against p99);
+	// 0 or >99 sizes to raw peak, as does a percentile with no data.
+	CPUTargetPercentile    float64 // Utilization percentile to size CPU against (default: 95)
+	MemoryTargetPercentile float64 // Utilization percentile to size memory against (default: 99)
+
+	// Scale-to-zero eligibility
+	ScaleToZeroLabels []string // key=value labels marking units that may scale to zero (default: dev/staging env labels)
+
+	// Recommendation controls per category (zero value: enabled, no savings floor)
+	CPUResize    RecommendationRule // Floor applies to CPU wasted cost
+	MemoryResize RecommendationRule // Floor applies to memory wasted cost
+	ScaleDown    RecommendationRule // Floor applies to replica wasted cost
+	ScaleToZero  RecommendationRule // Also covers batch workloads; floor applies to scale-to-zero savings
+	Terminate    RecommendationRule // Floor applies to the unit's estimated monthly cost
+}
+
+// RecommendationRule tunes one category of waste recommendation, e.g. to turn
+// off terminate suggestions or only suggest scale-down above $50/month
+type RecommendationRule struct {
+	Disabled          bool    // Never emit this recommendation
+	MinMonthlySavings float64 // Emit only when the category's monthly waste exceeds this (0: no floor)
+}
+
+// allows reports whether a recommendation backed by monthlyWaste may be emitted
+func (r RecommendationRule) allows(monthlyWaste float64) bool {
+	if r.Disabled {
+		return false
+	}
+	return r.MinMonthlySavings <= 0 || monthlyWaste > r.MinMonthlySavings
 }
 
 // DefaultWasteThresholds provides sensible defaults for waste detection
@@ -67,10 +106,50 @@ var DefaultWasteThresholds = &WasteThresholds{
 	MemoryUnderutilizedThreshold: 40.0,
 	MemoryOverprovisionedRatio:   2.5,
 	MinMonthlyCostForAnalysis:    1.00,
+	EgressToComputeRatio:         0.5,
 	WasteScoreHighThreshold:      80.0,
 	WasteScoreMediumThreshold:    50.0,
 	IdleDurationDays:             7,
 	UnderutilizedDurationDays:    14,
+	CPUTargetPercentile:          95,
+	MemoryTargetPercentile:       99,
+	ScaleToZeroLabels:            []string{"env=dev", "env=staging", "environment=dev", "environment=staging"},
+}
+
+// WorkloadSchedule describes when a workload is expected to be busy
+type WorkloadSchedule string
+
+const (
+	ScheduleAlwaysOn      WorkloadSchedule = "always-on"      // Busy around the clock (default)
+	ScheduleBusinessHours WorkloadSchedule = "business-hours" // Busy weekdays during business hours
+	ScheduleBatch         WorkloadSchedule = "batch"          // Busy only while jobs run
+)
+
+// WorkloadScheduleLabel sets a unit's WorkloadSchedule
+const WorkloadScheduleLabel = "cost-optimizer.io/schedule"
+
+// Business hours used for ScheduleBusinessHours, in UTC
+const (
+	businessHoursStart = 9
+	businessHoursEnd   = 18
+)
+
+// workloadSchedule reads the schedule label, defaulting to always-on
+func workloadSchedule(labels map[string]string) WorkloadSchedule {
+	switch schedule := WorkloadSchedule(labels[WorkloadScheduleLabel]); schedule {
+	case ScheduleBusinessHours, ScheduleBatch:
+		return schedule
+	default:
+		return ScheduleAlwaysOn
+	}
+}
+
+// HourlyUsage is average utilization for one hour of the week
+type HourlyUsage struct {
+	Weekday       time.Weekday
+	Hour          int // 0-23, UTC
+	CPUPercent    float64
+	MemoryPercent float64
 }
 
 // ActualUsageMetrics represents real usage data from monitoring systems
@@ -88,11 +167,12 @@ type ActualUsageMetrics struct {
 	// Actual resource consumption
 	CPUCoresUsed      float64 // Average cores actually used
 	MemoryBytesUsed   int64   // Average memory bytes actually used
-	NetworkBytesTotal int64   // Total network I/O
+	NetworkBytesTotal int64   // Network bytes sent over the time range, priced as egress
 	StorageBytesUsed  int64   // Actual storage consumed
 
 	// Cost data from monitoring systems (e.g., OpenCost)
-	ActualMonthlyCost float64 // Actual cost based on usage
+	ActualMonthlyCost float64       // Actual cost based on usage
+	CostWindow        time.Duration // Window ActualMonthlyCost was normalized from (e.g., OpenCost allocation window)
 
 	// Replica and availability data
 	AverageReplicas float64 // Average number of running replicas
@@ -101,6 +181,38 @@ type ActualUsageMetrics struct {
 	// Peak usage for rightsizing recommendations
 	CPUPeakPercent    float64 // Peak CPU utilization
 	MemoryPeakPercent float64 // Peak memory utilization
+
+	// Utilization percentiles (0 = not available, falls back to peak)
+	CPUP50    float64 // 50th percentile CPU utilization %
+	CPUP95    float64 // 95th percentile CPU utilization %
+	CPUP99    float64 // 99th percentile CPU utilization %
+	MemoryP50 float64 // 50th percentile memory utilization %
+	MemoryP95 float64 // 95th percentile memory utilization %
+	MemoryP99 float64 // 99th percentile memory utilization %
+
+	// Utilization by hour of week (optional), used to find a workload's active window
+	HourlyUtilization []HourlyUsage
+
+	// VerticalPodAutoscaler recommendation (optional); preferred over derived
+	// resize recommendations when set
+	VPA *VPARecommendation
+}
+
+// UsageSource supplies actual usage for a unit on demand, so any monitoring
+// backend (Prometheus, OpenCost, Datadog, ...) can feed the waste analyzer.
+// ok is false when the source has no data for the unit. The full estimate is
+// passed because sources usually need the unit's name and requests, not
+// just its ID.
+type UsageSource interface {
+	Usage(ctx context.Context, estimate UnitCostEstimate) (usage ActualUsageMetrics, ok bool, err error)
+}
+
+// usageByUnitID adapts pre-built usage data to UsageSource
+type usageByUnitID map[string]ActualUsageMetrics
+
+func (u usageByUnitID) Usage(_ context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+	usage, ok := u[estimate.UnitID]
+	return usage, ok, nil
 }
 
 // WasteDetection represents the results of waste analysis for a single unit
@@ -109,6 +221,8 @@ type WasteDetection struct {
 	UnitName string
 	Space    string
 	Type     string // deployment, statefulset, etc.
+	Schedule WorkloadSchedule
+	Labels   map[string]string
 
 	// Cost comparison
 	EstimatedMonthlyCost float64 // From ConfigHub analysis
@@ -127,12 +241,18 @@ type WasteDetection struct {
 	ReplicaWaste ReplicaWaste
 
 	// Recommendations
-	Recommendations  []WasteRecommendation
-	PotentialSavings float64 // Monthly savings potential
+	Recommendations      []WasteRecommendation
+	PotentialSavings     float64 // Monthly savings potential
+	PotentialSavingsLow  float64 // Conservative end of the savings range
+	PotentialSavingsHigh float64 // Optimistic end of the savings range
 
 	// Analysis metadata
 	AnalyzedAt  time.Time
 	DataQuality string // EXCELLENT, GOOD, FAIR, POOR
+
+	// Exclusions (see SetExclusions)
+	Protected        bool   // Aggressive recommendations suppressed, nothing auto-applyable
+	ProtectionReason string // e.g. "label tier=critical"
 }
 
 // WasteCategory represents different types of waste
@@ -164,14 +284,16 @@ type ReplicaWaste struct {
 
 // WasteRecommendation provides actionable waste reduction suggestions
 type WasteRecommendation struct {
-	Type             string  // resize, scale-down, consolidate, terminate
-	Priority         string  // HIGH, MEDIUM, LOW
-	Action           string  // Human-readable action description
-	Implementation   string  // Technical implementation details
-	PotentialSavings float64 // Monthly savings if implemented
-	Risk             string  // LOW, MEDIUM, HIGH
-	RiskDescription  string  // Description of implementation risks
-	AutoApplyable    bool    // Whether this can be auto-applied
+	Type                 string  // resize, scale-down, scale-to-zero, consolidate, terminate
+	Priority             string  // HIGH, MEDIUM, LOW
+	Action               string  // Human-readable action description
+	Implementation       string  // Technical implementation details
+	PotentialSavings     float64 // Monthly savings if implemented
+	PotentialSavingsLow  float64 // Conservative end of the savings range
+	PotentialSavingsHigh float64 // Optimistic end of the savings range
+	Risk                 string  // LOW, MEDIUM, HIGH
+	RiskDescription      string  // Description of implementation risks
+	AutoApplyable        bool    // Whether this can be auto-applied
 }
 
 // SpaceWasteAnalysis represents waste analysis for an entire space
@@ -189,6 +311,7 @@ type SpaceWasteAnalysis struct {
 	// Unit-level analysis
 	UnitsAnalyzed       int
 	UnitsWithWaste      int
+	UnitsProtected      int
 	UnitWasteDetections []WasteDetection
 
 	// Waste breakdown by category
@@ -219,26 +342,68 @@ func NewWasteAnalyzer(app *DevOpsApp, spaceID uuid.UUID) *WasteAnalyzer {
 	}
 }
 
+// SetWasteAlert sends an alert to notifier whenever an analysis finds more
+// than monthlyUSD of total waste. nil turns alerts off.
+func (wa *WasteAnalyzer) SetWasteAlert(notifier Notifier, monthlyUSD float64) {
+	wa.alerts = notifier
+	wa.alertThreshold = monthlyUSD
+}
+
 // SetThresholds allows customization of waste detection thresholds
 func (wa *WasteAnalyzer) SetThresholds(thresholds *WasteThresholds) {
 	wa.thresholds = thresholds
 }
 
+// SetPricing sets the pricing model used to cost units, e.g. one with a
+// committed-use discount (see CostAnalyzer.SetCommitment)
+func (wa *WasteAnalyzer) SetPricing(pricing *PricingModel) {
+	wa.costAnalyzer.SetPricing(pricing)
+}
+
+// SetCache shares cache with the analyzer's cost analysis, so a waste
+// analysis of a space just analyzed for cost doesn't list its units again
+func (wa *WasteAnalyzer) SetCache(cache *AnalysisCache) {
+	wa.costAnalyzer.SetCache(cache)
+}
+
+// SetExclusions protects units that carry any of the given labels (e.g.
+// tier: critical) or whose slug matches any of the glob patterns (e.g. "db-*").
+// Protected units are still analyzed and reported, but never receive
+// scale-down, scale-to-zero or terminate recommendations, and none of their
+// recommendations are auto-applyable.
+func (wa *WasteAnalyzer) SetExclusions(labels map[string]string, slugPatterns []string) {
+	wa.excludeLabels = labels
+	wa.excludeSlugPatterns = slugPatterns
+}
+
 // AnalyzeWaste performs comprehensive waste analysis by comparing estimates vs actuals
 func (wa *WasteAnalyzer) AnalyzeWaste(actualUsageData []ActualUsageMetrics) (*SpaceWasteAnalysis, error) {
+	// Create usage lookup map
+	usageMap := make(usageByUnitID)
+	for _, usage := range actualUsageData {
+		usageMap[usage.UnitID] = usage
+	}
+
+	return wa.AnalyzeWasteFromSource(context.Background(), usageMap)
+}
+
+// AnalyzeWasteFromSource is like AnalyzeWaste but asks source for each unit's
+// usage as the unit is analyzed. Units the source fails on are analyzed
+// without usage data.
+func (wa *WasteAnalyzer) AnalyzeWasteFromSource(ctx context.Context, source UsageSource) (*SpaceWasteAnalysis, error) {
+	ctx, span := startSpan(ctx, "WasteAnalyzer.AnalyzeWaste")
+	defer span.End()
+	span.SetAttribute("space.id", wa.spaceID.String())
+
 	wa.app.Logger.Printf("🔍 Analyzing waste in ConfigHub space: %s", wa.spaceID)
 
 	// Get cost estimates from ConfigHub
-	costAnalysis, err := wa.costAnalyzer.AnalyzeSpace()
+	costAnalysis, err := wa.costAnalyzer.analyzeSpace(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze costs: %v", err)
-	}
-
-	// Create usage lookup map
-	usageMap := make(map[string]ActualUsageMetrics)
-	for _, usage := range actualUsageData {
-		usageMap[usage.UnitID] = usage
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to analyze costs: %w", err)
 	}
+	span.SetAttribute("unit.count", len(costAnalysis.Units))
 
 	analysis := &SpaceWasteAnalysis{
 		SpaceID:             wa.spaceID.String(),
@@ -253,7 +418,18 @@ func (wa *WasteAnalyzer) AnalyzeWaste(actualUsageData []ActualUsageMetrics) (*Sp
 
 	// Analyze waste for each unit
 	for _, costEstimate := range costAnalysis.Units {
-		usage, hasUsageData := usageMap[costEstimate.UnitID]
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if costEstimate.Type == LoadBalancerServiceType {
+			continue // No pods to right-size
+		}
+
+		usage, hasUsageData, err := source.Usage(ctx, costEstimate)
+		if err != nil {
+			wa.app.Logger.Printf("⚠️  Could not fetch usage for %s: %v", costEstimate.UnitName, err)
+			hasUsageData = false
+		}
 
 		wasteDetection := wa.analyzeUnitWaste(costEstimate, usage, hasUsageData)
 		if wasteDetection != nil {
@@ -266,6 +442,9 @@ func (wa *WasteAnalyzer) AnalyzeWaste(actualUsageData []ActualUsageMetrics) (*Sp
 			if wasteDetection.WasteScore > 0 {
 				analysis.UnitsWithWaste++
 			}
+			if wasteDetection.Protected {
+				analysis.UnitsProtected++
+			}
 		}
 	}
 
@@ -280,6 +459,12 @@ func (wa *WasteAnalyzer) AnalyzeWaste(actualUsageData []ActualUsageMetrics) (*Sp
 	wa.app.Logger.Printf("✅ Waste analysis complete: %.1f%% waste detected, $%.2f potential savings",
 		analysis.WastePercent, analysis.TotalWastedCost)
 
+	if wa.alerts != nil && analysis.TotalWastedCost > wa.alertThreshold {
+		if err := wa.alerts.Notify(ctx, wasteAlert(wa.app.Cub, wa.spaceID, analysis)); err != nil {
+			wa.app.Logger.Printf("⚠️  Could not send waste alert: %v", err)
+		}
+	}
+
 	return analysis, nil
 }
 
@@ -301,11 +486,16 @@ func (wa *WasteAnalyzer) analyzeUnitWaste(estimate UnitCostEstimate, usage Actua
 		Recommendations:      []WasteRecommendation{},
 		AnalyzedAt:           time.Now(),
 		DataQuality:          "POOR", // Default
+		Schedule:             workloadSchedule(estimate.Labels),
+		Labels:               estimate.Labels,
 	}
 
 	if hasUsageData {
 		detection.ActualMonthlyCost = usage.ActualMonthlyCost
 		detection.DataQuality = wa.assessDataQuality(usage)
+		if usage.VPA != nil {
+			detection.DataQuality = "EXCELLENT" // VPA has already done the sizing
+		}
 
 		// Analyze CPU waste
 		detection.CPUWaste = wa.analyzeCPUWaste(estimate, usage)
@@ -318,6 +508,9 @@ func (wa *WasteAnalyzer) analyzeUnitWaste(estimate UnitCostEstimate, usage Actua
 
 		// Categorize waste
 		detection.WasteCategories = wa.categorizeWaste(detection, usage)
+		if category := wa.categorizeEgress(estimate, usage); category != nil {
+			detection.WasteCategories = append(detection.WasteCategories, *category)
+		}
 
 		// Generate recommendations
 		detection.Recommendations = wa.generateWasteRecommendations(detection, estimate, usage)
@@ -327,15 +520,101 @@ func (wa *WasteAnalyzer) analyzeUnitWaste(estimate UnitCostEstimate, usage Actua
 		detection = wa.analyzeWithoutUsageData(estimate)
 	}
 
+	// Keep aggressive changes away from critical units
+	if reason := wa.exclusionReason(estimate); reason != "" {
+		protectDetection(detection, reason)
+	}
+
 	// Calculate overall waste score and severity
 	detection.WastedMonthlyCost = detection.EstimatedMonthlyCost - detection.ActualMonthlyCost
 	detection.WasteScore = wa.calculateWasteScore(detection)
 	detection.WasteSeverity = wa.determineWasteSeverity(detection.WasteScore)
 	detection.PotentialSavings = wa.calculatePotentialSavings(detection)
 
+	// Express savings as a range so speculative estimates aren't over-counted
+	band := savingsBand(detection.DataQuality, usage, hasUsageData)
+	detection.PotentialSavingsLow, detection.PotentialSavingsHigh = savingsRange(detection.PotentialSavings, band, detection.EstimatedMonthlyCost)
+	for i := range detection.Recommendations {
+		rec := &detection.Recommendations[i]
+		rec.PotentialSavingsLow, rec.PotentialSavingsHigh = savingsRange(rec.PotentialSavings, band, detection.EstimatedMonthlyCost)
+	}
+
 	return detection
 }
 
+// savingsBand returns the relative half-width of the savings range. Poor data
+// gives a wide band; bursty usage (peak far above average) widens it further,
+// since average-based savings may not survive the peaks.
+func savingsBand(dataQuality string, usage ActualUsageMetrics, hasUsageData bool) float64 {
+	var band float64
+	switch dataQuality {
+	case "EXCELLENT":
+		band = 0.10
+	case "GOOD":
+		band = 0.20
+	case "FAIR":
+		band = 0.35
+	default:
+		band = 0.60
+	}
+
+	if hasUsageData {
+		volatility := math.Max(
+			utilizationVolatility(usage.CPUUtilizationPercent, usage.CPUPeakPercent),
+			utilizationVolatility(usage.MemoryUtilizationPercent, usage.MemoryPeakPercent))
+		band += volatility * 0.3
+	}
+
+	return math.Min(band, 0.9)
+}
+
+// utilizationVolatility is how far peak utilization sits above the average, 0-1
+func utilizationVolatility(average, peak float64) float64 {
+	if peak <= 0 || peak <= average {
+		return 0
+	}
+	return (peak - average) / peak
+}
+
+// savingsRange widens a point estimate by band, capping the high end at the
+// unit's cost
+func savingsRange(savings, band, maxSavings float64) (float64, float64) {
+	return savings * (1 - band), math.Min(savings*(1+band), maxSavings)
+}
+
+// exclusionReason explains why a unit is protected, or returns "" if it isn't
+func (wa *WasteAnalyzer) exclusionReason(estimate UnitCostEstimate) string {
+	for _, key := range sortedKeys(wa.excludeLabels) {
+		if value, ok := estimate.Labels[key]; ok && value == wa.excludeLabels[key] {
+			return fmt.Sprintf("label %s=%s", key, value)
+		}
+	}
+	for _, pattern := range wa.excludeSlugPatterns {
+		if matched, _ := path.Match(pattern, estimate.UnitName); matched {
+			return fmt.Sprintf("slug matches %s", pattern)
+		}
+	}
+	return ""
+}
+
+// protectDetection drops aggressive recommendations from a protected unit and
+// keeps the remaining informational ones from being auto-applied
+func protectDetection(detection *WasteDetection, reason string) {
+	detection.Protected = true
+	detection.ProtectionReason = reason
+
+	kept := detection.Recommendations[:0]
+	for _, rec := range detection.Recommendations {
+		switch rec.Type {
+		case "scale-down", "scale-to-zero", "terminate":
+			continue
+		}
+		rec.AutoApplyable = false
+		kept = append(kept, rec)
+	}
+	detection.Recommendations = kept
+}
+
 // analyzeCPUWaste analyzes CPU resource waste
 func (wa *WasteAnalyzer) analyzeCPUWaste(estimate UnitCostEstimate, usage ActualUsageMetrics) ResourceWaste {
 	allocatedCores := float64(estimate.CPU.MilliValue()) / 1000.0
@@ -347,8 +626,14 @@ func (wa *WasteAnalyzer) analyzeCPUWaste(estimate UnitCostEstimate, usage Actual
 		wastePercent = ((allocatedCores - usedCores) / allocatedCores) * 100
 	}
 
-	// Calculate recommended allocation (110% of peak usage with minimum safety buffer)
-	recommendedCores := math.Max(usage.CPUPeakPercent/100.0*allocatedCores*1.1, 0.1)
+	// Calculate recommended allocation (110% of target percentile usage with minimum safety buffer)
+	targetPercent := targetUtilizationPercent(wa.thresholds.CPUTargetPercentile,
+		usage.CPUP50, usage.CPUP95, usage.CPUP99, usage.CPUPeakPercent)
+	recommendedCores := math.Max(targetPercent/100.0*allocatedCores*1.1, 0.1)
+	recommendation := fmt.Sprintf("%.1f cores", recommendedCores)
+	if usage.VPA != nil && usage.VPA.TargetCPUCores > 0 {
+		recommendation = fmt.Sprintf("%dm", int64(math.Ceil(usage.VPA.TargetCPUCores*1000)))
+	}
 
 	return ResourceWaste{
 		Allocated:          fmt.Sprintf("%.2f cores", allocatedCores),
@@ -356,7 +641,7 @@ func (wa *WasteAnalyzer) analyzeCPUWaste(estimate UnitCostEstimate, usage Actual
 		UtilizationPercent: utilizationPercent,
 		WastePercent:       wastePercent,
 		WastedCost:         estimate.Breakdown.CPUCost * (wastePercent / 100.0),
-		Recommendation:     fmt.Sprintf("%.1f cores", recommendedCores),
+		Recommendation:     recommendation,
 	}
 }
 
@@ -371,8 +656,14 @@ func (wa *WasteAnalyzer) analyzeMemoryWaste(estimate UnitCostEstimate, usage Act
 		wastePercent = (float64(allocatedBytes-usedBytes) / float64(allocatedBytes)) * 100
 	}
 
-	// Calculate recommended allocation (120% of peak usage with minimum safety buffer)
-	recommendedGB := math.Max(float64(allocatedBytes)*(usage.MemoryPeakPercent/100.0)*1.2/(1024*1024*1024), 0.128)
+	// Calculate recommended allocation (120% of target percentile usage with minimum safety buffer)
+	targetPercent := targetUtilizationPercent(wa.thresholds.MemoryTargetPercentile,
+		usage.MemoryP50, usage.MemoryP95, usage.MemoryP99, usage.MemoryPeakPercent)
+	recommendedGB := math.Max(float64(allocatedBytes)*(targetPercent/100.0)*1.2/(1024*1024*1024), 0.128)
+	recommendation := fmt.Sprintf("%.1fGi", recommendedGB)
+	if usage.VPA != nil && usage.VPA.TargetMemoryBytes > 0 {
+		recommendation = fmt.Sprintf("%dMi", int64(math.Ceil(float64(usage.VPA.TargetMemoryBytes)/(1024*1024))))
+	}
 
 	return ResourceWaste{
 		Allocated:          fmt.Sprintf("%.2fGi", float64(allocatedBytes)/(1024*1024*1024)),
@@ -380,10 +671,32 @@ func (wa *WasteAnalyzer) analyzeMemoryWaste(estimate UnitCostEstimate, usage Act
 		UtilizationPercent: utilizationPercent,
 		WastePercent:       wastePercent,
 		WastedCost:         estimate.Breakdown.MemoryCost * (wastePercent / 100.0),
-		Recommendation:     fmt.Sprintf("%.1fGi", recommendedGB),
+		Recommendation:     recommendation,
 	}
 }
 
+// targetUtilizationPercent picks the utilization to size against for the configured
+// percentile, rounding up to the next measured one (p50, p95, p99) and falling
+// back to peak when the percentile is unset or has no data
+func targetUtilizationPercent(percentile, p50, p95, p99, peak float64) float64 {
+	var value float64
+	switch {
+	case percentile <= 0 || percentile > 99:
+		return peak
+	case percentile <= 50:
+		value = p50
+	case percentile <= 95:
+		value = p95
+	default:
+		value = p99
+	}
+
+	if value <= 0 {
+		return peak
+	}
+	return value
+}
+
 // analyzeReplicaWaste analyzes replica count waste
 func (wa *WasteAnalyzer) analyzeReplicaWaste(estimate UnitCostEstimate, usage ActualUsageMetrics) ReplicaWaste {
 	configured := estimate.Replicas
@@ -413,14 +726,25 @@ func (wa *WasteAnalyzer) analyzeReplicaWaste(estimate UnitCostEstimate, usage Ac
 func (wa *WasteAnalyzer) categorizeWaste(detection *WasteDetection, usage ActualUsageMetrics) []WasteCategory {
 	var categories []WasteCategory
 
-	// Check for idle resources
-	if usage.CPUUtilizationPercent < wa.thresholds.CPUIdleThreshold &&
-		usage.MemoryUtilizationPercent < wa.thresholds.MemoryIdleThreshold {
+	// Check for idle resources across the workload's active window, not its 24h average
+	activeCPU, activeMemory := activeWindowUtilization(detection.Schedule, usage)
+	if activeCPU < wa.thresholds.CPUIdleThreshold &&
+		activeMemory < wa.thresholds.MemoryIdleThreshold {
 		categories = append(categories, WasteCategory{
 			Type:        "idle",
 			Severity:    "HIGH",
 			Impact:      detection.EstimatedMonthlyCost * 0.8,
-			Description: "Resource is largely idle with minimal CPU and memory usage",
+			Description: fmt.Sprintf("Resource is largely idle with minimal CPU and memory usage during its active window (%s)", detection.Schedule),
+		})
+	}
+
+	// Check for non-production workloads left running while unused
+	if wa.isScaleToZeroCandidate(detection, usage) {
+		categories = append(categories, WasteCategory{
+			Type:        "scale-to-zero-candidate",
+			Severity:    "MEDIUM",
+			Impact:      scaleToZeroSavings(detection, usage, wa.thresholds.CPUIdleThreshold),
+			Description: fmt.Sprintf("Running %.0f%% of the time with near-zero utilization; eligible to scale to zero", usage.UptimePercent),
 		})
 	}
 
@@ -467,12 +791,125 @@ func (wa *WasteAnalyzer) categorizeWaste(detection *WasteDetection, usage Actual
 	return categories
 }
 
+// categorizeEgress flags workloads whose measured egress costs a large share of
+// their compute. Egress is only known from real metrics, never from manifests.
+func (wa *WasteAnalyzer) categorizeEgress(estimate UnitCostEstimate, usage ActualUsageMetrics) *WasteCategory {
+	networkCost := wa.costAnalyzer.NetworkCost(usage)
+	computeCost := estimate.Breakdown.CPUCost + estimate.Breakdown.MemoryCost
+	if networkCost < wa.thresholds.MinMonthlyCostForAnalysis || computeCost <= 0 ||
+		networkCost <= computeCost*wa.thresholds.EgressToComputeRatio {
+		return nil
+	}
+
+	severity := "MEDIUM"
+	if networkCost > computeCost {
+		severity = "HIGH"
+	}
+	return &WasteCategory{
+		Type:        "high-egress",
+		Severity:    severity,
+		Impact:      networkCost,
+		Description: fmt.Sprintf("Measured egress costs $%.2f/month, %.0f%% of CPU and memory cost", networkCost, networkCost/computeCost*100),
+	}
+}
+
+// isScaleToZeroCandidate reports whether an eligible unit stayed up with
+// near-zero utilization for at least IdleDurationDays
+func (wa *WasteAnalyzer) isScaleToZeroCandidate(detection *WasteDetection, usage ActualUsageMetrics) bool {
+	if !hasAnyLabel(detection.Labels, wa.thresholds.ScaleToZeroLabels) {
+		return false
+	}
+	if usage.TimeRangeStart.IsZero() || usage.TimeRangeEnd.Sub(usage.TimeRangeStart) < time.Duration(wa.thresholds.IdleDurationDays)*24*time.Hour {
+		return false
+	}
+	return usage.UptimePercent >= 90 &&
+		usage.CPUUtilizationPercent < wa.thresholds.CPUIdleThreshold &&
+		usage.MemoryUtilizationPercent < wa.thresholds.MemoryIdleThreshold
+}
+
+// hasAnyLabel reports whether labels contain any of the key=value selectors
+func hasAnyLabel(labels map[string]string, selectors []string) bool {
+	for _, selector := range selectors {
+		key, value, _ := strings.Cut(selector, "=")
+		if v, ok := labels[key]; ok && v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// scaleToZeroSavings is the cost of the hours the unit runs without work:
+// idle hours from the histogram, or all running hours without one
+func scaleToZeroSavings(detection *WasteDetection, usage ActualUsageMetrics, cpuIdleThreshold float64) float64 {
+	idleFraction, ok := idleHourFraction(usage, cpuIdleThreshold)
+	if !ok {
+		idleFraction = usage.UptimePercent / 100
+	}
+	return detection.EstimatedMonthlyCost * idleFraction
+}
+
+// activeWindowUtilization returns CPU and memory utilization during the hours
+// the schedule expects the workload to be busy. Batch workloads use their
+// busiest hour, since they are idle between runs by design.
+func activeWindowUtilization(schedule WorkloadSchedule, usage ActualUsageMetrics) (float64, float64) {
+	switch schedule {
+	case ScheduleBatch:
+		if len(usage.HourlyUtilization) == 0 {
+			return usage.CPUPeakPercent, usage.MemoryPeakPercent
+		}
+		var cpu, memory float64
+		for _, hour := range usage.HourlyUtilization {
+			cpu = math.Max(cpu, hour.CPUPercent)
+			memory = math.Max(memory, hour.MemoryPercent)
+		}
+		return cpu, memory
+
+	case ScheduleBusinessHours:
+		var cpu, memory float64
+		var hours int
+		for _, hour := range usage.HourlyUtilization {
+			if hour.Weekday == time.Saturday || hour.Weekday == time.Sunday ||
+				hour.Hour < businessHoursStart || hour.Hour >= businessHoursEnd {
+				continue
+			}
+			cpu += hour.CPUPercent
+			memory += hour.MemoryPercent
+			hours++
+		}
+		if hours > 0 {
+			return cpu / float64(hours), memory / float64(hours)
+		}
+	}
+
+	return usage.CPUUtilizationPercent, usage.MemoryUtilizationPercent
+}
+
+// idleHourFraction returns the share of histogram hours below the CPU idle
+// threshold, or false without hourly data
+func idleHourFraction(usage ActualUsageMetrics, cpuIdleThreshold float64) (float64, bool) {
+	if len(usage.HourlyUtilization) == 0 {
+		return 0, false
+	}
+	var idle int
+	for _, hour := range usage.HourlyUtilization {
+		if hour.CPUPercent < cpuIdleThreshold {
+			idle++
+		}
+	}
+	return float64(idle) / float64(len(usage.HourlyUtilization)), true
+}
+
 // generateWasteRecommendations generates actionable recommendations
 func (wa *WasteAnalyzer) generateWasteRecommendations(detection *WasteDetection, estimate UnitCostEstimate, usage ActualUsageMetrics) []WasteRecommendation {
 	var recommendations []WasteRecommendation
 
+	// Batch workloads are sized for their runs; reclaim the time between runs instead
+	if detection.Schedule == ScheduleBatch {
+		return wa.generateBatchRecommendations(detection, usage)
+	}
+
 	// CPU rightsizing recommendation
-	if detection.CPUWaste.WastePercent > 30 {
+	if detection.CPUWaste.WastePercent > 30 && wa.thresholds.CPUResize.allows(detection.CPUWaste.WastedCost) {
 		recommendations = append(recommendations, WasteRecommendation{
 			Type:             "resize",
 			Priority:         wa.determinePriority(detection.CPUWaste.WastedCost),
@@ -486,7 +923,7 @@ func (wa *WasteAnalyzer) generateWasteRecommendations(detection *WasteDetection,
 	}
 
 	// Memory rightsizing recommendation
-	if detection.MemoryWaste.WastePercent > 30 {
+	if detection.MemoryWaste.WastePercent > 30 && wa.thresholds.MemoryResize.allows(detection.MemoryWaste.WastedCost) {
 		recommendations = append(recommendations, WasteRecommendation{
 			Type:             "resize",
 			Priority:         wa.determinePriority(detection.MemoryWaste.WastedCost),
@@ -500,7 +937,7 @@ func (wa *WasteAnalyzer) generateWasteRecommendations(detection *WasteDetection,
 	}
 
 	// Replica scaling recommendation
-	if detection.ReplicaWaste.IdleReplicas > 0.5 {
+	if detection.ReplicaWaste.IdleReplicas > 0.5 && wa.thresholds.ScaleDown.allows(detection.ReplicaWaste.WastedCost) {
 		recommendations = append(recommendations, WasteRecommendation{
 			Type:             "scale-down",
 			Priority:         wa.determinePriority(detection.ReplicaWaste.WastedCost),
@@ -513,8 +950,26 @@ func (wa *WasteAnalyzer) generateWasteRecommendations(detection *WasteDetection,
 		})
 	}
 
+	// Scale-to-zero recommendation for idle non-production workloads
+	if savings := scaleToZeroSavings(detection, usage, wa.thresholds.CPUIdleThreshold); wa.isScaleToZeroCandidate(detection, usage) && wa.thresholds.ScaleToZero.allows(savings) {
+		recommendations = append(recommendations, WasteRecommendation{
+			Type:     "scale-to-zero",
+			Priority: wa.determinePriority(savings),
+			Action:   fmt.Sprintf("Scale %s to zero while it is not in use", detection.UnitName),
+			Implementation: fmt.Sprintf("Add a KEDA ScaledObject targeting %s with minReplicaCount: 0 and a cron trigger "+
+				"(start: \"0 8 * * 1-5\", end: \"0 19 * * 1-5\", desiredReplicas: \"%d\"), "+
+				"or a CronJob that runs kubectl scale --replicas=0 in the evening and restores %d replicas in the morning",
+				detection.UnitName, detection.ReplicaWaste.ConfiguredReplicas, detection.ReplicaWaste.ConfiguredReplicas),
+			PotentialSavings: savings,
+			Risk:             "LOW",
+			RiskDescription:  "Only non-production units are eligible; the first request after scale-up waits for pods to start",
+			AutoApplyable:    false,
+		})
+	}
+
 	// Termination recommendation for completely idle resources
-	if usage.CPUUtilizationPercent < 1.0 && usage.MemoryUtilizationPercent < 5.0 && usage.UptimePercent < 50.0 {
+	activeCPU, activeMemory := activeWindowUtilization(detection.Schedule, usage)
+	if activeCPU < 1.0 && activeMemory < 5.0 && usage.UptimePercent < 50.0 && wa.thresholds.Terminate.allows(detection.EstimatedMonthlyCost) {
 		recommendations = append(recommendations, WasteRecommendation{
 			Type:             "terminate",
 			Priority:         "HIGH",
@@ -530,6 +985,33 @@ func (wa *WasteAnalyzer) generateWasteRecommendations(detection *WasteDetection,
 	return recommendations
 }
 
+// generateBatchRecommendations suggests running a batch workload only while it
+// has work, rather than resizing it against its mostly idle average
+func (wa *WasteAnalyzer) generateBatchRecommendations(detection *WasteDetection, usage ActualUsageMetrics) []WasteRecommendation {
+	idleFraction, ok := idleHourFraction(usage, wa.thresholds.CPUIdleThreshold)
+	if !ok {
+		idleFraction = 0.5 // Assume half the time is between runs without hourly data
+	}
+	if idleFraction < 0.25 {
+		return nil // Busy most of the day; nothing to reclaim between runs
+	}
+
+	savings := detection.EstimatedMonthlyCost * idleFraction
+	if !wa.thresholds.ScaleToZero.allows(savings) {
+		return nil
+	}
+	return []WasteRecommendation{{
+		Type:             "scale-to-zero",
+		Priority:         wa.determinePriority(savings),
+		Action:           fmt.Sprintf("Run as a CronJob or scale to zero between runs (idle %.0f%% of the time)", idleFraction*100),
+		Implementation:   "Convert the workload to a CronJob, or scale spec.replicas to 0 outside its run window (e.g., with KEDA)",
+		PotentialSavings: savings * 0.9,
+		Risk:             "MEDIUM",
+		RiskDescription:  "Jobs must tolerate cold starts and be scheduled to cover every run window",
+		AutoApplyable:    false,
+	}}
+}
+
 // analyzeWithoutUsageData provides heuristic waste analysis when no metrics are available
 func (wa *WasteAnalyzer) analyzeWithoutUsageData(estimate UnitCostEstimate) *WasteDetection {
 	detection := &WasteDetection{
@@ -544,6 +1026,8 @@ func (wa *WasteAnalyzer) analyzeWithoutUsageData(estimate UnitCostEstimate) *Was
 		Recommendations:      []WasteRecommendation{},
 		AnalyzedAt:           time.Now(),
 		DataQuality:          "POOR",
+		Schedule:             workloadSchedule(estimate.Labels),
+		Labels:               estimate.Labels,
 	}
 
 	// Apply heuristic rules based on resource allocation patterns
@@ -657,15 +1141,21 @@ func (wa *WasteAnalyzer) assessDataQuality(usage ActualUsageMetrics) string {
 	dataSpan := usage.TimeRangeEnd.Sub(usage.TimeRangeStart)
 
 	// Assess based on data freshness and span
+	quality := "POOR"
 	if dataAge < 24*time.Hour && dataSpan >= 7*24*time.Hour {
-		return "EXCELLENT"
+		quality = "EXCELLENT"
 	} else if dataAge < 3*24*time.Hour && dataSpan >= 3*24*time.Hour {
-		return "GOOD"
+		quality = "GOOD"
 	} else if dataAge < 7*24*time.Hour && dataSpan >= 24*time.Hour {
-		return "FAIR"
+		quality = "FAIR"
 	}
 
-	return "POOR"
+	// Costs extrapolated from a short allocation window are at best FAIR
+	if usage.CostWindow > 0 && usage.CostWindow < 3*24*time.Hour && (quality == "EXCELLENT" || quality == "GOOD") {
+		quality = "FAIR"
+	}
+
+	return quality
 }
 
 // determinePriority determines recommendation priority based on cost impact
@@ -824,7 +1314,11 @@ func (wa *WasteAnalyzer) GenerateWasteReport(analysis *SpaceWasteAnalysis) strin
 	report.WriteString(fmt.Sprintf("Space: %s\n", analysis.SpaceName))
 	report.WriteString(fmt.Sprintf("Analyzed At: %s\n", analysis.AnalyzedAt.Format("2006-01-02 15:04:05")))
 	report.WriteString(fmt.Sprintf("Units Analyzed: %d\n", analysis.UnitsAnalyzed))
-	report.WriteString(fmt.Sprintf("Units with Waste: %d\n\n", analysis.UnitsWithWaste))
+	report.WriteString(fmt.Sprintf("Units with Waste: %d\n", analysis.UnitsWithWaste))
+	if analysis.UnitsProtected > 0 {
+		report.WriteString(fmt.Sprintf("Units Protected: %d\n", analysis.UnitsProtected))
+	}
+	report.WriteString("\n")
 
 	// Cost summary
 	report.WriteString("Cost Summary:\n")
@@ -849,9 +1343,13 @@ func (wa *WasteAnalyzer) GenerateWasteReport(analysis *SpaceWasteAnalysis) strin
 		if i >= 5 {
 			break
 		}
-		report.WriteString(fmt.Sprintf("%-25s %8s  $%6.2f wasted  $%6.2f savings  [%s]\n",
+		protected := ""
+		if unit.Protected {
+			protected = fmt.Sprintf("  (protected: %s)", unit.ProtectionReason)
+		}
+		report.WriteString(fmt.Sprintf("%-25s %8s  $%6.2f wasted  $%6.2f savings ($%.2f-$%.2f)  [%s]%s\n",
 			unit.UnitName, unit.WasteSeverity, unit.WastedMonthlyCost,
-			unit.PotentialSavings, unit.Type))
+			unit.PotentialSavings, unit.PotentialSavingsLow, unit.PotentialSavingsHigh, unit.Type, protected))
 	}
 
 	// Top recommendations
@@ -861,8 +1359,8 @@ func (wa *WasteAnalyzer) GenerateWasteReport(analysis *SpaceWasteAnalysis) strin
 		if i >= 5 {
 			break
 		}
-		report.WriteString(fmt.Sprintf("• [%s] %s ($%.2f savings)\n",
-			rec.Priority, rec.Action, rec.PotentialSavings))
+		report.WriteString(fmt.Sprintf("• [%s] %s ($%.2f savings, range $%.2f-$%.2f)\n",
+			rec.Priority, rec.Action, rec.PotentialSavings, rec.PotentialSavingsLow, rec.PotentialSavingsHigh))
 		report.WriteString(fmt.Sprintf("  Risk: %s - %s\n\n", rec.Risk, rec.RiskDescription))
 	}
 
@@ -0,0 +1,492 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchHistogram is busy for the first runHours of every day and idle otherwise
+func batchHistogram(runHours int, busyPercent float64) []HourlyUsage {
+	var hours []HourlyUsage
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		for hour := 0; hour < 24; hour++ {
+			usage := HourlyUsage{Weekday: day, Hour: hour, CPUPercent: 0.5, MemoryPercent: 2}
+			if hour < runHours {
+				usage.CPUPercent, usage.MemoryPercent = busyPercent, busyPercent
+			}
+			hours = append(hours, usage)
+		}
+	}
+	return hours
+}
+
+func hasCategory(categories []WasteCategory, categoryType string) bool {
+	for _, category := range categories {
+		if category.Type == categoryType {
+			return true
+		}
+	}
+	return false
+}
+
+// Test schedule-aware idle detection
+func TestWorkloadScheduleIdleDetection(t *testing.T) {
+	wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+
+	// 4 busy hours a day average out below the idle thresholds
+	usage := ActualUsageMetrics{
+		CPUUtilizationPercent:    4,
+		MemoryUtilizationPercent: 8,
+		UptimePercent:            100,
+		HourlyUtilization:        batchHistogram(4, 60),
+	}
+
+	t.Run("ScheduleLabel", func(t *testing.T) {
+		assert.Equal(t, ScheduleAlwaysOn, workloadSchedule(nil))
+		assert.Equal(t, ScheduleBatch, workloadSchedule(map[string]string{WorkloadScheduleLabel: "batch"}))
+		assert.Equal(t, ScheduleAlwaysOn, workloadSchedule(map[string]string{WorkloadScheduleLabel: "nightly"}))
+	})
+
+	t.Run("AlwaysOnUsesAverage", func(t *testing.T) {
+		detection := &WasteDetection{Schedule: ScheduleAlwaysOn, EstimatedMonthlyCost: 100}
+		assert.True(t, hasCategory(wa.categorizeWaste(detection, usage), "idle"))
+	})
+
+	t.Run("BatchUsesActiveWindow", func(t *testing.T) {
+		detection := &WasteDetection{Schedule: ScheduleBatch, EstimatedMonthlyCost: 100}
+		assert.False(t, hasCategory(wa.categorizeWaste(detection, usage), "idle"))
+
+		idle := usage
+		idle.HourlyUtilization = batchHistogram(4, 2)
+		assert.True(t, hasCategory(wa.categorizeWaste(detection, idle), "idle"))
+	})
+
+	t.Run("BusinessHoursIgnoresNightsAndWeekends", func(t *testing.T) {
+		detection := &WasteDetection{Schedule: ScheduleBusinessHours, EstimatedMonthlyCost: 100}
+		busy := usage
+		busy.HourlyUtilization = nil
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			for hour := 0; hour < 24; hour++ {
+				cpu := 0.5
+				if day != time.Saturday && day != time.Sunday && hour >= 9 && hour < 18 {
+					cpu = 20
+				}
+				busy.HourlyUtilization = append(busy.HourlyUtilization, HourlyUsage{Weekday: day, Hour: hour, CPUPercent: cpu, MemoryPercent: cpu})
+			}
+		}
+
+		cpu, _ := activeWindowUtilization(ScheduleBusinessHours, busy)
+		assert.InDelta(t, 20, cpu, 0.001)
+		assert.False(t, hasCategory(wa.categorizeWaste(detection, busy), "idle"))
+	})
+
+	t.Run("BatchRecommendsScaleToZero", func(t *testing.T) {
+		detection := &WasteDetection{
+			Schedule:             ScheduleBatch,
+			EstimatedMonthlyCost: 100,
+			CPUWaste:             ResourceWaste{WastePercent: 90, WastedCost: 50},
+			ReplicaWaste:         ReplicaWaste{IdleReplicas: 2},
+		}
+
+		recommendations := wa.generateWasteRecommendations(detection, UnitCostEstimate{}, usage)
+		require.Len(t, recommendations, 1)
+		assert.Equal(t, "scale-to-zero", recommendations[0].Type)
+		assert.Contains(t, recommendations[0].Action, "CronJob")
+		assert.InDelta(t, 100*20.0/24*0.9, recommendations[0].PotentialSavings, 0.001)
+	})
+}
+
+// Test scale-to-zero detection for idle non-production units
+func TestScaleToZeroCandidates(t *testing.T) {
+	wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+
+	end := time.Now()
+	usage := ActualUsageMetrics{
+		TimeRangeStart:           end.Add(-10 * 24 * time.Hour),
+		TimeRangeEnd:             end,
+		CPUUtilizationPercent:    1,
+		MemoryUtilizationPercent: 6,
+		UptimePercent:            100,
+	}
+	newDetection := func(labels map[string]string) *WasteDetection {
+		return &WasteDetection{
+			UnitName:             "preview-api",
+			Schedule:             ScheduleAlwaysOn,
+			Labels:               labels,
+			EstimatedMonthlyCost: 120,
+			ReplicaWaste:         ReplicaWaste{ConfiguredReplicas: 2},
+		}
+	}
+
+	t.Run("DevUnit", func(t *testing.T) {
+		detection := newDetection(map[string]string{"env": "dev"})
+		assert.True(t, hasCategory(wa.categorizeWaste(detection, usage), "scale-to-zero-candidate"))
+
+		var found *WasteRecommendation
+		recommendations := wa.generateWasteRecommendations(detection, UnitCostEstimate{}, usage)
+		for i := range recommendations {
+			if recommendations[i].Type == "scale-to-zero" {
+				found = &recommendations[i]
+			}
+		}
+		require.NotNil(t, found)
+		assert.Contains(t, found.Implementation, "ScaledObject")
+		assert.Contains(t, found.Implementation, `desiredReplicas: "2"`)
+		assert.InDelta(t, 120, found.PotentialSavings, 0.001, "no histogram: every running hour is idle")
+
+		withHistogram := usage
+		withHistogram.HourlyUtilization = batchHistogram(6, 30)
+		assert.InDelta(t, 120*18.0/24, scaleToZeroSavings(detection, withHistogram, wa.thresholds.CPUIdleThreshold), 0.001)
+	})
+
+	t.Run("ProdUnitNotEligible", func(t *testing.T) {
+		detection := newDetection(map[string]string{"env": "prod"})
+		assert.False(t, hasCategory(wa.categorizeWaste(detection, usage), "scale-to-zero-candidate"))
+	})
+
+	t.Run("ShortWindowNotEligible", func(t *testing.T) {
+		short := usage
+		short.TimeRangeStart = end.Add(-24 * time.Hour)
+		assert.False(t, wa.isScaleToZeroCandidate(newDetection(map[string]string{"env": "dev"}), short))
+	})
+
+	t.Run("ConfigurableLabels", func(t *testing.T) {
+		thresholds := *DefaultWasteThresholds
+		thresholds.ScaleToZeroLabels = []string{"lifecycle=ephemeral"}
+		custom := NewWasteAnalyzer(wa.app, uuid.New())
+		custom.SetThresholds(&thresholds)
+
+		assert.False(t, custom.isScaleToZeroCandidate(newDetection(map[string]string{"env": "dev"}), usage))
+		assert.True(t, custom.isScaleToZeroCandidate(newDetection(map[string]string{"lifecycle": "ephemeral"}), usage))
+	})
+}
+
+// Test protecting critical units from aggressive recommendations
+func TestWasteAnalyzerExclusions(t *testing.T) {
+	wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	wa.SetExclusions(map[string]string{"tier": "critical"}, []string{"postgres-*"})
+
+	estimate := func(name string, labels map[string]string) UnitCostEstimate {
+		return UnitCostEstimate{
+			UnitID:      uuid.NewString(),
+			UnitName:    name,
+			Replicas:    4,
+			CPU:         ParseQuantity("4"),
+			Memory:      ParseQuantity("8Gi"),
+			MonthlyCost: 400,
+			Breakdown:   CostBreakdown{CPUCost: 250, MemoryCost: 150},
+			Labels:      labels,
+		}
+	}
+	// Over-provisioned, over-replicated and barely running
+	usage := ActualUsageMetrics{
+		CPUUtilizationPercent:    0.5,
+		MemoryUtilizationPercent: 2,
+		CPUCoresUsed:             0.02,
+		MemoryBytesUsed:          128 * 1024 * 1024,
+		AverageReplicas:          1,
+		UptimePercent:            30,
+		ActualMonthlyCost:        40,
+	}
+	recommendationTypes := func(detection *WasteDetection) []string {
+		var types []string
+		for _, rec := range detection.Recommendations {
+			types = append(types, rec.Type)
+		}
+		return types
+	}
+
+	t.Run("UnprotectedUnit", func(t *testing.T) {
+		detection := wa.analyzeUnitWaste(estimate("api", nil), usage, true)
+		assert.False(t, detection.Protected)
+		assert.Contains(t, recommendationTypes(detection), "scale-down")
+		assert.Contains(t, recommendationTypes(detection), "terminate")
+	})
+
+	for name, est := range map[string]UnitCostEstimate{
+		"ByLabel": estimate("orders-db", map[string]string{"tier": "critical"}),
+		"BySlug":  estimate("postgres-main", nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			detection := wa.analyzeUnitWaste(est, usage, true)
+			require.True(t, detection.Protected)
+			assert.NotEmpty(t, detection.ProtectionReason)
+			assert.Equal(t, []string{"resize", "resize"}, recommendationTypes(detection))
+			for _, rec := range detection.Recommendations {
+				assert.False(t, rec.AutoApplyable)
+			}
+		})
+	}
+
+	t.Run("RecommendationRules", func(t *testing.T) {
+		thresholds := *DefaultWasteThresholds
+		thresholds.Terminate = RecommendationRule{Disabled: true}
+		thresholds.MemoryResize = RecommendationRule{MinMonthlySavings: 1e6}
+		strict := NewWasteAnalyzer(wa.app, wa.spaceID)
+		strict.SetThresholds(&thresholds)
+
+		detection := strict.analyzeUnitWaste(estimate("api", nil), usage, true)
+		assert.Equal(t, []string{"resize", "scale-down"}, recommendationTypes(detection))
+		assert.Contains(t, detection.Recommendations[0].Action, "CPU")
+
+		replicaWaste := detection.ReplicaWaste.WastedCost
+		require.Greater(t, replicaWaste, 0.0)
+		thresholds.ScaleDown.MinMonthlySavings = replicaWaste
+		detection = strict.analyzeUnitWaste(estimate("api", nil), usage, true)
+		assert.NotContains(t, recommendationTypes(detection), "scale-down", "waste must exceed the floor")
+
+		thresholds.ScaleDown.MinMonthlySavings = replicaWaste / 2
+		detection = strict.analyzeUnitWaste(estimate("api", nil), usage, true)
+		assert.Contains(t, recommendationTypes(detection), "scale-down")
+	})
+
+	t.Run("ReportMarksProtected", func(t *testing.T) {
+		detection := wa.analyzeUnitWaste(estimate("orders-db", map[string]string{"tier": "critical"}), usage, true)
+		analysis := &SpaceWasteAnalysis{UnitsProtected: 1, TopWasteUnits: []WasteDetection{*detection}}
+		report := wa.GenerateWasteReport(analysis)
+		assert.Contains(t, report, "Units Protected: 1")
+		assert.Contains(t, report, "(protected: label tier=critical)")
+	})
+}
+
+// Test savings ranges widen with poor data and bursty usage
+func TestWasteSavingsRange(t *testing.T) {
+	steady := ActualUsageMetrics{CPUUtilizationPercent: 20, CPUPeakPercent: 20, MemoryUtilizationPercent: 30, MemoryPeakPercent: 30}
+	bursty := ActualUsageMetrics{CPUUtilizationPercent: 20, CPUPeakPercent: 80, MemoryUtilizationPercent: 30, MemoryPeakPercent: 30}
+
+	assert.InDelta(t, 0.10, savingsBand("EXCELLENT", steady, true), 0.001)
+	assert.InDelta(t, 0.60, savingsBand("POOR", ActualUsageMetrics{}, false), 0.001)
+	assert.InDelta(t, 0.10+0.75*0.3, savingsBand("EXCELLENT", bursty, true), 0.001)
+	assert.Greater(t, savingsBand("POOR", steady, true), savingsBand("GOOD", steady, true))
+
+	low, high := savingsRange(100, 0.6, 130)
+	assert.InDelta(t, 40, low, 0.001)
+	assert.InDelta(t, 130, high, 0.001, "high end is capped at the unit's cost")
+
+	wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	end := time.Now()
+	detection := wa.analyzeUnitWaste(UnitCostEstimate{
+		UnitName:    "api",
+		Replicas:    2,
+		CPU:         ParseQuantity("2"),
+		Memory:      ParseQuantity("4Gi"),
+		MonthlyCost: 200,
+		Breakdown:   CostBreakdown{CPUCost: 120, MemoryCost: 80},
+	}, ActualUsageMetrics{
+		TimeRangeStart:           end.Add(-14 * 24 * time.Hour),
+		TimeRangeEnd:             end,
+		CPUUtilizationPercent:    10,
+		CPUPeakPercent:           10,
+		CPUCoresUsed:             0.2,
+		MemoryUtilizationPercent: 20,
+		MemoryPeakPercent:        20,
+		MemoryBytesUsed:          800 * 1024 * 1024,
+		AverageReplicas:          2,
+		UptimePercent:            100,
+		ActualMonthlyCost:        60,
+	}, true)
+
+	require.Equal(t, "EXCELLENT", detection.DataQuality)
+	require.NotEmpty(t, detection.Recommendations)
+	assert.InDelta(t, detection.PotentialSavings*0.9, detection.PotentialSavingsLow, 0.001)
+	assert.InDelta(t, detection.PotentialSavings*1.1, detection.PotentialSavingsHigh, 0.001)
+	rec := detection.Recommendations[0]
+	assert.InDelta(t, rec.PotentialSavings*0.9, rec.PotentialSavingsLow, 0.001)
+
+	report := wa.GenerateWasteReport(&SpaceWasteAnalysis{
+		TopWasteUnits:      []WasteDetection{*detection},
+		TopRecommendations: detection.Recommendations,
+	})
+	assert.Contains(t, report, fmt.Sprintf("range $%.2f-$%.2f", rec.PotentialSavingsLow, rec.PotentialSavingsHigh))
+}
+
+// Test egress cost from measured traffic
+func TestEgressCost(t *testing.T) {
+	wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+	end := time.Now()
+	// 30GiB over 73 hours is 300GiB in a 730-hour month
+	usage := ActualUsageMetrics{
+		TimeRangeStart:    end.Add(-73 * time.Hour),
+		TimeRangeEnd:      end,
+		NetworkBytesTotal: 30 * 1024 * 1024 * 1024,
+	}
+
+	t.Run("NetworkCost", func(t *testing.T) {
+		assert.InDelta(t, 300*0.09, wa.costAnalyzer.NetworkCost(usage), 0.001)
+		assert.Zero(t, wa.costAnalyzer.NetworkCost(ActualUsageMetrics{NetworkBytesTotal: 1 << 30}), "no time range to normalize from")
+
+		pricing := *DefaultPricing
+		pricing.HoursPerMonth = 720
+		thirtyDays := NewCostAnalyzer(wa.app, uuid.New())
+		thirtyDays.SetPricing(&pricing)
+		assert.InDelta(t, 30*720.0/73*0.09, thirtyDays.NetworkCost(usage), 0.001, "normalized to HoursPerMonth")
+
+		estimate := UnitCostEstimate{MonthlyCost: 50, Breakdown: CostBreakdown{CPUCost: 30, MemoryCost: 20}}
+		wa.costAnalyzer.ApplyNetworkCost(&estimate, usage)
+		assert.InDelta(t, 27, estimate.Breakdown.NetworkCost, 0.001)
+		assert.InDelta(t, 77, estimate.MonthlyCost, 0.001)
+	})
+
+	t.Run("HighEgressCategory", func(t *testing.T) {
+		cheap := UnitCostEstimate{Breakdown: CostBreakdown{CPUCost: 15, MemoryCost: 5}}
+		category := wa.categorizeEgress(cheap, usage)
+		require.NotNil(t, category)
+		assert.Equal(t, "high-egress", category.Type)
+		assert.Equal(t, "HIGH", category.Severity)
+		assert.InDelta(t, 27, category.Impact, 0.001)
+
+		expensive := UnitCostEstimate{Breakdown: CostBreakdown{CPUCost: 300, MemoryCost: 100}}
+		assert.Nil(t, wa.categorizeEgress(expensive, usage))
+		assert.Nil(t, wa.categorizeEgress(cheap, ActualUsageMetrics{}), "no metrics, no egress")
+	})
+}
+
+// usageSourceFunc adapts a function to UsageSource
+type usageSourceFunc func(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error)
+
+func (f usageSourceFunc) Usage(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+	return f(ctx, estimate)
+}
+
+// Test pulling usage lazily from a pluggable source
+func TestAnalyzeWasteFromSource(t *testing.T) {
+	deployment := func(name string) string {
+		return fmt.Sprintf("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: %s\nspec:\n  replicas: 2\n  template:\n    spec:\n      containers:\n      - name: app\n        resources:\n          requests:\n            cpu: \"1\"\n            memory: 1Gi\n", name)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "web", Data: deployment("web")}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "api", Data: deployment("api")}},
+			{"Unit": Unit{UnitID: uuid.New(), Slug: "worker", Data: deployment("worker")}},
+		})
+	}))
+	defer server.Close()
+	app := &DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}
+
+	t.Run("CustomSource", func(t *testing.T) {
+		var asked []string
+		source := usageSourceFunc(func(ctx context.Context, estimate UnitCostEstimate) (ActualUsageMetrics, bool, error) {
+			asked = append(asked, estimate.UnitName)
+			switch estimate.UnitName {
+			case "web":
+				return ActualUsageMetrics{
+					UnitID:                   estimate.UnitID,
+					CPUUtilizationPercent:    10,
+					MemoryUtilizationPercent: 20,
+					AverageReplicas:          2,
+					UptimePercent:            100,
+				}, true, nil
+			case "api":
+				return ActualUsageMetrics{}, false, fmt.Errorf("backend unavailable")
+			}
+			return ActualUsageMetrics{}, false, nil
+		})
+
+		analysis, err := NewWasteAnalyzer(app, uuid.New()).AnalyzeWasteFromSource(context.Background(), source)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"web", "api", "worker"}, asked)
+		require.Len(t, analysis.UnitWasteDetections, 3)
+		assert.Greater(t, analysis.UnitWasteDetections[0].WastedMonthlyCost, 0.0, "web is 10% utilized")
+		assert.Zero(t, analysis.UnitWasteDetections[1].WastedMonthlyCost, "source errors fall back to no usage data")
+		assert.Zero(t, analysis.UnitWasteDetections[2].WastedMonthlyCost)
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := NewWasteAnalyzer(app, uuid.New()).AnalyzeWasteFromSource(ctx, usageByUnitID{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("PrometheusSource", func(t *testing.T) {
+		value := "0.5"
+		prometheus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/query", r.URL.Path)
+			if value == "" {
+				fmt.Fprint(w, `{"status":"success","data":{"result":[]}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"status":"success","data":{"result":[{"value":[0,%q]}]}}`, value)
+		}))
+		defer prometheus.Close()
+
+		source := NewPrometheusUsageSource(prometheus.URL, 24*time.Hour)
+		estimate := UnitCostEstimate{UnitID: "web-id", UnitName: "web", Replicas: 2, CPU: ParseQuantity("1")}
+
+		usage, ok, err := source.Usage(context.Background(), estimate)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "web-id", usage.UnitID)
+		assert.InDelta(t, 50, usage.CPUUtilizationPercent, 0.001)
+
+		value = ""
+		_, ok, err = source.Usage(context.Background(), estimate)
+		require.NoError(t, err)
+		assert.False(t, ok, "no samples means no data")
+	})
+}
+
+// Test sizing against the configured utilization percentile
+func TestTargetUtilizationPercent(t *testing.T) {
+	const p50, p95, p99, peak = 20.0, 45.0, 60.0, 90.0
+	for _, tc := range []struct {
+		percentile float64
+		want       float64
+	}{
+		{50, p50},
+		{30, p50},
+		{95, p95},
+		{75, p95}, // Rounds up to the next measured percentile
+		{90, p95},
+		{99, p99},
+		{96, p99},
+		{0, peak},
+		{-5, peak},
+		{99.5, peak},
+		{100, peak},
+	} {
+		assert.Equal(t, tc.want, targetUtilizationPercent(tc.percentile, p50, p95, p99, peak), "percentile %v", tc.percentile)
+	}
+
+	// Percentiles without data fall back to peak
+	assert.Equal(t, peak, targetUtilizationPercent(50, 0, p95, p99, peak))
+	assert.Equal(t, peak, targetUtilizationPercent(95, p50, 0, p99, peak))
+	assert.Equal(t, peak, targetUtilizationPercent(99, p50, p95, 0, peak))
+
+	t.Run("Recommendations", func(t *testing.T) {
+		estimate := UnitCostEstimate{UnitName: "api", Replicas: 1, CPU: ParseQuantity("2"), Memory: ParseQuantity("10Gi")}
+		usage := ActualUsageMetrics{
+			CPUPeakPercent:    80,
+			CPUP50:            10,
+			CPUP95:            50,
+			MemoryPeakPercent: 80,
+			MemoryP99:         40,
+		}
+		wa := NewWasteAnalyzer(&DevOpsApp{Logger: log.New(io.Discard, "", 0)}, uuid.New())
+
+		// Defaults: CPU at p95, memory at p99
+		assert.Equal(t, "1.1 cores", wa.analyzeCPUWaste(estimate, usage).Recommendation)
+		assert.Equal(t, "4.8Gi", wa.analyzeMemoryWaste(estimate, usage).Recommendation)
+
+		thresholds := *DefaultWasteThresholds
+		thresholds.CPUTargetPercentile = 50
+		thresholds.MemoryTargetPercentile = 0
+		wa.SetThresholds(&thresholds)
+		assert.Equal(t, "0.2 cores", wa.analyzeCPUWaste(estimate, usage).Recommendation)
+		assert.Equal(t, "9.6Gi", wa.analyzeMemoryWaste(estimate, usage).Recommendation, "sized to peak")
+
+		usage.CPUP50 = 0
+		assert.Equal(t, "1.8 cores", wa.analyzeCPUWaste(estimate, usage).Recommendation, "no p50 data falls back to peak")
+	})
+}
@@ -0,0 +1,175 @@
+// where.go - WHERE clause builder for the DevOps SDK
+//
+// ConfigHub selects units, spaces and function targets with SQL-like WHERE
+// clauses. Building them with fmt.Sprintf breaks on values containing a single
+// quote and lets such values rewrite the clause. WhereBuilder quotes every
+// value and rejects malformed field names.
+//
+// Example:
+//
+//	where, err := NewWhere().Eq("Slug", slug).And().Eq("Labels.app", app).Build()
+//	// Slug = 'it''s-a-test' AND Labels.app = 'web'
+package sdk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// whereFieldPattern matches field paths such as Slug, Labels.app or
+// Labels.app.kubernetes.io/name: an identifier followed by dotted segments
+var whereFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z0-9_][A-Za-z0-9_./-]*)*$`)
+
+// WhereBuilder composes a WHERE clause from conditions joined by AND and OR.
+// Conditions without an explicit And or Or between them are ANDed. The first
+// error is kept and returned by Build.
+type WhereBuilder struct {
+	clause   strings.Builder
+	operator string // Pending AND/OR before the next condition
+	empty    bool   // No condition added yet
+	err      error
+}
+
+// NewWhere starts an empty WHERE clause
+func NewWhere() *WhereBuilder {
+	return &WhereBuilder{empty: true}
+}
+
+// Eq adds field = value
+func (w *WhereBuilder) Eq(field string, value interface{}) *WhereBuilder {
+	return w.compare(field, "=", value)
+}
+
+// Ne adds field != value
+func (w *WhereBuilder) Ne(field string, value interface{}) *WhereBuilder {
+	return w.compare(field, "!=", value)
+}
+
+// In adds field IN (values...). At least one value is required.
+func (w *WhereBuilder) In(field string, values ...interface{}) *WhereBuilder {
+	if len(values) == 0 {
+		return w.fail(fmt.Errorf("where: IN on %s needs at least one value", field))
+	}
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literal, err := whereLiteral(value)
+		if err != nil {
+			return w.fail(err)
+		}
+		literals[i] = literal
+	}
+	return w.condition(field, fmt.Sprintf("%s IN (%s)", field, strings.Join(literals, ", ")))
+}
+
+// Group adds the conditions of inner in parentheses, e.g. to OR two
+// conditions inside an AND
+func (w *WhereBuilder) Group(inner *WhereBuilder) *WhereBuilder {
+	clause, err := inner.Build()
+	if err != nil {
+		return w.fail(err)
+	}
+	if clause == "" {
+		return w.fail(fmt.Errorf("where: empty group"))
+	}
+	return w.append("(" + clause + ")")
+}
+
+// And joins the previous and next conditions with AND
+func (w *WhereBuilder) And() *WhereBuilder {
+	return w.join("AND")
+}
+
+// Or joins the previous and next conditions with OR
+func (w *WhereBuilder) Or() *WhereBuilder {
+	return w.join("OR")
+}
+
+// Build returns the clause, or the first error: a malformed field name, an
+// unsupported value type, or a dangling And/Or
+func (w *WhereBuilder) Build() (string, error) {
+	if w.err != nil {
+		return "", w.err
+	}
+	if w.operator != "" {
+		return "", fmt.Errorf("where: %s without a following condition", w.operator)
+	}
+	return w.clause.String(), nil
+}
+
+// MustBuild is like Build but panics on error. Use it only with field names
+// fixed in code; values are always safe.
+func (w *WhereBuilder) MustBuild() string {
+	clause, err := w.Build()
+	if err != nil {
+		panic(err)
+	}
+	return clause
+}
+
+func (w *WhereBuilder) compare(field, op string, value interface{}) *WhereBuilder {
+	literal, err := whereLiteral(value)
+	if err != nil {
+		return w.fail(err)
+	}
+	return w.condition(field, fmt.Sprintf("%s %s %s", field, op, literal))
+}
+
+func (w *WhereBuilder) condition(field, condition string) *WhereBuilder {
+	if !whereFieldPattern.MatchString(field) {
+		return w.fail(fmt.Errorf("where: invalid field name %q", field))
+	}
+	return w.append(condition)
+}
+
+func (w *WhereBuilder) append(condition string) *WhereBuilder {
+	if w.err != nil {
+		return w
+	}
+	if !w.empty {
+		operator := w.operator
+		if operator == "" {
+			operator = "AND"
+		}
+		w.clause.WriteString(" " + operator + " ")
+	}
+	w.clause.WriteString(condition)
+	w.operator = ""
+	w.empty = false
+	return w
+}
+
+func (w *WhereBuilder) join(operator string) *WhereBuilder {
+	switch {
+	case w.empty:
+		return w.fail(fmt.Errorf("where: %s before any condition", operator))
+	case w.operator != "":
+		return w.fail(fmt.Errorf("where: %s after %s", operator, w.operator))
+	}
+	w.operator = operator
+	return w
+}
+
+func (w *WhereBuilder) fail(err error) *WhereBuilder {
+	if w.err == nil {
+		w.err = err
+	}
+	return w
+}
+
+// whereLiteral formats a value for a WHERE clause. Strings and UUIDs are
+// single-quoted with embedded quotes doubled; numbers and booleans are bare.
+func whereLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case uuid.UUID:
+		return "'" + v.String() + "'", nil
+	case int, int32, int64, uint, uint32, uint64, float64, bool:
+		return fmt.Sprintf("%v", v), nil
+	default:
+		return "", fmt.Errorf("where: unsupported value type %T", value)
+	}
+}
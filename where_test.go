@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test building WHERE clauses with quoted values and checked field names
+func TestWhereBuilder(t *testing.T) {
+	t.Run("EscapesQuotes", func(t *testing.T) {
+		where, err := NewWhere().Eq("Slug", "it's-a-test").And().Eq("Labels.app", "web").Build()
+		require.NoError(t, err)
+		assert.Equal(t, "Slug = 'it''s-a-test' AND Labels.app = 'web'", where)
+
+		where, err = NewWhere().Eq("Slug", "x' OR '1'='1").Build()
+		require.NoError(t, err)
+		assert.Equal(t, "Slug = 'x'' OR ''1''=''1'", where)
+	})
+
+	t.Run("Compose", func(t *testing.T) {
+		id1, id2 := uuid.New(), uuid.New()
+		where, err := NewWhere().
+			Eq("UpstreamSpaceID", id1).
+			In("UnitID", id1, id2).
+			Group(NewWhere().Eq("Labels.tier", "critical").Or().Ne("Labels.app.kubernetes.io/name", "it's")).
+			And().Eq("Version", 3).
+			Build()
+		require.NoError(t, err)
+		assert.Equal(t, "UpstreamSpaceID = '"+id1.String()+"' AND UnitID IN ('"+id1.String()+"', '"+id2.String()+"')"+
+			" AND (Labels.tier = 'critical' OR Labels.app.kubernetes.io/name != 'it''s') AND Version = 3", where)
+
+		where, err = NewWhere().Build()
+		require.NoError(t, err)
+		assert.Empty(t, where)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for name, builder := range map[string]*WhereBuilder{
+			"FieldWithSpace":   NewWhere().Eq("Slug = 'a' OR Slug", "b"),
+			"FieldWithQuote":   NewWhere().Eq("Labels.it's", "b"),
+			"EmptyField":       NewWhere().Eq("", "b"),
+			"LeadingDot":       NewWhere().Eq(".Slug", "b"),
+			"LeadingOperator":  NewWhere().And().Eq("Slug", "a"),
+			"DoubleOperator":   NewWhere().Eq("Slug", "a").And().Or().Eq("Slug", "b"),
+			"TrailingOperator": NewWhere().Eq("Slug", "a").Or(),
+			"EmptyIn":          NewWhere().In("UnitID"),
+			"UnsupportedValue": NewWhere().Eq("Slug", []string{"a"}),
+			"EmptyGroup":       NewWhere().Group(NewWhere()),
+		} {
+			_, err := builder.Build()
+			assert.Error(t, err, name)
+		}
+
+		assert.Panics(t, func() { NewWhere().Eq("bad field", "a").MustBuild() })
+	})
+
+	t.Run("ListUnitsCallSite", func(t *testing.T) {
+		var where string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			where = r.URL.Query().Get("where")
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+		}))
+		defer server.Close()
+
+		engine := NewOptimizationEngine(&DevOpsApp{Cub: NewConfigHubClient(server.URL, "test-token"), Logger: log.New(io.Discard, "", 0)}, uuid.New())
+		_, err := engine.findUnitBySlug("it's-a-test")
+		assert.ErrorContains(t, err, "not found")
+		assert.Equal(t, "Slug = 'it''s-a-test'", where)
+	})
+}